@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/email"
+)
+
+var emailCmd = &cobra.Command{
+	Use:   "email",
+	Short: "Manage the outbound email queue",
+}
+
+var emailFailedCmd = &cobra.Command{
+	Use:   "failed",
+	Short: "List emails the queue gave up sending",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := initSystemDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		msgs, err := email.Failed(db.GetDB())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(msgs) == 0 {
+			fmt.Println("No failed emails")
+			return
+		}
+		for _, msg := range msgs {
+			fmt.Printf("%d\t%s\t%s\t%s\n", msg.ID, msg.CreatedAt.Format("2006-01-02 15:04"), msg.To, msg.Subject)
+			fmt.Printf("\t%d attempt(s), last error: %s\n", msg.Attempts, msg.LastError)
+		}
+		fmt.Printf("\n%d failed email(s). Resend with: stinky email retry <id>... or --all\n", len(msgs))
+	},
+}
+
+var emailRetryCmd = &cobra.Command{
+	Use:   "retry [id...]",
+	Short: "Queue failed emails to be sent again",
+	Long: `Queue emails the queue gave up on to be sent again by the server, for
+example after fixing the SMTP settings. Give their IDs from "email failed",
+or --all.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		if all == (len(args) > 0) {
+			fmt.Fprintln(os.Stderr, "Error: give email IDs or --all")
+			os.Exit(1)
+		}
+		var ids []uint
+		for _, arg := range args {
+			id, err := strconv.ParseUint(arg, 10, 32)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid email ID %q\n", arg)
+				os.Exit(1)
+			}
+			ids = append(ids, uint(id))
+		}
+
+		if err := initSystemDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		count, err := email.Retry(db.GetDB(), ids...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Queued %d email(s) to be sent again\n", count)
+	},
+}
+
+func init() {
+	emailRetryCmd.Flags().Bool("all", false, "Resend every failed email")
+
+	emailCmd.AddCommand(emailFailedCmd)
+	emailCmd.AddCommand(emailRetryCmd)
+	rootCmd.AddCommand(emailCmd)
+}
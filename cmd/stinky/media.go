@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: MIT
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/sites"
+)
+
+var mediaCmd = &cobra.Command{
+	Use:   "media",
+	Short: "Manage uploaded media",
+	Long:  "Commands for managing uploaded media and its caches",
+}
+
+var mediaCacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the transformed image cache",
+}
+
+var mediaCachePurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete cached transformed images",
+	Long: `Delete resized and cropped images cached by /assets/<file>?w=...&h=...
+
+They're drawn again the next time they're requested. Use --older-than to
+keep recent ones, e.g. --older-than 720h.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := initConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		olderThan, _ := cmd.Flags().GetDuration("older-than")
+		var cutoff time.Time
+		if olderThan > 0 {
+			cutoff = time.Now().Add(-olderThan)
+		}
+
+		files, size, err := media.PurgeTransformCache(cutoff)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Removed %d cached image(s), %.1f MB\n", files, float64(size)/(1024*1024))
+	},
+}
+
+var mediaScrubCmd = &cobra.Command{
+	Use:   "scrub",
+	Short: "Strip EXIF/XMP metadata from existing images",
+	Long: `Strip EXIF, XMP and IPTC metadata (including GPS positions) from images
+already in the media library, turning sideways photos upright first.
+
+New uploads are scrubbed automatically unless media.keep_metadata is set.
+Capture dates are kept on the media items. Use --dry-run to see what would
+change.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := initSystemDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		subdomain, _ := cmd.Flags().GetString("site")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		query := db.GetDB().Order("id")
+		if subdomain != "" {
+			site, err := sites.GetSiteBySubdomain(db.GetDB(), subdomain)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			query = query.Where("site_id = ?", site.ID)
+		}
+		var items []models.MediaItem
+		if err := query.Find(&items).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		verb := "Scrubbed"
+		if dryRun {
+			verb = "Would scrub"
+		}
+		scrubbed, rotated, failed := 0, 0, 0
+		for i := range items {
+			item := &items[i]
+			res, err := media.ScrubItem(db.GetDB(), item, dryRun)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s (%s): %v\n", item.Filename, item.OriginalName, err)
+				failed++
+				continue
+			}
+			if !res.Changed {
+				continue
+			}
+			scrubbed++
+			note := ""
+			if res.Rotated {
+				rotated++
+				note = ", turned upright"
+			}
+			fmt.Printf("%s %s (%s)%s\n", verb, item.Filename, item.OriginalName, note)
+		}
+
+		// Cached resized copies of turned images were drawn sideways
+		if rotated > 0 && !dryRun {
+			if _, _, err := media.PurgeTransformCache(time.Time{}); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to clear the image cache: %v\n", err)
+			}
+		}
+
+		fmt.Printf("%s %d of %d image(s)", verb, scrubbed, len(items))
+		if failed > 0 {
+			fmt.Printf(", %d failed", failed)
+		}
+		fmt.Println()
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+var mediaReindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the record of which blocks use which media",
+	Long: `Re-read every block and record the images and documents it uses.
+
+The record is kept up to date as blocks are saved and is built on the first
+start after upgrading; run this if blocks were changed outside the admin.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := initSystemDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		subdomain, _ := cmd.Flags().GetString("site")
+		var siteList []models.Site
+		if subdomain != "" {
+			site, err := sites.GetSiteBySubdomain(db.GetDB(), subdomain)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			siteList = append(siteList, *site)
+		} else if err := db.GetDB().Order("id").Find(&siteList).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, site := range siteList {
+			count, err := media.RebuildReferences(db.GetDB(), site.ID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s: %v\n", site.Subdomain, err)
+				os.Exit(1)
+			}
+			fmt.Printf("Indexed %d block(s) on %s\n", count, site.Subdomain)
+		}
+	},
+}
+
+var mediaDedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Merge media items with the same content",
+	Long: `Find files uploaded more than once to the same site and keep only the
+oldest copy. Blocks, post covers, share images and logos using a removed copy
+are pointed at the kept one, and tags are carried over.
+
+Files uploaded before content hashes were recorded are hashed first. Use
+--dry-run to see what would be merged.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := initSystemDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		subdomain, _ := cmd.Flags().GetString("site")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		var siteID uint
+		if subdomain != "" {
+			site, err := sites.GetSiteBySubdomain(db.GetDB(), subdomain)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			siteID = site.ID
+		}
+
+		res, err := media.Dedupe(db.GetDB(), siteID, dryRun)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		verb := "Merged"
+		if dryRun {
+			verb = "Would merge"
+		}
+		for _, m := range res.Merges {
+			note := ""
+			if !dryRun {
+				note = fmt.Sprintf(", %d block(s) updated", m.Blocks)
+			}
+			fmt.Printf("%s %s (%s) into %s (%s)%s\n", verb, m.Removed.Filename, m.Removed.OriginalName,
+				m.Kept.Filename, m.Kept.OriginalName, note)
+		}
+
+		fmt.Printf("%s %d duplicate(s), %.1f MB", verb, len(res.Merges), float64(res.Freed)/(1024*1024))
+		if res.Hashed > 0 {
+			fmt.Printf("; hashed %d file(s)", res.Hashed)
+		}
+		if res.Failed > 0 {
+			fmt.Printf(", %d couldn't be read", res.Failed)
+		}
+		fmt.Println()
+		if res.Failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	mediaCachePurgeCmd.Flags().Duration("older-than", 0, "Only delete images cached longer ago than this")
+
+	mediaScrubCmd.Flags().String("site", "", "Only scrub this site's media (subdomain)")
+	mediaScrubCmd.Flags().Bool("dry-run", false, "List what would change without changing it")
+
+	mediaReindexCmd.Flags().String("site", "", "Only reindex this site (subdomain)")
+
+	mediaDedupeCmd.Flags().String("site", "", "Only dedupe this site's media (subdomain)")
+	mediaDedupeCmd.Flags().Bool("dry-run", false, "List what would be merged without changing anything")
+
+	mediaCacheCmd.AddCommand(mediaCachePurgeCmd)
+	mediaCmd.AddCommand(mediaScrubCmd)
+	mediaCmd.AddCommand(mediaReindexCmd)
+	mediaCmd.AddCommand(mediaDedupeCmd)
+	mediaCmd.AddCommand(mediaCacheCmd)
+	rootCmd.AddCommand(mediaCmd)
+}
@@ -18,9 +18,12 @@ import (
 	"github.com/thatcatcamp/stinkykitty/internal/backup"
 	"github.com/thatcatcamp/stinkykitty/internal/config"
 	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/email"
 	"github.com/thatcatcamp/stinkykitty/internal/handlers"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
 	"github.com/thatcatcamp/stinkykitty/internal/middleware"
 	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/shifts"
 	"github.com/thatcatcamp/stinkykitty/internal/themes"
 	"github.com/thatcatcamp/stinkykitty/internal/tls"
 	"gorm.io/gorm"
@@ -46,6 +49,11 @@ var serverStartCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		// Record which media blocks use, for sites upgraded from before it was kept
+		if err := media.EnsureReferences(db.GetDB()); err != nil {
+			log.Printf("Warning: failed to index media used by blocks: %v", err)
+		}
+
 		// Initialize backup scheduler
 		backupPath := config.GetString("backups.path")
 		backupManager := backup.NewBackupManager(backupPath)
@@ -57,6 +65,31 @@ var serverStartCmd = &cobra.Command{
 		schedulerDone := scheduler.Start()
 		log.Println("Backup scheduler started")
 
+		// Start outbound email queue in background
+		emailQueue := email.NewQueue(db.GetDB())
+		if interval := config.GetDuration("email.queue_interval"); interval > 0 {
+			emailQueue.PollInterval = interval
+		}
+		if maxAttempts := config.GetInt("email.max_attempts"); maxAttempts > 0 {
+			emailQueue.MaxAttempts = maxAttempts
+		}
+		if retryFor := config.GetDuration("email.retry_for"); retryFor > 0 {
+			emailQueue.RetryFor = retryFor
+		}
+		emailQueueDone := emailQueue.Start()
+		log.Println("Email queue started")
+
+		// Start volunteer shift reminders in background
+		shiftReminder := shifts.NewReminder(db.GetDB(), handlers.ShiftCancelKey(), handlers.SiteURL)
+		if lead := config.GetDuration("shifts.reminder_lead"); lead > 0 {
+			shiftReminder.Lead = lead
+		}
+		if interval := config.GetDuration("shifts.reminder_interval"); interval > 0 {
+			shiftReminder.PollInterval = interval
+		}
+		shiftReminderDone := shiftReminder.Start()
+		log.Println("Shift reminders started")
+
 		// Setup signal handling for graceful shutdown
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -65,6 +98,8 @@ var serverStartCmd = &cobra.Command{
 			sig := <-sigChan
 			log.Printf("Received signal: %v, shutting down gracefully...", sig)
 			scheduler.Stop()
+			emailQueue.Stop()
+			shiftReminder.Stop()
 		}()
 
 		// Wait for scheduler to finish in a separate goroutine
@@ -73,6 +108,16 @@ var serverStartCmd = &cobra.Command{
 			log.Println("Backup scheduler stopped")
 		}()
 
+		go func() {
+			<-emailQueueDone
+			log.Println("Email queue stopped")
+		}()
+
+		go func() {
+			<-shiftReminderDone
+			log.Println("Shift reminders stopped")
+		}()
+
 		// Create Gin router
 		r := gin.Default()
 
@@ -113,6 +158,7 @@ var serverStartCmd = &cobra.Command{
 		siteGroup := r.Group("/")
 		siteGroup.Use(middleware.SiteResolutionMiddleware(db.GetDB(), baseDomain))
 		siteGroup.Use(themeMiddleware(db.GetDB()))
+		siteGroup.Use(handlers.PrivateSiteMiddleware())
 		{
 			// Favicon - simple cat SVG
 			siteGroup.GET("/favicon.ico", func(c *gin.Context) {
@@ -131,6 +177,9 @@ var serverStartCmd = &cobra.Command{
 			// Public content routes
 			siteGroup.GET("/", handlers.ServeHomepage)
 
+			// Generated link preview images
+			siteGroup.GET("/og-image/:file", handlers.ShareImageHandler)
+
 			// Search endpoint
 			siteGroup.GET("/search", handlers.SearchHandler)
 
@@ -138,6 +187,34 @@ var serverStartCmd = &cobra.Command{
 			siteGroup.GET("/contact", handlers.ContactFormHandler)
 			siteGroup.POST("/contact", handlers.ContactFormHandler)
 
+			// Password-protected pages and sites
+			siteGroup.POST("/unlock", middleware.RateLimitMiddleware(loginRateLimiter, "/unlock"), handlers.UnlockPageHandler)
+			siteGroup.POST("/site-unlock", middleware.RateLimitMiddleware(loginRateLimiter, "/site-unlock"), handlers.SiteUnlockHandler)
+
+			// Form block submissions
+			siteGroup.POST("/forms/:block_id", handlers.FormSubmitHandler)
+
+			// Events calendar and iCal feed
+			siteGroup.GET("/events", handlers.EventsPageHandler)
+			siteGroup.GET("/events.ics", handlers.EventsICSHandler)
+			siteGroup.GET("/events/:id", handlers.EventDetailHandler)
+
+			// Volunteer shift sign-ups
+			siteGroup.POST("/shifts/:id/signup", handlers.ShiftSignupHandler)
+			siteGroup.GET("/shift-signups/:id/cancel", handlers.ShiftCancelHandler)
+			siteGroup.POST("/shift-signups/:id/cancel", handlers.ShiftCancelHandler)
+
+			// Blog archive, posts and feeds
+			siteGroup.GET("/blog", handlers.BlogArchiveHandler)
+			siteGroup.GET("/blog/tag/:tag", handlers.BlogTagHandler)
+			siteGroup.GET("/blog/:slug", handlers.BlogPostHandler)
+			siteGroup.GET("/feed.xml", handlers.RSSFeedHandler)
+			siteGroup.GET("/atom.xml", handlers.AtomFeedHandler)
+
+			// Yearly archives of the site
+			siteGroup.GET("/archive", handlers.ArchivesIndexHandler)
+			siteGroup.GET("/archive/:year/*path", handlers.ArchivePageHandler)
+
 			// SEO files
 			siteGroup.GET("/robots.txt", handlers.RobotsTxtHandler)
 			siteGroup.GET("/sitemap.xml", handlers.SitemapXMLHandler)
@@ -187,6 +264,9 @@ var serverStartCmd = &cobra.Command{
 					adminGroup.POST("/pages/:id/publish", handlers.PublishPageHandler)
 					adminGroup.POST("/pages/:id/unpublish", handlers.UnpublishPageHandler)
 					adminGroup.POST("/pages/:id/delete", handlers.DeletePageHandler)
+					adminGroup.POST("/pages/:id/move", handlers.MovePageHandler)
+					adminGroup.POST("/pages/:id/seo", handlers.UpdatePageSEOHandler)
+					adminGroup.POST("/pages/:id/visibility", handlers.UpdatePageVisibilityHandler)
 					adminGroup.POST("/pages/:id/blocks", handlers.CreateBlockHandler)
 					adminGroup.GET("/pages/:id/blocks/new-image", handlers.NewImageBlockFormHandler)
 					adminGroup.GET("/pages/:id/blocks/:block_id/edit", handlers.EditBlockHandler)
@@ -203,10 +283,58 @@ var serverStartCmd = &cobra.Command{
 					adminGroup.GET("/settings", handlers.AdminSettingsHandler)
 					adminGroup.POST("/settings", handlers.AdminSettingsSaveHandler)
 					adminGroup.GET("/export", handlers.ExportSiteHandler(db.GetDB()))
+					adminGroup.GET("/export/static", handlers.StaticExportHandler)
+					adminGroup.GET("/contact", handlers.ContactInboxHandler)
+					adminGroup.GET("/contact/:id", handlers.ContactMessageHandler)
+					adminGroup.POST("/contact/:id/unread", handlers.ContactMarkUnreadHandler)
+					adminGroup.POST("/contact/:id/delete", handlers.ContactDeleteHandler)
+					adminGroup.GET("/forms", handlers.FormsListHandler)
+					adminGroup.GET("/forms/:block_id", handlers.FormSubmissionsHandler)
+					adminGroup.GET("/forms/:block_id/export", handlers.FormSubmissionsExportHandler)
+					adminGroup.POST("/forms/submissions/:id/delete", handlers.FormSubmissionDeleteHandler)
+					adminGroup.GET("/events", handlers.EventsListHandler)
+					adminGroup.GET("/events/new", handlers.NewEventFormHandler)
+					adminGroup.POST("/events", handlers.CreateEventHandler)
+					adminGroup.GET("/events/:id/edit", handlers.EditEventFormHandler)
+					adminGroup.POST("/events/:id", handlers.UpdateEventHandler)
+					adminGroup.POST("/events/:id/delete", handlers.DeleteEventHandler)
+					adminGroup.GET("/shifts", handlers.ShiftsListHandler)
+					adminGroup.GET("/shifts/new", handlers.NewShiftFormHandler)
+					adminGroup.GET("/shifts/export", handlers.ShiftRosterExportHandler)
+					adminGroup.POST("/shifts", handlers.CreateShiftHandler)
+					adminGroup.GET("/shifts/:id", handlers.ShiftRosterHandler)
+					adminGroup.GET("/shifts/:id/edit", handlers.EditShiftFormHandler)
+					adminGroup.POST("/shifts/:id", handlers.UpdateShiftHandler)
+					adminGroup.POST("/shifts/:id/delete", handlers.DeleteShiftHandler)
+					adminGroup.POST("/shifts/signups/:id/delete", handlers.ShiftSignupDeleteHandler)
+					adminGroup.GET("/posts", handlers.PostsListHandler)
+					adminGroup.GET("/posts/new", handlers.NewPostFormHandler)
+					adminGroup.POST("/posts", handlers.CreatePostHandler)
+					adminGroup.GET("/posts/:id/edit", handlers.EditPostFormHandler)
+					adminGroup.POST("/posts/:id", handlers.UpdatePostHandler)
+					adminGroup.POST("/posts/:id/delete", handlers.DeletePostHandler)
+					adminGroup.GET("/redirects", handlers.RedirectsListHandler)
+					adminGroup.POST("/redirects", handlers.CreateRedirectHandler)
+					adminGroup.POST("/redirects/import", handlers.ImportRedirectsHandler)
+					adminGroup.POST("/redirects/:id/delete", handlers.DeleteRedirectHandler)
+					adminGroup.GET("/archives", handlers.ArchivesListHandler)
+					adminGroup.POST("/archives", handlers.CreateArchiveHandler)
+					adminGroup.POST("/archives/:id/delete", handlers.DeleteArchiveHandler)
+					adminGroup.GET("/announcements", handlers.AnnouncementsListHandler)
+					adminGroup.POST("/announcements", handlers.CreateAnnouncementHandler)
+					adminGroup.POST("/announcements/:id/end", handlers.EndAnnouncementHandler)
+					adminGroup.POST("/announcements/:id/delete", handlers.DeleteAnnouncementHandler)
+					adminGroup.GET("/access", handlers.SiteAccessHandler)
+					adminGroup.POST("/access", handlers.UpdateSiteAccessHandler)
 					adminGroup.GET("/docs", handlers.DocsHandler)
 					// Media library
 					adminGroup.GET("/media", handlers.MediaLibraryHandler)
 					adminGroup.POST("/media/upload", handlers.MediaUploadHandler)
+					adminGroup.POST("/media/import", handlers.MediaImportZipHandler)
+					adminGroup.POST("/media/bulk", handlers.MediaBulkHandler)
+					adminGroup.POST("/media/collections", handlers.MediaCollectionCreateHandler)
+					adminGroup.POST("/media/collections/:id/rename", handlers.MediaCollectionRenameHandler)
+					adminGroup.POST("/media/collections/:id/delete", handlers.MediaCollectionDeleteHandler)
 					adminGroup.POST("/media/:id/tags", handlers.MediaTagsHandler)
 					adminGroup.GET("/media/tags/autocomplete", handlers.MediaTagAutocompleteHandler)
 					adminGroup.POST("/media/:id/delete", handlers.MediaDeleteHandler)
@@ -228,7 +356,7 @@ var serverStartCmd = &cobra.Command{
 		}
 
 		// Handle all other routes as potential pages
-		r.NoRoute(middleware.SiteResolutionMiddleware(db.GetDB(), baseDomain), themeMiddleware(db.GetDB()), handlers.ServePage)
+		r.NoRoute(middleware.SiteResolutionMiddleware(db.GetDB(), baseDomain), themeMiddleware(db.GetDB()), handlers.PrivateSiteMiddleware(), handlers.ServePage)
 
 		// Check if TLS is enabled
 		if config.GetBool("server.tls_enabled") {
@@ -3,17 +3,27 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/thatcatcamp/stinkykitty/internal/announcements"
 	"github.com/thatcatcamp/stinkykitty/internal/config"
 	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/handlers"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
 	"github.com/thatcatcamp/stinkykitty/internal/sites"
+	"github.com/thatcatcamp/stinkykitty/internal/staticsite"
+	"github.com/thatcatcamp/stinkykitty/internal/storage"
 	"github.com/thatcatcamp/stinkykitty/internal/users"
+	"github.com/thatcatcamp/stinkykitty/internal/visibility"
 )
 
 var siteCmd = &cobra.Command{
@@ -349,6 +359,9 @@ var siteRemoveAllowedIPCmd = &cobra.Command{
 		}
 
 		fmt.Printf("Removed %s from allowlist for %s\n", cidr, subdomain)
+		if site.Visibility == visibility.IPAllowlist && site.AllowedIPs == "" {
+			fmt.Printf("Warning: %s is limited to its IP allowlist, which is now empty, so nobody can visit it\n", subdomain)
+		}
 	},
 }
 
@@ -389,9 +402,402 @@ var siteListAllowedIPsCmd = &cobra.Command{
 	},
 }
 
+var siteSetVisibilityCmd = &cobra.Command{
+	Use:   "set-visibility <subdomain> <public|password|members|ip>",
+	Short: "Set who can visit a site",
+	Long: `Set who can visit a site's public pages:
+
+  public    anyone
+  password  visitors who enter the site password (set with --password)
+  members   people who can log in to the site's admin
+  ip        visitors from the site's IP allowlist (see allow-ip)
+
+Private sites show a login page instead of their content and ask search
+engines not to index them. Logged-in members can always see the site.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := initSystemDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		subdomain, mode := args[0], args[1]
+		password, _ := cmd.Flags().GetString("password")
+
+		site, err := sites.GetSiteBySubdomain(db.GetDB(), subdomain)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := handlers.SetSiteVisibility(site, mode, password); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Set %s to %s\n", subdomain, visibility.Label(mode))
+	},
+}
+
+var siteSetStorageCmd = &cobra.Command{
+	Use:   "set-storage <subdomain> <local|s3>",
+	Short: "Set where a site's media is stored",
+	Long: `Set where a site's new uploads are stored:
+
+  local  the media directory on this server
+  s3     an S3-compatible bucket (--bucket, or storage.s3.bucket in config)
+
+Existing files stay where they are and keep working. Use --move to copy them
+to the new storage and remove the old copies.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := initSystemDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		subdomain, storageType := args[0], args[1]
+		bucket, _ := cmd.Flags().GetString("bucket")
+		move, _ := cmd.Flags().GetBool("move")
+		if storageType != "local" && storageType != media.StorageS3 {
+			fmt.Fprintf(os.Stderr, "Error: storage must be local or s3\n")
+			os.Exit(1)
+		}
+
+		site, err := sites.GetSiteBySubdomain(db.GetDB(), subdomain)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		site.StorageType = storageType
+		if cmd.Flags().Changed("bucket") {
+			site.S3Bucket = bucket
+		}
+		// Check the bucket is usable before saving, so uploads don't start failing
+		store, target, err := media.StorageForSite(site)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := store.Stat("uploads/.stinky-check"); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			fmt.Fprintf(os.Stderr, "Error: storage isn't reachable: %v\n", err)
+			os.Exit(1)
+		}
+		if err := db.GetDB().Model(site).Updates(map[string]interface{}{
+			"storage_type": site.StorageType,
+			"s3_bucket":    site.S3Bucket,
+		}).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if target == "" {
+			fmt.Printf("%s now stores new uploads on this server\n", subdomain)
+		} else {
+			fmt.Printf("%s now stores new uploads in bucket %s\n", subdomain, target)
+		}
+
+		if !move {
+			return
+		}
+		var items []models.MediaItem
+		db.GetDB().Where("site_id = ? AND bucket <> ?", site.ID, target).Find(&items)
+		moved, failed := 0, 0
+		for i := range items {
+			if err := media.MoveItem(db.GetDB(), &items[i], target); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to move %s: %v\n", items[i].Filename, err)
+				failed++
+				continue
+			}
+			moved++
+		}
+		fmt.Printf("Moved %d file(s)", moved)
+		if failed > 0 {
+			fmt.Printf(", %d failed (they stay where they were)", failed)
+		}
+		fmt.Println()
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+var siteSetQuotaCmd = &cobra.Command{
+	Use:   "set-quota <subdomain> <size|default|unlimited>",
+	Short: "Set how much media a site may upload",
+	Long: `Set a site's storage quota, e.g. 500MB or 5GB. "default" goes back to
+storage.quota from the config and "unlimited" removes the limit.
+
+Uploads that would take a site over its quota are turned away, and the owner
+is emailed when the site reaches 80% and 100% of it.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := initSystemDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		subdomain, size := args[0], args[1]
+		site, err := sites.GetSiteBySubdomain(db.GetDB(), subdomain)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var quota *int64
+		if size != "default" {
+			n, err := media.ParseSize(size)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			quota = &n
+		}
+		if err := db.GetDB().Model(site).Update("storage_quota", quota).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		site.StorageQuota = quota
+
+		// Usage may now be under (or over) a level the owner was told about
+		media.UpdateQuotaAlerts(db.GetDB(), site)
+		fmt.Printf("Set %s's storage quota to %s (%s used)\n", subdomain, quotaLabel(media.Quota(site)),
+			media.FormatSize(media.SiteUsage(db.GetDB(), site.ID)))
+	},
+}
+
+var siteUsageCmd = &cobra.Command{
+	Use:   "usage [subdomain]",
+	Short: "Show how much of their storage quota sites use",
+	Long: `Show each site's media usage against its storage quota, counting the
+smaller copies and thumbnails of images. Images uploaded before usage was
+tracked are measured first.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := initSystemDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var siteList []models.Site
+		if len(args) == 1 {
+			site, err := sites.GetSiteBySubdomain(db.GetDB(), args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			siteList = append(siteList, *site)
+		} else {
+			list, err := sites.ListSites(db.GetDB())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing sites: %v\n", err)
+				os.Exit(1)
+			}
+			siteList = list
+		}
+
+		for _, s := range siteList {
+			if _, err := media.MeasureUsage(db.GetDB(), s.ID); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to measure %s: %v\n", s.Subdomain, err)
+			}
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SUBDOMAIN\tUSED\tQUOTA\tPERCENT")
+		for i := range siteList {
+			s := &siteList[i]
+			usage, quota := media.SiteUsage(db.GetDB(), s.ID), media.Quota(s)
+			percent := "-"
+			if quota > 0 {
+				percent = fmt.Sprintf("%d%%", media.UsagePercent(usage, quota))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Subdomain, media.FormatSize(usage), quotaLabel(quota), percent)
+		}
+		w.Flush()
+	},
+}
+
+// quotaLabel formats a storage quota for the site commands
+func quotaLabel(quota int64) string {
+	if quota <= 0 {
+		return "unlimited"
+	}
+	return media.FormatSize(quota)
+}
+
+var siteAnnounceCmd = &cobra.Command{
+	Use:   "announce <subdomain> [message]",
+	Short: "Post a banner announcement on every page of a site",
+	Long: `Post a banner shown at the top of every public page of a site, for urgent
+notices like "gate closed due to weather". It shows right away and stays up
+until ended, unless --starts, --ends or --for say otherwise. Times use the
+format 2006-01-02T15:04 in the server's local time.
+
+Use --list to see the site's announcements and --clear to end all of them.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := initSystemDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		site, err := sites.GetSiteBySubdomain(db.GetDB(), args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if clear, _ := cmd.Flags().GetBool("clear"); clear {
+			n, err := announcements.EndAll(db.GetDB(), site.ID, time.Now())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Ended %d announcement(s) on %s\n", n, site.Subdomain)
+			return
+		}
+
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			var all []models.Announcement
+			db.GetDB().Where("site_id = ?", site.ID).Order("created_at DESC").Find(&all)
+			if len(all) == 0 {
+				fmt.Printf("No announcements for %s\n", site.Subdomain)
+				return
+			}
+			now := time.Now()
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tSEVERITY\tSHOWING\tENDS\tMESSAGE")
+			for _, a := range all {
+				ends := "-"
+				if a.EndsAt != nil {
+					ends = a.EndsAt.Local().Format(announcements.TimeLayout)
+				}
+				fmt.Fprintf(w, "%d\t%s\t%t\t%s\t%s\n", a.ID, a.Severity, announcements.IsActive(&a, now), ends, a.Message)
+			}
+			w.Flush()
+			return
+		}
+
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "Error: a message is required")
+			os.Exit(1)
+		}
+
+		severity, _ := cmd.Flags().GetString("severity")
+		link, _ := cmd.Flags().GetString("link")
+		linkText, _ := cmd.Flags().GetString("link-text")
+		dismissible, _ := cmd.Flags().GetBool("dismissible")
+		startsFlag, _ := cmd.Flags().GetString("starts")
+		endsFlag, _ := cmd.Flags().GetString("ends")
+		duration, _ := cmd.Flags().GetDuration("for")
+
+		startsAt, err := announcements.ParseTime(startsFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --starts: %v\n", err)
+			os.Exit(1)
+		}
+		endsAt, err := announcements.ParseTime(endsFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --ends: %v\n", err)
+			os.Exit(1)
+		}
+		if duration > 0 {
+			if endsAt != nil {
+				fmt.Fprintln(os.Stderr, "Error: use either --ends or --for, not both")
+				os.Exit(1)
+			}
+			end := time.Now().UTC()
+			if startsAt != nil {
+				end = *startsAt
+			}
+			end = end.Add(duration)
+			endsAt = &end
+		}
+
+		a := &models.Announcement{
+			SiteID:      site.ID,
+			Severity:    severity,
+			Message:     args[1],
+			LinkURL:     link,
+			LinkText:    linkText,
+			StartsAt:    startsAt,
+			EndsAt:      endsAt,
+			Dismissible: dismissible,
+		}
+		if err := announcements.Create(db.GetDB(), a); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Posted %s announcement %d on %s\n", a.Severity, a.ID, site.Subdomain)
+	},
+}
+
+var siteRenderStaticCmd = &cobra.Command{
+	Use:   "render-static <subdomain> <outdir>",
+	Short: "Render a site as static HTML for offline use",
+	Long: `Render every published page of a site as plain HTML files in <outdir>,
+with relative links, the media the pages use and a search page that works
+without a server. A zip of the folder is written to <outdir>.zip.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := initSystemDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		subdomain, outDir := args[0], filepath.Clean(args[1])
+		site, err := sites.GetSiteBySubdomain(db.GetDB(), subdomain)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		result, err := handlers.RenderStaticSite(site, outDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering site: %v\n", err)
+			os.Exit(1)
+		}
+		for _, name := range result.MissingAssets {
+			fmt.Fprintf(os.Stderr, "Warning: missing media file %s\n", name)
+		}
+
+		zipPath := outDir + ".zip"
+		f, err := os.Create(zipPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating zip: %v\n", err)
+			os.Exit(1)
+		}
+		if err := staticsite.ZipDir(f, outDir); err != nil {
+			f.Close()
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := f.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing zip: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Rendered %d pages and %d media files to %s\n", result.Pages, result.Assets, outDir)
+		fmt.Printf("Zip: %s\n", zipPath)
+	},
+}
+
 func init() {
 	siteCreateCmd.Flags().String("owner", "", "Email of the site owner (required)")
 	siteAddUserCmd.Flags().String("role", "editor", "User role (owner, admin, editor)")
+	siteSetVisibilityCmd.Flags().String("password", "", "Site password for password mode (keeps the current one if omitted)")
+	siteSetStorageCmd.Flags().String("bucket", "", "Bucket for s3 storage (default storage.s3.bucket)")
+	siteSetStorageCmd.Flags().Bool("move", false, "Move existing media to the new storage")
+	siteAnnounceCmd.Flags().String("severity", "info", "Severity (info, warning, critical)")
+	siteAnnounceCmd.Flags().String("link", "", "Optional link, a site path or http(s) URL")
+	siteAnnounceCmd.Flags().String("link-text", "", "Label for the link (default \"Learn more\")")
+	siteAnnounceCmd.Flags().String("starts", "", "When to start showing it (default now)")
+	siteAnnounceCmd.Flags().String("ends", "", "When to stop showing it (default until ended)")
+	siteAnnounceCmd.Flags().Duration("for", 0, "How long to show it, e.g. 6h (instead of --ends)")
+	siteAnnounceCmd.Flags().Bool("dismissible", false, "Let visitors dismiss it")
+	siteAnnounceCmd.Flags().Bool("list", false, "List the site's announcements")
+	siteAnnounceCmd.Flags().Bool("clear", false, "End all of the site's announcements")
 
 	siteCmd.AddCommand(siteCreateCmd)
 	siteCmd.AddCommand(siteListCmd)
@@ -402,5 +808,11 @@ func init() {
 	siteCmd.AddCommand(siteAllowIPCmd)
 	siteCmd.AddCommand(siteRemoveAllowedIPCmd)
 	siteCmd.AddCommand(siteListAllowedIPsCmd)
+	siteCmd.AddCommand(siteSetVisibilityCmd)
+	siteCmd.AddCommand(siteSetStorageCmd)
+	siteCmd.AddCommand(siteSetQuotaCmd)
+	siteCmd.AddCommand(siteUsageCmd)
+	siteCmd.AddCommand(siteAnnounceCmd)
+	siteCmd.AddCommand(siteRenderStaticCmd)
 	rootCmd.AddCommand(siteCmd)
 }
@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: MIT
+
+// Package announcements manages site-wide banners for urgent notices, shown
+// above every public page between their start and end times.
+package announcements
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+)
+
+// Severity levels, from least to most urgent
+const (
+	Info     = "info"
+	Warning  = "warning"
+	Critical = "critical"
+)
+
+// maxMessageLength bounds a banner's message so it stays a banner
+const maxMessageLength = 500
+
+// TimeLayout is the format of start and end times in forms and the CLI
+const TimeLayout = "2006-01-02T15:04"
+
+var (
+	// ErrEmptyMessage is returned for an announcement without a message
+	ErrEmptyMessage = errors.New("announcement message is required")
+	// ErrMessageTooLong is returned for messages over the length limit
+	ErrMessageTooLong = errors.New("announcement message must be 500 characters or fewer")
+	// ErrInvalidSeverity is returned for an unknown severity
+	ErrInvalidSeverity = errors.New("severity must be info, warning or critical")
+	// ErrInvalidLink is returned for a link that isn't a site path or http(s) URL
+	ErrInvalidLink = errors.New("link must be a path like /weather or a full http(s) URL")
+	// ErrInvalidSchedule is returned when an announcement ends before it starts
+	ErrInvalidSchedule = errors.New("end time must be after start time")
+	// ErrInvalidTime is returned for a start or end time that can't be parsed
+	ErrInvalidTime = errors.New("times must look like 2006-01-02T15:04")
+)
+
+// ValidSeverity reports whether s is a severity level
+func ValidSeverity(s string) bool {
+	return s == Info || s == Warning || s == Critical
+}
+
+// rank orders severities so the most urgent banner comes first
+func rank(severity string) int {
+	switch severity {
+	case Critical:
+		return 0
+	case Warning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// ParseTime parses a start or end time in the server's local time. An empty
+// value means no time.
+func ParseTime(value string) (*time.Time, error) {
+	return ParseTimeIn(value, time.Local)
+}
+
+// ParseTimeIn parses a start or end time entered in loc, such as the zone of
+// the organizer's browser. An empty value means no time.
+func ParseTimeIn(value string, loc *time.Location) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(TimeLayout, value, loc)
+	if err != nil {
+		return nil, ErrInvalidTime
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// validateLink checks an optional link and returns it trimmed
+func validateLink(link string) (string, error) {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return "", nil
+	}
+	if strings.ContainsAny(link, "\r\n\t") {
+		return "", ErrInvalidLink
+	}
+	if strings.HasPrefix(link, "/") {
+		// Browsers treat //host and /\host as links to another site
+		if strings.HasPrefix(link, "//") || strings.HasPrefix(link, `/\`) {
+			return "", ErrInvalidLink
+		}
+		return link, nil
+	}
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", ErrInvalidLink
+	}
+	return link, nil
+}
+
+// Create validates and saves a new announcement. A blank severity is info.
+func Create(db *gorm.DB, a *models.Announcement) error {
+	a.Message = strings.TrimSpace(a.Message)
+	a.LinkText = strings.TrimSpace(a.LinkText)
+	if a.Severity == "" {
+		a.Severity = Info
+	}
+	switch {
+	case a.Message == "":
+		return ErrEmptyMessage
+	case len([]rune(a.Message)) > maxMessageLength:
+		return ErrMessageTooLong
+	case !ValidSeverity(a.Severity):
+		return ErrInvalidSeverity
+	case a.StartsAt != nil && a.EndsAt != nil && !a.EndsAt.After(*a.StartsAt):
+		return ErrInvalidSchedule
+	}
+	link, err := validateLink(a.LinkURL)
+	if err != nil {
+		return err
+	}
+	a.LinkURL = link
+	return db.Create(a).Error
+}
+
+// Active returns a site's announcements showing at the given time, most
+// urgent first and newest first within a severity
+func Active(db *gorm.DB, siteID uint, now time.Time) ([]models.Announcement, error) {
+	now = now.UTC()
+	var list []models.Announcement
+	if err := db.Where("site_id = ? AND (starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?)", siteID, now, now).
+		Order("created_at DESC").Order("id DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return rank(list[i].Severity) < rank(list[j].Severity)
+	})
+	return list, nil
+}
+
+// IsActive reports whether an announcement is showing at the given time
+func IsActive(a *models.Announcement, now time.Time) bool {
+	return (a.StartsAt == nil || !a.StartsAt.After(now)) && (a.EndsAt == nil || a.EndsAt.After(now))
+}
+
+// End takes a site's announcement down now, keeping it for the record
+func End(db *gorm.DB, siteID, id uint, now time.Time) error {
+	res := db.Model(&models.Announcement{}).Where("id = ? AND site_id = ?", id, siteID).Update("ends_at", now.UTC())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// EndAll takes down every announcement a site is showing or has scheduled,
+// returning how many were ended
+func EndAll(db *gorm.DB, siteID uint, now time.Time) (int64, error) {
+	now = now.UTC()
+	res := db.Model(&models.Announcement{}).
+		Where("site_id = ? AND (ends_at IS NULL OR ends_at > ?)", siteID, now).
+		Update("ends_at", now)
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: MIT
+package announcements
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupAnnouncementsTestDB(t *testing.T) *gorm.DB {
+	testDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := testDB.AutoMigrate(&models.Announcement{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	return testDB
+}
+
+func TestCreate_Validation(t *testing.T) {
+	db := setupAnnouncementsTestDB(t)
+	now := time.Now().UTC()
+	earlier := now.Add(-time.Hour)
+
+	cases := []struct {
+		a    models.Announcement
+		want error
+	}{
+		{models.Announcement{Message: "  "}, ErrEmptyMessage},
+		{models.Announcement{Message: "Hi", Severity: "urgent"}, ErrInvalidSeverity},
+		{models.Announcement{Message: "Hi", LinkURL: "javascript:alert(1)"}, ErrInvalidLink},
+		{models.Announcement{Message: "Hi", LinkURL: "//evil.example"}, ErrInvalidLink},
+		{models.Announcement{Message: "Hi", LinkURL: `/\evil.example`}, ErrInvalidLink},
+		{models.Announcement{Message: "Hi", StartsAt: &now, EndsAt: &earlier}, ErrInvalidSchedule},
+	}
+	for _, tc := range cases {
+		a := tc.a
+		a.SiteID = 1
+		if err := Create(db, &a); !errors.Is(err, tc.want) {
+			t.Errorf("Create(%+v) = %v, want %v", tc.a, err, tc.want)
+		}
+	}
+
+	a := models.Announcement{SiteID: 1, Message: "Gate closed", LinkURL: "/weather"}
+	if err := Create(db, &a); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if a.Severity != Info {
+		t.Errorf("Expected blank severity to default to info, got %q", a.Severity)
+	}
+}
+
+func TestActive_ScheduleAndOrder(t *testing.T) {
+	db := setupAnnouncementsTestDB(t)
+	now := time.Now().UTC()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	Create(db, &models.Announcement{SiteID: 1, Message: "info"})
+	Create(db, &models.Announcement{SiteID: 1, Message: "critical", Severity: Critical})
+	Create(db, &models.Announcement{SiteID: 1, Message: "later", StartsAt: &future})
+	Create(db, &models.Announcement{SiteID: 1, Message: "over", StartsAt: &past, EndsAt: &now})
+	Create(db, &models.Announcement{SiteID: 2, Message: "other site"})
+
+	list, err := Active(db, 1, now)
+	if err != nil {
+		t.Fatalf("Active: %v", err)
+	}
+	if len(list) != 2 || list[0].Message != "critical" || list[1].Message != "info" {
+		t.Fatalf("Expected critical then info, got %+v", list)
+	}
+
+	n, err := EndAll(db, 1, now)
+	if err != nil || n != 3 {
+		t.Errorf("Expected 3 announcements ended, got %d (%v)", n, err)
+	}
+	if list, _ := Active(db, 1, now.Add(2*time.Hour)); len(list) != 0 {
+		t.Errorf("Expected nothing showing after EndAll, got %d", len(list))
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	if got, err := ParseTime(""); got != nil || err != nil {
+		t.Errorf("Expected empty time to be nil, got %v %v", got, err)
+	}
+	if _, err := ParseTime("tomorrow"); !errors.Is(err, ErrInvalidTime) {
+		t.Errorf("Expected ErrInvalidTime, got %v", err)
+	}
+	got, err := ParseTime("2026-08-28T18:30")
+	if err != nil || got.Location() != time.UTC {
+		t.Errorf("Expected a UTC time, got %v %v", got, err)
+	}
+
+	// 18:00 in an organizer's browser at UTC-7 is 01:00 UTC the next day
+	got, err = ParseTimeIn("2026-08-28T18:00", time.FixedZone("", -7*60*60))
+	if err != nil || !got.Equal(time.Date(2026, 8, 29, 1, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 2026-08-29 01:00 UTC, got %v %v", got, err)
+	}
+}
@@ -0,0 +1,247 @@
+// SPDX-License-Identifier: MIT
+
+// Package antispam protects public forms with a honeypot field, a signed
+// minimum-fill-time token, per-IP rate limiting and an optional proof-of-work
+// challenge that is solved in the visitor's browser.
+package antispam
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+)
+
+// Form field names added to protected forms
+const (
+	HoneypotField     = "website"
+	TokenField        = "_form_token"
+	PowChallengeField = "_pow_challenge"
+	PowNonceField     = "_pow_nonce"
+)
+
+// Rejection reasons, recorded so admins can see what is being blocked
+const (
+	ReasonRateLimited  = "rate_limited"
+	ReasonHoneypot     = "honeypot"
+	ReasonInvalidToken = "invalid_token"
+	ReasonTooFast      = "too_fast"
+	ReasonProofOfWork  = "proof_of_work"
+)
+
+// ReasonLabels gives a short human-readable description of each rejection reason
+var ReasonLabels = map[string]string{
+	ReasonRateLimited:  "Rate limited",
+	ReasonHoneypot:     "Honeypot",
+	ReasonInvalidToken: "Invalid or expired form",
+	ReasonTooFast:      "Submitted too fast",
+	ReasonProofOfWork:  "Failed proof-of-work",
+}
+
+// Rejection is returned by Guard.Check when a submission looks like spam
+type Rejection struct {
+	Reason string
+}
+
+func (r *Rejection) Error() string {
+	return "submission rejected: " + r.Reason
+}
+
+// Guard checks public form submissions for spam
+type Guard struct {
+	Key           []byte                  // HMAC key for tokens and challenges
+	MinFillTime   time.Duration           // Forms submitted faster than this are rejected
+	MaxTokenAge   time.Duration           // Tokens older than this are rejected
+	PowDifficulty int                     // Required leading zero bits; 0 disables proof-of-work
+	Limiter       *middleware.RateLimiter // Per-IP limiter; nil disables rate limiting
+
+	mu   sync.Mutex
+	used map[string]time.Time // Used tokens and solved challenges and their expiry, to stop replays
+}
+
+// NewGuard creates a guard with sensible defaults and no rate limiting
+func NewGuard(key []byte) *Guard {
+	return &Guard{
+		Key:         key,
+		MinFillTime: 3 * time.Second,
+		MaxTokenAge: 24 * time.Hour,
+		used:        make(map[string]time.Time),
+	}
+}
+
+// sign returns the hex HMAC of the given parts
+func (g *Guard) sign(parts ...string) string {
+	mac := hmac.New(sha256.New, g.Key)
+	mac.Write([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IssueToken returns a signed token of the form "<unix>.<random>.<signature>"
+// recording when the form was rendered. scope ties the token to one site so
+// it can't be reused elsewhere, and each token is accepted only once.
+func (g *Guard) IssueToken(scope string, now time.Time) string {
+	nonce := make([]byte, 8)
+	_, _ = rand.Read(nonce)
+	body := strconv.FormatInt(now.Unix(), 10) + "." + hex.EncodeToString(nonce)
+	return body + "." + g.sign("form", scope, body)
+}
+
+// checkToken verifies a token's signature and that it was issued long enough
+// ago. It returns when the token was issued.
+func (g *Guard) checkToken(scope, token string, now time.Time) (time.Time, error) {
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return time.Time{}, &Rejection{Reason: ReasonInvalidToken}
+	}
+	body, sig := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(g.sign("form", scope, body))) {
+		return time.Time{}, &Rejection{Reason: ReasonInvalidToken}
+	}
+
+	ts, _, _ := strings.Cut(body, ".")
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}, &Rejection{Reason: ReasonInvalidToken}
+	}
+	issued := time.Unix(unix, 0)
+	age := now.Sub(issued)
+	if age > g.MaxTokenAge {
+		return time.Time{}, &Rejection{Reason: ReasonInvalidToken}
+	}
+	if age < g.MinFillTime {
+		return time.Time{}, &Rejection{Reason: ReasonTooFast}
+	}
+	return issued, nil
+}
+
+// claim records a one-time value as used until expires, returning false if
+// it already was
+func (g *Guard) claim(value string, expires, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.used == nil {
+		g.used = make(map[string]time.Time)
+	}
+	for v, exp := range g.used {
+		if now.After(exp) {
+			delete(g.used, v)
+		}
+	}
+	if _, seen := g.used[value]; seen {
+		return false
+	}
+	g.used[value] = expires
+	return true
+}
+
+// unclaim forgets a one-time value so it can be used again
+func (g *Guard) unclaim(value string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.used, value)
+}
+
+// isUsed reports whether a one-time value has already been claimed
+func (g *Guard) isUsed(value string, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	exp, seen := g.used[value]
+	return seen && !now.After(exp)
+}
+
+// FieldsHTML returns the hidden inputs (and proof-of-work script, if enabled)
+// to embed inside a protected <form>
+func (g *Guard) FieldsHTML(scope string, now time.Time) string {
+	var b strings.Builder
+
+	// Visually hidden rather than type="hidden" so naive bots fill it in
+	fmt.Fprintf(&b, `<div style="position: absolute; left: -10000px; width: 1px; height: 1px; overflow: hidden;" aria-hidden="true">
+			<label>Leave this field empty <input type="text" name="%s" value="" tabindex="-1" autocomplete="off"></label>
+		</div>
+		<input type="hidden" name="%s" value="%s">`, HoneypotField, TokenField, html.EscapeString(g.IssueToken(scope, now)))
+
+	if g.PowDifficulty > 0 {
+		fmt.Fprintf(&b, `
+		<input type="hidden" name="%s" value="%s" data-bits="%d">
+		<input type="hidden" name="%s" value="">
+		%s`, PowChallengeField, html.EscapeString(g.IssueChallenge(scope, now)), g.PowDifficulty, PowNonceField, powScript)
+	}
+
+	return b.String()
+}
+
+// Check runs every enabled check against a submission. get returns posted
+// form values (e.g. c.PostForm). A *Rejection is returned if the submission
+// should be refused. Check doesn't use up the token; call Consume once the
+// submission has been accepted.
+func (g *Guard) Check(scope, ip string, get func(name string) string, now time.Time) error {
+	if g.Limiter != nil {
+		if allowed, _, _ := g.Limiter.Allow(scope + "|" + ip); !allowed {
+			return &Rejection{Reason: ReasonRateLimited}
+		}
+	}
+
+	if get(HoneypotField) != "" {
+		return &Rejection{Reason: ReasonHoneypot}
+	}
+
+	token := get(TokenField)
+	if _, err := g.checkToken(scope, token, now); err != nil {
+		return err
+	}
+
+	if g.PowDifficulty > 0 {
+		if err := g.checkChallenge(scope, get(PowChallengeField), get(PowNonceField), now); err != nil {
+			return err
+		}
+	}
+
+	if g.isUsed("form|"+token, now) {
+		return &Rejection{Reason: ReasonInvalidToken}
+	}
+
+	return nil
+}
+
+// Consume uses up a checked submission's token (and proof-of-work challenge)
+// so a bot can't fetch the form once and post it over and over. Handlers call
+// it after validating the submission, so a visitor who is asked to fix a
+// field can send the same form again. If storing the submission then fails,
+// calling release makes the token usable again.
+func (g *Guard) Consume(scope string, get func(name string) string, now time.Time) (release func(), err error) {
+	token := get(TokenField)
+	issued, err := g.checkToken(scope, token, now)
+	if err != nil {
+		return nil, err
+	}
+	if !g.claim("form|"+token, issued.Add(g.MaxTokenAge), now) {
+		return nil, &Rejection{Reason: ReasonInvalidToken}
+	}
+
+	claimed := []string{"form|" + token}
+	if g.PowDifficulty > 0 {
+		challenge := "pow|" + get(PowChallengeField)
+		if !g.claim(challenge, now.Add(g.MaxTokenAge), now) {
+			g.unclaim(claimed[0])
+			return nil, &Rejection{Reason: ReasonProofOfWork}
+		}
+		claimed = append(claimed, challenge)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			for _, v := range claimed {
+				g.unclaim(v)
+			}
+		})
+	}, nil
+}
@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: MIT
+package antispam
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+)
+
+func reason(err error) string {
+	var r *Rejection
+	if errors.As(err, &r) {
+		return r.Reason
+	}
+	return ""
+}
+
+func values(m map[string]string) func(string) string {
+	return func(name string) string { return m[name] }
+}
+
+func TestCheckAcceptsValidSubmission(t *testing.T) {
+	g := NewGuard([]byte("secret"))
+	now := time.Now()
+	token := g.IssueToken("site:1", now.Add(-10*time.Second))
+
+	err := g.Check("site:1", "1.2.3.4", values(map[string]string{TokenField: token}), now)
+	if err != nil {
+		t.Errorf("Expected submission to pass, got %v", err)
+	}
+}
+
+func TestCheckRejections(t *testing.T) {
+	g := NewGuard([]byte("secret"))
+	now := time.Now()
+	good := g.IssueToken("site:1", now.Add(-10*time.Second))
+
+	tests := []struct {
+		name   string
+		fields map[string]string
+		want   string
+	}{
+		{"honeypot filled", map[string]string{TokenField: good, HoneypotField: "http://spam"}, ReasonHoneypot},
+		{"missing token", map[string]string{}, ReasonInvalidToken},
+		{"tampered token", map[string]string{TokenField: good + "0"}, ReasonInvalidToken},
+		{"other site's token", map[string]string{TokenField: g.IssueToken("site:2", now.Add(-time.Minute))}, ReasonInvalidToken},
+		{"expired token", map[string]string{TokenField: g.IssueToken("site:1", now.Add(-48*time.Hour))}, ReasonInvalidToken},
+		{"too fast", map[string]string{TokenField: g.IssueToken("site:1", now)}, ReasonTooFast},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := g.Check("site:1", "1.2.3.4", values(tt.fields), now)
+			if got := reason(err); got != tt.want {
+				t.Errorf("Expected reason %q, got %q (%v)", tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestCheckRejectsReusedToken(t *testing.T) {
+	g := NewGuard([]byte("secret"))
+	now := time.Now()
+	token := g.IssueToken("site:1", now.Add(-10*time.Second))
+
+	// Turned away for another reason, the token can still be used
+	honeypot := map[string]string{TokenField: token, HoneypotField: "http://spam"}
+	if got := reason(g.Check("site:1", "1.2.3.4", values(honeypot), now)); got != ReasonHoneypot {
+		t.Fatalf("Expected honeypot rejection, got %q", got)
+	}
+
+	// Checking alone doesn't use the token up, so a submission that fails
+	// validation can be corrected and sent again
+	fields := values(map[string]string{TokenField: token})
+	for i := 0; i < 2; i++ {
+		if err := g.Check("site:1", "1.2.3.4", fields, now); err != nil {
+			t.Fatalf("Expected submission %d to pass, got %v", i+1, err)
+		}
+	}
+
+	// A failed save gives the token back
+	release, err := g.Consume("site:1", fields, now)
+	if err != nil {
+		t.Fatalf("Expected token to be consumed, got %v", err)
+	}
+	release()
+	if _, err := g.Consume("site:1", fields, now); err != nil {
+		t.Fatalf("Expected released token to be usable, got %v", err)
+	}
+
+	if got := reason(g.Check("site:1", "5.6.7.8", fields, now)); got != ReasonInvalidToken {
+		t.Errorf("Expected a reused token to be rejected, got %q", got)
+	}
+	if _, err := g.Consume("site:1", fields, now); reason(err) != ReasonInvalidToken {
+		t.Errorf("Expected a reused token not to be consumed twice, got %v", err)
+	}
+
+	// Forms rendered in the same second get different tokens
+	if g.IssueToken("site:1", now) == g.IssueToken("site:1", now) {
+		t.Error("Expected every token to be unique")
+	}
+}
+
+func TestCheckRateLimit(t *testing.T) {
+	g := NewGuard([]byte("secret"))
+	g.Limiter = middleware.NewRateLimiter(2, time.Minute)
+	now := time.Now()
+	// Each submission comes from a freshly rendered form
+	fields := func() func(string) string {
+		return values(map[string]string{TokenField: g.IssueToken("site:1", now.Add(-time.Minute))})
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := g.Check("site:1", "1.2.3.4", fields(), now); err != nil {
+			t.Fatalf("Submission %d should pass: %v", i+1, err)
+		}
+	}
+	if got := reason(g.Check("site:1", "1.2.3.4", fields(), now)); got != ReasonRateLimited {
+		t.Errorf("Expected rate limit, got %q", got)
+	}
+	if err := g.Check("site:1", "5.6.7.8", fields(), now); err != nil {
+		t.Errorf("Other IPs should not be limited: %v", err)
+	}
+}
+
+// solve brute-forces a nonce the same way the browser script does
+func solve(challenge string, difficulty int) string {
+	for n := 0; ; n++ {
+		nonce := strconv.Itoa(n)
+		if LeadingZeroBits(challenge, nonce) >= difficulty {
+			return nonce
+		}
+	}
+}
+
+func TestProofOfWork(t *testing.T) {
+	g := NewGuard([]byte("secret"))
+	g.PowDifficulty = 8
+	now := time.Now()
+	token := g.IssueToken("site:1", now.Add(-time.Minute))
+	challenge := g.IssueChallenge("site:1", now)
+
+	unsolved := map[string]string{TokenField: token, PowChallengeField: challenge, PowNonceField: "x"}
+	if LeadingZeroBits(challenge, "x") < 8 {
+		if got := reason(g.Check("site:1", "1.2.3.4", values(unsolved), now)); got != ReasonProofOfWork {
+			t.Errorf("Expected proof-of-work rejection, got %q", got)
+		}
+	}
+
+	solved := map[string]string{TokenField: token, PowChallengeField: challenge, PowNonceField: solve(challenge, 8)}
+	if err := g.Check("site:1", "1.2.3.4", values(solved), now); err != nil {
+		t.Fatalf("Expected solved challenge to pass: %v", err)
+	}
+	if _, err := g.Consume("site:1", values(solved), now); err != nil {
+		t.Fatalf("Expected solved challenge to be consumed: %v", err)
+	}
+
+	// Replaying the same solution is rejected
+	if got := reason(g.Check("site:1", "1.2.3.4", values(solved), now)); got != ReasonProofOfWork {
+		t.Errorf("Expected replay to be rejected, got %q", got)
+	}
+}
+
+func TestFieldsHTML(t *testing.T) {
+	g := NewGuard([]byte("secret"))
+	html := g.FieldsHTML("site:1", time.Now())
+	if !strings.Contains(html, `name="website"`) || !strings.Contains(html, `name="_form_token"`) {
+		t.Errorf("Expected honeypot and token fields, got: %s", html)
+	}
+	if strings.Contains(html, "_pow_challenge") {
+		t.Error("Proof-of-work fields should be omitted when disabled")
+	}
+
+	g.PowDifficulty = 12
+	html = g.FieldsHTML("site:1", time.Now())
+	if !strings.Contains(html, `data-bits="12"`) || !strings.Contains(html, "<script>") {
+		t.Errorf("Expected proof-of-work challenge and script, got: %s", html)
+	}
+}
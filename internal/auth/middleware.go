@@ -60,29 +60,7 @@ func RequireAuth() gin.HandlerFunc {
 			return
 		}
 
-		// Check if user has access to this site
-		hasAccess := false
-
-		// Global admins can access any site
-		if user.IsGlobalAdmin {
-			hasAccess = true
-		}
-
-		// Site owner can access
-		if site.OwnerID == user.ID {
-			hasAccess = true
-		}
-
-		// Check if user is in SiteUsers (member of site)
-		if !hasAccess {
-			var siteUser models.SiteUser
-			err := db.GetDB().Where("site_id = ? AND user_id = ?", site.ID, user.ID).First(&siteUser).Error
-			if err == nil {
-				hasAccess = true
-			}
-		}
-
-		if !hasAccess {
+		if !HasSiteAccess(&user, site) {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You don't have access to this site"})
 			return
 		}
@@ -100,6 +78,25 @@ func RequireAuth() gin.HandlerFunc {
 	}
 }
 
+// HasSiteAccess reports whether a user may manage a site: global admins, the
+// site owner and the site's members
+func HasSiteAccess(user *models.User, site *models.Site) bool {
+	// Global admins can access any site
+	if user.IsGlobalAdmin {
+		return true
+	}
+
+	// Site owner can access
+	if site.OwnerID == user.ID {
+		return true
+	}
+
+	// Check if user is in SiteUsers (member of site)
+	var siteUser models.SiteUser
+	err := db.GetDB().Where("site_id = ? AND user_id = ?", site.ID, user.ID).First(&siteUser).Error
+	return err == nil
+}
+
 // RequireGlobalAdmin middleware requires global admin privileges
 func RequireGlobalAdmin() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: MIT
+package blocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// ChildPagesBlockData represents the JSON structure for child-pages blocks
+type ChildPagesBlockData struct {
+	Title string `json:"title"`
+}
+
+// ParseChildPagesBlockData parses child-pages block JSON
+func ParseChildPagesBlockData(dataJSON string) (*ChildPagesBlockData, error) {
+	var data ChildPagesBlockData
+	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse child pages block data: %w", err)
+	}
+	return &data, nil
+}
+
+// RenderChildPagesBlock renders links to a page's subpages. Pages live in the
+// database, so the caller looks up the children (typically with pages.Children).
+func RenderChildPagesBlock(dataJSON string, children []models.Page) (string, error) {
+	data, err := ParseChildPagesBlockData(dataJSON)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(`<nav class="child-pages-block" style="margin: 40px 0;">`)
+	if data.Title != "" {
+		fmt.Fprintf(&b, "\n\t<h2>%s</h2>", html.EscapeString(data.Title))
+	}
+
+	if len(children) == 0 {
+		b.WriteString("\n\t" + `<p style="color: var(--color-text-secondary, #666);">No subpages yet.</p>`)
+	} else {
+		b.WriteString("\n\t" + `<ul style="list-style: none; padding: 0; margin: 0;">`)
+		for _, child := range children {
+			fmt.Fprintf(&b, `
+		<li style="padding: 10px 0; border-bottom: 1px solid var(--color-border, #eee);"><a href="%s" style="font-weight: 600;">%s</a></li>`,
+				html.EscapeString(child.Slug), html.EscapeString(child.Title))
+		}
+		b.WriteString("\n\t</ul>")
+	}
+
+	b.WriteString("\n</nav>")
+	return b.String(), nil
+}
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: MIT
+package blocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/events"
+)
+
+// defaultEventsLimit is how many upcoming events an events block shows when no limit is set
+const defaultEventsLimit = 5
+
+// EventsBlockData represents the JSON structure for events and calendar blocks
+type EventsBlockData struct {
+	Title string `json:"title"`
+	Limit int    `json:"limit,omitempty"` // Events blocks only: how many upcoming events to list
+}
+
+// ParseEventsBlockData parses events or calendar block JSON and fills in defaults
+func ParseEventsBlockData(dataJSON string) (*EventsBlockData, error) {
+	var data EventsBlockData
+	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse events block data: %w", err)
+	}
+	if data.Limit <= 0 {
+		data.Limit = defaultEventsLimit
+	}
+	return &data, nil
+}
+
+// eventURL links to an event's detail page, pinned to the occurrence's date
+func eventURL(occ events.Occurrence) string {
+	return fmt.Sprintf("/events/%d?date=%s", occ.Event.ID, occ.Start.Format("2006-01-02"))
+}
+
+// RenderEventsBlock renders a list of upcoming events. Events live in the database,
+// so the caller looks up the occurrences (typically with events.Upcoming).
+func RenderEventsBlock(dataJSON string, occurrences []events.Occurrence) (string, error) {
+	data, err := ParseEventsBlockData(dataJSON)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(`<div class="events-block" style="margin: 40px 0;">`)
+	if data.Title != "" {
+		fmt.Fprintf(&b, "\n\t<h2>%s</h2>", html.EscapeString(data.Title))
+	}
+
+	if len(occurrences) == 0 {
+		b.WriteString("\n\t" + `<p style="color: var(--color-text-secondary, #666);">No upcoming events.</p>`)
+	} else {
+		b.WriteString("\n\t" + `<ul style="list-style: none; padding: 0; margin: 0;">`)
+		for _, occ := range occurrences {
+			fmt.Fprintf(&b, `
+		<li style="padding: 12px 0; border-bottom: 1px solid var(--color-border, #eee);">
+			<a href="%s" style="font-weight: 600; font-size: 1.1em;">%s</a>
+			<div style="color: var(--color-text-secondary, #666); font-size: 0.95em;">%s</div>`,
+				eventURL(occ), html.EscapeString(occ.Event.Title), html.EscapeString(occ.When()))
+			if occ.Event.Location != "" {
+				fmt.Fprintf(&b, "\n\t\t\t"+`<div style="color: var(--color-text-secondary, #666); font-size: 0.95em;">%s</div>`,
+					html.EscapeString(occ.Event.Location))
+			}
+			b.WriteString("\n\t\t</li>")
+		}
+		b.WriteString("\n\t</ul>")
+	}
+
+	b.WriteString("\n\t" + `<p style="margin-top: 12px; font-size: 0.9em;"><a href="/events">All events</a> · <a href="/events.ics">Subscribe (iCal)</a></p>`)
+	b.WriteString("\n</div>")
+	return b.String(), nil
+}
+
+// RenderCalendarBlock renders a month grid for the month containing month, with
+// occurrences placed on each day they span. Weeks start on Sunday.
+func RenderCalendarBlock(dataJSON string, month time.Time, occurrences []events.Occurrence) (string, error) {
+	data, err := ParseEventsBlockData(dataJSON)
+	if err != nil {
+		return "", err
+	}
+
+	from, to := events.MonthRange(month)
+
+	// Bucket occurrences by day of month
+	byDay := make(map[int][]events.Occurrence)
+	for _, occ := range occurrences {
+		last := occ.End
+		if !occ.Event.AllDay && last.After(occ.Start) {
+			// An event ending exactly at midnight doesn't spill into the next day
+			last = last.Add(-time.Nanosecond)
+		}
+		for d := occ.Start; ; d = d.AddDate(0, 0, 1) {
+			day := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, from.Location())
+			if day.After(last) || !day.Before(to) {
+				break
+			}
+			if !day.Before(from) {
+				byDay[day.Day()] = append(byDay[day.Day()], occ)
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(`<div class="calendar-block" style="margin: 40px 0;">`)
+	if data.Title != "" {
+		fmt.Fprintf(&b, "\n\t<h2>%s</h2>", html.EscapeString(data.Title))
+	}
+	fmt.Fprintf(&b, `
+	<div style="display: flex; justify-content: space-between; align-items: center; margin-bottom: 10px;">
+		<a href="/events?month=%s">&larr; %s</a>
+		<strong>%s</strong>
+		<a href="/events?month=%s">%s &rarr;</a>
+	</div>
+	<table class="calendar" style="width: 100%%; border-collapse: collapse; table-layout: fixed;">
+		<thead><tr>`,
+		from.AddDate(0, -1, 0).Format("2006-01"), from.AddDate(0, -1, 0).Format("Jan"),
+		from.Format("January 2006"),
+		to.Format("2006-01"), to.Format("Jan"))
+	for _, wd := range []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"} {
+		fmt.Fprintf(&b, `<th style="padding: 6px; font-size: 0.85em;">%s</th>`, wd)
+	}
+	b.WriteString("</tr></thead>\n\t\t<tbody>\n\t\t\t<tr>")
+
+	cellStyle := "vertical-align: top; height: 80px; padding: 4px; border: 1px solid var(--color-border, #ddd); font-size: 0.85em;"
+	for i := 0; i < int(from.Weekday()); i++ {
+		fmt.Fprintf(&b, `<td style="%s"></td>`, cellStyle)
+	}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Sunday && day.Day() != 1 {
+			b.WriteString("</tr>\n\t\t\t<tr>")
+		}
+		fmt.Fprintf(&b, `<td style="%s"><div style="font-weight: 600;">%d</div>`, cellStyle, day.Day())
+		for _, occ := range byDay[day.Day()] {
+			fmt.Fprintf(&b, `<div style="margin-top: 2px; overflow: hidden; text-overflow: ellipsis; white-space: nowrap;"><a href="%s" title="%s">%s</a></div>`,
+				eventURL(occ), html.EscapeString(occ.When()), html.EscapeString(occ.Event.Title))
+		}
+		b.WriteString("</td>")
+	}
+	for i := int(to.AddDate(0, 0, -1).Weekday()) + 1; i < 7; i++ {
+		fmt.Fprintf(&b, `<td style="%s"></td>`, cellStyle)
+	}
+	b.WriteString("</tr>\n\t\t</tbody>\n\t</table>")
+	b.WriteString("\n\t" + `<p style="margin-top: 12px; font-size: 0.9em;"><a href="/events.ics">Subscribe (iCal)</a></p>`)
+	b.WriteString("\n</div>")
+	return b.String(), nil
+}
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: MIT
+package blocks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/events"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+func TestRenderEventsBlock(t *testing.T) {
+	ev := &models.Event{ID: 3, Title: "Build <Weekend>", Location: "The Playa"}
+	occs := []events.Occurrence{{
+		Event: ev,
+		Start: time.Date(2025, 8, 23, 18, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, 8, 23, 21, 0, 0, 0, time.UTC),
+	}}
+
+	out, err := RenderEventsBlock(`{"title":"Coming Up"}`, occs)
+	if err != nil {
+		t.Fatalf("RenderEventsBlock failed: %v", err)
+	}
+	for _, want := range []string{"Coming Up", "Build &lt;Weekend&gt;", "/events/3?date=2025-08-23", "The Playa", "/events.ics"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q", want)
+		}
+	}
+
+	empty, _ := RenderEventsBlock(`{}`, nil)
+	if !strings.Contains(empty, "No upcoming events") {
+		t.Error("Expected empty state message")
+	}
+}
+
+func TestRenderCalendarBlock(t *testing.T) {
+	ev := &models.Event{ID: 5, Title: "Burn Week", AllDay: true}
+	occs := []events.Occurrence{{
+		Event: ev,
+		Start: time.Date(2025, 8, 30, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC),
+	}}
+
+	out, err := RenderCalendarBlock(`{"title":"Calendar"}`, time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC), occs)
+	if err != nil {
+		t.Fatalf("RenderCalendarBlock failed: %v", err)
+	}
+	if !strings.Contains(out, "August 2025") {
+		t.Error("Expected month heading")
+	}
+	if !strings.Contains(out, "/events?month=2025-07") || !strings.Contains(out, "/events?month=2025-09") {
+		t.Error("Expected previous and next month links")
+	}
+	// Aug 30 and 31 are in range; Sep 1-2 belong to next month's grid
+	if n := strings.Count(out, "Burn Week</a>"); n != 2 {
+		t.Errorf("Expected event on 2 days in August, got %d", n)
+	}
+	// August 2025 starts on a Friday and spans 6 weeks
+	if n := strings.Count(out, "<tr>"); n != 7 {
+		t.Errorf("Expected header plus 6 week rows, got %d", n)
+	}
+}
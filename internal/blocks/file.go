@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: MIT
+package blocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// FileBlockData represents the JSON structure for file download blocks
+type FileBlockData struct {
+	URL         string `json:"url"`   // /assets/<file> of a document in the media library
+	Title       string `json:"title"` // Shown instead of the uploaded file name when set
+	Description string `json:"description"`
+}
+
+// FileDownload describes the file behind a file download block, as recorded
+// in the media library
+type FileDownload struct {
+	Name string // Original file name, offered when downloading
+	Size string // Human-readable, e.g. "1.4 MB"
+	Type string // e.g. "PDF"
+	Icon string // Preview icon HTML (trusted)
+}
+
+// ParseFileBlockData parses file download block JSON
+func ParseFileBlockData(dataJSON string) (*FileBlockData, error) {
+	var data FileBlockData
+	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse file block data: %w", err)
+	}
+	return &data, nil
+}
+
+// RenderFileBlock renders a download link for a document. The file's details
+// live in the media library, so the caller looks them up; file may be nil for
+// files that aren't in it.
+func RenderFileBlock(dataJSON string, file *FileDownload) (string, error) {
+	data, err := ParseFileBlockData(dataJSON)
+	if err != nil {
+		return "", err
+	}
+	if data.URL == "" {
+		return "", nil
+	}
+
+	name := data.Title
+	download := ""
+	var details []string
+	icon := ""
+	if file != nil {
+		if name == "" {
+			name = file.Name
+		}
+		download = fmt.Sprintf(` download="%s"`, html.EscapeString(file.Name))
+		if file.Type != "" {
+			details = append(details, file.Type)
+		}
+		if file.Size != "" {
+			details = append(details, file.Size)
+		}
+		icon = file.Icon
+	}
+	if name == "" {
+		name = data.URL[strings.LastIndex(data.URL, "/")+1:]
+	}
+
+	var b strings.Builder
+	b.WriteString(`<div class="file-block" style="display: flex; align-items: center; gap: 16px; margin: 1.5em 0; padding: 16px; border: 1px solid var(--color-border, #ddd); border-radius: 8px;">`)
+	if icon != "" {
+		fmt.Fprintf(&b, "\n\t"+`<div class="file-icon" style="flex-shrink: 0;">%s</div>`, icon)
+	}
+	b.WriteString("\n\t" + `<div style="flex: 1; min-width: 0;">`)
+	fmt.Fprintf(&b, "\n\t\t"+`<a href="%s"%s style="font-weight: 600;">%s</a>`,
+		html.EscapeString(data.URL), download, html.EscapeString(name))
+	if len(details) > 0 {
+		fmt.Fprintf(&b, "\n\t\t"+`<div class="file-details" style="font-size: 0.9em; color: var(--color-text-secondary, #666);">%s</div>`,
+			html.EscapeString(strings.Join(details, " · ")))
+	}
+	if data.Description != "" {
+		fmt.Fprintf(&b, "\n\t\t"+`<p style="margin: 8px 0 0;">%s</p>`, html.EscapeString(data.Description))
+	}
+	b.WriteString("\n\t</div>\n</div>")
+	return b.String(), nil
+}
@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: MIT
+package blocks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderFileBlock(t *testing.T) {
+	file := &FileDownload{Name: "waiver <2026>.pdf", Size: "1.4 MB", Type: "PDF", Icon: "<svg></svg>"}
+
+	out, err := RenderFileBlock(`{"url":"/assets/abc.pdf","description":"Sign before arrival"}`, file)
+	if err != nil {
+		t.Fatalf("RenderFileBlock failed: %v", err)
+	}
+	for _, want := range []string{`href="/assets/abc.pdf"`, `download="waiver &lt;2026&gt;.pdf"`, ">waiver &lt;2026&gt;.pdf</a>", "PDF · 1.4 MB", "<svg></svg>", "Sign before arrival"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q", want)
+		}
+	}
+
+	titled, _ := RenderFileBlock(`{"url":"/assets/abc.pdf","title":"Camp Waiver"}`, file)
+	if !strings.Contains(titled, ">Camp Waiver</a>") {
+		t.Error("Expected the title to be used as link text")
+	}
+
+	// Files missing from the library still get a link
+	bare, _ := RenderFileBlock(`{"url":"/assets/abc.pdf"}`, nil)
+	if !strings.Contains(bare, ">abc.pdf</a>") || strings.Contains(bare, "download=") {
+		t.Errorf("Unexpected output for unknown file: %s", bare)
+	}
+
+	empty, _ := RenderFileBlock(`{"url":""}`, file)
+	if empty != "" {
+		t.Error("Expected no output without a file")
+	}
+}
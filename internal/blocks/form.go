@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: MIT
+package blocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// FormFieldTypes lists the input types supported by form blocks
+var FormFieldTypes = []string{"text", "email", "select", "checkbox", "textarea"}
+
+// maxFormValueLength caps how much text a single form field will accept
+const maxFormValueLength = 5000
+
+// FormField describes a single input in a form block
+type FormField struct {
+	Name     string   `json:"name"`  // Input name, derived from the label
+	Label    string   `json:"label"` // Text shown to visitors
+	Type     string   `json:"type"`  // text, email, select, checkbox, textarea
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"` // Choices for select fields
+}
+
+// FormBlockData represents the JSON structure for form blocks
+type FormBlockData struct {
+	Title               string      `json:"title"`
+	Description         string      `json:"description"`
+	Fields              []FormField `json:"fields"`
+	SubmitLabel         string      `json:"submit_label"`
+	ConfirmationMessage string      `json:"confirmation_message"`
+	NotifyEmail         string      `json:"notify_email"` // Optional address to email on each submission
+}
+
+// IsValidFormFieldType reports whether t is a supported form field type
+func IsValidFormFieldType(t string) bool {
+	for _, valid := range FormFieldTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+var fieldNameCleaner = regexp.MustCompile(`[^a-z0-9]+`)
+
+// FormFieldName derives an input name from a field label, e.g. "Your Email" -> "your_email"
+func FormFieldName(label string) string {
+	name := fieldNameCleaner.ReplaceAllString(strings.ToLower(label), "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		name = "field"
+	}
+	return name
+}
+
+// ParseFormBlockData parses form block JSON and fills in defaults
+func ParseFormBlockData(dataJSON string) (*FormBlockData, error) {
+	var data FormBlockData
+	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse form block data: %w", err)
+	}
+	if data.SubmitLabel == "" {
+		data.SubmitLabel = "Submit"
+	}
+	if data.ConfirmationMessage == "" {
+		data.ConfirmationMessage = "Thanks! Your response has been received."
+	}
+	return &data, nil
+}
+
+// RenderFormBlock renders a form block. Submissions are posted to /forms/<blockID>,
+// so unlike other blocks the caller must supply the block's ID. extraHTML is
+// placed inside the form and is used for hidden anti-spam fields.
+func RenderFormBlock(blockID uint, dataJSON string, extraHTML string) (string, error) {
+	data, err := ParseFormBlockData(dataJSON)
+	if err != nil {
+		return "", err
+	}
+
+	inputStyle := "width: 100%; padding: 10px; border: 1px solid #ddd; border-radius: 4px; font-size: 14px; box-sizing: border-box; font-family: inherit;"
+	labelStyle := "display: block; margin-bottom: 8px; font-weight: 500;"
+
+	var b strings.Builder
+	b.WriteString(`<div class="form-block" style="margin: 40px 0;">`)
+	if data.Title != "" {
+		fmt.Fprintf(&b, "\n\t<h2>%s</h2>", html.EscapeString(data.Title))
+	}
+	if data.Description != "" {
+		fmt.Fprintf(&b, "\n\t<p>%s</p>", html.EscapeString(data.Description))
+	}
+	fmt.Fprintf(&b, "\n\t"+`<form method="POST" action="/forms/%d" style="max-width: 500px;">`, blockID)
+	if extraHTML != "" {
+		b.WriteString("\n\t\t" + extraHTML)
+	}
+
+	for _, field := range data.Fields {
+		id := fmt.Sprintf("form-%d-%s", blockID, field.Name)
+		name := "f_" + field.Name
+		label := html.EscapeString(field.Label)
+		required := ""
+		if field.Required {
+			required = " required"
+			label += ` <span style="color: #dc2626;">*</span>`
+		}
+
+		b.WriteString("\n\t\t" + `<div style="margin-bottom: 20px;">`)
+		switch field.Type {
+		case "textarea":
+			fmt.Fprintf(&b, `
+			<label for="%s" style="%s">%s</label>
+			<textarea id="%s" name="%s" rows="6"%s style="%s"></textarea>`,
+				id, labelStyle, label, id, name, required, inputStyle)
+		case "select":
+			fmt.Fprintf(&b, `
+			<label for="%s" style="%s">%s</label>
+			<select id="%s" name="%s"%s style="%s">
+				<option value="">Choose...</option>`,
+				id, labelStyle, label, id, name, required, inputStyle)
+			for _, opt := range field.Options {
+				safeOpt := html.EscapeString(opt)
+				fmt.Fprintf(&b, "\n\t\t\t\t"+`<option value="%s">%s</option>`, safeOpt, safeOpt)
+			}
+			b.WriteString("\n\t\t\t</select>")
+		case "checkbox":
+			fmt.Fprintf(&b, `
+			<label for="%s" style="display: flex; gap: 8px; align-items: center; font-weight: 500;">
+				<input type="checkbox" id="%s" name="%s" value="yes"%s> %s
+			</label>`,
+				id, id, name, required, label)
+		default:
+			inputType := "text"
+			if field.Type == "email" {
+				inputType = "email"
+			}
+			fmt.Fprintf(&b, `
+			<label for="%s" style="%s">%s</label>
+			<input type="%s" id="%s" name="%s"%s style="%s">`,
+				id, labelStyle, label, inputType, id, name, required, inputStyle)
+		}
+		b.WriteString("\n\t\t</div>")
+	}
+
+	fmt.Fprintf(&b, `
+		<button type="submit" style="background: var(--color-primary, #2563eb); color: white; padding: 12px 24px; border: none; border-radius: 4px; cursor: pointer; font-size: 14px; font-weight: 600;">%s</button>
+	</form>
+</div>`, html.EscapeString(data.SubmitLabel))
+
+	return b.String(), nil
+}
+
+// ValidateSubmission checks posted values against the form's fields. get is called
+// with each field's input name (e.g. c.PostForm). It returns the accepted values keyed
+// by field name, and a list of human-readable errors if any field is invalid.
+func (d *FormBlockData) ValidateSubmission(get func(name string) string) (map[string]string, []string) {
+	values := make(map[string]string, len(d.Fields))
+	var errs []string
+
+	for _, field := range d.Fields {
+		value := strings.TrimSpace(get("f_" + field.Name))
+		if len(value) > maxFormValueLength {
+			errs = append(errs, fmt.Sprintf("%s is too long", field.Label))
+			continue
+		}
+
+		if value == "" {
+			if field.Required {
+				errs = append(errs, fmt.Sprintf("%s is required", field.Label))
+			}
+			values[field.Name] = ""
+			continue
+		}
+
+		switch field.Type {
+		case "email":
+			if _, err := mail.ParseAddress(value); err != nil {
+				errs = append(errs, fmt.Sprintf("%s must be a valid email address", field.Label))
+				continue
+			}
+		case "select":
+			found := false
+			for _, opt := range field.Options {
+				if opt == value {
+					found = true
+					break
+				}
+			}
+			if !found {
+				errs = append(errs, fmt.Sprintf("%s has an invalid choice", field.Label))
+				continue
+			}
+		case "checkbox":
+			value = "yes"
+		}
+
+		values[field.Name] = value
+	}
+
+	return values, errs
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: MIT
+package blocks
+
+import (
+	"strings"
+	"testing"
+)
+
+const testFormJSON = `{
+	"title": "Volunteer <Signup>",
+	"fields": [
+		{"name":"name","label":"Name","type":"text","required":true},
+		{"name":"email","label":"Email","type":"email","required":true},
+		{"name":"team","label":"Team","type":"select","options":["Kitchen","Build"]},
+		{"name":"agree","label":"I agree","type":"checkbox","required":true},
+		{"name":"notes","label":"Notes","type":"textarea"}
+	]
+}`
+
+func TestRenderFormBlock(t *testing.T) {
+	html, err := RenderFormBlock(42, testFormJSON, `<input type="hidden" name="_form_token" value="t">`)
+	if err != nil {
+		t.Fatalf("RenderFormBlock failed: %v", err)
+	}
+
+	if !strings.Contains(html, `action="/forms/42"`) {
+		t.Errorf("Expected form to post to /forms/42, got: %s", html)
+	}
+	if !strings.Contains(html, "Volunteer &lt;Signup&gt;") {
+		t.Errorf("Expected escaped title, got: %s", html)
+	}
+	for _, want := range []string{`name="_form_token"`, `name="f_name"`, `type="email"`, `<option value="Kitchen">`, `type="checkbox"`, `<textarea`, ">Submit</button>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected HTML to contain %q", want)
+		}
+	}
+}
+
+func TestRenderFormBlockInvalidJSON(t *testing.T) {
+	if _, err := RenderFormBlock(1, `{invalid`, ""); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestFormValidateSubmission(t *testing.T) {
+	data, err := ParseFormBlockData(testFormJSON)
+	if err != nil {
+		t.Fatalf("ParseFormBlockData failed: %v", err)
+	}
+
+	posted := map[string]string{
+		"f_name":  "  Whiskers ",
+		"f_email": "whiskers@example.com",
+		"f_team":  "Build",
+		"f_agree": "yes",
+	}
+	values, errs := data.ValidateSubmission(func(name string) string { return posted[name] })
+	if len(errs) != 0 {
+		t.Fatalf("Expected no errors, got %v", errs)
+	}
+	if values["name"] != "Whiskers" {
+		t.Errorf("Expected trimmed name, got %q", values["name"])
+	}
+	if values["agree"] != "yes" {
+		t.Errorf("Expected checkbox value 'yes', got %q", values["agree"])
+	}
+	if _, ok := values["notes"]; !ok {
+		t.Error("Expected optional empty field to be present")
+	}
+}
+
+func TestFormValidateSubmissionErrors(t *testing.T) {
+	data, _ := ParseFormBlockData(testFormJSON)
+
+	posted := map[string]string{
+		"f_email": "not-an-email",
+		"f_team":  "Sabotage",
+	}
+	_, errs := data.ValidateSubmission(func(name string) string { return posted[name] })
+
+	// name required, email invalid, team invalid choice, agree required
+	if len(errs) != 4 {
+		t.Errorf("Expected 4 errors, got %d: %v", len(errs), errs)
+	}
+}
+
+func TestFormFieldName(t *testing.T) {
+	tests := map[string]string{
+		"Your Email":     "your_email",
+		"  T-Shirt Size": "t_shirt_size",
+		"!!!":            "field",
+	}
+	for label, want := range tests {
+		if got := FormFieldName(label); got != want {
+			t.Errorf("FormFieldName(%q) = %q, want %q", label, got, want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: MIT
+package blocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/thatcatcamp/stinkykitty/internal/events"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/posts"
+)
+
+// defaultPostsLimit is how many posts a latest-posts block shows when no limit is set
+const defaultPostsLimit = 3
+
+// PostsBlockData represents the JSON structure for latest-posts blocks
+type PostsBlockData struct {
+	Title string `json:"title"`
+	Limit int    `json:"limit,omitempty"`
+	Tag   string `json:"tag,omitempty"` // Only show posts with this tag; empty shows all
+}
+
+// ParsePostsBlockData parses latest-posts block JSON and fills in defaults
+func ParsePostsBlockData(dataJSON string) (*PostsBlockData, error) {
+	var data PostsBlockData
+	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse posts block data: %w", err)
+	}
+	if data.Limit <= 0 {
+		data.Limit = defaultPostsLimit
+	}
+	return &data, nil
+}
+
+// PostDate formats a post's publish date for display
+func PostDate(p *models.Post) string {
+	return p.PublishedAt.In(events.Location()).Format("January 2, 2006")
+}
+
+// RenderPostSummaries renders post cards (cover image, title, date, author and
+// summary) as used by the blog archive and latest-posts blocks
+func RenderPostSummaries(list []models.Post) string {
+	var b strings.Builder
+	for i := range list {
+		p := &list[i]
+		b.WriteString("\n\t" + `<article class="post-summary" style="display: flex; gap: 16px; padding: 16px 0; border-bottom: 1px solid var(--color-border, #eee);">`)
+		if p.CoverImage != "" {
+			fmt.Fprintf(&b, `
+		<a href="%s" style="flex-shrink: 0;"><img src="%s" alt="" loading="lazy" style="width: 160px; height: 110px; object-fit: cover; border-radius: 6px;"></a>`,
+				html.EscapeString(posts.URL(p)), html.EscapeString(p.CoverImage))
+		}
+		byline := PostDate(p)
+		if p.Author != "" {
+			byline += " · " + p.Author
+		}
+		fmt.Fprintf(&b, `
+		<div style="min-width: 0;">
+			<h3 style="margin: 0 0 4px;"><a href="%s">%s</a></h3>
+			<div style="color: var(--color-text-secondary, #666); font-size: 0.9em;">%s</div>
+			<p style="margin: 8px 0 0;">%s</p>
+		</div>
+	</article>`, html.EscapeString(posts.URL(p)), html.EscapeString(p.Title), html.EscapeString(byline), html.EscapeString(posts.Summary(p)))
+	}
+	return b.String()
+}
+
+// RenderPostsBlock renders a site's latest posts. Posts live in the database,
+// so the caller looks them up (typically with posts.Latest).
+func RenderPostsBlock(dataJSON string, list []models.Post) (string, error) {
+	data, err := ParsePostsBlockData(dataJSON)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(`<div class="posts-block" style="margin: 40px 0;">`)
+	if data.Title != "" {
+		fmt.Fprintf(&b, "\n\t<h2>%s</h2>", html.EscapeString(data.Title))
+	}
+
+	if len(list) == 0 {
+		b.WriteString("\n\t" + `<p style="color: var(--color-text-secondary, #666);">No posts yet.</p>`)
+	} else {
+		b.WriteString(RenderPostSummaries(list))
+	}
+
+	more := "/blog"
+	if data.Tag != "" {
+		more = posts.TagURL(data.Tag)
+	}
+	fmt.Fprintf(&b, "\n\t"+`<p style="margin-top: 12px; font-size: 0.9em;"><a href="%s">All posts</a> · <a href="/feed.xml">RSS</a></p>`, html.EscapeString(more))
+	b.WriteString("\n</div>")
+	return b.String(), nil
+}
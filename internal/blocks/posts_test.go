@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: MIT
+package blocks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+func TestRenderPostsBlock(t *testing.T) {
+	list := []models.Post{{
+		Slug: "build-week", Title: "Build <Week>", Author: "Mittens", Excerpt: "We built things",
+		CoverImage: "/assets/cover.jpg", PublishedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}}
+
+	out, err := RenderPostsBlock(`{"title":"Latest News","tag":"news"}`, list)
+	if err != nil {
+		t.Fatalf("RenderPostsBlock failed: %v", err)
+	}
+	for _, want := range []string{"Latest News", "Build &lt;Week&gt;", `href="/blog/build-week"`, "/assets/cover.jpg", "Mittens", "We built things", `href="/blog/tag/news"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q", want)
+		}
+	}
+
+	empty, _ := RenderPostsBlock(`{}`, nil)
+	if !strings.Contains(empty, "No posts yet") || !strings.Contains(empty, `href="/blog"`) {
+		t.Error("Expected empty state message and archive link")
+	}
+}
@@ -28,7 +28,7 @@ func RenderBlock(blockType string, dataJSON string) (string, error) {
 	case "spacer":
 		return renderSpacerBlock(dataJSON)
 	case "contact":
-		return renderContactBlock(dataJSON)
+		return RenderContactBlock(dataJSON, "")
 	case "columns":
 		return renderColumnsBlock(dataJSON)
 	default:
@@ -64,8 +64,24 @@ type ImageBlockData struct {
 	Caption string `json:"caption"`
 }
 
+// ResponsiveImage describes an uploaded image's size and the smaller copies
+// browsers can pick from
+type ResponsiveImage struct {
+	Width  int
+	Height int
+	SrcSet string
+	Sizes  string
+}
+
 // renderImageBlock renders an image block
 func renderImageBlock(dataJSON string) (string, error) {
+	return RenderImageBlock(dataJSON, nil)
+}
+
+// RenderImageBlock renders an image block. When img is set the image gets a
+// srcset and its dimensions, so browsers download a copy that fits and keep
+// space for it while it loads.
+func RenderImageBlock(dataJSON string, img *ResponsiveImage) (string, error) {
 	var data ImageBlockData
 	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
 		return "", fmt.Errorf("failed to parse image block data: %w", err)
@@ -75,9 +91,17 @@ func renderImageBlock(dataJSON string) (string, error) {
 	safeAlt := html.EscapeString(data.Alt)
 	safeCaption := html.EscapeString(data.Caption)
 
+	attrs := ""
+	if img != nil && img.Width > 0 && img.Height > 0 {
+		attrs += fmt.Sprintf(` width="%d" height="%d"`, img.Width, img.Height)
+		if img.SrcSet != "" {
+			attrs += fmt.Sprintf(` srcset="%s" sizes="%s"`, html.EscapeString(img.SrcSet), html.EscapeString(img.Sizes))
+		}
+	}
+
 	// Build image HTML
 	htmlStr := fmt.Sprintf(`<div class="image-block">
-		<img src="%s" alt="%s" style="max-width: 100%%; height: auto; display: block;">`, data.URL, safeAlt)
+		<img src="%s" alt="%s"%s loading="lazy" decoding="async" style="max-width: 100%%; height: auto; display: block;">`, data.URL, safeAlt, attrs)
 
 	if safeCaption != "" {
 		htmlStr += fmt.Sprintf(`<p style="font-size: 14px; color: #666; margin-top: 8px; font-style: italic;">%s</p>`, safeCaption)
@@ -250,8 +274,9 @@ type ContactBlockData struct {
 	Subtitle string `json:"subtitle"`
 }
 
-// renderContactBlock renders an embedded contact form
-func renderContactBlock(dataJSON string) (string, error) {
+// RenderContactBlock renders an embedded contact form. extraHTML is placed
+// inside the form and is used for hidden anti-spam fields.
+func RenderContactBlock(dataJSON string, extraHTML string) (string, error) {
 	var data ContactBlockData
 	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
 		return "", fmt.Errorf("failed to parse contact block data: %w", err)
@@ -275,6 +300,7 @@ func renderContactBlock(dataJSON string) (string, error) {
 
 	formHTML += `
 	<form method="POST" action="/contact" style="max-width: 500px;">
+		` + extraHTML + `
 		<div style="margin-bottom: 20px;">
 			<label for="contact-name" style="display: block; margin-bottom: 8px; font-weight: 500;">Name:</label>
 			<input type="text" id="contact-name" name="name" required style="width: 100%; padding: 10px; border: 1px solid #ddd; border-radius: 4px; font-size: 14px; box-sizing: border-box;">
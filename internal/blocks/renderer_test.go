@@ -130,3 +130,28 @@ func TestRenderColumnsBlockValidatesColumnCount(t *testing.T) {
 		t.Errorf("Expected invalid column count to default to 2, got: %s", html)
 	}
 }
+
+func TestRenderImageBlockResponsive(t *testing.T) {
+	dataJSON := `{"url":"/assets/a.jpg","alt":"Camp"}`
+	html, err := RenderImageBlock(dataJSON, &ResponsiveImage{
+		Width:  2000,
+		Height: 1000,
+		SrcSet: "/assets/w400/a.jpg 400w, /assets/a.jpg 2000w",
+		Sizes:  "760px",
+	})
+	if err != nil {
+		t.Fatalf("RenderImageBlock failed: %v", err)
+	}
+
+	for _, want := range []string{`width="2000" height="1000"`, `srcset="/assets/w400/a.jpg 400w, /assets/a.jpg 2000w"`, `sizes="760px"`, `loading="lazy"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected HTML to contain %s, got: %s", want, html)
+		}
+	}
+
+	// Images outside the media library still load lazily, without a srcset
+	html, _ = RenderBlock("image", dataJSON)
+	if strings.Contains(html, "srcset") || !strings.Contains(html, `loading="lazy"`) {
+		t.Errorf("Expected a plain lazy image, got: %s", html)
+	}
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: MIT
+package blocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/thatcatcamp/stinkykitty/internal/shifts"
+)
+
+// ShiftsBlockData represents the JSON structure for volunteer shift sign-up blocks
+type ShiftsBlockData struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Role        string `json:"role"` // Only list shifts with this role; empty lists all
+}
+
+// ParseShiftsBlockData parses shifts block JSON
+func ParseShiftsBlockData(dataJSON string) (*ShiftsBlockData, error) {
+	var data ShiftsBlockData
+	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse shifts block data: %w", err)
+	}
+	return &data, nil
+}
+
+// RenderShiftsBlock renders upcoming shifts, each with a sign-up form posting to
+// /shifts/<id>/signup. Shifts live in the database, so the caller looks up the
+// slots. extraHTML is placed inside each form and is used for anti-spam fields.
+func RenderShiftsBlock(dataJSON string, slots []shifts.Slot, extraHTML string) (string, error) {
+	data, err := ParseShiftsBlockData(dataJSON)
+	if err != nil {
+		return "", err
+	}
+
+	inputStyle := "flex: 1; min-width: 140px; padding: 8px; border: 1px solid #ddd; border-radius: 4px; font-size: 14px; font-family: inherit;"
+
+	var b strings.Builder
+	b.WriteString(`<div class="shifts-block" style="margin: 40px 0;">`)
+	if data.Title != "" {
+		fmt.Fprintf(&b, "\n\t<h2>%s</h2>", html.EscapeString(data.Title))
+	}
+	if data.Description != "" {
+		fmt.Fprintf(&b, "\n\t<p>%s</p>", html.EscapeString(data.Description))
+	}
+
+	if len(slots) == 0 {
+		b.WriteString("\n\t" + `<p style="color: var(--color-text-secondary, #666);">No upcoming shifts.</p>`)
+	}
+
+	for _, slot := range slots {
+		shift := slot.Shift
+		fmt.Fprintf(&b, `
+	<div class="shift" style="padding: 16px; border: 1px solid var(--color-border, #ddd); border-radius: 6px; margin-bottom: 12px;">
+		<div style="display: flex; justify-content: space-between; gap: 12px; flex-wrap: wrap;">
+			<strong>%s</strong>
+			<span style="color: var(--color-text-secondary, #666);">%s</span>
+		</div>`, html.EscapeString(shift.Role), html.EscapeString(shifts.When(&shift)))
+		if shift.Description != "" {
+			fmt.Fprintf(&b, "\n\t\t"+`<p style="margin: 8px 0;">%s</p>`, html.EscapeString(shift.Description))
+		}
+
+		left := slot.SpotsLeft()
+		if left == 0 {
+			b.WriteString("\n\t\t" + `<p style="margin: 8px 0 0; font-weight: 600;">Full</p>`)
+		} else {
+			spots := "spots"
+			if left == 1 {
+				spots = "spot"
+			}
+			fmt.Fprintf(&b, `
+		<p style="margin: 8px 0;">%d of %d %s left</p>
+		<form method="POST" action="/shifts/%d/signup" style="display: flex; gap: 8px; flex-wrap: wrap;">`,
+				left, shift.Capacity, spots, shift.ID)
+			if extraHTML != "" {
+				b.WriteString("\n\t\t\t" + extraHTML)
+			}
+			fmt.Fprintf(&b, `
+			<input type="text" name="name" placeholder="Your name" required aria-label="Your name" style="%s">
+			<input type="email" name="email" placeholder="Your email" required aria-label="Your email" style="%s">
+			<button type="submit" style="background: var(--color-primary, #2563eb); color: white; padding: 8px 16px; border: none; border-radius: 4px; cursor: pointer; font-size: 14px; font-weight: 600;">Sign Up</button>
+		</form>`, inputStyle, inputStyle)
+		}
+		b.WriteString("\n\t</div>")
+	}
+
+	b.WriteString("\n</div>")
+	return b.String(), nil
+}
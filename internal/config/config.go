@@ -57,6 +57,28 @@ func setDefaults() {
 	v.SetDefault("storage.sites_dir", "/var/lib/stinkykitty/sites")
 	v.SetDefault("storage.backups_dir", "/var/lib/stinkykitty/backups")
 	v.SetDefault("storage.media_dir", "/var/lib/stinkykitty/media")
+	v.SetDefault("storage.quota", "2GB") // Media each site may upload unless it has its own quota; "unlimited" for none
+
+	// S3-compatible media storage, used by sites with storage type "s3"
+	v.SetDefault("storage.s3.endpoint", "") // e.g. https://s3.us-west-2.amazonaws.com or http://localhost:9000
+	v.SetDefault("storage.s3.region", "us-east-1")
+	v.SetDefault("storage.s3.bucket", "") // Default bucket for sites that don't set their own
+	v.SetDefault("storage.s3.access_key", "")
+	v.SetDefault("storage.s3.secret_key", "")
+	v.SetDefault("storage.s3.path_style", true)  // endpoint/bucket addressing, needed by MinIO
+	v.SetDefault("storage.s3.serve", "redirect") // "redirect" to signed URLs or "proxy" through the server
+	v.SetDefault("storage.s3.url_expiry", "1h")  // How long signed URLs last
+
+	// Upload defaults
+	v.SetDefault("media.max_pixels", 50000000)                                     // Largest image accepted, as width × height
+	v.SetDefault("media.keep_metadata", false)                                     // Keep EXIF/XMP data (GPS included) in uploaded images
+	v.SetDefault("media.document_types", "pdf,txt,csv,docx,xlsx,pptx,odt,ods,odp") // Document extensions the media library accepts
+	v.SetDefault("media.zip.max_files", 500)                                       // Images per zip import
+	v.SetDefault("media.zip.max_size", "1GB")                                      // Uncompressed images per zip import
+
+	// Image transformation defaults (/assets/<file>?w=...&h=...)
+	v.SetDefault("media.transform.presets", "400x0,800x0,1200x0,1600x0,200x200,400x400,800x800,1200x630") // Allowed WIDTHxHEIGHT sizes, 0 follows the other side
+	v.SetDefault("media.transform.qualities", "60,75,85")                                                 // Allowed JPEG qualities
 
 	// Backup defaults
 	v.SetDefault("backups.path", "/var/lib/stinkykitty/backups")
@@ -77,6 +99,28 @@ func setDefaults() {
 	v.SetDefault("auth.jwt_expiry_hours", 8)
 	v.SetDefault("auth.bcrypt_cost", 12)
 
+	// Email queue defaults
+	v.SetDefault("email.queue_interval", "30s") // How often queued emails are sent
+	v.SetDefault("email.max_attempts", 6)       // Give up after this many failed sends...
+	v.SetDefault("email.retry_for", "48h")      // ...once an email has been queued this long
+
+	// Public form spam protection defaults
+	v.SetDefault("spam.min_fill_time", "3s")  // Reject forms submitted faster than this
+	v.SetDefault("spam.rate_limit", 5)        // Submissions allowed per IP per interval
+	v.SetDefault("spam.rate_interval", "10m") // Rate limit window
+	v.SetDefault("spam.pow_difficulty", 0)    // Proof-of-work leading zero bits (0 = off, ~16 is reasonable)
+
+	// Events defaults
+	v.SetDefault("events.timezone", "UTC") // IANA zone event times are entered and shown in
+
+	// Volunteer shift defaults
+	v.SetDefault("shifts.reminder_lead", "24h")     // How long before a shift to email a reminder
+	v.SetDefault("shifts.reminder_interval", "10m") // How often to check for due reminders
+
+	// Blog defaults
+	v.SetDefault("posts.per_page", 10)  // Posts per archive page
+	v.SetDefault("posts.feed_size", 20) // Posts included in RSS/Atom feeds
+
 	// TLS defaults
 	v.SetDefault("server.tls_enabled", false)
 	v.SetDefault("tls.email", "")
@@ -41,6 +41,21 @@ func InitDB(dbType, dbPath string) error {
 		&models.MenuItem{},
 		&models.MediaItem{},
 		&models.MediaTag{},
+		&models.MediaReference{},
+		&models.MediaCollection{},
+		&models.FormSubmission{},
+		&models.ContactSubmission{},
+		&models.OutboundEmail{},
+		&models.SpamCounter{},
+		&models.Event{},
+		&models.Shift{},
+		&models.ShiftSignup{},
+		&models.Post{},
+		&models.PostTag{},
+		&models.Redirect{},
+		&models.Archive{},
+		&models.ArchivePage{},
+		&models.Announcement{},
 	); err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
@@ -75,6 +90,18 @@ func InitFTSIndex() error {
 		return fmt.Errorf("failed to create FTS index: %w", err)
 	}
 
+	_, err = sqlDB.Exec(`
+		CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(
+			post_id UNINDEXED,
+			site_id UNINDEXED,
+			title,
+			content
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create posts FTS index: %w", err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,196 @@
+// SPDX-License-Identifier: MIT
+package email
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+)
+
+// Outbound email statuses
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+)
+
+// Sender delivers a single email. *EmailService satisfies this interface.
+type Sender interface {
+	SendEmail(to, subject, body string) error
+}
+
+// Enqueue stores an email for delivery by the queue worker
+func Enqueue(db *gorm.DB, to, subject, body string) error {
+	msg := models.OutboundEmail{
+		To:            to,
+		Subject:       subject,
+		Body:          body,
+		Status:        StatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := db.Create(&msg).Error; err != nil {
+		return fmt.Errorf("failed to queue email: %w", err)
+	}
+	return nil
+}
+
+// Queue delivers queued emails in the background, retrying failures with
+// backoff. An email is only given up on once it has had MaxAttempts tries and
+// has been queued for RetryFor, so a mail server that's down for a while
+// doesn't lose anything; given-up emails can still be resent with Retry.
+type Queue struct {
+	DB           *gorm.DB
+	Sender       Sender // If nil, an EmailService is created from the environment on each run
+	PollInterval time.Duration
+	MaxAttempts  int
+	RetryFor     time.Duration
+	BatchSize    int
+	ticker       *time.Ticker
+	done         chan bool
+	stopChan     chan bool
+}
+
+// NewQueue creates a new email queue worker
+func NewQueue(db *gorm.DB) *Queue {
+	return &Queue{
+		DB:           db,
+		PollInterval: 30 * time.Second,
+		MaxAttempts:  6,
+		RetryFor:     48 * time.Hour,
+		BatchSize:    20,
+		done:         make(chan bool, 1),
+		stopChan:     make(chan bool, 1),
+	}
+}
+
+// Start begins processing the queue in a goroutine
+// Returns a done channel that will be signalled when the queue stops
+func (q *Queue) Start() chan bool {
+	go func() {
+		q.ticker = time.NewTicker(q.PollInterval)
+		defer q.ticker.Stop()
+
+		if err := q.ProcessPending(); err != nil {
+			log.Printf("email queue: %v\n", err)
+		}
+
+		for {
+			select {
+			case <-q.stopChan:
+				q.done <- true
+				return
+			case <-q.ticker.C:
+				if err := q.ProcessPending(); err != nil {
+					log.Printf("email queue: %v\n", err)
+				}
+			}
+		}
+	}()
+
+	return q.done
+}
+
+// Stop stops the queue worker
+func (q *Queue) Stop() {
+	select {
+	case q.stopChan <- true:
+	default:
+	}
+}
+
+// ProcessPending attempts delivery of every queued email that is due
+func (q *Queue) ProcessPending() error {
+	var pending []models.OutboundEmail
+	if err := q.DB.Where("status = ? AND next_attempt_at <= ?", StatusPending, time.Now()).
+		Order("next_attempt_at ASC").Limit(q.BatchSize).Find(&pending).Error; err != nil {
+		return fmt.Errorf("failed to load queued emails: %w", err)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	sender := q.Sender
+	if sender == nil {
+		svc, err := NewEmailService()
+		if err != nil {
+			// Leave messages queued until SMTP is configured
+			return fmt.Errorf("%d emails waiting: %w", len(pending), err)
+		}
+		sender = svc
+	}
+
+	for i := range pending {
+		q.deliver(sender, &pending[i])
+	}
+	return nil
+}
+
+// deliver sends one email and records the outcome
+func (q *Queue) deliver(sender Sender, msg *models.OutboundEmail) {
+	msg.Attempts++
+	err := sender.SendEmail(msg.To, msg.Subject, msg.Body)
+
+	if err == nil {
+		now := time.Now()
+		msg.Status = StatusSent
+		msg.SentAt = &now
+		msg.LastError = ""
+	} else {
+		msg.LastError = err.Error()
+		if msg.Attempts >= q.MaxAttempts && time.Since(msg.CreatedAt) >= q.RetryFor {
+			msg.Status = StatusFailed
+			log.Printf("email queue: giving up on email %d to %s after %d attempts: %v\n", msg.ID, msg.To, msg.Attempts, err)
+		} else {
+			msg.NextAttemptAt = time.Now().Add(retryDelay(msg.Attempts))
+		}
+	}
+
+	if err := q.DB.Save(msg).Error; err != nil {
+		log.Printf("email queue: failed to update email %d: %v\n", msg.ID, err)
+	}
+}
+
+// retryDelay returns how long to wait before the next attempt: 1m, 2m, 4m... capped at 1h
+func retryDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts > 6 {
+		return time.Hour
+	}
+	delay := time.Minute << (attempts - 1)
+	if delay > time.Hour {
+		return time.Hour
+	}
+	return delay
+}
+
+// Failed returns the emails the queue gave up on, newest first
+func Failed(db *gorm.DB) ([]models.OutboundEmail, error) {
+	var msgs []models.OutboundEmail
+	if err := db.Where("status = ?", StatusFailed).Order("id DESC").Find(&msgs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load failed emails: %w", err)
+	}
+	return msgs, nil
+}
+
+// Retry queues failed emails to be sent again, all of them if no IDs are
+// given. It returns the number queued.
+func Retry(db *gorm.DB, ids ...uint) (int64, error) {
+	query := db.Model(&models.OutboundEmail{}).Where("status = ?", StatusFailed)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	res := query.Updates(map[string]interface{}{
+		"status":          StatusPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to requeue emails: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: MIT
+package email
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type fakeSender struct {
+	sent []string
+	err  error
+}
+
+func (f *fakeSender) SendEmail(to, subject, body string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, to)
+	return nil
+}
+
+func setupQueueTestDB(t *testing.T) *gorm.DB {
+	testDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := testDB.AutoMigrate(&models.OutboundEmail{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	return testDB
+}
+
+func TestQueueDeliversPending(t *testing.T) {
+	testDB := setupQueueTestDB(t)
+	if err := Enqueue(testDB, "owner@example.com", "Hello", "Body"); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	sender := &fakeSender{}
+	q := NewQueue(testDB)
+	q.Sender = sender
+	if err := q.ProcessPending(); err != nil {
+		t.Fatalf("ProcessPending failed: %v", err)
+	}
+
+	if len(sender.sent) != 1 || sender.sent[0] != "owner@example.com" {
+		t.Fatalf("Expected one email to owner, got %v", sender.sent)
+	}
+
+	var msg models.OutboundEmail
+	testDB.First(&msg)
+	if msg.Status != StatusSent || msg.SentAt == nil {
+		t.Errorf("Expected sent status, got %s", msg.Status)
+	}
+
+	// Already-sent emails are not resent
+	q.ProcessPending()
+	if len(sender.sent) != 1 {
+		t.Errorf("Expected no resend, got %d sends", len(sender.sent))
+	}
+}
+
+func TestQueueRetriesWithBackoff(t *testing.T) {
+	testDB := setupQueueTestDB(t)
+	Enqueue(testDB, "owner@example.com", "Hello", "Body")
+
+	q := NewQueue(testDB)
+	q.Sender = &fakeSender{err: errors.New("connection refused")}
+	q.ProcessPending()
+
+	var msg models.OutboundEmail
+	testDB.First(&msg)
+	if msg.Status != StatusPending || msg.Attempts != 1 {
+		t.Fatalf("Expected pending with 1 attempt, got %s/%d", msg.Status, msg.Attempts)
+	}
+	if msg.LastError != "connection refused" {
+		t.Errorf("Expected last error to be recorded, got %q", msg.LastError)
+	}
+	if !msg.NextAttemptAt.After(time.Now()) {
+		t.Error("Expected next attempt to be scheduled in the future")
+	}
+}
+
+func TestQueueGivesUpAfterMaxAttempts(t *testing.T) {
+	testDB := setupQueueTestDB(t)
+	Enqueue(testDB, "owner@example.com", "Hello", "Body")
+
+	q := NewQueue(testDB)
+	q.MaxAttempts = 2
+	q.RetryFor = 0
+	q.Sender = &fakeSender{err: errors.New("mailbox unavailable")}
+
+	for i := 0; i < 2; i++ {
+		// Make the message due again
+		testDB.Model(&models.OutboundEmail{}).Where("1 = 1").Update("next_attempt_at", time.Now().Add(-time.Second))
+		q.ProcessPending()
+	}
+
+	var msg models.OutboundEmail
+	testDB.First(&msg)
+	if msg.Status != StatusFailed {
+		t.Errorf("Expected failed status, got %s", msg.Status)
+	}
+}
+
+func TestQueueKeepsRetryingWithinRetryFor(t *testing.T) {
+	testDB := setupQueueTestDB(t)
+	Enqueue(testDB, "owner@example.com", "Hello", "Body")
+
+	q := NewQueue(testDB)
+	q.MaxAttempts = 2
+	q.Sender = &fakeSender{err: errors.New("connection refused")}
+
+	for i := 0; i < 4; i++ {
+		testDB.Model(&models.OutboundEmail{}).Where("1 = 1").Update("next_attempt_at", time.Now().Add(-time.Second))
+		q.ProcessPending()
+	}
+
+	var msg models.OutboundEmail
+	testDB.First(&msg)
+	if msg.Status != StatusPending || msg.Attempts != 4 {
+		t.Errorf("Expected email still pending after 4 attempts, got %s after %d", msg.Status, msg.Attempts)
+	}
+}
+
+func TestRetryFailed(t *testing.T) {
+	testDB := setupQueueTestDB(t)
+	Enqueue(testDB, "a@example.com", "One", "Body")
+	Enqueue(testDB, "b@example.com", "Two", "Body")
+	testDB.Model(&models.OutboundEmail{}).Where("1 = 1").Updates(map[string]interface{}{"status": StatusFailed, "attempts": 6})
+
+	failed, err := Failed(testDB)
+	if err != nil || len(failed) != 2 {
+		t.Fatalf("Expected 2 failed emails, got %d (%v)", len(failed), err)
+	}
+
+	if n, err := Retry(testDB, failed[0].ID); err != nil || n != 1 {
+		t.Fatalf("Expected 1 email requeued, got %d (%v)", n, err)
+	}
+	var msg models.OutboundEmail
+	testDB.First(&msg, failed[0].ID)
+	if msg.Status != StatusPending || msg.Attempts != 0 {
+		t.Errorf("Expected requeued email to be pending with no attempts, got %s/%d", msg.Status, msg.Attempts)
+	}
+
+	if n, _ := Retry(testDB); n != 1 {
+		t.Errorf("Expected the other failed email requeued, got %d", n)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := map[int]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		4:  8 * time.Minute,
+		10: time.Hour,
+		70: time.Hour,
+	}
+	for attempts, want := range tests {
+		if got := retryDelay(attempts); got != want {
+			t.Errorf("retryDelay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: MIT
+
+// Package events expands repeating calendar events into occurrences and
+// renders iCalendar feeds.
+package events
+
+import (
+	"sort"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// Recurrence options for events
+const (
+	RecurNone    = ""
+	RecurDaily   = "daily"
+	RecurWeekly  = "weekly"
+	RecurMonthly = "monthly"
+	RecurYearly  = "yearly"
+)
+
+// RecurrenceLabels maps each recurrence option to a display label, in menu order
+var RecurrenceLabels = []struct {
+	Value string
+	Label string
+}{
+	{RecurNone, "Does not repeat"},
+	{RecurDaily, "Daily"},
+	{RecurWeekly, "Weekly"},
+	{RecurMonthly, "Monthly"},
+	{RecurYearly, "Yearly"},
+}
+
+// maxOccurrences bounds how many repeats of a single event are expanded per query
+const maxOccurrences = 1000
+
+// IsValidRecurrence reports whether r is a supported recurrence option
+func IsValidRecurrence(r string) bool {
+	for _, opt := range RecurrenceLabels {
+		if opt.Value == r {
+			return true
+		}
+	}
+	return false
+}
+
+// Location returns the time zone event times are entered and displayed in
+func Location() *time.Location {
+	if name := config.GetString("events.timezone"); name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// Occurrence is a single instance of a possibly-repeating event
+type Occurrence struct {
+	Event *models.Event
+	Start time.Time
+	End   time.Time
+}
+
+// step advances t by n recurrence periods, keeping the wall-clock time. As in
+// RFC 5545, monthly and yearly events don't happen in periods without their
+// day (e.g. the 31st, or February 29th), for which it returns false.
+func step(t time.Time, recurrence string, n int) (time.Time, bool) {
+	var months int
+	switch recurrence {
+	case RecurDaily:
+		return t.AddDate(0, 0, n), true
+	case RecurWeekly:
+		return t.AddDate(0, 0, 7*n), true
+	case RecurMonthly:
+		months = n
+	case RecurYearly:
+		months = 12 * n
+	default:
+		return t, true
+	}
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	next := first.AddDate(0, 0, t.Day()-1)
+	return next, next.Month() == first.Month()
+}
+
+// skipPeriods returns how many whole recurrence periods can be skipped before
+// reaching from, erring on the side of skipping too few
+func skipPeriods(start time.Time, recurrence string, from time.Time) int {
+	if !from.After(start) {
+		return 0
+	}
+	var n int
+	switch recurrence {
+	case RecurDaily:
+		n = int(from.Sub(start).Hours() / 24)
+	case RecurWeekly:
+		n = int(from.Sub(start).Hours() / (24 * 7))
+	case RecurMonthly:
+		n = (from.Year()-start.Year())*12 + int(from.Month()) - int(start.Month())
+	case RecurYearly:
+		n = from.Year() - start.Year()
+	}
+	// Step back one period to absorb DST shifts and month-length differences
+	if n -= 1; n < 0 {
+		return 0
+	}
+	return n
+}
+
+// overlaps reports whether an occurrence from start to end falls within [from, to).
+// Zero-length occurrences count if they start inside the range.
+func overlaps(start, end, from, to time.Time) bool {
+	return start.Before(to) && (end.After(from) || !start.Before(from))
+}
+
+// Expand returns the occurrences of ev that overlap [from, to). All-day events
+// store midnight of their last day in EndsAt and are treated as lasting through it.
+func Expand(ev *models.Event, from, to time.Time) []Occurrence {
+	loc := Location()
+	start := ev.StartsAt.In(loc)
+	duration := ev.EndsAt.Sub(ev.StartsAt)
+	if duration < 0 {
+		duration = 0
+	}
+	visible := func(occStart, occEnd time.Time) bool {
+		if ev.AllDay {
+			occEnd = occEnd.AddDate(0, 0, 1)
+		}
+		return overlaps(occStart, occEnd, from, to)
+	}
+
+	if ev.Recurrence == RecurNone || !IsValidRecurrence(ev.Recurrence) {
+		end := start.Add(duration)
+		if visible(start, end) {
+			return []Occurrence{{Event: ev, Start: start, End: end}}
+		}
+		return nil
+	}
+
+	var out []Occurrence
+	first := skipPeriods(start, ev.Recurrence, from.Add(-duration-24*time.Hour))
+	for i := first; i < first+maxOccurrences; i++ {
+		occStart, ok := step(start, ev.Recurrence, i)
+		if !occStart.Before(to) {
+			break
+		}
+		if ev.RecurUntil != nil && occStart.After(*ev.RecurUntil) {
+			break
+		}
+		if !ok {
+			continue
+		}
+		occEnd := occStart.Add(duration)
+		if visible(occStart, occEnd) {
+			out = append(out, Occurrence{Event: ev, Start: occStart, End: occEnd})
+		}
+	}
+	return out
+}
+
+// Between returns all occurrences of the given events overlapping [from, to), sorted by start
+func Between(evs []models.Event, from, to time.Time) []Occurrence {
+	var out []Occurrence
+	for i := range evs {
+		out = append(out, Expand(&evs[i], from, to)...)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
+
+// Upcoming returns up to limit occurrences that haven't ended yet, looking ahead one year
+func Upcoming(evs []models.Event, now time.Time, limit int) []Occurrence {
+	out := Between(evs, now, now.AddDate(1, 0, 0))
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// MonthRange returns the first instant of month's month and of the month after, in the events time zone
+func MonthRange(month time.Time) (time.Time, time.Time) {
+	month = month.In(Location())
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, Location())
+	return from, from.AddDate(0, 1, 0)
+}
+
+// When formats an occurrence's date and time for display, e.g.
+// "Sat, Aug 23, 2025, 6:00 PM – 9:00 PM"
+func (o Occurrence) When() string {
+	const day = "Mon, Jan 2, 2006"
+	const clock = "3:04 PM"
+
+	sameDay := o.Start.Year() == o.End.Year() && o.Start.YearDay() == o.End.YearDay()
+	if o.Event.AllDay {
+		if sameDay || !o.End.After(o.Start) {
+			return o.Start.Format(day)
+		}
+		return o.Start.Format(day) + " – " + o.End.Format(day)
+	}
+	if !o.End.After(o.Start) {
+		return o.Start.Format(day + ", " + clock)
+	}
+	if sameDay {
+		return o.Start.Format(day+", "+clock) + " – " + o.End.Format(clock)
+	}
+	return o.Start.Format(day+", "+clock) + " – " + o.End.Format(day+", "+clock)
+}
@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: MIT
+package events
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// useTimezone sets events.timezone for a test
+func useTimezone(t *testing.T, name string) *time.Location {
+	if err := config.InitConfig(filepath.Join(t.TempDir(), "config.yaml")); err != nil {
+		t.Fatalf("Failed to init config: %v", err)
+	}
+	config.Set("events.timezone", name)
+	t.Cleanup(func() { config.Set("events.timezone", "") })
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("Time zone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func date(y int, m time.Month, d, h int) time.Time {
+	return time.Date(y, m, d, h, 0, 0, 0, time.UTC)
+}
+
+func TestExpandSingleEvent(t *testing.T) {
+	ev := &models.Event{StartsAt: date(2025, 8, 25, 18), EndsAt: date(2025, 8, 25, 21)}
+
+	if got := Expand(ev, date(2025, 8, 1, 0), date(2025, 9, 1, 0)); len(got) != 1 {
+		t.Errorf("Expected 1 occurrence in August, got %d", len(got))
+	}
+	if got := Expand(ev, date(2025, 9, 1, 0), date(2025, 10, 1, 0)); len(got) != 0 {
+		t.Errorf("Expected no occurrences in September, got %d", len(got))
+	}
+	// Still in progress counts as overlapping
+	if got := Expand(ev, date(2025, 8, 25, 20), date(2025, 8, 26, 0)); len(got) != 1 {
+		t.Errorf("Expected in-progress event to be included, got %d", len(got))
+	}
+}
+
+func TestExpandWeekly(t *testing.T) {
+	until := date(2025, 3, 31, 0)
+	ev := &models.Event{
+		StartsAt:   date(2025, 1, 6, 19), // A Monday
+		EndsAt:     date(2025, 1, 6, 20),
+		Recurrence: RecurWeekly,
+		RecurUntil: &until,
+	}
+
+	march := Expand(ev, date(2025, 3, 1, 0), date(2025, 4, 1, 0))
+	if len(march) != 4 {
+		t.Fatalf("Expected 4 Mondays in March before the 31st, got %d", len(march))
+	}
+	for _, occ := range march {
+		if occ.Start.Weekday() != time.Monday || occ.Start.Hour() != 19 {
+			t.Errorf("Unexpected occurrence %v", occ.Start)
+		}
+	}
+
+	if got := Expand(ev, date(2025, 4, 1, 0), date(2025, 5, 1, 0)); len(got) != 0 {
+		t.Errorf("Expected no occurrences after RecurUntil, got %d", len(got))
+	}
+}
+
+func TestExpandDailyFarFuture(t *testing.T) {
+	ev := &models.Event{StartsAt: date(2015, 1, 1, 9), EndsAt: date(2015, 1, 1, 10), Recurrence: RecurDaily}
+
+	got := Expand(ev, date(2025, 6, 1, 0), date(2025, 6, 8, 0))
+	if len(got) != 7 {
+		t.Errorf("Expected 7 daily occurrences ten years later, got %d", len(got))
+	}
+}
+
+func TestExpandAcrossDST(t *testing.T) {
+	loc := useTimezone(t, "America/Los_Angeles")
+	start := time.Date(2025, 3, 3, 19, 0, 0, 0, loc) // A Monday before DST starts
+	ev := &models.Event{StartsAt: start, EndsAt: start.Add(time.Hour), Recurrence: RecurWeekly}
+
+	got := Expand(ev, time.Date(2025, 3, 1, 0, 0, 0, 0, loc), time.Date(2025, 3, 18, 0, 0, 0, 0, loc))
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 occurrences, got %d", len(got))
+	}
+	for _, occ := range got {
+		if occ.Start.Hour() != 19 {
+			t.Errorf("Expected 7pm local time, got %v", occ.Start)
+		}
+	}
+	if got[0].Start.UTC().Hour() == got[2].Start.UTC().Hour() {
+		t.Error("Expected the UTC time to change with DST")
+	}
+}
+
+func TestExpandMonthEnd(t *testing.T) {
+	ev := &models.Event{StartsAt: date(2025, 1, 31, 18), EndsAt: date(2025, 1, 31, 19), Recurrence: RecurMonthly}
+
+	got := Expand(ev, date(2025, 1, 1, 0), date(2025, 7, 1, 0))
+	var days []string
+	for _, occ := range got {
+		days = append(days, occ.Start.Format("Jan 2"))
+	}
+	// Months without a 31st are skipped
+	want := "Jan 31, Mar 31, May 31"
+	if strings.Join(days, ", ") != want {
+		t.Errorf("Expected %s, got %s", want, strings.Join(days, ", "))
+	}
+
+	leap := &models.Event{StartsAt: date(2024, 2, 29, 12), EndsAt: date(2024, 2, 29, 13), Recurrence: RecurYearly}
+	if got := Expand(leap, date(2025, 1, 1, 0), date(2028, 1, 1, 0)); len(got) != 0 {
+		t.Errorf("Expected no February 29th until 2028, got %v", got[0].Start)
+	}
+	if got := Expand(leap, date(2028, 1, 1, 0), date(2029, 1, 1, 0)); len(got) != 1 {
+		t.Errorf("Expected February 29th 2028, got %d occurrences", len(got))
+	}
+}
+
+func TestUpcomingSortsAndLimits(t *testing.T) {
+	now := date(2025, 6, 1, 0)
+	evs := []models.Event{
+		{ID: 1, Title: "Later", StartsAt: date(2025, 7, 1, 0), EndsAt: date(2025, 7, 1, 1)},
+		{ID: 2, Title: "Past", StartsAt: date(2025, 5, 1, 0), EndsAt: date(2025, 5, 1, 1)},
+		{ID: 3, Title: "Sooner", StartsAt: date(2025, 6, 2, 0), EndsAt: date(2025, 6, 2, 1)},
+		{ID: 4, Title: "Monthly", StartsAt: date(2025, 1, 15, 0), EndsAt: date(2025, 1, 15, 1), Recurrence: RecurMonthly},
+	}
+
+	got := Upcoming(evs, now, 3)
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 occurrences, got %d", len(got))
+	}
+	want := []string{"Sooner", "Monthly", "Later"}
+	for i, occ := range got {
+		if occ.Event.Title != want[i] {
+			t.Errorf("Position %d: expected %s, got %s", i, want[i], occ.Event.Title)
+		}
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	evs := []models.Event{{
+		ID:          7,
+		Title:       "Build week; bring gloves, water",
+		Location:    "Camp HQ",
+		Description: "Line one\nLine two " + strings.Repeat("long ", 20),
+		StartsAt:    date(2025, 8, 20, 16),
+		EndsAt:      date(2025, 8, 20, 22),
+		Recurrence:  RecurDaily,
+	}}
+
+	var b strings.Builder
+	if err := WriteICS(&b, "My Camp", "mycamp.example.com", evs); err != nil {
+		t.Fatalf("WriteICS failed: %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:event-7@mycamp.example.com\r\n",
+		"DTSTART:20250820T160000Z\r\n",
+		"RRULE:FREQ=DAILY\r\n",
+		`SUMMARY:Build week\; bring gloves\, water`,
+		`Line one\nLine two`,
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected ICS to contain %q", want)
+		}
+	}
+
+	for _, l := range strings.Split(out, "\r\n") {
+		if len(l) > 75 {
+			t.Errorf("Line exceeds 75 octets: %q", l)
+		}
+	}
+}
+
+func TestWriteICSTimezone(t *testing.T) {
+	loc := useTimezone(t, "America/Los_Angeles")
+	start := time.Date(2025, 3, 3, 19, 0, 0, 0, loc)
+	evs := []models.Event{
+		{ID: 1, Title: "Weekly meeting", StartsAt: start, EndsAt: start.Add(time.Hour), Recurrence: RecurWeekly},
+		{ID: 2, Title: "Burn", StartsAt: time.Date(2025, 8, 24, 9, 0, 0, 0, loc), EndsAt: time.Date(2025, 8, 24, 10, 0, 0, 0, loc)},
+	}
+
+	var b strings.Builder
+	if err := WriteICS(&b, "My Camp", "mycamp.example.com", evs); err != nil {
+		t.Fatalf("WriteICS failed: %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"BEGIN:VTIMEZONE\r\nTZID:America/Los_Angeles\r\n",
+		"BEGIN:DAYLIGHT\r\nDTSTART:20240310T020000\r\nRRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=2SU\r\nTZOFFSETFROM:-0800\r\nTZOFFSETTO:-0700\r\n",
+		"BEGIN:STANDARD\r\nDTSTART:20241103T020000\r\nRRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=1SU\r\nTZOFFSETFROM:-0700\r\nTZOFFSETTO:-0800\r\n",
+		"DTSTART;TZID=America/Los_Angeles:20250303T190000\r\n",
+		// Events that don't repeat stay in UTC
+		"DTSTART:20250824T160000Z\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected ICS to contain %q, got:\n%s", want, out)
+		}
+	}
+}
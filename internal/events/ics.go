@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: MIT
+package events
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// icsEscaper escapes TEXT values per RFC 5545
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// foldLine splits a content line into 75-octet chunks joined by CRLF + space
+func foldLine(line string) string {
+	if len(line) <= 75 {
+		return line + "\r\n"
+	}
+	var b strings.Builder
+	limit := 75
+	for len(line) > limit {
+		// Don't split a multi-byte UTF-8 character
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // Continuation lines start with a space
+	}
+	b.WriteString(line + "\r\n")
+	return b.String()
+}
+
+// rrule returns the RRULE value for an event, or "" if it doesn't repeat
+func rrule(ev *models.Event) string {
+	var freq string
+	switch ev.Recurrence {
+	case RecurDaily:
+		freq = "DAILY"
+	case RecurWeekly:
+		freq = "WEEKLY"
+	case RecurMonthly:
+		freq = "MONTHLY"
+	case RecurYearly:
+		freq = "YEARLY"
+	default:
+		return ""
+	}
+	rule := "FREQ=" + freq
+	if ev.RecurUntil != nil {
+		rule += ";UNTIL=" + ev.RecurUntil.UTC().Format("20060102T150405Z")
+	}
+	return rule
+}
+
+// icsWeekdays are RFC 5545's weekday codes, indexed by time.Weekday
+var icsWeekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// zoneTransitions returns the instants in [from, to) at which loc's UTC
+// offset changes, to the minute
+func zoneTransitions(loc *time.Location, from, to time.Time) []time.Time {
+	var out []time.Time
+	_, prev := from.In(loc).Zone()
+	for t := from; t.Before(to); t = t.Add(24 * time.Hour) {
+		next := t.Add(24 * time.Hour)
+		_, offset := next.In(loc).Zone()
+		if offset == prev {
+			continue
+		}
+		lo, hi := t, next
+		for hi.Sub(lo) > time.Minute {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, o := mid.In(loc).Zone(); o == prev {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		out = append(out, hi.Truncate(time.Minute))
+		prev = offset
+	}
+	return out
+}
+
+// icsOffset formats a UTC offset in seconds as e.g. -0700
+func icsOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+}
+
+// writeVTimezone writes a VTIMEZONE for loc, with yearly rules for its
+// changes between standard and daylight time taken from the given year, so
+// recurring events keep their local time after a DST change
+func writeVTimezone(line func(string, ...interface{}), loc *time.Location, year int) {
+	from := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	line("BEGIN:VTIMEZONE")
+	line("TZID:%s", loc.String())
+	transitions := zoneTransitions(loc, from, from.AddDate(1, 0, 0))
+	if len(transitions) == 0 {
+		name, offset := from.In(loc).Zone()
+		line("BEGIN:STANDARD")
+		line("DTSTART:19700101T000000")
+		line("TZOFFSETFROM:%s", icsOffset(offset))
+		line("TZOFFSETTO:%s", icsOffset(offset))
+		line("TZNAME:%s", name)
+		line("END:STANDARD")
+	}
+	for _, tr := range transitions {
+		after := tr.In(loc)
+		name, offsetTo := after.Zone()
+		_, offsetFrom := tr.Add(-time.Minute).In(loc).Zone()
+		// Observances start at the local time before the change
+		local := tr.In(time.FixedZone("", offsetFrom))
+		nth := strconv.Itoa((local.Day()-1)/7 + 1)
+		if local.AddDate(0, 0, 7).Month() != local.Month() {
+			nth = "-1"
+		}
+		kind := "STANDARD"
+		if after.IsDST() {
+			kind = "DAYLIGHT"
+		}
+		line("BEGIN:%s", kind)
+		line("DTSTART:%s", local.Format("20060102T150405"))
+		line("RRULE:FREQ=YEARLY;BYMONTH=%d;BYDAY=%s%s", local.Month(), nth, icsWeekdays[local.Weekday()])
+		line("TZOFFSETFROM:%s", icsOffset(offsetFrom))
+		line("TZOFFSETTO:%s", icsOffset(offsetTo))
+		line("TZNAME:%s", name)
+		line("END:%s", kind)
+	}
+	line("END:VTIMEZONE")
+}
+
+// WriteICS writes an iCalendar feed of the given events. host is used to build
+// stable UIDs and event URLs, e.g. "mycamp.example.com".
+func WriteICS(w io.Writer, calendarName, host string, evs []models.Event) error {
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		b.WriteString(foldLine(fmt.Sprintf(format, args...)))
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//StinkyKitty//Events//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:%s", icsEscaper.Replace(calendarName))
+
+	// Repeating events are written in local time, since that's what they
+	// keep across DST changes; UTC would drift an hour for half the year
+	loc := Location()
+	zoned := loc != time.UTC
+	firstYear := 0
+	for i := range evs {
+		if ev := &evs[i]; !ev.AllDay && rrule(ev) != "" {
+			if year := ev.StartsAt.In(loc).Year(); firstYear == 0 || year < firstYear {
+				firstYear = year
+			}
+		}
+	}
+	if zoned && firstYear != 0 {
+		writeVTimezone(line, loc, firstYear-1)
+	}
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	for i := range evs {
+		ev := &evs[i]
+		line("BEGIN:VEVENT")
+		line("UID:event-%d@%s", ev.ID, host)
+		line("DTSTAMP:%s", stamp)
+		if ev.AllDay {
+			start := ev.StartsAt.In(loc)
+			// DTEND is exclusive for all-day events
+			end := ev.EndsAt.In(loc).AddDate(0, 0, 1)
+			line("DTSTART;VALUE=DATE:%s", start.Format("20060102"))
+			line("DTEND;VALUE=DATE:%s", end.Format("20060102"))
+		} else if zoned && rrule(ev) != "" {
+			line("DTSTART;TZID=%s:%s", loc.String(), ev.StartsAt.In(loc).Format("20060102T150405"))
+			line("DTEND;TZID=%s:%s", loc.String(), ev.EndsAt.In(loc).Format("20060102T150405"))
+		} else {
+			line("DTSTART:%s", ev.StartsAt.UTC().Format("20060102T150405Z"))
+			line("DTEND:%s", ev.EndsAt.UTC().Format("20060102T150405Z"))
+		}
+		if rule := rrule(ev); rule != "" {
+			line("RRULE:%s", rule)
+		}
+		line("SUMMARY:%s", icsEscaper.Replace(ev.Title))
+		if ev.Location != "" {
+			line("LOCATION:%s", icsEscaper.Replace(ev.Location))
+		}
+		if ev.Description != "" {
+			line("DESCRIPTION:%s", icsEscaper.Replace(ev.Description))
+		}
+		line("URL:https://%s/events/%d", host, ev.ID)
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/thatcatcamp/stinkykitty/internal/auth"
 	"github.com/thatcatcamp/stinkykitty/internal/config"
 	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
 	"github.com/thatcatcamp/stinkykitty/internal/middleware"
 	"github.com/thatcatcamp/stinkykitty/internal/models"
 )
@@ -282,6 +283,26 @@ func LoginFormHandler(c *gin.Context) {
 	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
 }
 
+// storageMeterHTML shows how much of its storage quota a site uses on the
+// dashboard
+func storageMeterHTML(siteID uint, storageQuota *int64) string {
+	usage := media.SiteUsage(db.GetDB(), siteID)
+	quota := media.Quota(&models.Site{StorageQuota: storageQuota})
+	if quota <= 0 {
+		return fmt.Sprintf(`<div class="storage-meter"><small>%s of media (no limit)</small></div>`, media.FormatSize(usage))
+	}
+	percent := media.UsagePercent(usage, quota)
+	class := ""
+	switch {
+	case percent >= media.QuotaFullLevel:
+		class = " full"
+	case percent >= media.QuotaWarnLevel:
+		class = " warn"
+	}
+	return fmt.Sprintf(`<div class="storage-meter" title="%d%% of storage used"><div class="storage-bar"><div class="storage-fill%s" style="width: %d%%"></div></div><small>%s of %s used</small></div>`,
+		percent, class, min(percent, 100), media.FormatSize(usage), media.FormatSize(quota))
+}
+
 // DashboardHandler shows the admin dashboard with list of sites
 func DashboardHandler(c *gin.Context) {
 	// Get user from context
@@ -303,13 +324,14 @@ func DashboardHandler(c *gin.Context) {
 		ID           uint
 		Subdomain    string
 		CustomDomain *string
+		StorageQuota *int64
 		Role         string
 	}
 
 	if user.IsGlobalAdmin {
 		// Global admins see all sites
 		db.GetDB().Raw(`
-			SELECT sites.id, sites.subdomain, sites.custom_domain, COALESCE(site_users.role, 'owner') as role
+			SELECT sites.id, sites.subdomain, sites.custom_domain, sites.storage_quota, COALESCE(site_users.role, 'owner') as role
 			FROM sites
 			LEFT JOIN site_users ON sites.id = site_users.site_id AND site_users.user_id = ?
 			ORDER BY sites.subdomain
@@ -317,7 +339,7 @@ func DashboardHandler(c *gin.Context) {
 	} else {
 		// Regular users see only their sites
 		db.GetDB().Raw(`
-			SELECT sites.id, sites.subdomain, sites.custom_domain, site_users.role
+			SELECT sites.id, sites.subdomain, sites.custom_domain, sites.storage_quota, site_users.role
 			FROM sites
 			JOIN site_users ON sites.id = site_users.site_id
 			WHERE site_users.user_id = ? AND (site_users.role = 'owner' OR site_users.role = 'admin')
@@ -343,6 +365,7 @@ func DashboardHandler(c *gin.Context) {
 					<div class="site-info">
 						<h3>` + us.Subdomain + `</h3>
 						<small>` + domainDisplay + `</small>
+						` + storageMeterHTML(us.ID, us.StorageQuota) + `
 					</div>
 					<div class="site-actions">
 						<a href="/admin/pages?site=` + fmt.Sprintf("%d", us.ID) + `" class="btn-small">Edit</a>
@@ -531,6 +554,32 @@ func DashboardHandler(c *gin.Context) {
             color: var(--color-text-secondary);
         }
 
+        .storage-meter {
+            margin-top: var(--spacing-sm);
+            max-width: 220px;
+        }
+
+        .storage-bar {
+            height: 6px;
+            background: var(--color-border);
+            border-radius: 3px;
+            overflow: hidden;
+            margin-bottom: 4px;
+        }
+
+        .storage-fill {
+            height: 100%;
+            background: var(--color-accent);
+        }
+
+        .storage-fill.warn {
+            background: #d97706;
+        }
+
+        .storage-fill.full {
+            background: #dc2626;
+        }
+
         .site-actions {
             display: flex;
             gap: var(--spacing-sm);
@@ -0,0 +1,316 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/announcements"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+)
+
+// AnnouncementsListHandler lists a site's announcements with a form to post one
+func AnnouncementsListHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	renderAnnouncementsList(c, siteVal.(*models.Site), http.StatusOK, "")
+}
+
+// formatAnnouncementTime shows a start or end time in the server's local
+// time; the page's script switches it to the browser's
+func formatAnnouncementTime(t *time.Time, empty string) string {
+	if t == nil {
+		return empty
+	}
+	return fmt.Sprintf(`<time datetime="%s">%s</time>`, t.UTC().Format(time.RFC3339), t.Local().Format("Jan 2, 2006 3:04 PM"))
+}
+
+// browserZone returns the zone of a UTC offset posted by the announcement
+// form, in minutes east of UTC, or the server's zone if there isn't one
+func browserZone(offset string) *time.Location {
+	minutes, err := strconv.Atoi(offset)
+	if err != nil || minutes < -14*60 || minutes > 14*60 {
+		return time.Local
+	}
+	return time.FixedZone("", minutes*60)
+}
+
+// renderAnnouncementsList renders the announcement manager, with an optional error message
+func renderAnnouncementsList(c *gin.Context, site *models.Site, status int, errMsg string) {
+	var list []models.Announcement
+	db.GetDB().Where("site_id = ?", site.ID).Order("created_at DESC").Order("id DESC").Limit(100).Find(&list)
+
+	csrfToken := middleware.GetCSRFTokenHTML(c)
+	now := time.Now()
+
+	var tableRows strings.Builder
+	for _, a := range list {
+		state := "Ended"
+		switch {
+		case announcements.IsActive(&a, now):
+			state = "<strong>Showing</strong>"
+		case a.StartsAt != nil && a.StartsAt.After(now):
+			state = "Scheduled"
+		}
+		endButton := ""
+		if a.EndsAt == nil || a.EndsAt.After(now) {
+			endButton = fmt.Sprintf(`
+					<form method="POST" action="/admin/announcements/%d/end" style="display: inline;">
+						%s
+						<button type="submit" class="btn btn-small btn-secondary">End Now</button>
+					</form>`, a.ID, csrfToken)
+		}
+		dismissible := "No"
+		if a.Dismissible {
+			dismissible = "Yes"
+		}
+		fmt.Fprintf(&tableRows, `
+			<tr>
+				<td>%s</td>
+				<td><span class="severity severity-%s">%s</span></td>
+				<td>%s</td>
+				<td>%s</td>
+				<td>%s</td>
+				<td>%s</td>
+				<td>%s
+					<form method="POST" action="/admin/announcements/%d/delete" style="display: inline;" onsubmit="return confirm('Delete this announcement?');">
+						%s
+						<button type="submit" class="btn btn-small btn-danger">Delete</button>
+					</form>
+				</td>
+			</tr>`, state, a.Severity, a.Severity, html.EscapeString(a.Message),
+			formatAnnouncementTime(a.StartsAt, "Right away"), formatAnnouncementTime(a.EndsAt, "Until ended"),
+			dismissible, endButton, a.ID, csrfToken)
+	}
+	if tableRows.Len() == 0 {
+		tableRows.WriteString(`<tr><td colspan="7" style="text-align: center; color: #666;">No announcements yet.</td></tr>`)
+	}
+
+	errorHTML := ""
+	if errMsg != "" {
+		errorHTML = `<div style="background: #f8d7da; border: 1px solid #f5c6cb; color: #721c24; padding: 12px; border-radius: 4px; margin-bottom: 16px;">` + html.EscapeString(errMsg) + `</div>`
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Announcements - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 1200px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+		.announcement-form { display: grid; grid-template-columns: repeat(auto-fit, minmax(220px, 1fr)); gap: 12px; }
+		.announcement-form label { display: block; font-weight: 600; margin-bottom: 4px; }
+		.announcement-form .wide { grid-column: 1 / -1; }
+		.severity { padding: 2px 8px; border-radius: 10px; font-size: 12px; }
+		.severity-info { background: #dbeafe; color: #1e40af; }
+		.severity-warning { background: #fef3c7; color: #92400e; }
+		.severity-critical { background: #fee2e2; color: #991b1b; }
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>Announcements</h1>
+			<div class="header-actions">
+				<a href="/admin/pages?site=%d" class="btn btn-secondary">← Back to Pages</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		<p>Announcements show as a banner at the top of every public page, for notices like a gate closure or a schedule change. They appear as soon as they're posted unless you schedule them. Organizers can also post from a shell with <code>stinky site announce</code>.</p>
+		%s
+		<div class="card">
+			<h2>Post an Announcement</h2>
+			<form method="POST" action="/admin/announcements" class="announcement-form">
+				%s
+				<div class="wide">
+					<label for="message">Message</label>
+					<input type="text" id="message" name="message" maxlength="500" required placeholder="Gate closed due to weather until further notice" style="width: 100%%; box-sizing: border-box;">
+				</div>
+				<div>
+					<label for="severity">Severity</label>
+					<select id="severity" name="severity">
+						<option value="info">Info</option>
+						<option value="warning">Warning</option>
+						<option value="critical">Critical</option>
+					</select>
+				</div>
+				<div>
+					<label for="link_url">Link (optional)</label>
+					<input type="text" id="link_url" name="link_url" placeholder="/weather">
+				</div>
+				<div>
+					<label for="link_text">Link Text</label>
+					<input type="text" id="link_text" name="link_text" placeholder="Learn more">
+				</div>
+				<div>
+					<label for="starts_at">Starts</label>
+					<input type="datetime-local" id="starts_at" name="starts_at">
+					<input type="hidden" name="starts_at_offset">
+				</div>
+				<div>
+					<label for="ends_at">Ends</label>
+					<input type="datetime-local" id="ends_at" name="ends_at">
+					<input type="hidden" name="ends_at_offset">
+				</div>
+				<div>
+					<label><input type="checkbox" name="dismissible" value="1"> Visitors can dismiss it</label>
+				</div>
+				<div class="wide">
+					<button type="submit" class="btn">Post Announcement</button>
+				</div>
+			</form>
+			<p style="color: #666; font-size: 14px;">Leave Starts blank to show it right away and Ends blank to keep it up until you end it. Times are in your own time zone.</p>
+		</div>
+
+		<div class="card">
+			<table class="data-table">
+				<thead>
+					<tr>
+						<th>Status</th>
+						<th>Severity</th>
+						<th>Message</th>
+						<th>Starts</th>
+						<th>Ends</th>
+						<th>Dismissible</th>
+						<th>Actions</th>
+					</tr>
+				</thead>
+				<tbody>
+					%s
+				</tbody>
+			</table>
+		</div>
+	</div>
+	<script>
+		// Show times in the organizer's time zone, and send the zone of the
+		// times they enter (for that date, so daylight saving is right)
+		document.querySelectorAll('time[datetime]').forEach(function(el) {
+			el.textContent = new Date(el.getAttribute('datetime')).toLocaleString(undefined, {dateStyle: 'medium', timeStyle: 'short'});
+		});
+		document.querySelector('.announcement-form').addEventListener('submit', function() {
+			['starts_at', 'ends_at'].forEach(function(name) {
+				const value = this.elements[name].value;
+				this.elements[name + '_offset'].value = value ? -new Date(value).getTimezoneOffset() : '';
+			}, this);
+		});
+	</script>
+</body>
+</html>`, GetDesignSystemCSS(), site.ID, errorHTML, csrfToken, tableRows.String())
+
+	c.Data(status, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// CreateAnnouncementHandler posts a new announcement
+func CreateAnnouncementHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	startsAt, err := announcements.ParseTimeIn(c.PostForm("starts_at"), browserZone(c.PostForm("starts_at_offset")))
+	if err != nil {
+		renderAnnouncementsList(c, site, http.StatusBadRequest, err.Error())
+		return
+	}
+	endsAt, err := announcements.ParseTimeIn(c.PostForm("ends_at"), browserZone(c.PostForm("ends_at_offset")))
+	if err != nil {
+		renderAnnouncementsList(c, site, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	a := &models.Announcement{
+		SiteID:      site.ID,
+		Severity:    c.PostForm("severity"),
+		Message:     c.PostForm("message"),
+		LinkURL:     c.PostForm("link_url"),
+		LinkText:    c.PostForm("link_text"),
+		StartsAt:    startsAt,
+		EndsAt:      endsAt,
+		Dismissible: c.PostForm("dismissible") != "",
+	}
+	if err := announcements.Create(db.GetDB(), a); err != nil {
+		switch {
+		case errors.Is(err, announcements.ErrEmptyMessage), errors.Is(err, announcements.ErrMessageTooLong),
+			errors.Is(err, announcements.ErrInvalidSeverity), errors.Is(err, announcements.ErrInvalidLink),
+			errors.Is(err, announcements.ErrInvalidSchedule):
+			renderAnnouncementsList(c, site, http.StatusBadRequest, err.Error())
+		default:
+			log.Printf("Error creating announcement for site %d: %v", site.ID, err)
+			renderAnnouncementsList(c, site, http.StatusInternalServerError, "Failed to post announcement")
+		}
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/announcements")
+}
+
+// EndAnnouncementHandler takes an announcement down now
+func EndAnnouncementHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid announcement ID")
+		return
+	}
+	if err := announcements.End(db.GetDB(), site.ID, uint(id), time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.String(http.StatusNotFound, "Announcement not found")
+			return
+		}
+		c.String(http.StatusInternalServerError, "Failed to end announcement")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/announcements")
+}
+
+// DeleteAnnouncementHandler removes an announcement
+func DeleteAnnouncementHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	result := db.GetDB().Where("id = ? AND site_id = ?", c.Param("id"), site.ID).Delete(&models.Announcement{})
+	if result.Error != nil {
+		c.String(http.StatusInternalServerError, "Failed to delete announcement")
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.String(http.StatusNotFound, "Announcement not found")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/announcements")
+}
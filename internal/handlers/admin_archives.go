@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/seo"
+)
+
+// ArchivesListHandler lists a site's yearly archives with a form to snapshot the site
+func ArchivesListHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	renderArchivesList(c, siteVal.(*models.Site), http.StatusOK, "")
+}
+
+// renderArchivesList renders the archive manager, with an optional error message
+func renderArchivesList(c *gin.Context, site *models.Site, status int, errMsg string) {
+	var archives []models.Archive
+	db.GetDB().Where("site_id = ?", site.ID).Order("year DESC").Find(&archives)
+
+	csrfToken := middleware.GetCSRFTokenHTML(c)
+
+	var tableRows strings.Builder
+	for _, a := range archives {
+		var pageCount int64
+		db.GetDB().Model(&models.ArchivePage{}).Where("archive_id = ?", a.ID).Count(&pageCount)
+		fmt.Fprintf(&tableRows, `
+			<tr>
+				<td>%d</td>
+				<td><a href="%s/" target="_blank">%s</a></td>
+				<td>%d</td>
+				<td>%s</td>
+				<td>
+					<form method="POST" action="/admin/archives/%d/delete" style="display: inline;" onsubmit="return confirm('Delete this archive? This cannot be undone.');">
+						%s
+						<button type="submit" class="btn btn-small btn-danger">Delete</button>
+					</form>
+				</td>
+			</tr>`, a.Year, archiveRoot(a.Year), html.EscapeString(a.Title), pageCount,
+			a.CreatedAt.Format("Jan 2, 2006"), a.ID, csrfToken)
+	}
+	if tableRows.Len() == 0 {
+		tableRows.WriteString(`<tr><td colspan="5" style="text-align: center; color: #666;">No archives yet.</td></tr>`)
+	}
+
+	errorHTML := ""
+	if errMsg != "" {
+		errorHTML = `<div style="background: #f8d7da; border: 1px solid #f5c6cb; color: #721c24; padding: 12px; border-radius: 4px; margin-bottom: 16px;">` + html.EscapeString(errMsg) + `</div>`
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Archives - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 1200px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+		.archive-form { display: flex; gap: 12px; flex-wrap: wrap; align-items: flex-end; }
+		.archive-form label { display: block; font-weight: 600; margin-bottom: 4px; }
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>Archives</h1>
+			<div class="header-actions">
+				<a href="/admin/pages?site=%d" class="btn btn-secondary">← Back to Pages</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		<p>An archive is a read-only snapshot of every published page, the menu and the pictures they use. It's served at <code>/archive/&lt;year&gt;/</code> with a banner linking back to the current site, so you can rework the site for a new year without losing the old one.</p>
+		%s
+		<div class="card">
+			<h2>Snapshot as Archive</h2>
+			<form method="POST" action="/admin/archives" class="archive-form">
+				%s
+				<div>
+					<label for="year">Year</label>
+					<input type="number" id="year" name="year" value="%d" min="1990" max="%d" required>
+				</div>
+				<div>
+					<label for="title">Title (optional)</label>
+					<input type="text" id="title" name="title" placeholder="%s">
+				</div>
+				<button type="submit" class="btn">Create Archive</button>
+			</form>
+			<p style="color: #666; font-size: 14px;">Later edits to the live site don't change an archive. Each year can only be archived once; delete an archive to take it again.</p>
+		</div>
+
+		<div class="card">
+			<table class="data-table">
+				<thead>
+					<tr>
+						<th>Year</th>
+						<th>Title</th>
+						<th>Pages</th>
+						<th>Created</th>
+						<th>Actions</th>
+					</tr>
+				</thead>
+				<tbody>
+					%s
+				</tbody>
+			</table>
+		</div>
+	</div>
+</body>
+</html>`, GetDesignSystemCSS(), site.ID, errorHTML, csrfToken, time.Now().Year(), time.Now().Year()+1,
+		html.EscapeString(fmt.Sprintf("%s %d", seo.SiteName(site), time.Now().Year())), tableRows.String())
+
+	c.Data(status, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// CreateArchiveHandler snapshots the site's published pages as an archive
+func CreateArchiveHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	year, err := strconv.Atoi(c.PostForm("year"))
+	if err != nil {
+		renderArchivesList(c, site, http.StatusBadRequest, ErrInvalidArchiveYear.Error())
+		return
+	}
+	if _, err := CreateArchive(site, year, c.PostForm("title")); err != nil {
+		if errors.Is(err, ErrArchiveExists) || errors.Is(err, ErrInvalidArchiveYear) {
+			renderArchivesList(c, site, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("Error archiving site %d: %v", site.ID, err)
+		renderArchivesList(c, site, http.StatusInternalServerError, "Failed to create archive")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/archives")
+}
+
+// DeleteArchiveHandler removes an archive
+func DeleteArchiveHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	var archive models.Archive
+	if err := db.GetDB().Where("id = ? AND site_id = ?", c.Param("id"), site.ID).First(&archive).Error; err != nil {
+		c.String(http.StatusNotFound, "Archive not found")
+		return
+	}
+	if err := DeleteArchive(&archive); err != nil {
+		c.String(http.StatusInternalServerError, "Failed to delete archive")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/archives")
+}
@@ -6,11 +6,9 @@ import (
 	"fmt"
 	htmlpkg "html"
 	"net/http"
-	"path/filepath"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/thatcatcamp/stinkykitty/internal/config"
 	"github.com/thatcatcamp/stinkykitty/internal/db"
 	"github.com/thatcatcamp/stinkykitty/internal/media"
 	"github.com/thatcatcamp/stinkykitty/internal/middleware"
@@ -53,15 +51,22 @@ func CreateBlockHandler(c *gin.Context) {
 	// Get block type from POST form
 	blockType := c.PostForm("type")
 	validTypes := map[string]bool{
-		"text":    true,
-		"image":   true,
-		"heading": true,
-		"quote":   true,
-		"button":  true,
-		"video":   true,
-		"spacer":  true,
-		"contact": true,
-		"columns": true,
+		"text":     true,
+		"image":    true,
+		"heading":  true,
+		"quote":    true,
+		"button":   true,
+		"video":    true,
+		"spacer":   true,
+		"contact":  true,
+		"columns":  true,
+		"form":     true,
+		"events":   true,
+		"calendar": true,
+		"shifts":   true,
+		"posts":    true,
+		"children": true,
+		"file":     true,
 	}
 	if !validTypes[blockType] {
 		c.String(http.StatusBadRequest, "Invalid block type")
@@ -108,6 +113,20 @@ func CreateBlockHandler(c *gin.Context) {
 		blockData = `{"title":"Get in Touch","subtitle":""}`
 	case "columns":
 		blockData = `{"column_count":2,"columns":[{"content":""},{"content":""}]}`
+	case "form":
+		blockData = `{"title":"Sign Up","fields":[{"name":"name","label":"Name","type":"text","required":true},{"name":"email","label":"Email","type":"email","required":true}],"submit_label":"Submit","confirmation_message":"Thanks! Your response has been received."}`
+	case "events":
+		blockData = `{"title":"Upcoming Events","limit":5}`
+	case "calendar":
+		blockData = `{"title":"Calendar"}`
+	case "shifts":
+		blockData = `{"title":"Volunteer","description":"","role":""}`
+	case "posts":
+		blockData = `{"title":"Latest News","limit":3}`
+	case "children":
+		blockData = `{"title":"In This Section"}`
+	case "file":
+		blockData = `{"url":"","title":"","description":""}`
 	}
 
 	// Create new block
@@ -123,6 +142,11 @@ func CreateBlockHandler(c *gin.Context) {
 		return
 	}
 
+	// Record the media the block uses
+	if err := media.UpdateBlockReferences(db.GetDB(), &block); err != nil {
+		fmt.Printf("Warning: Failed to record media used by block %d: %v\n", block.ID, err)
+	}
+
 	// Re-index the page in FTS
 	if err := search.IndexPage(db.GetDB(), &page); err != nil {
 		// Log error but don't fail the request
@@ -1039,6 +1063,18 @@ func EditBlockHandler(c *gin.Context) {
 				return ""
 			}(),
 			columnInputsHTML, pageIDStr)
+	} else if block.Type == "form" {
+		html = renderFormBlockEditor(c, pageIDStr, blockIDStr, block.Data)
+	} else if block.Type == "events" || block.Type == "calendar" {
+		html = renderEventsBlockEditor(c, pageIDStr, blockIDStr, block.Type, block.Data)
+	} else if block.Type == "shifts" {
+		html = renderShiftsBlockEditor(c, site, pageIDStr, blockIDStr, block.Data)
+	} else if block.Type == "posts" {
+		html = renderPostsBlockEditor(c, pageIDStr, blockIDStr, block.Data)
+	} else if block.Type == "children" {
+		html = renderChildPagesBlockEditor(c, pageIDStr, blockIDStr, block.Data)
+	} else if block.Type == "file" {
+		html = renderFileBlockEditor(c, pageIDStr, blockIDStr, block.Data)
 	} else {
 		c.String(http.StatusBadRequest, "Block type '%s' does not support editing yet", block.Type)
 		return
@@ -1128,34 +1164,61 @@ func UpdateBlockHandler(c *gin.Context) {
 			// Check if new image was uploaded
 			fileHeader, err := c.FormFile("image")
 			if err == nil && fileHeader != nil {
-				// Upload new image to centralized storage
-				filename, err := media.SaveToCentralizedStorage(fileHeader)
+				upload, err := media.PrepareImage(fileHeader)
 				if err != nil {
 					c.String(http.StatusBadRequest, fmt.Sprintf("Failed to upload image: %v", err))
 					return
 				}
-				url = "/assets/" + filename
-
-				// Create media item record for tracking
-				mediaItem := models.MediaItem{
-					SiteID:             site.ID,
-					UploadedFromSiteID: &site.ID,
-					Filename:           filename,
-					OriginalName:       fileHeader.Filename,
-					FileSize:           fileHeader.Size,
-					MimeType:           fileHeader.Header.Get("Content-Type"),
-					UploadedBy:         user.ID,
+				if existing := media.FindDuplicate(db.GetDB(), site.ID, upload.Hash); existing != nil {
+					// Reuse the copy already in the library
+					url = "/assets/" + existing.Filename
+				} else {
+					release, err := media.ReserveQuota(db.GetDB(), site, upload.Size)
+					if err != nil {
+						c.String(http.StatusRequestEntityTooLarge, fmt.Sprintf("Failed to upload image: %v", err))
+						return
+					}
+					defer release()
+
+					// Upload new image to the site's storage
+					store, bucket, err := media.StorageForSite(site)
+					if err != nil {
+						c.String(http.StatusInternalServerError, fmt.Sprintf("Failed to upload image: %v", err))
+						return
+					}
+					saved, err := upload.Store(store)
+					if err != nil {
+						c.String(http.StatusBadRequest, fmt.Sprintf("Failed to upload image: %v", err))
+						return
+					}
+					filename := saved.Filename
+					url = "/assets/" + filename
+
+					// Create media item record for tracking
+					mediaItem := models.MediaItem{
+						SiteID:             site.ID,
+						UploadedFromSiteID: &site.ID,
+						Filename:           filename,
+						OriginalName:       fileHeader.Filename,
+						FileSize:           saved.Size,
+						MimeType:           saved.ContentType,
+						UploadedBy:         user.ID,
+						Bucket:             bucket,
+						TakenAt:            saved.TakenAt,
+						ContentHash:        saved.Hash,
+					}
+					// Measure the image and make smaller copies for responsive pages
+					if err := media.GenerateDerivatives(store, &mediaItem); err != nil {
+						fmt.Printf("Warning: Failed to generate image sizes for %s: %v\n", filename, err)
+					}
+
+					// Generate thumbnail alongside it
+					_ = media.SaveThumbnail(store, filename)
+					mediaItem.DerivativesSize = media.MeasureDerivatives(store, &mediaItem)
+
+					db.GetDB().Create(&mediaItem) // Ignore error - not critical
+					media.UpdateQuotaAlerts(db.GetDB(), site)
 				}
-				db.GetDB().Create(&mediaItem) // Ignore error - not critical
-
-				// Generate thumbnail in centralized storage
-				mediaDir := config.GetString("storage.media_dir")
-				if mediaDir == "" {
-					mediaDir = "/var/lib/stinkykitty/media"
-				}
-				srcPath := filepath.Join(mediaDir, "uploads", filename)
-				thumbPath := filepath.Join(mediaDir, "uploads", "thumbs", filename)
-				_ = media.GenerateThumbnail(srcPath, thumbPath, 200, 200)
 			}
 			// Otherwise, keep existing URL
 		}
@@ -1284,6 +1347,54 @@ func UpdateBlockHandler(c *gin.Context) {
 			return
 		}
 		block.Data = string(jsonData)
+
+	case "form":
+		jsonData, err := formBlockDataFromRequest(c)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to encode block data")
+			return
+		}
+		block.Data = jsonData
+
+	case "events", "calendar":
+		jsonData, err := eventsBlockDataFromRequest(c, block.Type)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to encode block data")
+			return
+		}
+		block.Data = jsonData
+
+	case "shifts":
+		jsonData, err := shiftsBlockDataFromRequest(c)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to encode block data")
+			return
+		}
+		block.Data = jsonData
+
+	case "posts":
+		jsonData, err := postsBlockDataFromRequest(c)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to encode block data")
+			return
+		}
+		block.Data = jsonData
+
+	case "children":
+		jsonData, err := childPagesBlockDataFromRequest(c)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to encode block data")
+			return
+		}
+		block.Data = jsonData
+
+	case "file":
+		jsonData, err := fileBlockDataFromRequest(c, site, user)
+		if err != nil {
+			c.String(http.StatusBadRequest, fmt.Sprintf("Failed to upload document: %v", err))
+			return
+		}
+		block.Data = jsonData
 	}
 
 	// Save to database
@@ -1292,6 +1403,11 @@ func UpdateBlockHandler(c *gin.Context) {
 		return
 	}
 
+	// Record the media the block uses
+	if err := media.UpdateBlockReferences(db.GetDB(), &block); err != nil {
+		fmt.Printf("Warning: Failed to record media used by block %d: %v\n", block.ID, err)
+	}
+
 	// Re-index the page in FTS
 	if err := search.IndexPage(db.GetDB(), &page); err != nil {
 		// Log error but don't fail the request
@@ -1355,6 +1471,11 @@ func DeleteBlockHandler(c *gin.Context) {
 		return
 	}
 
+	// Forget the media the block used
+	if err := media.DeleteBlockReferences(db.GetDB(), block.ID); err != nil {
+		fmt.Printf("Warning: Failed to forget media used by block %d: %v\n", block.ID, err)
+	}
+
 	// Re-index the page in FTS
 	if err := search.IndexPage(db.GetDB(), &page); err != nil {
 		// Log error but don't fail the request
@@ -0,0 +1,288 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// contactInboxPageSize is the number of messages shown per inbox page
+const contactInboxPageSize = 50
+
+// countUnreadContactMessages returns the number of unread contact messages for a site
+func countUnreadContactMessages(siteID uint) int64 {
+	var count int64
+	db.GetDB().Model(&models.ContactSubmission{}).Where("site_id = ? AND is_read = ?", siteID, false).Count(&count)
+	return count
+}
+
+// ContactInboxHandler lists contact form messages for the current site
+func ContactInboxHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	unreadOnly := c.Query("filter") == "unread"
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	query := db.GetDB().Model(&models.ContactSubmission{}).Where("site_id = ?", site.ID)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var messages []models.ContactSubmission
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * contactInboxPageSize).Limit(contactInboxPageSize).
+		Find(&messages).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load messages")
+		return
+	}
+
+	var tableRows strings.Builder
+	for _, msg := range messages {
+		weight := "normal"
+		status := "Read"
+		if !msg.IsRead {
+			weight = "700"
+			status = "New"
+		}
+		fmt.Fprintf(&tableRows, `
+			<tr style="font-weight: %s;">
+				<td>%s</td>
+				<td>%s<br><span style="font-weight: normal; color: #666; font-size: 12px;">%s</span></td>
+				<td><a href="/admin/contact/%d">%s</a></td>
+				<td>%s</td>
+			</tr>
+		`, weight, status, html.EscapeString(msg.Name), html.EscapeString(msg.Email),
+			msg.ID, html.EscapeString(msg.Subject), msg.CreatedAt.Format("2006-01-02 15:04"))
+	}
+	if len(messages) == 0 {
+		tableRows.WriteString(`<tr><td colspan="4" style="text-align: center; color: #666;">No messages.</td></tr>`)
+	}
+
+	filterParam := ""
+	if unreadOnly {
+		filterParam = "&filter=unread"
+	}
+	var pager string
+	if page > 1 {
+		pager += fmt.Sprintf(`<a href="/admin/contact?page=%d%s" class="btn btn-small btn-secondary">← Newer</a> `, page-1, filterParam)
+	}
+	if int64(page*contactInboxPageSize) < total {
+		pager += fmt.Sprintf(`<a href="/admin/contact?page=%d%s" class="btn btn-small btn-secondary">Older →</a>`, page+1, filterParam)
+	}
+
+	allClass, unreadClass := "btn btn-small", "btn btn-small btn-secondary"
+	if unreadOnly {
+		allClass, unreadClass = unreadClass, allClass
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Contact Inbox - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 1200px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+		.filters { display: flex; gap: 8px; margin-bottom: var(--spacing-md); }
+		.pager { margin-top: var(--spacing-md); display: flex; gap: 8px; }
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>Contact Inbox</h1>
+			<div class="header-actions">
+				<a href="/admin/pages?site=%d" class="btn btn-secondary">← Back to Pages</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		%s
+		<div class="filters">
+			<a href="/admin/contact" class="%s">All</a>
+			<a href="/admin/contact?filter=unread" class="%s">Unread (%d)</a>
+		</div>
+		<div class="card">
+			<table class="data-table">
+				<thead>
+					<tr>
+						<th>Status</th>
+						<th>From</th>
+						<th>Subject</th>
+						<th>Received</th>
+					</tr>
+				</thead>
+				<tbody>
+					%s
+				</tbody>
+			</table>
+			<div class="pager">%s</div>
+		</div>
+	</div>
+</body>
+</html>`, GetDesignSystemCSS(), site.ID, renderSpamSummary(site.ID), allClass, unreadClass, countUnreadContactMessages(site.ID), tableRows.String(), pager)
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// loadSiteContactMessage loads a contact message by the :id param, scoped to the site
+func loadSiteContactMessage(c *gin.Context, site *models.Site) (*models.ContactSubmission, bool) {
+	var msg models.ContactSubmission
+	if err := db.GetDB().Where("id = ? AND site_id = ?", c.Param("id"), site.ID).First(&msg).Error; err != nil {
+		c.String(http.StatusNotFound, "Message not found")
+		return nil, false
+	}
+	return &msg, true
+}
+
+// ContactMessageHandler shows a single contact message and marks it as read
+func ContactMessageHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	msg, ok := loadSiteContactMessage(c, site)
+	if !ok {
+		return
+	}
+
+	if !msg.IsRead {
+		msg.IsRead = true
+		db.GetDB().Model(msg).Update("is_read", true) // Ignore error - not critical
+	}
+
+	csrfToken := middleware.GetCSRFTokenHTML(c)
+	replyLink := html.EscapeString("mailto:" + msg.Email + "?subject=" + url.PathEscape("Re: "+msg.Subject))
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>%s - Contact Inbox - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 900px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+		.meta { color: #666; margin-bottom: var(--spacing-md); }
+		.message-body { white-space: pre-wrap; line-height: 1.6; }
+		.actions { display: flex; gap: 8px; margin-top: var(--spacing-lg); }
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>%s</h1>
+			<div class="header-actions">
+				<a href="/admin/contact" class="btn btn-secondary">← Back to Inbox</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		<div class="card">
+			<div class="meta">
+				From <strong>%s</strong> &lt;%s&gt;<br>
+				Received %s
+			</div>
+			<div class="message-body">%s</div>
+			<div class="actions">
+				<a href="%s" class="btn">Reply</a>
+				<form method="POST" action="/admin/contact/%d/unread" style="display: inline;">
+					%s
+					<button type="submit" class="btn btn-secondary">Mark Unread</button>
+				</form>
+				<form method="POST" action="/admin/contact/%d/delete" style="display: inline;" onsubmit="return confirm('Delete this message?');">
+					%s
+					<button type="submit" class="btn btn-danger">Delete</button>
+				</form>
+			</div>
+		</div>
+	</div>
+</body>
+</html>`, html.EscapeString(msg.Subject), GetDesignSystemCSS(), html.EscapeString(msg.Subject),
+		html.EscapeString(msg.Name), html.EscapeString(msg.Email), msg.CreatedAt.Format("2006-01-02 15:04"),
+		html.EscapeString(msg.Message), replyLink, msg.ID, csrfToken, msg.ID, csrfToken)
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// ContactMarkUnreadHandler marks a contact message as unread
+func ContactMarkUnreadHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	msg, ok := loadSiteContactMessage(c, site)
+	if !ok {
+		return
+	}
+
+	if err := db.GetDB().Model(msg).Update("is_read", false).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to update message")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/contact")
+}
+
+// ContactDeleteHandler soft-deletes a contact message
+func ContactDeleteHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	msg, ok := loadSiteContactMessage(c, site)
+	if !ok {
+		return
+	}
+
+	if err := db.GetDB().Delete(msg).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to delete message")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/contact")
+}
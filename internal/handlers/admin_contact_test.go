@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+)
+
+func setupContactTest(t *testing.T) (*gorm.DB, *models.Site) {
+	gin.SetMode(gin.TestMode)
+	testDB := setupTestDB(t)
+	if err := testDB.AutoMigrate(&models.User{}, &models.MenuItem{}, &models.ContactSubmission{}, &models.OutboundEmail{}, &models.SpamCounter{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	db.SetDB(testDB)
+
+	owner := &models.User{ID: 1, Email: "owner@example.com"}
+	testDB.Create(owner)
+	site := &models.Site{ID: 1, Subdomain: "test", OwnerID: owner.ID, SiteDir: "/tmp/test"}
+	testDB.Create(site)
+
+	return testDB, site
+}
+
+func TestContactFormHandler_StoresAndQueues(t *testing.T) {
+	testDB, site := setupContactTest(t)
+
+	form := url.Values{
+		"name":    {"Mittens"},
+		"email":   {"mittens@example.com"},
+		"subject": {"Camp <dues>"},
+		"message": {"When are they due?"},
+	}
+	for k, v := range validSpamFields(site) {
+		form[k] = v
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/contact", strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Set("site", site)
+
+	ContactFormHandler(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var msg models.ContactSubmission
+	if err := testDB.First(&msg).Error; err != nil {
+		t.Fatalf("Expected contact submission to be stored: %v", err)
+	}
+	if msg.Subject != "Camp <dues>" || msg.IsRead {
+		t.Errorf("Unexpected stored message: %+v", msg)
+	}
+
+	var queued models.OutboundEmail
+	if err := testDB.First(&queued).Error; err != nil {
+		t.Fatalf("Expected notification email to be queued: %v", err)
+	}
+	if queued.To != "owner@example.com" || queued.Status != "pending" {
+		t.Errorf("Unexpected queued email: %+v", queued)
+	}
+}
+
+func TestContactMessageHandler_MarksRead(t *testing.T) {
+	testDB, site := setupContactTest(t)
+
+	msg := &models.ContactSubmission{SiteID: site.ID, Name: "Mittens", Email: "m@example.com", Subject: "Hi", Message: "<b>hello</b>"}
+	testDB.Create(msg)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/contact/1", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set("site", site)
+
+	ContactMessageHandler(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "<b>hello</b>") {
+		t.Error("Expected message body to be escaped")
+	}
+
+	var reloaded models.ContactSubmission
+	testDB.First(&reloaded, msg.ID)
+	if !reloaded.IsRead {
+		t.Error("Expected message to be marked read after viewing")
+	}
+	if countUnreadContactMessages(site.ID) != 0 {
+		t.Error("Expected no unread messages")
+	}
+}
+
+func TestContactMessageHandler_OtherSite(t *testing.T) {
+	testDB, site := setupContactTest(t)
+
+	other := &models.Site{ID: 2, Subdomain: "other", OwnerID: 1, SiteDir: "/tmp/other"}
+	testDB.Create(other)
+	testDB.Create(&models.ContactSubmission{SiteID: other.ID, Name: "A", Email: "a@example.com", Subject: "Private"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/contact/1", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set("site", site)
+
+	ContactMessageHandler(c)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for another site's message, got %d", w.Code)
+	}
+}
@@ -0,0 +1,523 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/blocks"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/events"
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// EventsListHandler lists a site's events
+func EventsListHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	csrfToken := middleware.GetCSRFTokenHTML(c)
+	loc := events.Location()
+
+	var tableRows strings.Builder
+	for _, ev := range loadSiteEvents(site.ID) {
+		occ := events.Occurrence{Event: &ev, Start: ev.StartsAt.In(loc), End: ev.EndsAt.In(loc)}
+		repeats := "—"
+		for _, opt := range events.RecurrenceLabels {
+			if ev.Recurrence != events.RecurNone && opt.Value == ev.Recurrence {
+				repeats = opt.Label
+			}
+		}
+		fmt.Fprintf(&tableRows, `
+			<tr>
+				<td>%s</td>
+				<td>%s</td>
+				<td>%s</td>
+				<td>%s</td>
+				<td>
+					<div style="display: flex; gap: 8px;">
+						<a href="/admin/events/%d/edit" class="btn btn-small">Edit</a>
+						<a href="/events/%d" class="btn btn-small btn-secondary" target="_blank">View</a>
+						<form method="POST" action="/admin/events/%d/delete" style="display: inline;" onsubmit="return confirm('Delete this event?');">
+							%s
+							<button type="submit" class="btn btn-small btn-danger">Delete</button>
+						</form>
+					</div>
+				</td>
+			</tr>`, html.EscapeString(ev.Title), html.EscapeString(occ.When()), repeats, html.EscapeString(ev.Location),
+			ev.ID, ev.ID, ev.ID, csrfToken)
+	}
+	if tableRows.Len() == 0 {
+		tableRows.WriteString(`<tr><td colspan="5" style="text-align: center; color: #666;">No events yet.</td></tr>`)
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Events - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 1200px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>Events</h1>
+			<div class="header-actions">
+				<a href="/admin/events/new" class="btn">+ New Event</a>
+				<a href="/admin/pages?site=%d" class="btn btn-secondary">← Back to Pages</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		<p>Events appear at <a href="/events" target="_blank">/events</a>, in Events List and Calendar blocks, and in the <a href="/events.ics">iCal feed</a> members can subscribe to from their phones. Times are in %s.</p>
+		<div class="card">
+			<table class="data-table">
+				<thead>
+					<tr>
+						<th>Title</th>
+						<th>When</th>
+						<th>Repeats</th>
+						<th>Location</th>
+						<th>Actions</th>
+					</tr>
+				</thead>
+				<tbody>
+					%s
+				</tbody>
+			</table>
+		</div>
+	</div>
+</body>
+</html>`, GetDesignSystemCSS(), site.ID, html.EscapeString(loc.String()), tableRows.String())
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// renderEventForm renders the create/edit form for an event. ev.ID of 0 means a new event.
+func renderEventForm(c *gin.Context, ev *models.Event, errMsg string) {
+	loc := events.Location()
+	start := ev.StartsAt.In(loc)
+	end := ev.EndsAt.In(loc)
+
+	title := "New Event"
+	action := "/admin/events"
+	if ev.ID != 0 {
+		title = "Edit Event"
+		action = fmt.Sprintf("/admin/events/%d", ev.ID)
+	}
+
+	errorHTML := ""
+	status := http.StatusOK
+	if errMsg != "" {
+		errorHTML = `<div class="error-message">` + html.EscapeString(errMsg) + `</div>`
+		status = http.StatusBadRequest
+	}
+
+	var recurOptions strings.Builder
+	for _, opt := range events.RecurrenceLabels {
+		selected := ""
+		if opt.Value == ev.Recurrence {
+			selected = " selected"
+		}
+		fmt.Fprintf(&recurOptions, `<option value="%s"%s>%s</option>`, opt.Value, selected, opt.Label)
+	}
+
+	recurUntil := ""
+	if ev.RecurUntil != nil {
+		recurUntil = ev.RecurUntil.In(loc).Format("2006-01-02")
+	}
+	allDay := ""
+	if ev.AllDay {
+		allDay = " checked"
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>%s - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 800px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+		.form-group { margin-bottom: var(--spacing-md); }
+		.form-group label { display: block; margin-bottom: 6px; font-weight: 600; }
+		.form-group input[type="text"], .form-group select, .form-group textarea { width: 100%%; box-sizing: border-box; }
+		.form-row { display: flex; gap: var(--spacing-sm); }
+		.help-text { font-size: 13px; color: var(--color-text-secondary); margin-top: 4px; }
+		.error-message { background: #f8d7da; border: 1px solid #f5c6cb; color: #721c24; padding: 12px; border-radius: 4px; margin-bottom: var(--spacing-md); }
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>%s</h1>
+			<div class="header-actions">
+				<a href="/admin/events" class="btn btn-secondary">← Back to Events</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		%s
+		<div class="card">
+			<form method="POST" action="%s">
+				%s
+				<div class="form-group">
+					<label for="title">Title</label>
+					<input type="text" id="title" name="title" value="%s" required>
+				</div>
+				<div class="form-group">
+					<label>Starts</label>
+					<div class="form-row">
+						<input type="date" name="start_date" value="%s" required>
+						<input type="time" name="start_time" value="%s">
+					</div>
+				</div>
+				<div class="form-group">
+					<label>Ends</label>
+					<div class="form-row">
+						<input type="date" name="end_date" value="%s">
+						<input type="time" name="end_time" value="%s">
+					</div>
+					<div class="help-text">Leave the end date blank for an event that ends the same day. Times are in %s.</div>
+				</div>
+				<div class="form-group">
+					<label style="display: flex; gap: 8px; align-items: center; font-weight: normal;">
+						<input type="checkbox" name="all_day" value="1"%s> All-day event (times are ignored)
+					</label>
+				</div>
+				<div class="form-group">
+					<label for="location">Location (optional)</label>
+					<input type="text" id="location" name="location" value="%s">
+				</div>
+				<div class="form-group">
+					<label for="description">Description (optional)</label>
+					<textarea id="description" name="description" rows="6">%s</textarea>
+				</div>
+				<div class="form-group">
+					<label for="recurrence">Repeats</label>
+					<select id="recurrence" name="recurrence">%s</select>
+				</div>
+				<div class="form-group">
+					<label for="recur_until">Repeat Until (optional)</label>
+					<input type="date" id="recur_until" name="recur_until" value="%s">
+					<div class="help-text">Last date a repeating event occurs. Leave blank to repeat indefinitely.</div>
+				</div>
+				<button type="submit" class="btn">Save Event</button>
+			</form>
+		</div>
+	</div>
+</body>
+</html>`, title, GetDesignSystemCSS(), title, errorHTML, action, middleware.GetCSRFTokenHTML(c),
+		html.EscapeString(ev.Title),
+		dateValue(start), timeValue(start, ev.AllDay),
+		dateValue(end), timeValue(end, ev.AllDay),
+		html.EscapeString(loc.String()),
+		allDay,
+		html.EscapeString(ev.Location),
+		html.EscapeString(ev.Description),
+		recurOptions.String(),
+		recurUntil)
+
+	c.Data(status, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// dateValue formats t for a date input, leaving it blank for the zero time
+func dateValue(t time.Time) string {
+	if t.IsZero() || t.Year() <= 1 {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// timeValue formats t for a time input, leaving it blank for all-day events
+func timeValue(t time.Time, allDay bool) string {
+	if allDay || t.IsZero() || t.Year() <= 1 {
+		return ""
+	}
+	return t.Format("15:04")
+}
+
+// eventFromRequest fills ev from the posted event form, returning a message for
+// the user if anything is invalid
+func eventFromRequest(c *gin.Context, ev *models.Event) string {
+	loc := events.Location()
+
+	ev.Title = strings.TrimSpace(c.PostForm("title"))
+	ev.Location = strings.TrimSpace(c.PostForm("location"))
+	ev.Description = strings.TrimSpace(c.PostForm("description"))
+	ev.AllDay = c.PostForm("all_day") != ""
+	ev.Recurrence = c.PostForm("recurrence")
+
+	if ev.Title == "" {
+		return "Title is required"
+	}
+	if !events.IsValidRecurrence(ev.Recurrence) {
+		return "Invalid repeat option"
+	}
+
+	startDate := c.PostForm("start_date")
+	endDate := c.PostForm("end_date")
+	if endDate == "" {
+		endDate = startDate
+	}
+	startTime, endTime := c.PostForm("start_time"), c.PostForm("end_time")
+	if ev.AllDay {
+		startTime, endTime = "", ""
+	}
+
+	start, err := parseEventTime(startDate, startTime, loc)
+	if err != nil {
+		return "Start date is invalid"
+	}
+	if endTime == "" && !ev.AllDay {
+		// Timed event with no end time: keep it the same length as the start time
+		endTime = startTime
+	}
+	end, err := parseEventTime(endDate, endTime, loc)
+	if err != nil {
+		return "End date is invalid"
+	}
+	if end.Before(start) {
+		return "The event can't end before it starts"
+	}
+	ev.StartsAt = start
+	ev.EndsAt = end
+
+	ev.RecurUntil = nil
+	if until := c.PostForm("recur_until"); until != "" && ev.Recurrence != events.RecurNone {
+		day, err := time.ParseInLocation("2006-01-02", until, loc)
+		if err != nil {
+			return "Repeat-until date is invalid"
+		}
+		// Include occurrences starting any time on the final day
+		last := day.AddDate(0, 0, 1).Add(-time.Second)
+		ev.RecurUntil = &last
+	}
+
+	return ""
+}
+
+// parseEventTime combines a date input and an optional time input in loc
+func parseEventTime(date, clock string, loc *time.Location) (time.Time, error) {
+	if clock == "" {
+		return time.ParseInLocation("2006-01-02", date, loc)
+	}
+	return time.ParseInLocation("2006-01-02 15:04", date+" "+clock, loc)
+}
+
+// loadSiteEvent loads an event by the :id route param, scoped to the site
+func loadSiteEvent(c *gin.Context, site *models.Site) (*models.Event, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid event ID")
+		return nil, false
+	}
+	var ev models.Event
+	if err := db.GetDB().Where("id = ? AND site_id = ?", id, site.ID).First(&ev).Error; err != nil {
+		c.String(http.StatusNotFound, "Event not found")
+		return nil, false
+	}
+	return &ev, true
+}
+
+// NewEventFormHandler shows the form for creating an event
+func NewEventFormHandler(c *gin.Context) {
+	if _, exists := c.Get("site"); !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+
+	// Default to 7pm tomorrow, two hours long
+	now := time.Now().In(events.Location())
+	start := time.Date(now.Year(), now.Month(), now.Day()+1, 19, 0, 0, 0, events.Location())
+	renderEventForm(c, &models.Event{StartsAt: start, EndsAt: start.Add(2 * time.Hour)}, "")
+}
+
+// CreateEventHandler saves a new event
+func CreateEventHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	ev := &models.Event{SiteID: site.ID}
+	if errMsg := eventFromRequest(c, ev); errMsg != "" {
+		renderEventForm(c, ev, errMsg)
+		return
+	}
+
+	if err := db.GetDB().Create(ev).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to create event")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/events")
+}
+
+// EditEventFormHandler shows the form for editing an event
+func EditEventFormHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	ev, ok := loadSiteEvent(c, siteVal.(*models.Site))
+	if !ok {
+		return
+	}
+	renderEventForm(c, ev, "")
+}
+
+// UpdateEventHandler saves changes to an event
+func UpdateEventHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	ev, ok := loadSiteEvent(c, siteVal.(*models.Site))
+	if !ok {
+		return
+	}
+
+	if errMsg := eventFromRequest(c, ev); errMsg != "" {
+		renderEventForm(c, ev, errMsg)
+		return
+	}
+
+	if err := db.GetDB().Save(ev).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to update event")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/events")
+}
+
+// DeleteEventHandler deletes an event
+func DeleteEventHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	ev, ok := loadSiteEvent(c, siteVal.(*models.Site))
+	if !ok {
+		return
+	}
+
+	if err := db.GetDB().Delete(ev).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to delete event")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/events")
+}
+
+// renderEventsBlockEditor renders the edit screen for events-list and calendar blocks
+func renderEventsBlockEditor(c *gin.Context, pageIDStr, blockIDStr, blockType, dataJSON string) string {
+	data, err := blocks.ParseEventsBlockData(dataJSON)
+	if err != nil {
+		data, _ = blocks.ParseEventsBlockData(`{}`)
+	}
+
+	heading := "Edit Events List Block"
+	note := "This block lists the site's next upcoming events."
+	limitHTML := fmt.Sprintf(`
+            <label for="limit">Number of Events:</label>
+            <input type="number" id="limit" name="limit" value="%d" min="1" max="50">
+            <p class="help-text">How many upcoming events to show</p>`, data.Limit)
+	if blockType == "calendar" {
+		heading = "Edit Calendar Block"
+		note = "This block shows a calendar of the current month's events."
+		limitHTML = ""
+	}
+
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>%s</title>
+    <style>
+        body { font-family: system-ui, sans-serif; max-width: 800px; margin: 40px auto; padding: 0 20px; background: #f5f5f5; }
+        .container { background: white; padding: 30px; border-radius: 8px; box-shadow: 0 2px 4px rgba(0,0,0,0.1); }
+        h1 { color: #333; margin-top: 0; }
+        label { display: block; margin-bottom: 8px; font-weight: 600; color: #555; }
+        input[type="text"], input[type="number"] { width: 100%%; padding: 12px; border: 1px solid #ddd; border-radius: 4px; font-size: 14px; box-sizing: border-box; margin-bottom: 15px; }
+        input:focus { outline: none; border-color: #2563eb; }
+        .help-text { font-size: 12px; color: #666; margin-top: -10px; margin-bottom: 15px; }
+        .button-group { margin-top: 20px; display: flex; gap: 10px; }
+        button { padding: 10px 20px; border: none; border-radius: 4px; cursor: pointer; font-size: 14px; font-weight: 600; }
+        button[type="submit"] { background: #2563eb; color: white; }
+        button[type="submit"]:hover { background: #1d4ed8; }
+        a.cancel { padding: 10px 20px; background: #6b7280; color: white; text-decoration: none; border-radius: 4px; font-size: 14px; font-weight: 600; }
+        a.cancel:hover { background: #4b5563; }
+        .note { background: #f0f4f8; padding: 15px; border-radius: 4px; margin-bottom: 20px; color: #555; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1>%s</h1>
+        <div class="note">
+            <strong>Note:</strong> %s Events are managed on the <a href="/admin/events">Events</a> screen.
+        </div>
+        <form method="POST" action="/admin/pages/%s/blocks/%s">
+            `+middleware.GetCSRFTokenHTML(c)+`
+            <label for="title">Title (optional):</label>
+            <input type="text" id="title" name="title" value="%s" placeholder="Upcoming Events">
+            %s
+            <div class="button-group">
+                <button type="submit">Save &amp; Return</button>
+                <a href="/admin/pages/%s/edit" class="cancel">Cancel</a>
+            </div>
+        </form>
+    </div>
+</body>
+</html>`, heading, heading, note, pageIDStr, blockIDStr, html.EscapeString(data.Title), limitHTML, pageIDStr)
+}
+
+// eventsBlockDataFromRequest builds events or calendar block JSON from the posted editor form
+func eventsBlockDataFromRequest(c *gin.Context, blockType string) (string, error) {
+	data := blocks.EventsBlockData{Title: strings.TrimSpace(c.PostForm("title"))}
+	if blockType == "events" {
+		limit, err := strconv.Atoi(c.PostForm("limit"))
+		if err != nil || limit < 1 || limit > 50 {
+			limit = 5
+		}
+		data.Limit = limit
+	}
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
@@ -0,0 +1,607 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/blocks"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// maxFormFields caps how many fields a single form block can define
+const maxFormFields = 50
+
+// renderFormBlockEditor renders the edit screen for a form block
+func renderFormBlockEditor(c *gin.Context, pageIDStr, blockIDStr, dataJSON string) string {
+	formData, err := blocks.ParseFormBlockData(dataJSON)
+	if err != nil {
+		formData, _ = blocks.ParseFormBlockData(`{}`)
+	}
+
+	// Always offer a blank row so a new field can be added without JavaScript
+	fields := append(formData.Fields, blocks.FormField{Type: "text"})
+
+	var rowsHTML strings.Builder
+	for i, field := range fields {
+		rowsHTML.WriteString(formFieldEditorRow(i, field))
+	}
+
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>Edit Form Block</title>
+    <style>
+        body { font-family: system-ui, sans-serif; max-width: 900px; margin: 40px auto; padding: 0 20px; background: #f5f5f5; }
+        .container { background: white; padding: 30px; border-radius: 8px; box-shadow: 0 2px 4px rgba(0,0,0,0.1); }
+        h1 { color: #333; margin-top: 0; }
+        label { display: block; margin-bottom: 8px; font-weight: 600; color: #555; }
+        input[type="text"], input[type="email"], select, textarea { width: 100%%; padding: 12px; border: 1px solid #ddd; border-radius: 4px; font-size: 14px; box-sizing: border-box; margin-bottom: 15px; font-family: system-ui; }
+        input:focus, select:focus, textarea:focus { outline: none; border-color: #2563eb; }
+        .help-text { font-size: 12px; color: #666; margin-top: -10px; margin-bottom: 15px; }
+        .button-group { margin-top: 20px; display: flex; gap: 10px; }
+        button { padding: 10px 20px; border: none; border-radius: 4px; cursor: pointer; font-size: 14px; font-weight: 600; }
+        button[type="submit"] { background: #2563eb; color: white; }
+        button[type="submit"]:hover { background: #1d4ed8; }
+        button.add-field { background: #e5e7eb; color: #333; margin-bottom: 20px; }
+        a.cancel { padding: 10px 20px; background: #6b7280; color: white; text-decoration: none; border-radius: 4px; font-size: 14px; font-weight: 600; }
+        a.cancel:hover { background: #4b5563; }
+        .note { background: #f0f4f8; padding: 15px; border-radius: 4px; margin-bottom: 20px; color: #555; }
+        .field-row { background: #f8f9fa; padding: 15px; border-radius: 4px; margin-bottom: 15px; display: grid; grid-template-columns: 2fr 1fr; gap: 0 15px; }
+        .field-row .wide { grid-column: 1 / -1; }
+        .field-row label.inline { display: flex; gap: 8px; align-items: center; font-weight: normal; }
+    </style>
+    <script>
+        function addField() {
+            const container = document.getElementById('fields-container');
+            const countInput = document.getElementById('field_count');
+            const i = parseInt(countInput.value);
+            const template = document.getElementById('field-template').innerHTML;
+            container.insertAdjacentHTML('beforeend', template.replace(/__INDEX__/g, i));
+            countInput.value = i + 1;
+        }
+    </script>
+</head>
+<body>
+    <div class="container">
+        <h1>Edit Form Block</h1>
+        <div class="note">
+            <strong>Note:</strong> Visitors' responses are saved and can be viewed or exported from <a href="/admin/forms">Form Submissions</a>. To remove a field, clear its label.
+        </div>
+        <form method="POST" action="/admin/pages/%s/blocks/%s">
+            `+middleware.GetCSRFTokenHTML(c)+`
+            <label for="title">Form Title:</label>
+            <input type="text" id="title" name="title" value="%s" placeholder="Sign Up">
+
+            <label for="description">Description (optional):</label>
+            <textarea id="description" name="description" rows="3">%s</textarea>
+
+            <h2>Fields</h2>
+            <input type="hidden" id="field_count" name="field_count" value="%d">
+            <div id="fields-container">
+                %s
+            </div>
+            <button type="button" class="add-field" onclick="addField()">+ Add Field</button>
+
+            <label for="submit_label">Submit Button Text:</label>
+            <input type="text" id="submit_label" name="submit_label" value="%s">
+
+            <label for="confirmation_message">Confirmation Message:</label>
+            <textarea id="confirmation_message" name="confirmation_message" rows="3">%s</textarea>
+            <p class="help-text">Shown to visitors after they submit the form</p>
+
+            <label for="notify_email">Notification Email (optional):</label>
+            <input type="email" id="notify_email" name="notify_email" value="%s" placeholder="you@example.com">
+            <p class="help-text">Send a copy of each submission to this address</p>
+
+            <div class="button-group">
+                <button type="submit">Save &amp; Return</button>
+                <a href="/admin/pages/%s/edit" class="cancel">Cancel</a>
+            </div>
+        </form>
+        <template id="field-template">%s</template>
+    </div>
+</body>
+</html>`, pageIDStr, blockIDStr,
+		html.EscapeString(formData.Title),
+		html.EscapeString(formData.Description),
+		len(fields),
+		rowsHTML.String(),
+		html.EscapeString(formData.SubmitLabel),
+		html.EscapeString(formData.ConfirmationMessage),
+		html.EscapeString(formData.NotifyEmail),
+		pageIDStr,
+		formFieldEditorRow(-1, blocks.FormField{Type: "text"}))
+}
+
+// formFieldEditorRow renders the inputs for one form field. An index of -1 renders
+// the row with an __INDEX__ placeholder for use as a JavaScript template.
+func formFieldEditorRow(i int, field blocks.FormField) string {
+	idx := strconv.Itoa(i)
+	if i < 0 {
+		idx = "__INDEX__"
+	}
+
+	var typeOptions strings.Builder
+	for _, t := range blocks.FormFieldTypes {
+		selected := ""
+		if t == field.Type {
+			selected = " selected"
+		}
+		fmt.Fprintf(&typeOptions, `<option value="%s"%s>%s</option>`, t, selected, strings.ToUpper(t[:1])+t[1:])
+	}
+
+	required := ""
+	if field.Required {
+		required = " checked"
+	}
+
+	return fmt.Sprintf(`
+                <div class="field-row">
+                    <input type="hidden" name="field_%s_name" value="%s">
+                    <div>
+                        <label>Label:</label>
+                        <input type="text" name="field_%s_label" value="%s" placeholder="e.g. Your Name">
+                    </div>
+                    <div>
+                        <label>Type:</label>
+                        <select name="field_%s_type">%s</select>
+                    </div>
+                    <div class="wide">
+                        <label>Choices (select fields only, comma separated):</label>
+                        <input type="text" name="field_%s_options" value="%s">
+                    </div>
+                    <label class="inline wide"><input type="checkbox" name="field_%s_required" value="1"%s> Required</label>
+                </div>`,
+		idx, html.EscapeString(field.Name),
+		idx, html.EscapeString(field.Label),
+		idx, typeOptions.String(),
+		idx, html.EscapeString(strings.Join(field.Options, ", ")),
+		idx, required)
+}
+
+// formBlockDataFromRequest builds form block JSON from the block editor's POST data
+func formBlockDataFromRequest(c *gin.Context) (string, error) {
+	data := blocks.FormBlockData{
+		Title:               strings.TrimSpace(c.PostForm("title")),
+		Description:         strings.TrimSpace(c.PostForm("description")),
+		SubmitLabel:         strings.TrimSpace(c.PostForm("submit_label")),
+		ConfirmationMessage: strings.TrimSpace(c.PostForm("confirmation_message")),
+		Fields:              []blocks.FormField{},
+	}
+
+	// Only keep the notification address if it looks like a real one
+	if notify := strings.TrimSpace(c.PostForm("notify_email")); notify != "" {
+		if _, err := mail.ParseAddress(notify); err == nil {
+			data.NotifyEmail = notify
+		}
+	}
+
+	count, err := strconv.Atoi(c.PostForm("field_count"))
+	if err != nil || count < 0 {
+		count = 0
+	}
+	if count > maxFormFields {
+		count = maxFormFields
+	}
+
+	usedNames := map[string]bool{}
+	for i := 0; i < count; i++ {
+		label := strings.TrimSpace(c.PostForm(fmt.Sprintf("field_%d_label", i)))
+		if label == "" {
+			continue
+		}
+
+		fieldType := c.PostForm(fmt.Sprintf("field_%d_type", i))
+		if !blocks.IsValidFormFieldType(fieldType) {
+			fieldType = "text"
+		}
+
+		// Keep existing field names so stored submissions still line up after a label edit
+		name := blocks.FormFieldName(label)
+		if existing := c.PostForm(fmt.Sprintf("field_%d_name", i)); existing != "" {
+			name = blocks.FormFieldName(existing)
+		}
+		base := name
+		for n := 2; usedNames[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		usedNames[name] = true
+
+		field := blocks.FormField{
+			Name:     name,
+			Label:    label,
+			Type:     fieldType,
+			Required: c.PostForm(fmt.Sprintf("field_%d_required", i)) != "",
+		}
+		if fieldType == "select" {
+			for _, opt := range strings.Split(c.PostForm(fmt.Sprintf("field_%d_options", i)), ",") {
+				if opt = strings.TrimSpace(opt); opt != "" {
+					field.Options = append(field.Options, opt)
+				}
+			}
+		}
+
+		data.Fields = append(data.Fields, field)
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
+
+// formColumn is one column of a submissions table
+type formColumn struct {
+	Name  string
+	Label string
+}
+
+// formColumns returns the columns for a form's submissions: the block's current
+// fields in order, followed by any fields only present in older submissions
+func formColumns(formData *blocks.FormBlockData, submissions []models.FormSubmission) []formColumn {
+	var columns []formColumn
+	seen := map[string]bool{}
+	if formData != nil {
+		for _, field := range formData.Fields {
+			columns = append(columns, formColumn{Name: field.Name, Label: field.Label})
+			seen[field.Name] = true
+		}
+	}
+
+	var extra []string
+	for _, sub := range submissions {
+		for name := range sub.GetValues() {
+			if !seen[name] {
+				seen[name] = true
+				extra = append(extra, name)
+			}
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		columns = append(columns, formColumn{Name: name, Label: name})
+	}
+
+	return columns
+}
+
+// loadSiteFormBlock loads a form block by ID, ensuring it belongs to the site.
+// Blocks that have since been deleted are still returned so their submissions
+// remain accessible.
+func loadSiteFormBlock(site *models.Site, blockID int) (*models.Block, error) {
+	var block models.Block
+	err := db.GetDB().Unscoped().
+		Joins("JOIN pages ON pages.id = blocks.page_id").
+		Where("blocks.id = ? AND blocks.type = ? AND pages.site_id = ?", blockID, "form", site.ID).
+		First(&block).Error
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// FormsListHandler lists the site's forms along with their submission counts
+func FormsListHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	type formRow struct {
+		BlockID   uint
+		PageID    uint
+		PageTitle string
+		Data      string
+		Deleted   bool
+		Count     int64
+	}
+
+	var rows []formRow
+	if err := db.GetDB().Raw(`
+		SELECT b.id AS block_id, b.page_id, p.title AS page_title, b.data,
+			   (b.deleted_at IS NOT NULL OR p.deleted_at IS NOT NULL) AS deleted,
+			   (SELECT COUNT(*) FROM form_submissions fs WHERE fs.block_id = b.id AND fs.deleted_at IS NULL) AS count
+		FROM blocks b
+		INNER JOIN pages p ON p.id = b.page_id
+		WHERE b.type = 'form' AND p.site_id = ?
+		ORDER BY p.title, b.id
+	`, site.ID).Scan(&rows).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load forms")
+		return
+	}
+
+	var tableRows string
+	for _, row := range rows {
+		// Deleted forms are only worth listing if they still hold submissions
+		if row.Deleted && row.Count == 0 {
+			continue
+		}
+
+		title := "Untitled form"
+		if formData, err := blocks.ParseFormBlockData(row.Data); err == nil && formData.Title != "" {
+			title = formData.Title
+		}
+		pageLabel := html.EscapeString(row.PageTitle)
+		if row.Deleted {
+			pageLabel += ` <span style="color: #999;">(deleted)</span>`
+		}
+
+		tableRows += fmt.Sprintf(`
+			<tr>
+				<td>%s</td>
+				<td>%s</td>
+				<td>%d</td>
+				<td>
+					<div style="display: flex; gap: 8px;">
+						<a href="/admin/forms/%d" class="btn btn-small">View</a>
+						<a href="/admin/forms/%d/export" class="btn btn-small btn-secondary">Export CSV</a>
+					</div>
+				</td>
+			</tr>
+		`, html.EscapeString(title), pageLabel, row.Count, row.BlockID, row.BlockID)
+	}
+
+	if tableRows == "" {
+		tableRows = `<tr><td colspan="4" style="text-align: center; color: #666;">No forms yet. Add a Form block to a page to start collecting responses.</td></tr>`
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Form Submissions - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 1200px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>Form Submissions</h1>
+			<div class="header-actions">
+				<a href="/admin/pages?site=%d" class="btn btn-secondary">← Back to Pages</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		%s
+		<div class="card">
+			<table class="data-table">
+				<thead>
+					<tr>
+						<th>Form</th>
+						<th>Page</th>
+						<th>Submissions</th>
+						<th>Actions</th>
+					</tr>
+				</thead>
+				<tbody>
+					%s
+				</tbody>
+			</table>
+		</div>
+	</div>
+</body>
+</html>`, GetDesignSystemCSS(), site.ID, renderSpamSummary(site.ID), tableRows)
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// FormSubmissionsHandler shows the inbox of submissions for one form
+func FormSubmissionsHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	blockID, err := strconv.Atoi(c.Param("block_id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid form ID")
+		return
+	}
+
+	block, err := loadSiteFormBlock(site, blockID)
+	if err != nil {
+		c.String(http.StatusNotFound, "Form not found")
+		return
+	}
+	formData, _ := blocks.ParseFormBlockData(block.Data)
+
+	var submissions []models.FormSubmission
+	if err := db.GetDB().Where("site_id = ? AND block_id = ?", site.ID, block.ID).
+		Order("created_at DESC").Find(&submissions).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load submissions")
+		return
+	}
+
+	columns := formColumns(formData, submissions)
+	csrfToken := middleware.GetCSRFTokenHTML(c)
+
+	var headerCells strings.Builder
+	headerCells.WriteString("<th>Submitted</th>")
+	for _, col := range columns {
+		headerCells.WriteString("<th>" + html.EscapeString(col.Label) + "</th>")
+	}
+	headerCells.WriteString("<th>Actions</th>")
+
+	var tableRows strings.Builder
+	for _, sub := range submissions {
+		values := sub.GetValues()
+		tableRows.WriteString("<tr><td>" + sub.CreatedAt.Format("2006-01-02 15:04") + "</td>")
+		for _, col := range columns {
+			tableRows.WriteString(`<td style="white-space: pre-wrap;">` + html.EscapeString(values[col.Name]) + "</td>")
+		}
+		fmt.Fprintf(&tableRows, `
+				<td>
+					<form method="POST" action="/admin/forms/submissions/%d/delete" style="display: inline;" onsubmit="return confirm('Delete this submission?');">
+						%s
+						<button type="submit" class="btn btn-small btn-danger">Delete</button>
+					</form>
+				</td>
+			</tr>`, sub.ID, csrfToken)
+	}
+	if len(submissions) == 0 {
+		fmt.Fprintf(&tableRows, `<tr><td colspan="%d" style="text-align: center; color: #666;">No submissions yet.</td></tr>`, len(columns)+2)
+	}
+
+	title := "Untitled form"
+	if formData != nil && formData.Title != "" {
+		title = formData.Title
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>%s - Form Submissions - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 1200px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+		.card { overflow-x: auto; }
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>%s</h1>
+			<div class="header-actions">
+				<a href="/admin/forms/%d/export" class="btn">Export CSV</a>
+				<a href="/admin/forms" class="btn btn-secondary">← All Forms</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		<div class="card">
+			<table class="data-table">
+				<thead>
+					<tr>%s</tr>
+				</thead>
+				<tbody>
+					%s
+				</tbody>
+			</table>
+		</div>
+	</div>
+</body>
+</html>`, html.EscapeString(title), GetDesignSystemCSS(), html.EscapeString(title), block.ID, headerCells.String(), tableRows.String())
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// FormSubmissionsExportHandler downloads a form's submissions as CSV
+func FormSubmissionsExportHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	blockID, err := strconv.Atoi(c.Param("block_id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid form ID")
+		return
+	}
+
+	block, err := loadSiteFormBlock(site, blockID)
+	if err != nil {
+		c.String(http.StatusNotFound, "Form not found")
+		return
+	}
+	formData, _ := blocks.ParseFormBlockData(block.Data)
+
+	var submissions []models.FormSubmission
+	if err := db.GetDB().Where("site_id = ? AND block_id = ?", site.ID, block.ID).
+		Order("created_at ASC").Find(&submissions).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load submissions")
+		return
+	}
+
+	columns := formColumns(formData, submissions)
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-form-%d.csv", site.Subdomain, block.ID))
+
+	w := csv.NewWriter(c.Writer)
+	header := []string{"Submitted"}
+	for _, col := range columns {
+		header = append(header, col.Label)
+	}
+	_ = w.Write(header)
+
+	for _, sub := range submissions {
+		values := sub.GetValues()
+		record := []string{sub.CreatedAt.UTC().Format("2006-01-02 15:04:05")}
+		for _, col := range columns {
+			record = append(record, csvSafe(values[col.Name]))
+		}
+		_ = w.Write(record)
+	}
+	w.Flush()
+}
+
+// csvSafe neutralises values that spreadsheet apps would treat as formulas
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// FormSubmissionDeleteHandler removes a single form submission
+func FormSubmissionDeleteHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	var submission models.FormSubmission
+	if err := db.GetDB().Where("id = ? AND site_id = ?", c.Param("id"), site.ID).First(&submission).Error; err != nil {
+		c.String(http.StatusNotFound, "Submission not found")
+		return
+	}
+
+	if err := db.GetDB().Delete(&submission).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to delete submission")
+		return
+	}
+
+	c.Redirect(http.StatusFound, fmt.Sprintf("/admin/forms/%d", submission.BlockID))
+}
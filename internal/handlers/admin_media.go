@@ -2,18 +2,18 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"html"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/thatcatcamp/stinkykitty/internal/config"
 	"github.com/thatcatcamp/stinkykitty/internal/db"
 	"github.com/thatcatcamp/stinkykitty/internal/media"
 	"github.com/thatcatcamp/stinkykitty/internal/middleware"
 	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/storage"
 )
 
 // MediaLibraryHandler shows the main media library page
@@ -69,6 +69,14 @@ func MediaLibraryHandler(c *gin.Context) {
 	// Get orphaned filter
 	showOrphaned := c.Query("orphaned") == "true"
 
+	// Get collection filter, which includes the collections inside it
+	var collection *models.MediaCollection
+	if collectionStr := c.Query("collection"); collectionStr != "" {
+		if id, err := strconv.ParseUint(collectionStr, 10, 32); err == nil {
+			collection, _ = media.FindCollection(db.GetDB(), site.ID, uint(id))
+		}
+	}
+
 	// Query media items - show ALL media across all sites
 	query := db.GetDB().Model(&models.MediaItem{})
 
@@ -82,6 +90,10 @@ func MediaLibraryHandler(c *gin.Context) {
 			Where("media_tags.tag_name = ?", tagFilter)
 	}
 
+	if collection != nil {
+		query = query.Where("media_items.collection_id IN ?", media.CollectionIDs(db.GetDB(), site.ID, collection.ID))
+	}
+
 	var mediaItems []models.MediaItem
 	var totalCount int64
 
@@ -95,11 +107,11 @@ func MediaLibraryHandler(c *gin.Context) {
 		Find(&mediaItems)
 
 	// Filter orphaned if requested
+	usageCounts := media.UsageCounts(db.GetDB(), site.ID)
 	var displayItems []models.MediaItem
 	if showOrphaned {
 		for _, item := range mediaItems {
-			usages := media.FindImageUsage(db.GetDB(), site.ID, "/assets/"+item.Filename)
-			if len(usages) == 0 {
+			if usageCounts["/assets/"+item.Filename] == 0 {
 				displayItems = append(displayItems, item)
 			}
 		}
@@ -117,7 +129,7 @@ func MediaLibraryHandler(c *gin.Context) {
 	}
 
 	// Render page
-	renderMediaLibraryPage(c, site, user, displayItems, page, totalPages, search, tagFilter, showOrphaned)
+	renderMediaLibraryPage(c, site, user, displayItems, usageCounts, page, totalPages, search, tagFilter, showOrphaned, collection)
 }
 
 // MediaUploadHandler handles file uploads
@@ -151,66 +163,139 @@ func MediaUploadHandler(c *gin.Context) {
 		return
 	}
 
-	var uploadedItems []models.MediaItem
+	store, bucket, err := media.StorageForSite(site)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Media storage unavailable: %v", err)})
+		return
+	}
 
-	// Validate file sizes
-	const MaxFileSize = 5 * 1024 * 1024 // 5MB
-	for _, fileHeader := range files {
-		if fileHeader.Size > MaxFileSize {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s exceeds 5MB limit", fileHeader.Filename)})
-			return
+	// Files already in the library are offered for reuse instead of being
+	// stored again, unless the uploader asks for another copy
+	keepDuplicates := c.PostForm("keep_duplicates") == "true"
+	uploadedItems := []models.MediaItem{}
+	duplicates := []gin.H{}
+
+	// Each file succeeds or fails on its own, so one bad photo doesn't stop
+	// the rest of a batch
+	uploadErrors := []gin.H{}
+	status := http.StatusBadRequest
+	for i, fileHeader := range files {
+		if err := checkUploadSize(fileHeader.Filename, fileHeader.Size); err != nil {
+			uploadErrors = append(uploadErrors, gin.H{"index": i, "name": fileHeader.Filename, "error": err.Error()})
+			continue
+		}
+		upload, err := media.PrepareMedia(fileHeader)
+		if err != nil {
+			uploadErrors = append(uploadErrors, gin.H{"index": i, "name": fileHeader.Filename, "error": err.Error()})
+			continue
+		}
+		if !keepDuplicates {
+			if existing := media.FindDuplicate(db.GetDB(), site.ID, upload.Hash); existing != nil {
+				uploadedItems = append(uploadedItems, *existing)
+				duplicates = append(duplicates, gin.H{"index": i, "name": fileHeader.Filename, "existing": existing})
+				continue
+			}
 		}
-	}
 
-	for _, fileHeader := range files {
-		// Save file to centralized storage
-		filename, err := media.SaveToCentralizedStorage(fileHeader)
+		mediaItem, err := addToLibrary(site, user, store, bucket, fileHeader.Filename, upload)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to upload %s: %v", fileHeader.Filename, err)})
-			return
+			if errors.Is(err, media.ErrQuotaExceeded) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			uploadErrors = append(uploadErrors, gin.H{"index": i, "name": fileHeader.Filename, "error": err.Error()})
+			continue
 		}
+		uploadedItems = append(uploadedItems, *mediaItem)
+	}
 
-		// Get file info
-		fileSize := fileHeader.Size
-		mimeType := fileHeader.Header.Get("Content-Type")
+	// Let the owner know when the site is running out of space
+	media.UpdateQuotaAlerts(db.GetDB(), site)
 
-		// Create database record with UploadedFromSiteID
-		mediaItem := models.MediaItem{
-			SiteID:             site.ID,
-			Filename:           filename,
-			OriginalName:       fileHeader.Filename,
-			FileSize:           fileSize,
-			MimeType:           mimeType,
-			UploadedBy:         user.ID,
-			UploadedFromSiteID: &site.ID, // Track which site uploaded this
-		}
+	if len(uploadedItems) == 0 {
+		c.JSON(status, gin.H{
+			"error":  fmt.Sprintf("Failed to upload %s: %s", uploadErrors[0]["name"], uploadErrors[0]["error"]),
+			"errors": uploadErrors,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"items":      uploadedItems,
+		"duplicates": duplicates,
+		"errors":     uploadErrors,
+	})
+}
 
-		if err := db.GetDB().Create(&mediaItem).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save media item"})
-			return
+// Largest files the media library takes
+const (
+	maxImageUploadSize    = 5 * 1024 * 1024  // 5MB
+	maxDocumentUploadSize = 20 * 1024 * 1024 // 20MB, for PDFs of build plans and the like
+)
+
+// checkUploadSize refuses files over the media library's size limits
+func checkUploadSize(name string, size int64) error {
+	if media.IsDocument(name) {
+		if size > maxDocumentUploadSize {
+			return fmt.Errorf("%s exceeds 20MB limit", name)
 		}
+	} else if size > maxImageUploadSize {
+		return fmt.Errorf("%s exceeds 5MB limit", name)
+	}
+	return nil
+}
 
-		// Generate thumbnail in centralized location
-		mediaDir := config.GetString("storage.media_dir")
-		srcPath := filepath.Join(mediaDir, "uploads", filename)
-		thumbsDir := filepath.Join(mediaDir, "uploads", "thumbs")
-		if err := os.MkdirAll(thumbsDir, 0755); err != nil {
-			fmt.Printf("Warning: Failed to create thumbs directory: %v\n", err)
-		} else {
-			thumbPath := filepath.Join(thumbsDir, filename)
-			if err := media.GenerateThumbnail(srcPath, thumbPath, 200, 200); err != nil {
-				// Log error but don't fail the upload
-				fmt.Printf("Warning: Failed to generate thumbnail for %s: %v\n", filename, err)
-			}
+// addToLibrary stores a prepared upload in a site's storage, makes its
+// smaller copies and thumbnail, and records it in the media library. Files
+// that don't fit in the site's storage quota are turned away.
+func addToLibrary(site *models.Site, user *models.User, store storage.Storage, bucket, originalName string, upload *media.Upload) (*models.MediaItem, error) {
+	// Held until the item is recorded, so parallel uploads can't overfill the quota
+	release, err := media.ReserveQuota(db.GetDB(), site, upload.Size)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Save file to the site's storage
+	saved, err := upload.Store(store)
+	if err != nil {
+		return nil, err
+	}
+	filename := saved.Filename
+
+	// Create database record with UploadedFromSiteID
+	mediaItem := models.MediaItem{
+		SiteID:             site.ID,
+		Filename:           filename,
+		OriginalName:       originalName,
+		FileSize:           saved.Size,
+		MimeType:           saved.ContentType,
+		UploadedBy:         user.ID,
+		UploadedFromSiteID: &site.ID, // Track which site uploaded this
+		Bucket:             bucket,
+		TakenAt:            saved.TakenAt,
+		ContentHash:        saved.Hash,
+	}
+
+	// Measure the image and make smaller copies for responsive pages
+	if !saved.IsDocument {
+		if err := media.GenerateDerivatives(store, &mediaItem); err != nil {
+			fmt.Printf("Warning: Failed to generate image sizes for %s: %v\n", filename, err)
 		}
+	}
 
-		uploadedItems = append(uploadedItems, mediaItem)
+	// Generate thumbnail alongside the file; documents get an icon instead
+	if !saved.IsDocument {
+		if err := media.SaveThumbnail(store, filename); err != nil {
+			// Log error but don't fail the upload
+			fmt.Printf("Warning: Failed to generate thumbnail for %s: %v\n", filename, err)
+		}
+		mediaItem.DerivativesSize = media.MeasureDerivatives(store, &mediaItem)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"items":   uploadedItems,
-	})
+	if err := db.GetDB().Create(&mediaItem).Error; err != nil {
+		return nil, fmt.Errorf("failed to save media item: %w", err)
+	}
+	return &mediaItem, nil
 }
 
 // MediaTagsHandler handles adding/removing tags
@@ -355,40 +440,49 @@ func MediaDeleteHandler(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"in_use":  true,
 			"usages":  usageList,
-			"message": fmt.Sprintf("This image is used in %d place(s)", len(usages)),
+			"message": fmt.Sprintf("This file is used in %d place(s)", len(usages)),
 		})
 		return
 	}
 
-	// Get centralized media directory
-	mediaDir := config.GetString("storage.media_dir")
-	if mediaDir == "" {
-		mediaDir = "/var/lib/stinkykitty/media"
+	if err := removeMediaItem(&mediaItem); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete media item"})
+		return
 	}
 
-	// Delete file from centralized storage
-	filePath := filepath.Join(mediaDir, mediaItem.Filename)
-	if err := os.Remove(filePath); err != nil {
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// removeMediaItem deletes a media item's files and its record
+func removeMediaItem(mediaItem *models.MediaItem) error {
+	// Delete the file and its thumbnail from wherever they're stored
+	if err := media.DeleteStored(mediaItem); err != nil {
 		// Log error but continue (file might already be deleted)
-		fmt.Printf("Warning: Failed to delete file %s: %v\n", filePath, err)
+		fmt.Printf("Warning: Failed to delete file %s: %v\n", mediaItem.Filename, err)
 	}
 
-	// Delete thumbnail from centralized storage
-	thumbPath := filepath.Join(mediaDir, "thumbs", mediaItem.Filename)
-	os.Remove(thumbPath) // Ignore error
-
 	// Delete database record (and tags via cascade)
-	if err := db.GetDB().Delete(&mediaItem).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete media item"})
-		return
-	}
+	return db.GetDB().Delete(mediaItem).Error
+}
 
-	c.JSON(http.StatusOK, gin.H{"success": true})
+// uploadAccept lists the file types the media library accepts, for the file
+// picker's accept attribute
+func uploadAccept() string {
+	if docs := documentAccept(); docs != "" {
+		return "image/*," + docs
+	}
+	return "image/*"
 }
 
 // renderMediaLibraryPage renders the HTML for the media library
-func renderMediaLibraryPage(c *gin.Context, site *models.Site, user *models.User, items []models.MediaItem, page, totalPages int, search, tagFilter string, showOrphaned bool) {
+func renderMediaLibraryPage(c *gin.Context, site *models.Site, user *models.User, items []models.MediaItem, usageCounts map[string]int, page, totalPages int, search, tagFilter string, showOrphaned bool, collection *models.MediaCollection) {
 	csrfToken := middleware.GetCSRFTokenHTML(c)
+	zipLimits := media.ZipLimitsFor(maxImageUploadSize)
+	collections := media.CollectionTree(db.GetDB(), site.ID)
+	collectionPaths := map[uint]string{}
+	for _, node := range collections {
+		collectionPaths[node.ID] = node.Path
+	}
 
 	// Build filter badges
 	var filterBadges string
@@ -401,11 +495,27 @@ func renderMediaLibraryPage(c *gin.Context, site *models.Site, user *models.User
 	if showOrphaned {
 		filterBadges += `<span class="filter-badge">Orphaned only <a href="/admin/media">×</a></span>`
 	}
+	if collection != nil {
+		filterBadges += fmt.Sprintf(`<span class="filter-badge">Collection: %s <a href="/admin/media">×</a></span>`, html.EscapeString(collectionPaths[collection.ID]))
+	}
 
 	// Build image grid
 	var imageGrid string
 	for _, item := range items {
-		thumbURL := fmt.Sprintf("/assets/thumbs/%s", item.Filename)
+		thumbHTML := fmt.Sprintf(`<img src="/assets/thumbs/%s" alt="%s" loading="lazy">`, item.Filename, item.OriginalName)
+		details := ""
+		if media.IsDocument(item.Filename) {
+			thumbHTML = fmt.Sprintf(`<a href="/assets/%s" target="_blank" rel="noopener">%s</a>`, item.Filename, media.DocumentIcon(item.Filename))
+			details = fmt.Sprintf(`<div class="media-date">%s · %s</div>`, media.DocumentLabel(item.Filename), media.FormatSize(item.FileSize))
+		}
+		usage := "Not used on this site"
+		if n := usageCounts["/assets/"+item.Filename]; n > 0 {
+			usage = fmt.Sprintf("Used in %d block(s)", n)
+		}
+		details += fmt.Sprintf(`<div class="media-date">%s</div>`, usage)
+		if item.CollectionID != nil && collectionPaths[*item.CollectionID] != "" {
+			details += fmt.Sprintf(`<div class="media-date">In: %s</div>`, html.EscapeString(collectionPaths[*item.CollectionID]))
+		}
 
 		// Build tag badges
 		var tagBadges string
@@ -429,21 +539,23 @@ func renderMediaLibraryPage(c *gin.Context, site *models.Site, user *models.User
 		imageGrid += fmt.Sprintf(`
 		<div class="media-card" data-id="%d">
 			<div class="media-thumbnail">
-				<img src="%s" alt="%s" loading="lazy">
+				%s
 			</div>
 			<div class="media-info">
+				<label class="media-select"><input type="checkbox" class="select-item" value="%d"> Select</label>
 				<div class="media-filename" title="%s">%s</div>
 				<div class="media-tags">%s</div>
 				<div class="media-uploader">Uploaded by: %s</div>
 				<div class="media-date">%s</div>
+				%s
 				<div class="media-actions">
 					<button class="btn-small" onclick="editTags(%d)">Edit Tags</button>
 					%s
 				</div>
 			</div>
 		</div>
-		`, item.ID, thumbURL, item.OriginalName, item.OriginalName, item.OriginalName,
-			tagBadges, uploaderEmail, item.CreatedAt.Format("Jan 2, 2006"), item.ID, deleteButton)
+		`, item.ID, thumbHTML, item.ID, item.OriginalName, item.OriginalName,
+			tagBadges, uploaderEmail, item.CreatedAt.Format("Jan 2, 2006"), details, item.ID, deleteButton)
 	}
 
 	if len(items) == 0 {
@@ -492,6 +604,92 @@ func renderMediaLibraryPage(c *gin.Context, site *models.Site, user *models.User
 			background: var(--color-bg-secondary);
 		}
 
+		.upload-list {
+			list-style: none;
+			padding: 0;
+			margin: 0 0 var(--spacing-lg);
+		}
+
+		.upload-list li {
+			display: flex;
+			align-items: center;
+			gap: var(--spacing-base);
+			padding: 4px 0;
+			font-size: 14px;
+		}
+
+		.upload-list .upload-name {
+			flex: 1;
+			min-width: 0;
+			white-space: nowrap;
+			overflow: hidden;
+			text-overflow: ellipsis;
+		}
+
+		.upload-list progress {
+			width: 120px;
+		}
+
+		.upload-list li.failed .upload-status {
+			color: var(--color-danger);
+		}
+
+		.zip-import {
+			margin-bottom: var(--spacing-lg);
+		}
+
+		.zip-import form {
+			display: flex;
+			gap: var(--spacing-base);
+			flex-wrap: wrap;
+			align-items: center;
+			margin: var(--spacing-base) 0 var(--spacing-sm);
+		}
+
+		.zip-import .help-text {
+			font-size: 13px;
+			color: var(--color-text-secondary);
+		}
+
+		.collections-bar {
+			display: flex;
+			gap: var(--spacing-sm);
+			align-items: center;
+			flex-wrap: wrap;
+			margin-bottom: var(--spacing-base);
+		}
+
+		.bulk-bar {
+			display: none;
+			gap: var(--spacing-sm);
+			align-items: center;
+			flex-wrap: wrap;
+			position: sticky;
+			top: 0;
+			z-index: 10;
+			background: var(--color-bg-card);
+			padding: var(--spacing-sm) var(--spacing-base);
+			border-radius: var(--radius-base);
+			box-shadow: var(--shadow-sm);
+			margin-bottom: var(--spacing-base);
+		}
+
+		.bulk-bar.active {
+			display: flex;
+		}
+
+		.media-select {
+			display: block;
+			font-size: 12px;
+			color: var(--color-text-secondary);
+			margin-bottom: var(--spacing-sm);
+			cursor: pointer;
+		}
+
+		.media-card.selected {
+			outline: 2px solid var(--color-accent);
+		}
+
 		.filter-bar {
 			display: flex;
 			gap: var(--spacing-base);
@@ -626,11 +824,25 @@ func renderMediaLibraryPage(c *gin.Context, site *models.Site, user *models.User
 		<form id="upload-form" method="POST" action="/admin/media/upload" enctype="multipart/form-data">
 			%s
 			<div class="upload-zone" id="upload-zone">
-				<p>📤 Drag & drop images here</p>
+				<p>📤 Drag & drop images or documents here</p>
 				<p>or <label for="file-input" style="color: var(--color-accent); cursor: pointer;">click to browse</label></p>
-				<input type="file" id="file-input" name="images" multiple accept="image/*" style="display: none;">
+				<input type="file" id="file-input" name="images" multiple accept="%s" style="display: none;">
 			</div>
 		</form>
+		<ul class="upload-list" id="upload-list"></ul>
+
+		<details class="zip-import">
+			<summary>Import a ZIP of photos</summary>
+			<form id="zip-form">
+				<input type="file" id="zip-input" name="zip" accept=".zip">
+				<input type="text" id="zip-tag" name="tag" placeholder="Tag for these photos (optional)">
+				<button type="submit" class="btn">Import</button>
+			</form>
+			<p class="help-text">Up to %d images and %s per archive. Other files in it are skipped.</p>
+			<div id="zip-result"></div>
+		</details>
+
+		%s
 
 		<div class="filter-bar">
 			<input type="text" id="search-input" placeholder="Search images..." value="%s">
@@ -639,6 +851,8 @@ func renderMediaLibraryPage(c *gin.Context, site *models.Site, user *models.User
 
 		<div>%s</div>
 
+		%s
+
 		<div class="media-grid">
 			%s
 		</div>
@@ -681,39 +895,182 @@ func renderMediaLibraryPage(c *gin.Context, site *models.Site, user *models.User
 			}
 		});
 
-		// Upload files via AJAX
+		// The CSRF token for upload requests
+		function csrfTokenValue() {
+			return decodeURIComponent(
+				document.cookie
+					.split('; ')
+					.find(row => row.startsWith('csrf_token='))
+					?.substring('csrf_token='.length) || ''
+			);
+		}
+
+		// Upload files a few at a time, each with its own progress and result
 		async function uploadFiles() {
-			const formData = new FormData(uploadForm);
-
-			try {
-				const csrfToken = decodeURIComponent(
-					document.cookie
-						.split('; ')
-						.find(row => row.startsWith('csrf_token='))
-						?.substring('csrf_token='.length) || ''
-				);
-
-				const response = await fetch('/admin/media/upload', {
-					method: 'POST',
-					headers: {
-						'X-CSRF-Token': csrfToken
-					},
-					body: formData
-				});
+			const files = Array.from(fileInput.files);
+			const list = document.getElementById('upload-list');
+			list.innerHTML = '';
+			const rows = files.map(file => {
+				const row = document.createElement('li');
+				row.innerHTML = '<span class="upload-name"></span><progress max="100" value="0"></progress><span class="upload-status">Waiting</span>';
+				row.querySelector('.upload-name').textContent = file.name;
+				list.appendChild(row);
+				return row;
+			});
 
-				const result = await response.json();
+			const duplicates = [];
+			let failed = 0;
+			let next = 0;
+			async function worker() {
+				while (next < files.length) {
+					const i = next++;
+					const result = await uploadOne(files[i], rows[i], false);
+					if (!result.success) {
+						failed++;
+					} else if (result.duplicates.length > 0) {
+						duplicates.push({ file: files[i], row: rows[i], existing: result.duplicates[0].existing });
+					}
+				}
+			}
+			await Promise.all([worker(), worker(), worker()]);
 
-				if (result.success) {
-					// Reload page to show new images
-					window.location.reload();
-				} else {
-					alert('Upload failed: ' + (result.error || 'Unknown error'));
+			if (duplicates.length > 0) {
+				await offerDuplicates(duplicates);
+			}
+			if (failed === 0) {
+				// Reload page to show new images
+				window.location.reload();
+				return;
+			}
+			// Keep the errors on screen
+			const summary = document.createElement('li');
+			summary.innerHTML = '<span></span> <a href="/admin/media">Refresh the library</a>';
+			summary.querySelector('span').textContent = failed + ' of ' + files.length + ' file(s) failed.';
+			list.appendChild(summary);
+		}
+
+		// Send one file, showing its progress and result in its row
+		function uploadOne(file, row, keepDuplicate) {
+			return new Promise(resolve => {
+				const bar = row.querySelector('progress');
+				const status = row.querySelector('.upload-status');
+				const formData = new FormData();
+				formData.append('images', file);
+				if (keepDuplicate) {
+					formData.append('keep_duplicates', 'true');
+				}
+
+				const xhr = new XMLHttpRequest();
+				xhr.upload.addEventListener('progress', (e) => {
+					if (e.lengthComputable) {
+						bar.value = Math.round(e.loaded * 100 / e.total);
+					}
+				});
+				xhr.addEventListener('load', () => {
+					let result;
+					try {
+						result = JSON.parse(xhr.responseText);
+					} catch (e) {
+						result = { error: 'Unexpected response (' + xhr.status + ')' };
+					}
+					bar.value = 100;
+					if (result.success) {
+						status.textContent = result.duplicates.length > 0 ? 'Already in the library' : 'Done';
+					} else {
+						status.textContent = result.errors && result.errors.length > 0 ? result.errors[0].error : (result.error || 'Unknown error');
+						row.classList.add('failed');
+					}
+					resolve(result);
+				});
+				xhr.addEventListener('error', () => {
+					status.textContent = 'Network error';
+					row.classList.add('failed');
+					resolve({ error: 'Network error' });
+				});
+				status.textContent = 'Uploading';
+				xhr.open('POST', '/admin/media/upload');
+				xhr.setRequestHeader('X-CSRF-Token', csrfTokenValue());
+				xhr.send(formData);
+			});
+		}
+
+		// Files already in the library weren't stored again; upload them
+		// anyway only if asked
+		async function offerDuplicates(duplicates) {
+			const names = duplicates.map(d => d.file.name + ' (same as ' + d.existing.OriginalName + ')').join('\n• ');
+			if (confirm('Already in the library, so the existing copies were kept:\n\n• ' + names + '\n\nUpload another copy anyway?')) {
+				for (const d of duplicates) {
+					await uploadOne(d.file, d.row, true);
 				}
-			} catch (error) {
-				alert('Upload failed: ' + error.message);
 			}
 		}
 
+		// Import a zip archive of photos
+		document.getElementById('zip-form').addEventListener('submit', (e) => {
+			e.preventDefault();
+			const zipInput = document.getElementById('zip-input');
+			if (zipInput.files.length === 0) {
+				return;
+			}
+			const formData = new FormData();
+			formData.append('zip', zipInput.files[0]);
+			formData.append('tag', document.getElementById('zip-tag').value);
+
+			const out = document.getElementById('zip-result');
+			out.innerHTML = '<progress max="100" value="0"></progress> <span>Uploading</span>';
+			const bar = out.querySelector('progress');
+			const status = out.querySelector('span');
+
+			const xhr = new XMLHttpRequest();
+			xhr.upload.addEventListener('progress', (e) => {
+				if (e.lengthComputable) {
+					bar.value = Math.round(e.loaded * 100 / e.total);
+					if (e.loaded === e.total) {
+						status.textContent = 'Importing…';
+					}
+				}
+			});
+			xhr.addEventListener('load', () => {
+				let result;
+				try {
+					result = JSON.parse(xhr.responseText);
+				} catch (e) {
+					result = { error: 'Unexpected response (' + xhr.status + ')' };
+				}
+				bar.value = 100;
+				const lines = [];
+				if (result.error) {
+					lines.push(result.error);
+				}
+				let summary = 'Imported ' + (result.items || []).length + ' image(s)';
+				if (result.duplicates) {
+					summary += ', ' + result.duplicates + ' already in the library';
+				}
+				lines.push(summary + '.');
+				(result.errors || []).forEach(f => lines.push(f.name + ': ' + f.error));
+				(result.skipped || []).forEach(f => lines.push('Skipped ' + f.name + ': ' + f.reason));
+
+				status.textContent = '';
+				const list = document.createElement('ul');
+				lines.forEach(line => {
+					const item = document.createElement('li');
+					item.textContent = line;
+					list.appendChild(item);
+				});
+				out.appendChild(list);
+				const refresh = document.createElement('a');
+				refresh.href = '/admin/media';
+				refresh.textContent = 'Refresh the library';
+				out.appendChild(refresh);
+			});
+			xhr.addEventListener('error', () => {
+				status.textContent = 'Network error';
+			});
+			xhr.open('POST', '/admin/media/import');
+			xhr.setRequestHeader('X-CSRF-Token', csrfTokenValue());
+			xhr.send(formData);
+		});
+
 		// Search
 		const searchInput = document.getElementById('search-input');
 		let searchTimeout;
@@ -730,6 +1087,139 @@ func renderMediaLibraryPage(c *gin.Context, site *models.Site, user *models.User
 			window.location.href = '/admin/media?orphaned=' + (current === 'true' ? 'false' : 'true');
 		}
 
+		// Collections
+		function goToCollection(id) {
+			window.location.href = '/admin/media' + (id ? '?collection=' + id : '');
+		}
+
+		function postForm(url, params) {
+			return fetch(url, {
+				method: 'POST',
+				headers: {
+					'Content-Type': 'application/x-www-form-urlencoded',
+					'X-CSRF-Token': csrfTokenValue()
+				},
+				body: new URLSearchParams(params)
+			}).then(r => r.json());
+		}
+
+		async function newCollection(parentId) {
+			const name = prompt(parentId ? 'Name of the new collection inside this one:' : 'Name of the new collection:');
+			if (!name) return;
+			const data = await postForm('/admin/media/collections', {name: name, parent_id: parentId || ''});
+			if (data.success) {
+				goToCollection(data.collection.ID);
+			} else {
+				alert('Failed to create collection: ' + (data.error || 'Unknown error'));
+			}
+		}
+
+		async function renameCollection(id, current) {
+			const name = prompt('Rename collection:', current);
+			if (!name || name === current) return;
+			const data = await postForm('/admin/media/collections/' + id + '/rename', {name: name});
+			if (data.success) {
+				location.reload();
+			} else {
+				alert('Failed to rename collection: ' + (data.error || 'Unknown error'));
+			}
+		}
+
+		async function deleteCollection(id, parentId) {
+			if (!confirm('Delete this collection? Its media and sub-collections move up a level; no files are deleted.')) return;
+			const data = await postForm('/admin/media/collections/' + id + '/delete', {});
+			if (data.success) {
+				goToCollection(parentId);
+			} else {
+				alert('Failed to delete collection: ' + (data.error || 'Unknown error'));
+			}
+		}
+
+		// Bulk actions on selected media
+		const bulkBar = document.getElementById('bulk-bar');
+
+		function selectedIds() {
+			return Array.from(document.querySelectorAll('.select-item:checked')).map(cb => cb.value);
+		}
+
+		function updateBulkBar() {
+			document.querySelectorAll('.select-item').forEach(cb => {
+				cb.closest('.media-card').classList.toggle('selected', cb.checked);
+			});
+			const count = selectedIds().length;
+			bulkBar.classList.toggle('active', count > 0);
+			document.getElementById('bulk-count').textContent = count + ' selected';
+		}
+
+		function selectAllMedia(checked) {
+			document.querySelectorAll('.select-item').forEach(cb => { cb.checked = checked; });
+			updateBulkBar();
+		}
+
+		document.querySelectorAll('.select-item').forEach(cb => cb.addEventListener('change', updateBulkBar));
+
+		function bulkAction(params) {
+			if (!params.ids) params.ids = selectedIds().join(',');
+			return postForm('/admin/media/bulk', params);
+		}
+
+		function skippedNote(data) {
+			if (!data.skipped || data.skipped.length === 0) return '';
+			return '\n\nSkipped:\n• ' + data.skipped.map(s => s.name + ' (' + s.reason + ')').join('\n• ');
+		}
+
+		async function bulkTag(action) {
+			const tag = prompt(action === 'tag' ? 'Tag to add to the selected media:' : 'Tag to remove from the selected media:');
+			if (!tag) return;
+			const data = await bulkAction({action: action, tag: tag});
+			if (!data.success) {
+				alert('Failed: ' + (data.error || 'Unknown error'));
+				return;
+			}
+			const note = skippedNote(data);
+			if (note) alert('Updated ' + data.updated + ' item(s).' + note);
+			location.reload();
+		}
+
+		async function bulkMove(select) {
+			const target = select.value;
+			select.value = '';
+			if (!target) return;
+			const data = await bulkAction({action: 'move', collection_id: target === 'none' ? '' : target});
+			if (!data.success) {
+				alert('Failed to move: ' + (data.error || 'Unknown error'));
+				return;
+			}
+			const note = skippedNote(data);
+			if (note) alert('Moved ' + data.updated + ' item(s).' + note);
+			location.reload();
+		}
+
+		async function bulkDelete() {
+			const ids = selectedIds();
+			if (!confirm('Delete ' + ids.length + ' item(s)? This cannot be undone.')) return;
+			const data = await bulkAction({action: 'delete'});
+			if (!data.success) {
+				alert('Failed to delete: ' + (data.error || 'Unknown error'));
+				return;
+			}
+			let message = 'Deleted ' + data.updated + ' item(s).';
+			if (data.in_use.length > 0) {
+				const list = data.in_use.map(u => u.name + ': ' + u.usages.join(', ')).join('\n• ');
+				if (confirm('⚠️ ' + data.in_use.length + ' item(s) are in use and were kept:\n\n• ' + list + '\n\nDelete them anyway? Those blocks will show broken images or links.')) {
+					const forced = await bulkAction({action: 'delete', force: 'true', ids: data.in_use.map(u => u.id).join(',')});
+					if (forced.success) {
+						message = 'Deleted ' + (data.updated + forced.updated) + ' item(s).';
+					} else {
+						alert('Failed to delete: ' + (forced.error || 'Unknown error'));
+					}
+				}
+			}
+			const note = skippedNote(data);
+			if (note) alert(message + note);
+			location.reload();
+		}
+
 		// Edit tags
 		function editTags(id) {
 			const tagName = prompt('Enter tag name:');
@@ -812,9 +1302,10 @@ func renderMediaLibraryPage(c *gin.Context, site *models.Site, user *models.User
 		}
 	</script>
 </body>
-</html>`, site.SiteTitle, GetDesignSystemCSS(), csrfToken, search,
+</html>`, site.SiteTitle, GetDesignSystemCSS(), csrfToken, uploadAccept(), zipLimits.MaxFiles, media.FormatSize(zipLimits.MaxTotalSize),
+		collectionsBarHTML(collections, collection), search,
 		(map[bool]string{true: "Show All", false: "Show Orphaned"})[showOrphaned],
-		filterBadges, imageGrid, pagination)
+		filterBadges, bulkBarHTML(collections), imageGrid, pagination)
 
 	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
 }
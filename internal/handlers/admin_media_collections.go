@@ -0,0 +1,297 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// optionalID parses an ID form value, returning nil when it's empty
+func optionalID(value string) (*uint, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID %q", value)
+	}
+	n := uint(id)
+	return &n, nil
+}
+
+// collectionError responds to a failed collection change
+func collectionError(c *gin.Context, err error) {
+	if errors.Is(err, media.ErrCollectionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// MediaCollectionCreateHandler adds a collection, inside parent_id if given
+func MediaCollectionCreateHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Site not found"})
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	parentID, err := optionalID(c.PostForm("parent_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	col, err := media.CreateCollection(db.GetDB(), site.ID, parentID, c.PostForm("name"))
+	if err != nil {
+		collectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "collection": col})
+}
+
+// MediaCollectionRenameHandler renames a collection
+func MediaCollectionRenameHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Site not found"})
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return
+	}
+	if err := media.RenameCollection(db.GetDB(), site.ID, uint(id), c.PostForm("name")); err != nil {
+		collectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// MediaCollectionDeleteHandler removes a collection, moving what's in it up
+// a level
+func MediaCollectionDeleteHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Site not found"})
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return
+	}
+	if err := media.DeleteCollection(db.GetDB(), site.ID, uint(id)); err != nil {
+		collectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// MediaBulkHandler applies an action to several media items at once:
+//
+//	tag     add the tag in "tag"
+//	untag   remove the tag in "tag"
+//	move    file them in "collection_id" (empty for no collection)
+//	delete  delete them; items in use are listed instead unless force=true
+//
+// Items are given as comma-separated IDs in "ids". Only the site's own media
+// is changed, and only its uploader or a global admin can delete an item.
+func MediaBulkHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Site not found"})
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	// Get user from context
+	userVal, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	user := userVal.(*models.User)
+
+	var ids []uint
+	for _, s := range strings.Split(c.PostForm("ids"), ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid media ID"})
+			return
+		}
+		ids = append(ids, uint(id))
+	}
+	if len(ids) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No media selected"})
+		return
+	}
+
+	var items []models.MediaItem
+	db.GetDB().Where("id IN ?", ids).Find(&items)
+
+	// Tags and collections belong to the site, and usage is only checked on
+	// its pages, so every action only applies to its media
+	var siteItems []uint
+	otherSites := []gin.H{}
+	for _, item := range items {
+		if item.SiteID == site.ID {
+			siteItems = append(siteItems, item.ID)
+		} else {
+			otherSites = append(otherSites, gin.H{"id": item.ID, "name": item.OriginalName, "reason": "uploaded to another site"})
+		}
+	}
+
+	switch action := c.PostForm("action"); action {
+	case "tag", "untag":
+		tagName := strings.TrimSpace(c.PostForm("tag"))
+		if tagName == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tag name required"})
+			return
+		}
+		if action == "tag" {
+			for _, id := range siteItems {
+				if err := addMediaTag(id, tagName); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add tag"})
+					return
+				}
+			}
+		} else if err := db.GetDB().Where("media_item_id IN ? AND tag_name = ?", siteItems, tagName).Delete(&models.MediaTag{}).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove tag"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"success": true, "updated": len(siteItems), "skipped": otherSites})
+
+	case "move":
+		collectionID, err := optionalID(c.PostForm("collection_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		moved, err := media.MoveItems(db.GetDB(), site.ID, siteItems, collectionID)
+		if err != nil {
+			collectionError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"success": true, "updated": moved, "skipped": otherSites})
+
+	case "delete":
+		// Nothing in use is deleted without asking, as with single items
+		forceDelete := c.PostForm("force") == "true"
+		inUse := []gin.H{}
+		skipped := otherSites
+		deleted := 0
+		for i := range items {
+			item := &items[i]
+			if item.SiteID != site.ID {
+				continue
+			}
+			if item.UploadedBy != user.ID && !user.IsGlobalAdmin {
+				skipped = append(skipped, gin.H{"id": item.ID, "name": item.OriginalName, "reason": "uploaded by someone else"})
+				continue
+			}
+			if !forceDelete {
+				if usages := media.FindImageUsage(db.GetDB(), site.ID, "/assets/"+item.Filename); len(usages) > 0 {
+					var usageList []string
+					for _, usage := range usages {
+						usageList = append(usageList, fmt.Sprintf("%s → %s", usage.PageTitle, usage.BlockType))
+					}
+					inUse = append(inUse, gin.H{"id": item.ID, "name": item.OriginalName, "usages": usageList})
+					continue
+				}
+			}
+			if err := removeMediaItem(item); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete %s", item.OriginalName)})
+				return
+			}
+			deleted++
+		}
+		c.JSON(http.StatusOK, gin.H{"success": true, "updated": deleted, "in_use": inUse, "skipped": skipped})
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action"})
+	}
+}
+
+// collectionOptions renders <option>s for a site's collections, indented to
+// show nesting, with the one whose ID is selected chosen
+func collectionOptions(nodes []media.CollectionNode, selected uint) string {
+	var b strings.Builder
+	for _, node := range nodes {
+		sel := ""
+		if node.ID == selected {
+			sel = " selected"
+		}
+		fmt.Fprintf(&b, `<option value="%d"%s>%s%s</option>`, node.ID, sel, strings.Repeat("— ", node.Depth), html.EscapeString(node.Name))
+	}
+	return b.String()
+}
+
+// collectionsBarHTML renders the media library's collection chooser, with
+// buttons to add, rename and delete collections
+func collectionsBarHTML(nodes []media.CollectionNode, current *models.MediaCollection) string {
+	selected := uint(0)
+	parentArg := "null"
+	actions := ""
+	if current != nil {
+		selected = current.ID
+		parentArg = strconv.FormatUint(uint64(current.ID), 10)
+		upArg := "null"
+		if current.ParentID != nil {
+			upArg = strconv.FormatUint(uint64(*current.ParentID), 10)
+		}
+		actions = fmt.Sprintf(`
+				<button class="btn-small" data-name="%s" onclick="renameCollection(%d, this.dataset.name)">Rename</button>
+				<button class="btn-small btn-danger" onclick="deleteCollection(%d, %s)">Delete Collection</button>`,
+			html.EscapeString(current.Name), current.ID, current.ID, upArg)
+	}
+	newLabel := "+ New Collection"
+	if current != nil {
+		newLabel = "+ New Collection Inside"
+	}
+	return fmt.Sprintf(`<div class="collections-bar">
+				<label for="collection-select">Collection:</label>
+				<select id="collection-select" onchange="goToCollection(this.value)">
+					<option value="">All media</option>
+					%s
+				</select>
+				<button class="btn-small" onclick="newCollection(%s)">%s</button>%s
+			</div>`, collectionOptions(nodes, selected), parentArg, newLabel, actions)
+}
+
+// bulkBarHTML renders the actions for media selected in the library
+func bulkBarHTML(nodes []media.CollectionNode) string {
+	return fmt.Sprintf(`<div class="bulk-bar" id="bulk-bar">
+				<strong id="bulk-count">0 selected</strong>
+				<button class="btn-small" onclick="bulkTag('tag')">Tag…</button>
+				<button class="btn-small" onclick="bulkTag('untag')">Untag…</button>
+				<select id="bulk-move" onchange="bulkMove(this)">
+					<option value="">Move to…</option>
+					<option value="none">No collection</option>
+					%s
+				</select>
+				<button class="btn-small btn-danger" onclick="bulkDelete()">Delete</button>
+				<button class="btn-small btn-secondary" onclick="selectAllMedia(false)">Clear</button>
+			</div>`, collectionOptions(nodes, 0))
+}
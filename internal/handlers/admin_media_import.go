@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// MediaImportZipHandler adds the images in an uploaded zip archive to the
+// media library, for the hundreds of photos people have after an event. An
+// optional tag is put on every imported image. Images already in the library
+// are tagged rather than stored again.
+func MediaImportZipHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Site not found"})
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	// Get user from context
+	userVal, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	user := userVal.(*models.User)
+
+	fileHeader, err := c.FormFile("zip")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No zip file provided"})
+		return
+	}
+	if strings.ToLower(filepath.Ext(fileHeader.Filename)) != ".zip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s is not a zip file", fileHeader.Filename)})
+		return
+	}
+	limits := media.ZipLimitsFor(maxImageUploadSize)
+	if fileHeader.Size > limits.MaxTotalSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("%s exceeds the %s import limit", fileHeader.Filename, media.FormatSize(limits.MaxTotalSize))})
+		return
+	}
+	tag := strings.TrimSpace(c.PostForm("tag"))
+
+	store, bucket, err := media.StorageForSite(site)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Media storage unavailable: %v", err)})
+		return
+	}
+
+	archive, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read zip file"})
+		return
+	}
+	defer archive.Close()
+
+	imported := []models.MediaItem{}
+	duplicates := 0
+	importErrors := []gin.H{}
+	skipped, err := media.ReadZipImages(archive, fileHeader.Size, limits, func(name string, data []byte) error {
+		upload, err := media.PrepareImageData(name, data)
+		if err != nil {
+			importErrors = append(importErrors, gin.H{"name": name, "error": err.Error()})
+			return nil
+		}
+
+		item := media.FindDuplicate(db.GetDB(), site.ID, upload.Hash)
+		if item != nil {
+			duplicates++
+		} else {
+			item, err = addToLibrary(site, user, store, bucket, name, upload)
+			if errors.Is(err, media.ErrQuotaExceeded) {
+				// Nothing after this fits either
+				return err
+			}
+			if err != nil {
+				importErrors = append(importErrors, gin.H{"name": name, "error": err.Error()})
+				return nil
+			}
+			imported = append(imported, *item)
+		}
+
+		if tag != "" {
+			if err := addMediaTag(item.ID, tag); err != nil {
+				fmt.Printf("Warning: Failed to tag %s: %v\n", item.Filename, err)
+			}
+		}
+		return nil
+	})
+	if len(imported) > 0 {
+		media.UpdateQuotaAlerts(db.GetDB(), site)
+	}
+	if skipped == nil {
+		skipped = []media.ZipSkip{}
+	}
+
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, media.ErrQuotaExceeded) || errors.Is(err, media.ErrZipTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		msg := err.Error()
+		if len(imported) > 0 {
+			msg = fmt.Sprintf("Imported %d image(s), then stopped: %v", len(imported), err)
+		}
+		c.JSON(status, gin.H{
+			"error":      msg,
+			"items":      imported,
+			"duplicates": duplicates,
+			"skipped":    skipped,
+			"errors":     importErrors,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"items":      imported,
+		"duplicates": duplicates,
+		"skipped":    skipped,
+		"errors":     importErrors,
+	})
+}
+
+// addMediaTag tags a media item unless it already has the tag
+func addMediaTag(mediaItemID uint, tagName string) error {
+	var count int64
+	db.GetDB().Model(&models.MediaTag{}).Where("media_item_id = ? AND tag_name = ?", mediaItemID, tagName).Count(&count)
+	if count > 0 {
+		return nil
+	}
+	return db.GetDB().Create(&models.MediaTag{MediaItemID: mediaItemID, TagName: tagName}).Error
+}
@@ -4,23 +4,53 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
 	"github.com/thatcatcamp/stinkykitty/internal/models"
 )
 
 // MediaPickerHandler shows modal picker for block editors
 func MediaPickerHandler(c *gin.Context) {
-	// Get all media items
+	// Get all media items, or those in the chosen collection. Collections
+	// belong to the site, so they're only offered with one.
+	query := db.GetDB().Preload("Tags").Order("created_at DESC")
+	var collections []media.CollectionNode
+	var collection *models.MediaCollection
+	if siteVal, exists := c.Get("site"); exists {
+		site := siteVal.(*models.Site)
+		collections = media.CollectionTree(db.GetDB(), site.ID)
+		if id, err := strconv.ParseUint(c.Query("collection"), 10, 32); err == nil {
+			collection, _ = media.FindCollection(db.GetDB(), site.ID, uint(id))
+		}
+		if collection != nil {
+			query = query.Where("collection_id IN ?", media.CollectionIDs(db.GetDB(), site.ID, collection.ID))
+		}
+	}
 	var mediaItems []models.MediaItem
-	db.GetDB().Preload("Tags").
-		Order("created_at DESC").
-		Find(&mediaItems)
+	query.Find(&mediaItems)
+
+	collectionFilter := ""
+	if len(collections) > 0 {
+		selected := uint(0)
+		if collection != nil {
+			selected = collection.ID
+		}
+		collectionFilter = fmt.Sprintf(`<select id="collection-select" onchange="window.location.search = this.value ? '?collection=' + this.value : ''">
+				<option value="">All media</option>
+				%s
+			</select>`, collectionOptions(collections, selected))
+	}
 
 	// Build image grid
 	var imageGrid string
 	for _, item := range mediaItems {
+		// Documents are chosen in file download blocks
+		if media.IsDocument(item.Filename) {
+			continue
+		}
 		thumbURL := fmt.Sprintf("/assets/thumbs/%s", item.Filename)
 		imageURL := fmt.Sprintf("/assets/%s", item.Filename)
 
@@ -34,6 +64,9 @@ func MediaPickerHandler(c *gin.Context) {
 
 	if len(mediaItems) == 0 {
 		imageGrid = `<div class="empty-state">No images in library. Upload images from the Media Library page.</div>`
+		if collection != nil {
+			imageGrid = `<div class="empty-state">No images in this collection.</div>`
+		}
 	}
 
 	html := fmt.Sprintf(`<!DOCTYPE html>
@@ -102,6 +135,7 @@ func MediaPickerHandler(c *gin.Context) {
 	<div class="picker-header">
 		<h2>Select Image</h2>
 		<div style="display: flex; gap: 10px;">
+			%s
 			<button onclick="document.getElementById('upload-input').click()" class="btn btn-primary">Upload New</button>
 			<button onclick="window.close()" class="btn btn-secondary">Cancel</button>
 		</div>
@@ -179,7 +213,7 @@ func MediaPickerHandler(c *gin.Context) {
 		});
 	</script>
 </body>
-</html>`, GetDesignSystemCSS(), imageGrid)
+</html>`, GetDesignSystemCSS(), collectionFilter, imageGrid)
 
 	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
 }
@@ -2,20 +2,28 @@
 package handlers
 
 import (
+	"archive/zip"
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/thatcatcamp/stinkykitty/internal/auth"
 	"github.com/thatcatcamp/stinkykitty/internal/config"
 	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
 	"github.com/thatcatcamp/stinkykitty/internal/models"
 	"github.com/thatcatcamp/stinkykitty/internal/search"
 	"gorm.io/driver/sqlite"
@@ -28,7 +36,7 @@ func setupMediaTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("Failed to connect to test database: %v", err)
 	}
 
-	err = database.AutoMigrate(&models.User{}, &models.Site{}, &models.SiteUser{}, &models.MediaItem{}, &models.MediaTag{}, &models.Page{}, &models.Block{})
+	err = database.AutoMigrate(&models.User{}, &models.Site{}, &models.SiteUser{}, &models.MediaItem{}, &models.MediaTag{}, &models.MediaReference{}, &models.Page{}, &models.Block{}, &models.OutboundEmail{}, &models.MediaCollection{})
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -159,6 +167,208 @@ func TestMediaUploadHandler_CentralizedStorage(t *testing.T) {
 		t.Errorf("File should NOT exist at site-specific location %s, but it does", siteSpecificPath)
 	}
 }
+
+func TestMediaUploadHandler_OverQuota(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := setupMediaTestDB(t)
+	db.SetDB(database)
+
+	if err := config.InitConfig(filepath.Join(t.TempDir(), "config.yaml")); err != nil {
+		t.Fatalf("Failed to init config: %v", err)
+	}
+	config.Set("storage.media_dir", t.TempDir())
+
+	user := models.User{Email: "owner@example.com", PasswordHash: "hash"}
+	database.Create(&user)
+	quota := int64(1000)
+	site := models.Site{Subdomain: "fullsite", OwnerID: user.ID, SiteDir: t.TempDir(), StorageQuota: &quota}
+	database.Create(&site)
+	database.Create(&models.MediaItem{SiteID: site.ID, Filename: "old.jpg", OriginalName: "old.jpg", FileSize: 900, MimeType: "image/jpeg", UploadedBy: user.ID})
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("images", "big.png")
+	part.Write(append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 200)...))
+	writer.Close()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/media/upload", body)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	c.Set("site", &site)
+	c.Set("user", &user)
+	MediaUploadHandler(c)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected status 413, got %d. Response: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "storage quota exceeded") {
+		t.Errorf("Expected a quota error, got %s", w.Body.String())
+	}
+	var count int64
+	database.Model(&models.MediaItem{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected the upload not to be stored, found %d items", count)
+	}
+
+	// The owner hears about it once
+	var emails []models.OutboundEmail
+	database.Find(&emails)
+	if len(emails) != 1 || emails[0].To != "owner@example.com" || !strings.Contains(emails[0].Subject, "out of storage space") {
+		t.Errorf("Expected one out-of-space email to the owner, got %+v", emails)
+	}
+}
+
+// testPNG encodes a small image of one color
+func testPNG(t *testing.T, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestMediaUploadHandler_PerFileErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := setupMediaTestDB(t)
+	db.SetDB(database)
+	if err := config.InitConfig(filepath.Join(t.TempDir(), "config.yaml")); err != nil {
+		t.Fatalf("Failed to init config: %v", err)
+	}
+	config.Set("storage.media_dir", t.TempDir())
+
+	user := models.User{Email: "test@example.com", PasswordHash: "hash"}
+	database.Create(&user)
+	site := models.Site{Subdomain: "batch", OwnerID: user.ID, SiteDir: t.TempDir()}
+	database.Create(&site)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, data := range map[string][]byte{
+		"red.png":    testPNG(t, color.RGBA{255, 0, 0, 255}),
+		"script.exe": []byte("MZ not an image"),
+		"blue.png":   testPNG(t, color.RGBA{0, 0, 255, 255}),
+	} {
+		part, _ := writer.CreateFormFile("images", name)
+		part.Write(data)
+	}
+	writer.Close()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/media/upload", body)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	c.Set("site", &site)
+	c.Set("user", &user)
+	MediaUploadHandler(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Response: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Items  []models.MediaItem
+		Errors []struct{ Name, Error string }
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 2 {
+		t.Errorf("Expected both images to upload, got %d", len(resp.Items))
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Name != "script.exe" {
+		t.Errorf("Expected one error for script.exe, got %+v", resp.Errors)
+	}
+}
+
+func TestMediaImportZipHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := setupMediaTestDB(t)
+	db.SetDB(database)
+	if err := config.InitConfig(filepath.Join(t.TempDir(), "config.yaml")); err != nil {
+		t.Fatalf("Failed to init config: %v", err)
+	}
+	mediaDir := t.TempDir()
+	config.Set("storage.media_dir", mediaDir)
+
+	user := models.User{Email: "test@example.com", PasswordHash: "hash"}
+	database.Create(&user)
+	site := models.Site{Subdomain: "photos", OwnerID: user.ID, SiteDir: t.TempDir()}
+	database.Create(&site)
+
+	// One of the photos is already in the library
+	green := testPNG(t, color.RGBA{0, 255, 0, 255})
+	existing := models.MediaItem{SiteID: site.ID, Filename: "existing.png", OriginalName: "green.png", FileSize: int64(len(green)),
+		MimeType: "image/png", UploadedBy: user.ID, ContentHash: media.ContentHash(green)}
+	database.Create(&existing)
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, data := range map[string][]byte{
+		"burn/red.png":   testPNG(t, color.RGBA{255, 0, 0, 255}),
+		"burn/blue.png":  testPNG(t, color.RGBA{0, 0, 255, 255}),
+		"burn/green.png": green,
+		"burn/fake.png":  []byte("not really a png"),
+		"../escape.png":  testPNG(t, color.RGBA{1, 2, 3, 255}),
+		"readme.txt":     []byte("hi"),
+	} {
+		f, _ := zw.Create(name)
+		f.Write(data)
+	}
+	zw.Close()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("zip", "burn-photos.zip")
+	part.Write(archive.Bytes())
+	writer.WriteField("tag", "burn 2026")
+	writer.Close()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/media/import", body)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	c.Set("site", &site)
+	c.Set("user", &user)
+	MediaImportZipHandler(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Response: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Items      []models.MediaItem
+		Duplicates int
+		Skipped    []media.ZipSkip
+		Errors     []struct{ Name, Error string }
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 2 || resp.Duplicates != 1 {
+		t.Errorf("Expected 2 imported and 1 duplicate, got %d and %d", len(resp.Items), resp.Duplicates)
+	}
+	if len(resp.Skipped) != 2 {
+		t.Errorf("Expected the unsafe path and text file to be skipped, got %+v", resp.Skipped)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Name != "fake.png" {
+		t.Errorf("Expected an error for fake.png, got %+v", resp.Errors)
+	}
+
+	// Imported photos are stored with thumbnails and all are tagged
+	for _, item := range resp.Items {
+		if _, err := os.Stat(filepath.Join(mediaDir, "uploads", "thumbs", item.Filename)); err != nil {
+			t.Errorf("Expected a thumbnail for %s: %v", item.OriginalName, err)
+		}
+	}
+	var tagged int64
+	database.Model(&models.MediaTag{}).Where("tag_name = ?", "burn 2026").Count(&tagged)
+	if tagged != 3 {
+		t.Errorf("Expected 3 tagged items, got %d", tagged)
+	}
+}
+
 func TestUpdateBlockHandler_ImageCentralized(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	database := setupMediaTestDB(t)
@@ -223,3 +433,176 @@ func TestUpdateBlockHandler_ImageCentralized(t *testing.T) {
 		t.Errorf("Expected file to exist at %s, but it doesn't", centralizedPath)
 	}
 }
+
+func TestMediaDeleteHandler_ImageInColumnsIsInUse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := setupMediaTestDB(t)
+	db.SetDB(database)
+
+	user := models.User{Email: "test@example.com", PasswordHash: "hash", IsGlobalAdmin: true}
+	database.Create(&user)
+	site := models.Site{Subdomain: "testsite", OwnerID: user.ID, SiteDir: t.TempDir()}
+	database.Create(&site)
+	page := models.Page{SiteID: site.ID, Slug: "/about", Title: "About"}
+	database.Create(&page)
+	block := models.Block{PageID: page.ID, Type: "columns", Data: `{"column_count":2,"columns":[{"content":""},{"content":""}]}`}
+	database.Create(&block)
+	item := models.MediaItem{SiteID: site.ID, Filename: "3f2a.png", OriginalName: "logo.png", MimeType: "image/png", UploadedBy: user.ID}
+	database.Create(&item)
+
+	// Save the columns block with the image embedded in its HTML
+	form := url.Values{"column_count": {"2"}, "column_0": {`<p>Our logo</p><img src="/assets/3f2a.png?w=400" alt="">`}, "column_1": {"Hi"}}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/pages/1/blocks/1", strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Params = []gin.Param{{Key: "id", Value: fmt.Sprint(page.ID)}, {Key: "block_id", Value: fmt.Sprint(block.ID)}}
+	c.Set("site", &site)
+	c.Set("user", &user)
+	UpdateBlockHandler(c)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/media/1/delete", nil)
+	c.Params = []gin.Param{{Key: "id", Value: fmt.Sprint(item.ID)}}
+	c.Set("site", &site)
+	c.Set("user", &user)
+	MediaDeleteHandler(c)
+
+	var resp struct {
+		InUse  bool     `json:"in_use"`
+		Usages []string `json:"usages"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if !resp.InUse || len(resp.Usages) != 1 || resp.Usages[0] != "About → columns" {
+		t.Fatalf("Expected image to be in use by the columns block, got %s", w.Body.String())
+	}
+
+	var count int64
+	database.Model(&models.MediaItem{}).Count(&count)
+	if count != 1 {
+		t.Error("Expected media item in use not to be deleted")
+	}
+}
+
+func TestMediaBulkHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := setupMediaTestDB(t)
+	db.SetDB(database)
+
+	if err := config.InitConfig(filepath.Join(t.TempDir(), "config.yaml")); err != nil {
+		t.Fatalf("Failed to init config: %v", err)
+	}
+	config.Set("storage.media_dir", t.TempDir())
+
+	user := models.User{Email: "test@example.com", PasswordHash: "hash"}
+	database.Create(&user)
+	other := models.User{Email: "other@example.com", PasswordHash: "hash"}
+	database.Create(&other)
+	site := models.Site{Subdomain: "testsite", OwnerID: user.ID, SiteDir: t.TempDir()}
+	database.Create(&site)
+	otherSite := models.Site{Subdomain: "othersite", OwnerID: other.ID, SiteDir: t.TempDir()}
+	database.Create(&otherSite)
+
+	used := models.MediaItem{SiteID: site.ID, Filename: "used.png", OriginalName: "used.png", MimeType: "image/png", UploadedBy: user.ID}
+	unused := models.MediaItem{SiteID: site.ID, Filename: "unused.png", OriginalName: "unused.png", MimeType: "image/png", UploadedBy: user.ID}
+	theirs := models.MediaItem{SiteID: otherSite.ID, Filename: "theirs.png", OriginalName: "theirs.png", MimeType: "image/png", UploadedBy: other.ID}
+	database.Create(&used)
+	database.Create(&unused)
+	database.Create(&theirs)
+
+	page := models.Page{SiteID: site.ID, Slug: "/about", Title: "About"}
+	database.Create(&page)
+	block := models.Block{PageID: page.ID, Type: "image", Data: `{"url":"/assets/used.png","alt":""}`}
+	database.Create(&block)
+	media.UpdateBlockReferences(database, &block)
+
+	collection, err := media.CreateCollection(database, site.ID, nil, "Burn 2026")
+	if err != nil {
+		t.Fatalf("CreateCollection failed: %v", err)
+	}
+
+	ids := fmt.Sprintf("%d,%d,%d", used.ID, unused.ID, theirs.ID)
+	bulk := func(form url.Values) map[string]interface{} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/media/bulk", strings.NewReader(form.Encode()))
+		c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		c.Set("site", &site)
+		c.Set("user", &user)
+		MediaBulkHandler(c)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 for %s, got %d: %s", form.Get("action"), w.Code, w.Body.String())
+		}
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	// Another site's media is skipped
+	resp := bulk(url.Values{"action": {"tag"}, "ids": {ids}, "tag": {"playa"}})
+	if resp["updated"] != float64(2) || len(resp["skipped"].([]interface{})) != 1 {
+		t.Errorf("Expected 2 tagged and 1 skipped, got %v", resp)
+	}
+	var tagged int64
+	database.Model(&models.MediaTag{}).Where("tag_name = ?", "playa").Count(&tagged)
+	if tagged != 2 {
+		t.Errorf("Expected 2 tags, got %d", tagged)
+	}
+
+	bulk(url.Values{"action": {"untag"}, "ids": {fmt.Sprint(unused.ID)}, "tag": {"playa"}})
+	database.Model(&models.MediaTag{}).Where("tag_name = ?", "playa").Count(&tagged)
+	if tagged != 1 {
+		t.Errorf("Expected 1 tag left after untagging, got %d", tagged)
+	}
+
+	resp = bulk(url.Values{"action": {"move"}, "ids": {ids}, "collection_id": {fmt.Sprint(collection.ID)}})
+	if resp["updated"] != float64(2) {
+		t.Errorf("Expected 2 moved, got %v", resp)
+	}
+	database.First(&unused, unused.ID)
+	if unused.CollectionID == nil || *unused.CollectionID != collection.ID {
+		t.Error("Expected item to be moved into the collection")
+	}
+
+	// Items in use are kept and listed, unless forced
+	resp = bulk(url.Values{"action": {"delete"}, "ids": {ids}})
+	inUse := resp["in_use"].([]interface{})
+	if resp["updated"] != float64(1) || len(inUse) != 1 || len(resp["skipped"].([]interface{})) != 1 {
+		t.Fatalf("Expected 1 deleted, 1 in use and 1 skipped, got %v", resp)
+	}
+	if inUse[0].(map[string]interface{})["id"] != float64(used.ID) {
+		t.Errorf("Expected the used item to be in use, got %v", inUse[0])
+	}
+	var count int64
+	database.Model(&models.MediaItem{}).Count(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 items left, got %d", count)
+	}
+
+	bulk(url.Values{"action": {"delete"}, "ids": {fmt.Sprint(used.ID)}, "force": {"true"}})
+	database.Model(&models.MediaItem{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected only the other site's item left, got %d", count)
+	}
+
+	// An uploader can't delete their upload to another site from here, where
+	// its use on that site's pages wouldn't be seen
+	mine := models.MediaItem{SiteID: otherSite.ID, Filename: "mine.png", OriginalName: "mine.png", MimeType: "image/png", UploadedBy: user.ID}
+	database.Create(&mine)
+	otherPage := models.Page{SiteID: otherSite.ID, Slug: "/gallery", Title: "Gallery"}
+	database.Create(&otherPage)
+	otherBlock := models.Block{PageID: otherPage.ID, Type: "image", Data: `{"url":"/assets/mine.png","alt":""}`}
+	database.Create(&otherBlock)
+	media.UpdateBlockReferences(database, &otherBlock)
+
+	for _, force := range []string{"false", "true"} {
+		resp = bulk(url.Values{"action": {"delete"}, "ids": {fmt.Sprint(mine.ID)}, "force": {force}})
+		if resp["updated"] != float64(0) || len(resp["skipped"].([]interface{})) != 1 {
+			t.Errorf("Expected another site's item to be skipped (force=%s), got %v", force, resp)
+		}
+	}
+	if err := database.First(&mine, mine.ID).Error; err != nil {
+		t.Errorf("Expected another site's item to be kept: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/seo"
+)
+
+// pageSEOSectionHTML renders the page editor section for search engine and
+// social sharing metadata
+func pageSEOSectionHTML(c *gin.Context, site *models.Site, page *models.Page, csrfToken string) string {
+	errorHTML := ""
+	if msg := c.Query("seo_error"); msg != "" {
+		errorHTML = `<div class="error-message" style="background: #f8d7da; border: 1px solid #f5c6cb; color: #721c24; padding: 12px; border-radius: 4px; margin-bottom: 12px;">` + html.EscapeString(msg) + `</div>`
+	}
+
+	noIndexChecked := ""
+	if page.NoIndex {
+		noIndexChecked = " checked"
+	}
+
+	descriptionPlaceholder := "A sentence or two about this page"
+	if site.SiteTagline != "" {
+		descriptionPlaceholder = "Defaults to the site tagline: " + site.SiteTagline
+	}
+	imagePlaceholder := "Defaults to a generated image with the page title"
+
+	return fmt.Sprintf(`
+            <div class="section">
+                <h2>Search &amp; Social Sharing</h2>
+                %s
+                <form method="POST" action="/admin/pages/%d/seo">
+                    %s
+                    <div style="margin-bottom: 12px;">
+                        <label for="meta_description" style="display: block; font-weight: 600; margin-bottom: 4px;">Description</label>
+                        <textarea id="meta_description" name="meta_description" rows="2" maxlength="%d" placeholder="%s" style="width: 100%%; box-sizing: border-box;">%s</textarea>
+                        <small style="color: var(--color-text-secondary);">Shown by search engines and in link previews. About 160 characters works best.</small>
+                    </div>
+                    <div style="margin-bottom: 12px;">
+                        <label for="social_image" style="display: block; font-weight: 600; margin-bottom: 4px;">Social Image</label>
+                        <div style="display: flex; gap: 8px;">
+                            <input type="text" id="social_image" name="social_image" value="%s" placeholder="%s" style="flex: 1;">
+                            <button type="button" class="btn btn-secondary" onclick="window.open('/admin/media/picker', 'mediaPicker', 'width=800,height=600');">Choose from Library</button>
+                        </div>
+                        <small style="color: var(--color-text-secondary);">Shown when the page is shared on social media and in chat apps. Leave blank to use a <a href="%s" target="_blank">generated image</a> in your theme colors.</small>
+                    </div>
+                    <div style="margin-bottom: 12px;">
+                        <label for="canonical_url" style="display: block; font-weight: 600; margin-bottom: 4px;">Canonical URL (optional)</label>
+                        <input type="text" id="canonical_url" name="canonical_url" value="%s" placeholder="https://..." style="width: 100%%; box-sizing: border-box;">
+                        <small style="color: var(--color-text-secondary);">Only set this when the same content lives at another address that search engines should prefer.</small>
+                    </div>
+                    <div style="margin-bottom: 12px;">
+                        <label style="display: flex; gap: 8px; align-items: center;">
+                            <input type="checkbox" name="no_index" value="1"%s> Hide from search engines (noindex, left out of the sitemap)
+                        </label>
+                    </div>
+                    <button type="submit" class="btn btn-secondary">Save Sharing Settings</button>
+                </form>
+            </div>
+            <script>
+                window.addEventListener('message', function(event) {
+                    if (event.origin !== window.location.origin) return;
+                    if (event.data.type === 'image-selected') {
+                        document.getElementById('social_image').value = event.data.url;
+                    }
+                });
+            </script>`, errorHTML, page.ID, csrfToken, seo.MaxDescriptionLength,
+		html.EscapeString(descriptionPlaceholder), html.EscapeString(page.MetaDescription),
+		html.EscapeString(page.SocialImage), html.EscapeString(imagePlaceholder), html.EscapeString(shareImageURL(site, page)),
+		html.EscapeString(page.CanonicalURL), noIndexChecked)
+}
+
+// UpdatePageSEOHandler saves a page's description, social image, canonical URL and noindex flag
+func UpdatePageSEOHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	pageIDStr := c.Param("id")
+	pageID, err := strconv.Atoi(pageIDStr)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid page ID")
+		return
+	}
+
+	var page models.Page
+	if err := db.GetDB().Where("id = ? AND site_id = ?", pageID, site.ID).First(&page).Error; err != nil {
+		c.String(http.StatusNotFound, "Page not found")
+		return
+	}
+
+	editURL := "/admin/pages/" + pageIDStr + "/edit"
+	description, err := seo.ValidateDescription(c.PostForm("meta_description"))
+	if err == nil {
+		page.MetaDescription = description
+		page.SocialImage, err = seo.ValidateImage(c.PostForm("social_image"))
+	}
+	if err == nil {
+		page.CanonicalURL, err = seo.ValidateCanonical(c.PostForm("canonical_url"))
+	}
+	if err != nil {
+		c.Redirect(http.StatusFound, editURL+"?seo_error="+url.QueryEscape(err.Error()))
+		return
+	}
+	page.NoIndex = c.PostForm("no_index") == "1"
+
+	if err := db.GetDB().Model(&page).Select("meta_description", "social_image", "canonical_url", "no_index").Updates(&page).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to update page")
+		return
+	}
+
+	c.Redirect(http.StatusFound, editURL)
+}
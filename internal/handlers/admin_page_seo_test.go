@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+func TestServePage_RendersSocialMetadata(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	site.SiteTagline = "Meow at the playa"
+	testDB.Save(site)
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/about", Title: "About", Published: true, SocialImage: "/assets/team.jpg"})
+
+	w := getWithSite(site, "/about", nil, ServePage)
+	body := w.Body.String()
+	for _, want := range []string{
+		`<meta name="description" content="Meow at the playa">`,
+		`<link rel="canonical" href="https://test.localhost/about">`,
+		`<meta property="og:image" content="https://test.localhost/assets/team.jpg">`,
+		`<meta name="twitter:card" content="summary_large_image">`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected page to contain %s", want)
+		}
+	}
+}
+
+func TestUpdatePageSEOHandler(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/about", Title: "About"})
+
+	form := url.Values{"meta_description": {"All about us"}, "social_image": {"/assets/team.jpg"}, "no_index": {"1"}}
+	c, _ := postFormWithSite(site, "/admin/pages/1/seo", gin.Params{{Key: "id", Value: "1"}}, form, UpdatePageSEOHandler)
+	if c.Writer.Status() != http.StatusFound {
+		t.Fatalf("Expected redirect, got %d", c.Writer.Status())
+	}
+	var page models.Page
+	testDB.First(&page)
+	if page.MetaDescription != "All about us" || page.SocialImage != "/assets/team.jpg" || !page.NoIndex {
+		t.Errorf("Expected SEO fields to be saved, got %+v", page)
+	}
+
+	form = url.Values{"canonical_url": {"not a url"}}
+	c, _ = postFormWithSite(site, "/admin/pages/1/seo", gin.Params{{Key: "id", Value: "1"}}, form, UpdatePageSEOHandler)
+	if loc := c.Writer.Header().Get("Location"); !strings.Contains(loc, "seo_error=") {
+		t.Errorf("Expected an error redirect, got %q", loc)
+	}
+}
+
+func TestSitemapXMLHandler_SkipsHiddenPages(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/", Title: "Home", Published: true})
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/about", Title: "About", Published: true})
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/draft", Title: "Draft"})
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/private", Title: "Private", Published: true, NoIndex: true})
+
+	w := getWithSite(site, "/sitemap.xml", nil, SitemapXMLHandler)
+	body := w.Body.String()
+	if !strings.Contains(body, "<loc>https://test.localhost/</loc>") || !strings.Contains(body, "<loc>https://test.localhost/about</loc>") {
+		t.Errorf("Expected published pages in sitemap, got:\n%s", body)
+	}
+	if strings.Contains(body, "/draft") || strings.Contains(body, "/private") {
+		t.Errorf("Sitemap must skip drafts and noindex pages, got:\n%s", body)
+	}
+}
+
+func TestShareImageHandler(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := config.InitConfig(configPath); err != nil {
+		t.Fatalf("Failed to init config: %v", err)
+	}
+	config.Set("storage.media_dir", t.TempDir())
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/about", Title: "About", Published: true})
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/draft", Title: "Draft"})
+
+	w := getWithSite(site, "/og-image/1.png", gin.Params{{Key: "file", Value: "1.png"}}, ShareImageHandler)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("Expected a PNG, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	w = getWithSite(site, "/og-image/2.png", gin.Params{{Key: "file", Value: "2.png"}}, ShareImageHandler)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a draft page, got %d", w.Code)
+	}
+
+	// Pages without their own image point at the generated one
+	w = getWithSite(site, "/about", nil, ServePage)
+	if !strings.Contains(w.Body.String(), `<meta property="og:image" content="https://test.localhost/og-image/1.png?v=`) {
+		t.Errorf("Expected generated share image in page metadata")
+	}
+}
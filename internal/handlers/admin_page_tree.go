@@ -0,0 +1,233 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/blocks"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/pages"
+	"github.com/thatcatcamp/stinkykitty/internal/redirects"
+)
+
+// pageParentOptions renders <option>s for choosing a parent page, indented to
+// show the tree. The homepage can't be a parent, and a page can't be moved
+// under itself, so exclude (if set) and its subpages are left out.
+func pageParentOptions(siteID uint, selected *uint, exclude *models.Page) string {
+	var list []models.Page
+	db.GetDB().Where("site_id = ?", siteID).Find(&list)
+
+	var b strings.Builder
+	b.WriteString(`<option value="">(None - top level)</option>`)
+	skipDepth := -1
+	for _, node := range pages.Tree(list) {
+		if skipDepth >= 0 && node.Depth > skipDepth {
+			continue
+		}
+		skipDepth = -1
+		if exclude != nil && node.Page.ID == exclude.ID {
+			skipDepth = node.Depth
+			continue
+		}
+		if node.Page.Slug == pages.HomepageSlug {
+			continue
+		}
+		sel := ""
+		if selected != nil && *selected == node.Page.ID {
+			sel = " selected"
+		}
+		fmt.Fprintf(&b, `<option value="%d"%s>%s%s (%s)</option>`, node.Page.ID, sel,
+			strings.Repeat("&nbsp;&nbsp;&nbsp;", node.Depth), html.EscapeString(node.Page.Title), html.EscapeString(node.Page.Slug))
+	}
+	return b.String()
+}
+
+// parseParentID reads an optional parent page ID from a form value
+func parseParentID(value string) (*uint, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, pages.ErrInvalidParent
+	}
+	parentID := uint(id)
+	return &parentID, nil
+}
+
+// applySlugChanges points navigation menu links at pages' new addresses after a
+// move, and redirects the old addresses so shared links keep working
+func applySlugChanges(siteID uint, changes []pages.SlugChange) {
+	for _, change := range changes {
+		if err := db.GetDB().Model(&models.MenuItem{}).
+			Where("site_id = ? AND url = ?", siteID, change.OldSlug).
+			Update("url", change.NewSlug).Error; err != nil {
+			log.Printf("Error updating menu links for page %d: %v", change.PageID, err)
+		}
+		if err := redirects.ForSlugChange(db.GetDB(), siteID, change.OldSlug, change.NewSlug); err != nil {
+			log.Printf("Error adding redirect for page %d: %v", change.PageID, err)
+		}
+	}
+}
+
+// pageLocationSectionHTML renders the page editor section for changing a page's
+// parent and slug, along with a list of its subpages
+func pageLocationSectionHTML(c *gin.Context, site *models.Site, page *models.Page, csrfToken string) string {
+	if page.Slug == pages.HomepageSlug {
+		return ""
+	}
+
+	errorHTML := ""
+	if msg := c.Query("move_error"); msg != "" {
+		errorHTML = `<div class="error-message" style="background: #f8d7da; border: 1px solid #f5c6cb; color: #721c24; padding: 12px; border-radius: 4px; margin-bottom: 12px;">` + html.EscapeString(msg) + `</div>`
+	}
+
+	children, _ := pages.Children(db.GetDB(), site.ID, page.ID, false)
+	var childList strings.Builder
+	for _, child := range children {
+		fmt.Fprintf(&childList, `<li><a href="/admin/pages/%d/edit">%s</a> <code>%s</code></li>`,
+			child.ID, html.EscapeString(child.Title), html.EscapeString(child.Slug))
+	}
+	childrenHTML := `<p style="color: var(--color-text-secondary); font-size: 14px;">No subpages yet.</p>`
+	if childList.Len() > 0 {
+		childrenHTML = `<ul style="margin: 0 0 12px; padding-left: 20px;">` + childList.String() + `</ul>`
+	}
+
+	return fmt.Sprintf(`
+            <div class="section">
+                <h2>Page Address</h2>
+                %s
+                <form method="POST" action="/admin/pages/%d/move" style="display: flex; gap: 12px; flex-wrap: wrap; align-items: flex-end;">
+                    %s
+                    <div>
+                        <label for="parent_id" style="display: block; font-weight: 600; margin-bottom: 4px;">Parent Page</label>
+                        <select id="parent_id" name="parent_id">%s</select>
+                    </div>
+                    <div>
+                        <label for="segment" style="display: block; font-weight: 600; margin-bottom: 4px;">Slug</label>
+                        <input type="text" id="segment" name="segment" value="%s" required>
+                    </div>
+                    <button type="submit" class="btn btn-secondary">Move</button>
+                </form>
+                <p style="color: var(--color-text-secondary); font-size: 14px;">Currently at <a href="%s" target="_blank"><code>%s</code></a>. Moving a page also moves its subpages. Menu links are updated to match, and the old addresses redirect to the new ones.</p>
+                <h3 style="font-size: 15px; margin: 16px 0 8px;">Subpages</h3>
+                %s
+                <a href="/admin/pages/new?parent=%d" class="btn-small">+ Add Subpage</a>
+            </div>`, errorHTML, page.ID, csrfToken, pageParentOptions(site.ID, page.ParentID, page),
+		html.EscapeString(pages.Segment(page.Slug)), html.EscapeString(page.Slug), html.EscapeString(page.Slug),
+		childrenHTML, page.ID)
+}
+
+// MovePageHandler changes a page's parent and slug, cascading the new address
+// to its subpages
+func MovePageHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	pageIDStr := c.Param("id")
+	pageID, err := strconv.Atoi(pageIDStr)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid page ID")
+		return
+	}
+
+	var page models.Page
+	if err := db.GetDB().Where("id = ? AND site_id = ?", pageID, site.ID).First(&page).Error; err != nil {
+		c.String(http.StatusNotFound, "Page not found")
+		return
+	}
+
+	editURL := "/admin/pages/" + pageIDStr + "/edit"
+	parentID, err := parseParentID(c.PostForm("parent_id"))
+	if err == nil {
+		var changes []pages.SlugChange
+		changes, err = pages.Move(db.GetDB(), &page, parentID, c.PostForm("segment"))
+		if err == nil {
+			applySlugChanges(site.ID, changes)
+			c.Redirect(http.StatusFound, editURL)
+			return
+		}
+	}
+
+	switch {
+	case errors.Is(err, pages.ErrInvalidSegment), errors.Is(err, pages.ErrSlugTaken),
+		errors.Is(err, pages.ErrCycle), errors.Is(err, pages.ErrInvalidParent):
+		c.Redirect(http.StatusFound, editURL+"?move_error="+url.QueryEscape(err.Error()))
+	default:
+		log.Printf("Error moving page %d: %v", page.ID, err)
+		c.String(http.StatusInternalServerError, "Failed to move page")
+	}
+}
+
+// renderChildPagesBlockEditor renders the edit screen for child-pages blocks
+func renderChildPagesBlockEditor(c *gin.Context, pageIDStr, blockIDStr, dataJSON string) string {
+	data, err := blocks.ParseChildPagesBlockData(dataJSON)
+	if err != nil {
+		data = &blocks.ChildPagesBlockData{}
+	}
+
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>Edit Child Pages Block</title>
+    <style>
+        body { font-family: system-ui, sans-serif; max-width: 800px; margin: 40px auto; padding: 0 20px; background: #f5f5f5; }
+        .container { background: white; padding: 30px; border-radius: 8px; box-shadow: 0 2px 4px rgba(0,0,0,0.1); }
+        h1 { color: #333; margin-top: 0; }
+        label { display: block; margin-bottom: 8px; font-weight: 600; color: #555; }
+        input[type="text"] { width: 100%%; padding: 12px; border: 1px solid #ddd; border-radius: 4px; font-size: 14px; box-sizing: border-box; margin-bottom: 15px; }
+        input:focus { outline: none; border-color: #2563eb; }
+        .button-group { margin-top: 20px; display: flex; gap: 10px; }
+        button { padding: 10px 20px; border: none; border-radius: 4px; cursor: pointer; font-size: 14px; font-weight: 600; }
+        button[type="submit"] { background: #2563eb; color: white; }
+        button[type="submit"]:hover { background: #1d4ed8; }
+        a.cancel { padding: 10px 20px; background: #6b7280; color: white; text-decoration: none; border-radius: 4px; font-size: 14px; font-weight: 600; }
+        a.cancel:hover { background: #4b5563; }
+        .note { background: #f0f4f8; padding: 15px; border-radius: 4px; margin-bottom: 20px; color: #555; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1>Edit Child Pages Block</h1>
+        <div class="note">
+            <strong>Note:</strong> This block links to the published subpages of the page it's on. Add subpages from the page's <a href="/admin/pages/%s/edit">Page Address</a> section.
+        </div>
+        <form method="POST" action="/admin/pages/%s/blocks/%s">
+            %s
+            <label for="title">Title (optional):</label>
+            <input type="text" id="title" name="title" value="%s" placeholder="In This Section">
+            <div class="button-group">
+                <button type="submit">Save &amp; Return</button>
+                <a href="/admin/pages/%s/edit" class="cancel">Cancel</a>
+            </div>
+        </form>
+    </div>
+</body>
+</html>`, pageIDStr, pageIDStr, blockIDStr, middleware.GetCSRFTokenHTML(c), html.EscapeString(data.Title), pageIDStr)
+}
+
+// childPagesBlockDataFromRequest builds child-pages block JSON from the posted editor form
+func childPagesBlockDataFromRequest(c *gin.Context) (string, error) {
+	jsonData, err := json.Marshal(blocks.ChildPagesBlockData{Title: strings.TrimSpace(c.PostForm("title"))})
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+func postFormWithSite(site *models.Site, target string, params gin.Params, form url.Values, handler gin.HandlerFunc) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", target, strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Params = params
+	c.Set("site", site)
+	handler(c)
+	return c, w
+}
+
+func TestCreatePageHandler_WithParent(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	info := &models.Page{SiteID: site.ID, Slug: "/info", Title: "Info"}
+	testDB.Create(info)
+
+	c, w := postFormWithSite(site, "/admin/pages", nil, url.Values{"parent_id": {"1"}, "slug": {"arrival"}, "title": {"Arrival"}}, CreatePageHandler)
+	if c.Writer.Status() != http.StatusFound {
+		t.Fatalf("Expected redirect, got %d: %s", c.Writer.Status(), w.Body.String())
+	}
+	var child models.Page
+	testDB.Where("title = ?", "Arrival").First(&child)
+	if child.Slug != "/info/arrival" || child.ParentID == nil || *child.ParentID != info.ID {
+		t.Errorf("Expected /info/arrival under Info, got %+v", child)
+	}
+
+	// A full path is filed under its existing parent automatically
+	postFormWithSite(site, "/admin/pages", nil, url.Values{"slug": {"/info/parking"}, "title": {"Parking"}}, CreatePageHandler)
+	var parking models.Page
+	testDB.Where("title = ?", "Parking").First(&parking)
+	if parking.ParentID == nil || *parking.ParentID != info.ID {
+		t.Errorf("Expected /info/parking to be filed under Info, got %+v", parking)
+	}
+}
+
+func TestMovePageHandler_UpdatesMenuLinks(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	info := &models.Page{SiteID: site.ID, Slug: "/info", Title: "Info"}
+	testDB.Create(info)
+	testDB.Create(&models.Page{SiteID: site.ID, ParentID: &info.ID, Slug: "/info/arrival", Title: "Arrival"})
+	testDB.Create(&models.MenuItem{SiteID: site.ID, Label: "Arrival", URL: "/info/arrival"})
+
+	c, w := postFormWithSite(site, "/admin/pages/1/move", gin.Params{{Key: "id", Value: "1"}}, url.Values{"segment": {"guide"}}, MovePageHandler)
+	if c.Writer.Status() != http.StatusFound {
+		t.Fatalf("Expected redirect, got %d: %s", c.Writer.Status(), w.Body.String())
+	}
+	var item models.MenuItem
+	testDB.First(&item)
+	if item.URL != "/guide/arrival" {
+		t.Errorf("Expected menu link to follow the move, got %s", item.URL)
+	}
+	var redirect models.Redirect
+	if err := testDB.Where("source_path = ?", "/info/arrival").First(&redirect).Error; err != nil || redirect.Target != "/guide/arrival" {
+		t.Errorf("Expected a redirect from the old address, got %+v (%v)", redirect, err)
+	}
+
+	// Moving a page under its own child is refused
+	c, _ = postFormWithSite(site, "/admin/pages/1/move", gin.Params{{Key: "id", Value: "1"}}, url.Values{"parent_id": {"2"}, "segment": {"guide"}}, MovePageHandler)
+	if loc := c.Writer.Header().Get("Location"); !strings.Contains(loc, "move_error=") {
+		t.Errorf("Expected an error redirect, got %q", loc)
+	}
+}
+
+func TestDeletePageHandler_RefusesPagesWithChildren(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	info := &models.Page{SiteID: site.ID, Slug: "/info", Title: "Info"}
+	testDB.Create(info)
+	testDB.Create(&models.Page{SiteID: site.ID, ParentID: &info.ID, Slug: "/info/arrival", Title: "Arrival"})
+
+	_, w := postFormWithSite(site, "/admin/pages/1/delete", gin.Params{{Key: "id", Value: "1"}}, url.Values{}, DeletePageHandler)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestServePage_BreadcrumbsAndChildPages(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	info := &models.Page{SiteID: site.ID, Slug: "/info", Title: "Info", Published: true}
+	testDB.Create(info)
+	testDB.Create(&models.Block{PageID: info.ID, Type: "children", Order: 0, Data: `{"title":"In This Section"}`})
+	arrival := &models.Page{SiteID: site.ID, ParentID: &info.ID, Slug: "/info/arrival", Title: "Arrival", Published: true}
+	testDB.Create(arrival)
+	testDB.Create(&models.Page{SiteID: site.ID, ParentID: &info.ID, Slug: "/info/secret", Title: "Secret Draft"})
+
+	w := getWithSite(site, "/info/arrival", nil, ServePage)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if body := w.Body.String(); !strings.Contains(body, `class="breadcrumbs"`) || !strings.Contains(body, `<a href="/info">Info</a>`) {
+		t.Errorf("Expected breadcrumb trail through Info, got:\n%s", body)
+	}
+
+	w = getWithSite(site, "/info", nil, ServePage)
+	body := w.Body.String()
+	if !strings.Contains(body, "In This Section") || !strings.Contains(body, `href="/info/arrival"`) {
+		t.Errorf("Expected child pages block to link to Arrival, got:\n%s", body)
+	}
+	if strings.Contains(body, "Secret Draft") {
+		t.Error("Child pages block must not list unpublished pages")
+	}
+}
@@ -4,18 +4,30 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"path"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
 	"github.com/thatcatcamp/stinkykitty/internal/middleware"
 	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/pages"
+	"github.com/thatcatcamp/stinkykitty/internal/redirects"
 	"github.com/thatcatcamp/stinkykitty/internal/search"
+	"github.com/thatcatcamp/stinkykitty/internal/visibility"
 	"gorm.io/gorm"
 )
 
 // NewPageFormHandler shows the form to create a new page
 func NewPageFormHandler(c *gin.Context) {
+	siteVal, _ := c.Get("site")
+	site := siteVal.(*models.Site)
+
+	// ?parent=ID preselects the parent, e.g. from "+ Subpage" links
+	parentID, _ := parseParentID(c.Query("parent"))
+
 	csrfToken := middleware.GetCSRFTokenHTML(c)
 	html := `<!DOCTYPE html>
 <html>
@@ -65,7 +77,7 @@ func NewPageFormHandler(c *gin.Context) {
             font-weight: 600;
             color: #333;
         }
-        input[type="text"] {
+        input[type="text"], select {
             width: 100%;
             padding: 10px;
             border: 1px solid #ddd;
@@ -73,7 +85,7 @@ func NewPageFormHandler(c *gin.Context) {
             font-size: 14px;
             box-sizing: border-box;
         }
-        input[type="text"]:focus {
+        input[type="text"]:focus, select:focus {
             outline: none;
             border-color: #007bff;
         }
@@ -124,10 +136,16 @@ func NewPageFormHandler(c *gin.Context) {
 
         <form method="POST" action="/admin/pages">
             ` + csrfToken + `
+            <div class="form-group">
+                <label for="parent_id">Parent Page:</label>
+                <select id="parent_id" name="parent_id">` + pageParentOptions(site.ID, parentID, nil) + `</select>
+                <div class="help-text">Subpages live under their parent's address and show it in their breadcrumb trail</div>
+            </div>
+
             <div class="form-group">
                 <label for="slug">Slug:</label>
-                <input type="text" id="slug" name="slug" required placeholder="/about">
-                <div class="help-text">The URL path for this page (e.g., /about, /contact)</div>
+                <input type="text" id="slug" name="slug" required placeholder="about">
+                <div class="help-text">The URL path for this page (e.g., /about, /contact). Under a parent, just the last part (e.g., arrival for /info/arrival)</div>
             </div>
 
             <div class="form-group">
@@ -153,7 +171,7 @@ func CreatePageHandler(c *gin.Context) {
 	siteVal, _ := c.Get("site")
 	site := siteVal.(*models.Site)
 
-	slug := c.PostForm("slug")
+	slug := strings.TrimSpace(c.PostForm("slug"))
 	title := c.PostForm("title")
 
 	// Validate
@@ -166,6 +184,37 @@ func CreatePageHandler(c *gin.Context) {
 		return
 	}
 
+	parentID, err := parseParentID(c.PostForm("parent_id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid parent page")
+		return
+	}
+	if parentID != nil {
+		// Under a parent the slug is just the last segment of the address
+		var parent models.Page
+		if err := db.GetDB().Where("id = ? AND site_id = ?", *parentID, site.ID).First(&parent).Error; err != nil || parent.Slug == pages.HomepageSlug {
+			c.String(http.StatusBadRequest, "Invalid parent page")
+			return
+		}
+		segment, err := pages.CleanSegment(slug)
+		if err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		slug = pages.JoinSlug(&parent, segment)
+	} else {
+		if !strings.HasPrefix(slug, "/") {
+			slug = "/" + slug
+		}
+		// A full path like /info/arrival is filed under /info when that page exists
+		if dir := path.Dir(slug); dir != "/" {
+			var parent models.Page
+			if err := db.GetDB().Where("site_id = ? AND slug = ?", site.ID, dir).First(&parent).Error; err == nil {
+				parentID = &parent.ID
+			}
+		}
+	}
+
 	// Check if page with this slug already exists
 	var existing models.Page
 	result := db.GetDB().Where("site_id = ? AND slug = ?", site.ID, slug).First(&existing)
@@ -177,6 +226,7 @@ func CreatePageHandler(c *gin.Context) {
 	// Create page
 	page := models.Page{
 		SiteID:    site.ID,
+		ParentID:  parentID,
 		Slug:      slug,
 		Title:     title,
 		Published: false,
@@ -284,6 +334,20 @@ func EditPageHandler(c *gin.Context) {
 			blockTypeLabel = "Contact Form Block"
 		} else if block.Type == "columns" {
 			blockTypeLabel = "Columns Block"
+		} else if block.Type == "form" {
+			blockTypeLabel = "Form Block"
+		} else if block.Type == "events" {
+			blockTypeLabel = "Events List Block"
+		} else if block.Type == "calendar" {
+			blockTypeLabel = "Calendar Block"
+		} else if block.Type == "shifts" {
+			blockTypeLabel = "Volunteer Shifts Block"
+		} else if block.Type == "posts" {
+			blockTypeLabel = "Latest Posts Block"
+		} else if block.Type == "children" {
+			blockTypeLabel = "Child Pages Block"
+		} else if block.Type == "file" {
+			blockTypeLabel = "File Download Block"
 		}
 
 		// Extract preview from JSON content
@@ -650,6 +714,12 @@ func EditPageHandler(c *gin.Context) {
         .btn-video { background: var(--color-danger); }
         .btn-spacer { background: #e0e0e0; color: var(--color-text-primary); }
         .btn-columns { background: #f59e0b; }
+        .btn-form { background: #0d9488; }
+        .btn-events { background: #db2777; }
+        .btn-shifts { background: #65a30d; }
+        .btn-posts { background: #ea580c; }
+        .btn-children { background: #4f46e5; }
+        .btn-file { background: #b45309; }
     </style>
 </head>
 <body>
@@ -733,8 +803,46 @@ func EditPageHandler(c *gin.Context) {
                         <input type="hidden" name="type" value="columns">
                         <button type="submit" class="btn btn-columns">+ Columns</button>
                     </form>
+                    <form method="POST" action="/admin/pages/` + pageIDStr + `/blocks" style="display:inline;">
+                        ` + csrfToken + `
+                        <input type="hidden" name="type" value="form">
+                        <button type="submit" class="btn btn-form">+ Form</button>
+                    </form>
+                    <form method="POST" action="/admin/pages/` + pageIDStr + `/blocks" style="display:inline;">
+                        ` + csrfToken + `
+                        <input type="hidden" name="type" value="events">
+                        <button type="submit" class="btn btn-events">+ Events List</button>
+                    </form>
+                    <form method="POST" action="/admin/pages/` + pageIDStr + `/blocks" style="display:inline;">
+                        ` + csrfToken + `
+                        <input type="hidden" name="type" value="calendar">
+                        <button type="submit" class="btn btn-events">+ Calendar</button>
+                    </form>
+                    <form method="POST" action="/admin/pages/` + pageIDStr + `/blocks" style="display:inline;">
+                        ` + csrfToken + `
+                        <input type="hidden" name="type" value="shifts">
+                        <button type="submit" class="btn btn-shifts">+ Volunteer Shifts</button>
+                    </form>
+                    <form method="POST" action="/admin/pages/` + pageIDStr + `/blocks" style="display:inline;">
+                        ` + csrfToken + `
+                        <input type="hidden" name="type" value="posts">
+                        <button type="submit" class="btn btn-posts">+ Latest Posts</button>
+                    </form>
+                    <form method="POST" action="/admin/pages/` + pageIDStr + `/blocks" style="display:inline;">
+                        ` + csrfToken + `
+                        <input type="hidden" name="type" value="children">
+                        <button type="submit" class="btn btn-children">+ Child Pages</button>
+                    </form>
+                    <form method="POST" action="/admin/pages/` + pageIDStr + `/blocks" style="display:inline;">
+                        ` + csrfToken + `
+                        <input type="hidden" name="type" value="file">
+                        <button type="submit" class="btn btn-file">+ File Download</button>
+                    </form>
                 </div>
             </div>
+            ` + pageLocationSectionHTML(c, site, &page, csrfToken) + `
+            ` + pageVisibilitySectionHTML(c, &page, csrfToken) + `
+            ` + pageSEOSectionHTML(c, site, &page, csrfToken) + `
         </div>
     </div>
 </body>
@@ -935,6 +1043,14 @@ func DeletePageHandler(c *gin.Context) {
 		return
 	}
 
+	// Subpages would be left without a parent
+	var childCount int64
+	db.GetDB().Model(&models.Page{}).Where("site_id = ? AND parent_id = ?", site.ID, page.ID).Count(&childCount)
+	if childCount > 0 {
+		c.String(http.StatusBadRequest, "This page has subpages; move or delete its subpages first")
+		return
+	}
+
 	// Delete the page (soft delete)
 	if err := db.GetDB().Delete(&page).Error; err != nil {
 		c.String(http.StatusInternalServerError, "Failed to delete page")
@@ -947,6 +1063,17 @@ func DeletePageHandler(c *gin.Context) {
 		fmt.Printf("Warning: Failed to remove page %d from index: %v\n", page.ID, err)
 	}
 
+	media.RemoveShareImages(site.ID, page.ID)
+
+	// Send visitors with old links to the parent page, or the homepage
+	redirectTo := pages.HomepageSlug
+	if ancestors, err := pages.Ancestors(db.GetDB(), &page); err == nil && len(ancestors) > 0 {
+		redirectTo = ancestors[len(ancestors)-1].Slug
+	}
+	if err := redirects.ForDeletedPage(db.GetDB(), site.ID, page.Slug, redirectTo); err != nil {
+		fmt.Printf("Warning: Failed to add redirect for deleted page %d: %v\n", page.ID, err)
+	}
+
 	// Redirect back to dashboard
 	c.Redirect(http.StatusFound, "/admin/dashboard")
 }
@@ -1223,14 +1350,22 @@ func PagesListHandler(c *gin.Context) {
 	}
 
 	// Load all pages for this site
-	var pages []models.Page
-	db.GetDB().Where("site_id = ?", site.ID).Order("slug ASC").Find(&pages)
+	var sitePages []models.Page
+	db.GetDB().Where("site_id = ?", site.ID).Order("slug ASC").Find(&sitePages)
+
+	// Show how many contact messages are waiting
+	unreadBadge := ""
+	if unread := countUnreadContactMessages(site.ID); unread > 0 {
+		unreadBadge = fmt.Sprintf(" (%d)", unread)
+	}
 
 	// Build pages list HTML
 	var pagesList string
 	homepageExists := false
 
-	for _, page := range pages {
+	// Subpages are listed under their parent, indented by depth
+	for _, node := range pages.Tree(sitePages) {
+		page := node.Page
 		if page.Slug == "/" {
 			homepageExists = true
 			status := "Draft"
@@ -1250,11 +1385,19 @@ func PagesListHandler(c *gin.Context) {
 			if page.Published {
 				status = "Published"
 			}
+			if !visibility.IsPublic(&page) {
+				status += " · " + visibility.Label(page.Visibility)
+			}
+			indent := ""
+			if node.Depth > 0 {
+				indent = fmt.Sprintf(` style="margin-left: %dpx;"`, node.Depth*24)
+			}
 			pagesList += `
-				<div class="page-item">
+				<div class="page-item"` + indent + `>
 					<strong>` + page.Title + `</strong> <code>` + page.Slug + `</code> <span class="status">` + status + `</span>
 					<div class="actions">
 						<a href="/admin/pages/` + strconv.FormatUint(uint64(page.ID), 10) + `/edit" class="btn-small">Edit</a>
+						<a href="/admin/pages/new?parent=` + strconv.FormatUint(uint64(page.ID), 10) + `" class="btn-small">+ Subpage</a>
 						<form method="POST" action="/admin/pages/` + strconv.FormatUint(uint64(page.ID), 10) + `/delete" style="display:inline;" onsubmit="return confirm('Delete this page?')">
 							` + csrfToken + `
 							<button type="submit" class="btn-small btn-danger">Delete</button>
@@ -1480,7 +1623,17 @@ func PagesListHandler(c *gin.Context) {
                     <a href="/admin/pages/new" class="btn">+ Create New Page</a>
                     <a href="/admin/menu" class="btn" style="background: #17a2b8; margin-left: 10px;">Navigation Menu</a>
                     <a href="/admin/settings" class="btn" style="background: #6366f1; margin-left: 10px;">Theme Settings</a>
+                    <a href="/admin/contact" class="btn" style="background: #0ea5e9; margin-left: 10px;">Contact Inbox` + unreadBadge + `</a>
+                    <a href="/admin/forms" class="btn" style="background: #0d9488; margin-left: 10px;">Form Submissions</a>
+                    <a href="/admin/events" class="btn" style="background: #db2777; margin-left: 10px;">Events</a>
+                    <a href="/admin/shifts" class="btn" style="background: #65a30d; margin-left: 10px;">Volunteer Shifts</a>
+                    <a href="/admin/posts" class="btn" style="background: #ea580c; margin-left: 10px;">Blog Posts</a>
+                    <a href="/admin/announcements" class="btn" style="background: #dc2626; margin-left: 10px;">Announcements</a>
+                    <a href="/admin/redirects" class="btn" style="background: #475569; margin-left: 10px;">Redirects</a>
+                    <a href="/admin/archives" class="btn" style="background: #78716c; margin-left: 10px;">Archives</a>
+                    <a href="/admin/access" class="btn" style="background: #7c3aed; margin-left: 10px;">Site Access` + siteAccessBadge(site) + `</a>
                     <a href="/admin/export?site=` + fmt.Sprintf("%d", site.ID) + `" class="btn" style="background: #10b981; margin-left: 10px;">Download Site</a>
+                    <a href="/admin/export/static" class="btn" style="background: #059669; margin-left: 10px;" title="A zip of plain HTML pages that works without internet">Offline Copy</a>
                 </div>
             </div>
         </div>
@@ -23,7 +23,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// Auto-migrate all models
-	err = testDB.AutoMigrate(&models.Site{}, &models.Page{}, &models.Block{})
+	err = testDB.AutoMigrate(&models.Site{}, &models.Page{}, &models.Block{}, &models.MediaReference{})
 	if err != nil {
 		t.Fatalf("Failed to migrate database: %v", err)
 	}
@@ -0,0 +1,539 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/blocks"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/events"
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/posts"
+	"github.com/thatcatcamp/stinkykitty/internal/search"
+)
+
+// postStatus describes whether a post is a draft, scheduled or live
+func postStatus(p *models.Post, now time.Time) string {
+	switch {
+	case !p.Published:
+		return "Draft"
+	case p.PublishedAt.After(now):
+		return "Scheduled"
+	default:
+		return "Published"
+	}
+}
+
+// PostsListHandler lists a site's blog posts, newest first
+func PostsListHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	var list []models.Post
+	db.GetDB().Preload("Tags").Where("site_id = ?", site.ID).Order("published_at DESC, id DESC").Find(&list)
+
+	csrfToken := middleware.GetCSRFTokenHTML(c)
+	now := time.Now()
+
+	var tableRows strings.Builder
+	for i := range list {
+		p := &list[i]
+		fmt.Fprintf(&tableRows, `
+			<tr>
+				<td>%s</td>
+				<td>%s</td>
+				<td>%s</td>
+				<td>%s</td>
+				<td>%s</td>
+				<td>
+					<div style="display: flex; gap: 8px;">
+						<a href="/admin/posts/%d/edit" class="btn btn-small">Edit</a>
+						<a href="%s" class="btn btn-small btn-secondary" target="_blank">View</a>
+						<form method="POST" action="/admin/posts/%d/delete" style="display: inline;" onsubmit="return confirm('Delete this post?');">
+							%s
+							<button type="submit" class="btn btn-small btn-danger">Delete</button>
+						</form>
+					</div>
+				</td>
+			</tr>`, html.EscapeString(p.Title), postStatus(p, now), html.EscapeString(blocks.PostDate(p)),
+			html.EscapeString(p.Author), html.EscapeString(strings.Join(posts.TagNames(p), ", ")),
+			p.ID, html.EscapeString(posts.URL(p)), p.ID, csrfToken)
+	}
+	if tableRows.Len() == 0 {
+		tableRows.WriteString(`<tr><td colspan="6" style="text-align: center; color: #666;">No posts yet.</td></tr>`)
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Blog Posts - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 1200px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>Blog Posts</h1>
+			<div class="header-actions">
+				<a href="/admin/posts/new" class="btn">+ New Post</a>
+				<a href="/admin/pages?site=%d" class="btn btn-secondary">← Back to Pages</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		<p>Published posts appear at <a href="/blog" target="_blank">/blog</a>, in Latest Posts blocks, in search results and in the <a href="/feed.xml">RSS</a> and <a href="/atom.xml">Atom</a> feeds. Posts with a future publish date stay hidden until then.</p>
+		<div class="card">
+			<table class="data-table">
+				<thead>
+					<tr>
+						<th>Title</th>
+						<th>Status</th>
+						<th>Publish Date</th>
+						<th>Author</th>
+						<th>Tags</th>
+						<th>Actions</th>
+					</tr>
+				</thead>
+				<tbody>
+					%s
+				</tbody>
+			</table>
+		</div>
+	</div>
+</body>
+</html>`, GetDesignSystemCSS(), site.ID, tableRows.String())
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// renderPostForm renders the create/edit form for a post. p.ID of 0 means a new post.
+// tags is passed separately so a rejected submission keeps what was typed.
+func renderPostForm(c *gin.Context, p *models.Post, tags string, errMsg string) {
+	loc := events.Location()
+	publishedAt := p.PublishedAt.In(loc)
+
+	title := "New Post"
+	action := "/admin/posts"
+	if p.ID != 0 {
+		title = "Edit Post"
+		action = fmt.Sprintf("/admin/posts/%d", p.ID)
+	}
+
+	errorHTML := ""
+	status := http.StatusOK
+	if errMsg != "" {
+		errorHTML = `<div class="error-message">` + html.EscapeString(errMsg) + `</div>`
+		status = http.StatusBadRequest
+	}
+
+	published := ""
+	if p.Published {
+		published = " checked"
+	}
+	previewStyle := "display: none;"
+	if p.CoverImage != "" {
+		previewStyle = ""
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>%s - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 800px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+		.form-group { margin-bottom: var(--spacing-md); }
+		.form-group label { display: block; margin-bottom: 6px; font-weight: 600; }
+		.form-group input[type="text"], .form-group textarea { width: 100%%; box-sizing: border-box; }
+		.form-row { display: flex; gap: var(--spacing-sm); }
+		.help-text { font-size: 13px; color: var(--color-text-secondary); margin-top: 4px; }
+		.error-message { background: #f8d7da; border: 1px solid #f5c6cb; color: #721c24; padding: 12px; border-radius: 4px; margin-bottom: var(--spacing-md); }
+		.cover-preview { max-width: 100%%; max-height: 200px; margin-top: 8px; border-radius: 4px; }
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>%s</h1>
+			<div class="header-actions">
+				<a href="/admin/posts" class="btn btn-secondary">← Back to Posts</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		%s
+		<div class="card">
+			<form method="POST" action="%s">
+				%s
+				<div class="form-group">
+					<label for="title">Title</label>
+					<input type="text" id="title" name="title" value="%s" required>
+				</div>
+				<div class="form-group">
+					<label for="slug">Slug (optional)</label>
+					<input type="text" id="slug" name="slug" value="%s" placeholder="generated-from-the-title">
+					<div class="help-text">The post's address will be /blog/&lt;slug&gt;. Lowercase letters, numbers and dashes.</div>
+				</div>
+				<div class="form-group">
+					<label for="author">Author</label>
+					<input type="text" id="author" name="author" value="%s">
+				</div>
+				<div class="form-group">
+					<label>Publish Date</label>
+					<div class="form-row">
+						<input type="date" name="publish_date" value="%s">
+						<input type="time" name="publish_time" value="%s">
+					</div>
+					<div class="help-text">Leave blank to publish now. A future date schedules the post. Times are in %s.</div>
+				</div>
+				<div class="form-group">
+					<label style="display: flex; gap: 8px; align-items: center; font-weight: normal;">
+						<input type="checkbox" name="published" value="1"%s> Published (unchecked posts are drafts)
+					</label>
+				</div>
+				<div class="form-group">
+					<label for="cover_image">Cover Image (optional)</label>
+					<div class="form-row">
+						<input type="text" id="cover_image" name="cover_image" value="%s" placeholder="/assets/..." oninput="updatePreview()">
+						<button type="button" class="btn btn-secondary" onclick="openMediaPicker()">Choose from Library</button>
+					</div>
+					<img id="cover-preview" class="cover-preview" src="%s" alt="" style="%s">
+				</div>
+				<div class="form-group">
+					<label for="excerpt">Excerpt (optional)</label>
+					<textarea id="excerpt" name="excerpt" rows="3">%s</textarea>
+					<div class="help-text">Shown in the archive, Latest Posts blocks and feeds. Defaults to the start of the post.</div>
+				</div>
+				<div class="form-group">
+					<label for="content">Content</label>
+					<textarea id="content" name="content" rows="16">%s</textarea>
+					<div class="help-text">Basic HTML is allowed (links, lists, images, bold, italics).</div>
+				</div>
+				<div class="form-group">
+					<label for="tags">Tags (optional)</label>
+					<input type="text" id="tags" name="tags" value="%s" placeholder="news, build-week">
+					<div class="help-text">Separate tags with commas.</div>
+				</div>
+				<button type="submit" class="btn">Save Post</button>
+			</form>
+		</div>
+	</div>
+	<script>
+		function openMediaPicker() {
+			window.open('/admin/media/picker', 'mediaPicker', 'width=800,height=600');
+		}
+
+		function updatePreview() {
+			const url = document.getElementById('cover_image').value;
+			const preview = document.getElementById('cover-preview');
+			preview.src = url;
+			preview.style.display = url ? '' : 'none';
+		}
+
+		// Listen for selected image
+		window.addEventListener('message', (event) => {
+			if (event.origin !== window.location.origin) return;
+			if (event.data.type === 'image-selected') {
+				document.getElementById('cover_image').value = event.data.url;
+				updatePreview();
+			}
+		});
+	</script>
+</body>
+</html>`, title, GetDesignSystemCSS(), title, errorHTML, action, middleware.GetCSRFTokenHTML(c),
+		html.EscapeString(p.Title),
+		html.EscapeString(p.Slug),
+		html.EscapeString(p.Author),
+		dateValue(publishedAt), timeValue(publishedAt, false),
+		html.EscapeString(loc.String()),
+		published,
+		html.EscapeString(p.CoverImage), html.EscapeString(p.CoverImage), previewStyle,
+		html.EscapeString(p.Excerpt),
+		html.EscapeString(p.Content),
+		html.EscapeString(tags))
+
+	c.Data(status, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// postFromRequest fills p from the posted post form, returning a message for
+// the user if anything is invalid
+func postFromRequest(c *gin.Context, p *models.Post) string {
+	p.Title = strings.TrimSpace(c.PostForm("title"))
+	p.Slug = strings.TrimSpace(c.PostForm("slug"))
+	p.Author = strings.TrimSpace(c.PostForm("author"))
+	p.Excerpt = strings.TrimSpace(c.PostForm("excerpt"))
+	p.Content = strings.TrimSpace(c.PostForm("content"))
+	p.CoverImage = strings.TrimSpace(c.PostForm("cover_image"))
+	p.Published = c.PostForm("published") != ""
+
+	if p.Title == "" {
+		return "Title is required"
+	}
+	if p.Slug == "" {
+		p.Slug = posts.Slugify(p.Title)
+	}
+	if !posts.ValidSlug(p.Slug) {
+		return "Slug may only contain lowercase letters, numbers and dashes"
+	}
+	if p.CoverImage != "" && !strings.HasPrefix(p.CoverImage, "/") && !strings.HasPrefix(p.CoverImage, "https://") && !strings.HasPrefix(p.CoverImage, "http://") {
+		return "Cover image must be an image from the media library or a web address"
+	}
+
+	var existing models.Post
+	if err := db.GetDB().Where("site_id = ? AND slug = ? AND id <> ?", p.SiteID, p.Slug, p.ID).First(&existing).Error; err == nil {
+		return "Another post already uses this slug"
+	}
+
+	if date := c.PostForm("publish_date"); date != "" {
+		publishedAt, err := parseEventTime(date, c.PostForm("publish_time"), events.Location())
+		if err != nil {
+			return "Publish date is invalid"
+		}
+		p.PublishedAt = publishedAt.UTC()
+	} else if p.PublishedAt.IsZero() {
+		p.PublishedAt = time.Now().UTC().Truncate(time.Second)
+	}
+
+	return ""
+}
+
+// savePostTags stores a post's tags and refreshes its search index entry
+func savePostTags(p *models.Post, tags string) error {
+	if err := posts.SetTags(db.GetDB(), p, posts.ParseTags(tags)); err != nil {
+		return err
+	}
+	if err := search.IndexPost(db.GetDB(), p); err != nil {
+		// Log error but don't fail the request
+		fmt.Printf("Warning: Failed to index post %d: %v\n", p.ID, err)
+	}
+	return nil
+}
+
+// loadSitePost loads a post by the :id route param, scoped to the site
+func loadSitePost(c *gin.Context, site *models.Site) (*models.Post, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid post ID")
+		return nil, false
+	}
+	var p models.Post
+	if err := db.GetDB().Preload("Tags").Where("id = ? AND site_id = ?", id, site.ID).First(&p).Error; err != nil {
+		c.String(http.StatusNotFound, "Post not found")
+		return nil, false
+	}
+	return &p, true
+}
+
+// NewPostFormHandler shows the form for writing a post
+func NewPostFormHandler(c *gin.Context) {
+	if _, exists := c.Get("site"); !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+
+	// Default the author to whoever is writing
+	p := &models.Post{Published: true}
+	if userVal, ok := c.Get("user"); ok {
+		if user, ok := userVal.(*models.User); ok {
+			p.Author = user.Email
+		}
+	}
+	renderPostForm(c, p, "", "")
+}
+
+// CreatePostHandler saves a new post
+func CreatePostHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	p := &models.Post{SiteID: site.ID}
+	if errMsg := postFromRequest(c, p); errMsg != "" {
+		renderPostForm(c, p, c.PostForm("tags"), errMsg)
+		return
+	}
+
+	if err := db.GetDB().Create(p).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to create post")
+		return
+	}
+	if err := savePostTags(p, c.PostForm("tags")); err != nil {
+		c.String(http.StatusInternalServerError, "Failed to save tags")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/posts")
+}
+
+// EditPostFormHandler shows the form for editing a post
+func EditPostFormHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	p, ok := loadSitePost(c, siteVal.(*models.Site))
+	if !ok {
+		return
+	}
+	renderPostForm(c, p, strings.Join(posts.TagNames(p), ", "), "")
+}
+
+// UpdatePostHandler saves changes to a post
+func UpdatePostHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	p, ok := loadSitePost(c, siteVal.(*models.Site))
+	if !ok {
+		return
+	}
+
+	if errMsg := postFromRequest(c, p); errMsg != "" {
+		renderPostForm(c, p, c.PostForm("tags"), errMsg)
+		return
+	}
+
+	if err := db.GetDB().Omit("Tags").Save(p).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to update post")
+		return
+	}
+	if err := savePostTags(p, c.PostForm("tags")); err != nil {
+		c.String(http.StatusInternalServerError, "Failed to save tags")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/posts")
+}
+
+// DeletePostHandler deletes a post
+func DeletePostHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	p, ok := loadSitePost(c, siteVal.(*models.Site))
+	if !ok {
+		return
+	}
+
+	if err := db.GetDB().Delete(p).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to delete post")
+		return
+	}
+	db.GetDB().Where("post_id = ?", p.ID).Delete(&models.PostTag{})
+	if err := search.RemovePostFromIndex(db.GetDB(), p.ID); err != nil {
+		fmt.Printf("Warning: Failed to remove post %d from index: %v\n", p.ID, err)
+	}
+
+	c.Redirect(http.StatusFound, "/admin/posts")
+}
+
+// renderPostsBlockEditor renders the edit screen for latest-posts blocks
+func renderPostsBlockEditor(c *gin.Context, pageIDStr, blockIDStr, dataJSON string) string {
+	data, err := blocks.ParsePostsBlockData(dataJSON)
+	if err != nil {
+		data, _ = blocks.ParsePostsBlockData(`{}`)
+	}
+
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>Edit Latest Posts Block</title>
+    <style>
+        body { font-family: system-ui, sans-serif; max-width: 800px; margin: 40px auto; padding: 0 20px; background: #f5f5f5; }
+        .container { background: white; padding: 30px; border-radius: 8px; box-shadow: 0 2px 4px rgba(0,0,0,0.1); }
+        h1 { color: #333; margin-top: 0; }
+        label { display: block; margin-bottom: 8px; font-weight: 600; color: #555; }
+        input[type="text"], input[type="number"] { width: 100%%; padding: 12px; border: 1px solid #ddd; border-radius: 4px; font-size: 14px; box-sizing: border-box; margin-bottom: 15px; }
+        input:focus { outline: none; border-color: #2563eb; }
+        .help-text { font-size: 12px; color: #666; margin-top: -10px; margin-bottom: 15px; }
+        .button-group { margin-top: 20px; display: flex; gap: 10px; }
+        button { padding: 10px 20px; border: none; border-radius: 4px; cursor: pointer; font-size: 14px; font-weight: 600; }
+        button[type="submit"] { background: #2563eb; color: white; }
+        button[type="submit"]:hover { background: #1d4ed8; }
+        a.cancel { padding: 10px 20px; background: #6b7280; color: white; text-decoration: none; border-radius: 4px; font-size: 14px; font-weight: 600; }
+        a.cancel:hover { background: #4b5563; }
+        .note { background: #f0f4f8; padding: 15px; border-radius: 4px; margin-bottom: 20px; color: #555; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1>Edit Latest Posts Block</h1>
+        <div class="note">
+            <strong>Note:</strong> This block lists the site's newest blog posts. Posts are written on the <a href="/admin/posts">Blog Posts</a> screen.
+        </div>
+        <form method="POST" action="/admin/pages/%s/blocks/%s">
+            `+middleware.GetCSRFTokenHTML(c)+`
+            <label for="title">Title (optional):</label>
+            <input type="text" id="title" name="title" value="%s" placeholder="Latest News">
+            <label for="limit">Number of Posts:</label>
+            <input type="number" id="limit" name="limit" value="%d" min="1" max="20">
+            <p class="help-text">How many recent posts to show</p>
+            <label for="tag">Tag (optional):</label>
+            <input type="text" id="tag" name="tag" value="%s" placeholder="news">
+            <p class="help-text">Only show posts with this tag. Leave blank to show all posts.</p>
+            <div class="button-group">
+                <button type="submit">Save &amp; Return</button>
+                <a href="/admin/pages/%s/edit" class="cancel">Cancel</a>
+            </div>
+        </form>
+    </div>
+</body>
+</html>`, pageIDStr, blockIDStr, html.EscapeString(data.Title), data.Limit, html.EscapeString(data.Tag), pageIDStr)
+}
+
+// postsBlockDataFromRequest builds latest-posts block JSON from the posted editor form
+func postsBlockDataFromRequest(c *gin.Context) (string, error) {
+	limit, err := strconv.Atoi(c.PostForm("limit"))
+	if err != nil || limit < 1 || limit > 20 {
+		limit = 3
+	}
+	data := blocks.PostsBlockData{
+		Title: strings.TrimSpace(c.PostForm("title")),
+		Limit: limit,
+		Tag:   posts.Slugify(c.PostForm("tag")),
+	}
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
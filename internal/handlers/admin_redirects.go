@@ -0,0 +1,256 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/redirects"
+)
+
+// maxRedirectCSVSize bounds uploaded redirect CSV files
+const maxRedirectCSVSize = 2 << 20
+
+// RedirectsListHandler lists a site's redirects with forms to add and import them
+func RedirectsListHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	renderRedirectsList(c, siteVal.(*models.Site), http.StatusOK, "", nil)
+}
+
+// renderRedirectsList renders the redirect manager. message is shown above the
+// list; problems are shown as a list of errors, e.g. rejected CSV lines.
+func renderRedirectsList(c *gin.Context, site *models.Site, status int, message string, problems []string) {
+	var list []models.Redirect
+	db.GetDB().Where("site_id = ?", site.ID).Order("source_path ASC").Find(&list)
+
+	csrfToken := middleware.GetCSRFTokenHTML(c)
+
+	var tableRows strings.Builder
+	for _, r := range list {
+		lastHit := "Never"
+		if r.LastHitAt != nil {
+			lastHit = r.LastHitAt.Format("Jan 2, 2006")
+		}
+		kind := "Permanent"
+		if r.StatusCode == http.StatusFound {
+			kind = "Temporary"
+		}
+		fmt.Fprintf(&tableRows, `
+			<tr>
+				<td><code>%s</code></td>
+				<td><a href="%s" target="_blank">%s</a></td>
+				<td>%d %s</td>
+				<td>%d</td>
+				<td>%s</td>
+				<td>
+					<form method="POST" action="/admin/redirects/%d/delete" style="display: inline;" onsubmit="return confirm('Delete this redirect?');">
+						%s
+						<button type="submit" class="btn btn-small btn-danger">Delete</button>
+					</form>
+				</td>
+			</tr>`, html.EscapeString(r.SourcePath), html.EscapeString(r.Target), html.EscapeString(r.Target),
+			r.StatusCode, kind, r.Hits, lastHit, r.ID, csrfToken)
+	}
+	if tableRows.Len() == 0 {
+		tableRows.WriteString(`<tr><td colspan="6" style="text-align: center; color: #666;">No redirects yet.</td></tr>`)
+	}
+
+	messageHTML := ""
+	if message != "" {
+		color := "background: #d1fae5; border: 1px solid #6ee7b7; color: #065f46;"
+		if status != http.StatusOK || len(problems) > 0 {
+			color = "background: #f8d7da; border: 1px solid #f5c6cb; color: #721c24;"
+		}
+		problemsHTML := ""
+		if len(problems) > 0 {
+			var b strings.Builder
+			for _, p := range problems {
+				b.WriteString("<li>" + html.EscapeString(p) + "</li>")
+			}
+			problemsHTML = `<ul style="margin: 8px 0 0; padding-left: 20px;">` + b.String() + `</ul>`
+		}
+		messageHTML = fmt.Sprintf(`<div style="%s padding: 12px; border-radius: 4px; margin-bottom: 16px;">%s%s</div>`,
+			color, html.EscapeString(message), problemsHTML)
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Redirects - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 1200px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+		.redirect-form { display: flex; gap: 12px; flex-wrap: wrap; align-items: flex-end; }
+		.redirect-form label { display: block; font-weight: 600; margin-bottom: 4px; }
+		.redirect-form input[type="text"] { min-width: 240px; }
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>Redirects</h1>
+			<div class="header-actions">
+				<a href="/admin/pages?site=%d" class="btn btn-secondary">← Back to Pages</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		<p>Redirects send visitors from an old address to a new one. They're added automatically when a page moves or is deleted, and only apply when no published page uses the address.</p>
+		%s
+		<div class="card">
+			<h2>Add Redirect</h2>
+			<form method="POST" action="/admin/redirects" class="redirect-form">
+				%s
+				<div>
+					<label for="source">From</label>
+					<input type="text" id="source" name="source" placeholder="/old-page" required>
+				</div>
+				<div>
+					<label for="target">To</label>
+					<input type="text" id="target" name="target" placeholder="/new-page or https://..." required>
+				</div>
+				<div>
+					<label for="status">Type</label>
+					<select id="status" name="status">
+						<option value="301">301 Permanent</option>
+						<option value="302">302 Temporary</option>
+					</select>
+				</div>
+				<button type="submit" class="btn">Save Redirect</button>
+			</form>
+			<p style="color: #666; font-size: 14px;">Saving a redirect for an existing "From" address replaces it.</p>
+		</div>
+
+		<div class="card">
+			<h2>Import from CSV</h2>
+			<form method="POST" action="/admin/redirects/import" enctype="multipart/form-data" class="redirect-form">
+				%s
+				<input type="file" name="file" accept=".csv,text/csv" required>
+				<button type="submit" class="btn btn-secondary">Import</button>
+			</form>
+			<p style="color: #666; font-size: 14px;">One redirect per line: <code>source,target,status</code>. Status is optional (301 when blank), a header row is skipped, and full URLs from your old site are accepted as sources.</p>
+		</div>
+
+		<div class="card">
+			<table class="data-table">
+				<thead>
+					<tr>
+						<th>From</th>
+						<th>To</th>
+						<th>Type</th>
+						<th>Hits</th>
+						<th>Last Used</th>
+						<th>Actions</th>
+					</tr>
+				</thead>
+				<tbody>
+					%s
+				</tbody>
+			</table>
+		</div>
+	</div>
+</body>
+</html>`, GetDesignSystemCSS(), site.ID, messageHTML, csrfToken, csrfToken, tableRows.String())
+
+	c.Data(status, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// CreateRedirectHandler adds a redirect, or replaces the one for the same source
+func CreateRedirectHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	status, err := strconv.Atoi(c.DefaultPostForm("status", "301"))
+	if err != nil {
+		status = 0
+	}
+	if _, err := redirects.Set(db.GetDB(), site.ID, c.PostForm("source"), c.PostForm("target"), status); err != nil {
+		renderRedirectsList(c, site, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/redirects")
+}
+
+// DeleteRedirectHandler removes a redirect
+func DeleteRedirectHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	result := db.GetDB().Where("id = ? AND site_id = ?", c.Param("id"), site.ID).Delete(&models.Redirect{})
+	if result.Error != nil {
+		c.String(http.StatusInternalServerError, "Failed to delete redirect")
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.String(http.StatusNotFound, "Redirect not found")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/redirects")
+}
+
+// ImportRedirectsHandler adds redirects from an uploaded CSV file
+func ImportRedirectsHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		renderRedirectsList(c, site, http.StatusBadRequest, "Choose a CSV file to import", nil)
+		return
+	}
+	if fileHeader.Size > maxRedirectCSVSize {
+		renderRedirectsList(c, site, http.StatusBadRequest, "CSV file is too large (2MB max)", nil)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to read upload")
+		return
+	}
+	defer file.Close()
+
+	result, err := redirects.ImportCSV(db.GetDB(), site.ID, file)
+	if err != nil {
+		renderRedirectsList(c, site, http.StatusBadRequest,
+			fmt.Sprintf("Imported %d redirects before the file couldn't be read: %v", result.Imported, err), result.Errors)
+		return
+	}
+
+	message := fmt.Sprintf("Imported %d redirects.", result.Imported)
+	if len(result.Errors) > 0 {
+		message = fmt.Sprintf("Imported %d redirects; %d lines were skipped:", result.Imported, len(result.Errors))
+	}
+	renderRedirectsList(c, site, http.StatusOK, message, result.Errors)
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+func TestServePage_FollowsRedirect(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	testDB.Create(&models.Redirect{SiteID: site.ID, SourcePath: "/old-info", Target: "/info", StatusCode: http.StatusMovedPermanently})
+	testDB.Create(&models.Redirect{SiteID: 2, SourcePath: "/elsewhere", Target: "/info", StatusCode: http.StatusMovedPermanently})
+
+	w := getWithSite(site, "/old-info/?utm_source=flyer", nil, ServePage)
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/info?utm_source=flyer" {
+		t.Fatalf("Expected 301 to /info, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	var redirect models.Redirect
+	testDB.Where("source_path = ?", "/old-info").First(&redirect)
+	if redirect.Hits != 1 || redirect.LastHitAt == nil {
+		t.Errorf("Expected the hit to be counted, got %+v", redirect)
+	}
+
+	// A published page at the same address wins over the redirect
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/old-info", Title: "Old Info", Published: true})
+	if w := getWithSite(site, "/old-info", nil, ServePage); w.Code != http.StatusOK {
+		t.Errorf("Expected the page to be served, got %d", w.Code)
+	}
+
+	if w := getWithSite(site, "/elsewhere", nil, ServePage); w.Code != http.StatusNotFound {
+		t.Errorf("Expected another site's redirect to be ignored, got %d", w.Code)
+	}
+}
+
+func TestCreateRedirectHandler(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+
+	c, _ := postFormWithSite(site, "/admin/redirects", nil, url.Values{"source": {"/old"}, "target": {"/new"}, "status": {"302"}}, CreateRedirectHandler)
+	if c.Writer.Status() != http.StatusFound {
+		t.Fatalf("Expected redirect, got %d", c.Writer.Status())
+	}
+	var redirect models.Redirect
+	if err := testDB.First(&redirect).Error; err != nil || redirect.StatusCode != http.StatusFound {
+		t.Errorf("Expected a 302 redirect to be saved, got %+v (%v)", redirect, err)
+	}
+
+	_, w := postFormWithSite(site, "/admin/redirects", nil, url.Values{"source": {"/old"}, "target": {"javascript:alert(1)"}}, CreateRedirectHandler)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a bad target, got %d", w.Code)
+	}
+}
+
+func TestImportRedirectsHandler(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "redirects.csv")
+	part.Write([]byte("source,target\n/about-us.html,/about\nnot a redirect\n"))
+	writer.Close()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/redirects/import", body)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	c.Set("site", site)
+	ImportRedirectsHandler(c)
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Imported 1 redirects; 1 lines were skipped") {
+		t.Errorf("Expected import summary, got %d:\n%s", w.Code, w.Body.String())
+	}
+	var count int64
+	testDB.Model(&models.Redirect{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected 1 redirect, got %d", count)
+	}
+}
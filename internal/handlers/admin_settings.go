@@ -11,6 +11,7 @@ import (
 	"github.com/thatcatcamp/stinkykitty/internal/db"
 	"github.com/thatcatcamp/stinkykitty/internal/middleware"
 	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/seo"
 	"github.com/thatcatcamp/stinkykitty/internal/themes"
 )
 
@@ -285,6 +286,14 @@ func AdminSettingsHandler(c *gin.Context) {
                         </small>
                     </div>
 
+                    <div class="form-group">
+                        <label for="logo_path">Logo</label>
+                        <input type="text" id="logo_path" name="logo_path" value="` + html.EscapeString(site.LogoPath) + `" placeholder="/assets/...">
+                        <small style="color: var(--color-text-secondary); display: block; margin-top: 4px;">
+                            Image URL from the media library, drawn on the generated link preview images for your pages
+                        </small>
+                    </div>
+
                     <div class="form-group">
                         <label for="google_analytics_id">Google Analytics Tracking ID</label>
                         <input type="text" id="google_analytics_id" name="google_analytics_id" value="` + html.EscapeString(site.GoogleAnalyticsID) + `" placeholder="G-XXXXXXXXXX or UA-XXXXXXXXX">
@@ -351,6 +360,12 @@ func AdminSettingsSaveHandler(c *gin.Context) {
 	googleAnalyticsID := strings.TrimSpace(c.PostForm("google_analytics_id"))
 	copyrightText := strings.TrimSpace(c.PostForm("copyright_text"))
 
+	logoPath, err := seo.ValidateImage(c.PostForm("logo_path"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid logo: %v", err)
+		return
+	}
+
 	// Validate GA ID format if provided
 	if googleAnalyticsID != "" {
 		// GA4: G-XXXXXXXXXX or Universal Analytics: UA-XXXXXXXXX-X
@@ -383,6 +398,7 @@ func AdminSettingsSaveHandler(c *gin.Context) {
 	// Update site record with all fields
 	site.SiteTitle = siteTitle
 	site.SiteTagline = siteTagline
+	site.LogoPath = logoPath
 	site.GoogleAnalyticsID = googleAnalyticsID
 	site.CopyrightText = copyrightText
 	site.ThemePalette = palette
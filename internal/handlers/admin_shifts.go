@@ -0,0 +1,628 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/blocks"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/events"
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/shifts"
+	"gorm.io/gorm"
+)
+
+// maxShiftCapacity caps how many volunteers a single shift can take
+const maxShiftCapacity = 500
+
+// ShiftsListHandler lists a site's volunteer shifts with how full each one is
+func ShiftsListHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	// Show shifts from the last week onwards; older ones are still in the CSV export
+	var list []models.Shift
+	db.GetDB().Where("site_id = ? AND starts_at > ?", site.ID, time.Now().AddDate(0, 0, -7)).
+		Order("starts_at ASC").Find(&list)
+	slots, err := shifts.SlotsFor(db.GetDB(), list)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load shifts")
+		return
+	}
+
+	csrfToken := middleware.GetCSRFTokenHTML(c)
+
+	var tableRows strings.Builder
+	for _, slot := range slots {
+		shift := slot.Shift
+		fmt.Fprintf(&tableRows, `
+			<tr>
+				<td>%s</td>
+				<td>%s</td>
+				<td>%d / %d</td>
+				<td>
+					<div style="display: flex; gap: 8px;">
+						<a href="/admin/shifts/%d" class="btn btn-small">Roster</a>
+						<a href="/admin/shifts/%d/edit" class="btn btn-small btn-secondary">Edit</a>
+						<form method="POST" action="/admin/shifts/%d/delete" style="display: inline;" onsubmit="return confirm('Delete this shift and its sign-ups?');">
+							%s
+							<button type="submit" class="btn btn-small btn-danger">Delete</button>
+						</form>
+					</div>
+				</td>
+			</tr>`, html.EscapeString(shift.Role), html.EscapeString(shifts.When(&shift)), slot.Filled, shift.Capacity,
+			shift.ID, shift.ID, shift.ID, csrfToken)
+	}
+	if tableRows.Len() == 0 {
+		tableRows.WriteString(`<tr><td colspan="4" style="text-align: center; color: #666;">No upcoming shifts. Add one, then put a Volunteer Shifts block on a page.</td></tr>`)
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Volunteer Shifts - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 1200px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>Volunteer Shifts</h1>
+			<div class="header-actions">
+				<a href="/admin/shifts/new" class="btn">+ New Shift</a>
+				<a href="/admin/shifts/export" class="btn btn-secondary">Export Roster CSV</a>
+				<a href="/admin/pages?site=%d" class="btn btn-secondary">← Back to Pages</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		<p>Volunteers get a confirmation email with a cancel link when they sign up, and a reminder before their shift. Times are in %s.</p>
+		<div class="card">
+			<table class="data-table">
+				<thead>
+					<tr>
+						<th>Role</th>
+						<th>When</th>
+						<th>Signed Up</th>
+						<th>Actions</th>
+					</tr>
+				</thead>
+				<tbody>
+					%s
+				</tbody>
+			</table>
+		</div>
+	</div>
+</body>
+</html>`, GetDesignSystemCSS(), site.ID, html.EscapeString(events.Location().String()), tableRows.String())
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// renderShiftForm renders the create/edit form for a shift. shift.ID of 0 means a new shift.
+func renderShiftForm(c *gin.Context, shift *models.Shift, errMsg string) {
+	loc := events.Location()
+	start := shift.StartsAt.In(loc)
+	end := shift.EndsAt.In(loc)
+
+	title := "New Shift"
+	action := "/admin/shifts"
+	if shift.ID != 0 {
+		title = "Edit Shift"
+		action = fmt.Sprintf("/admin/shifts/%d", shift.ID)
+	}
+
+	errorHTML := ""
+	status := http.StatusOK
+	if errMsg != "" {
+		errorHTML = `<div class="error-message">` + html.EscapeString(errMsg) + `</div>`
+		status = http.StatusBadRequest
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>%s - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 800px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+		.form-group { margin-bottom: var(--spacing-md); }
+		.form-group label { display: block; margin-bottom: 6px; font-weight: 600; }
+		.form-group input[type="text"], .form-group textarea { width: 100%%; box-sizing: border-box; }
+		.form-row { display: flex; gap: var(--spacing-sm); }
+		.help-text { font-size: 13px; color: var(--color-text-secondary); margin-top: 4px; }
+		.error-message { background: #f8d7da; border: 1px solid #f5c6cb; color: #721c24; padding: 12px; border-radius: 4px; margin-bottom: var(--spacing-md); }
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>%s</h1>
+			<div class="header-actions">
+				<a href="/admin/shifts" class="btn btn-secondary">← Back to Shifts</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		%s
+		<div class="card">
+			<form method="POST" action="%s">
+				%s
+				<div class="form-group">
+					<label for="role">Role</label>
+					<input type="text" id="role" name="role" value="%s" placeholder="Kitchen, Build, Strike, Gate..." required>
+					<div class="help-text">Sign-up blocks can be limited to one role</div>
+				</div>
+				<div class="form-group">
+					<label for="description">Description (optional)</label>
+					<textarea id="description" name="description" rows="3">%s</textarea>
+				</div>
+				<div class="form-group">
+					<label>Starts</label>
+					<div class="form-row">
+						<input type="date" name="start_date" value="%s" required>
+						<input type="time" name="start_time" value="%s" required>
+					</div>
+				</div>
+				<div class="form-group">
+					<label>Ends</label>
+					<div class="form-row">
+						<input type="date" name="end_date" value="%s">
+						<input type="time" name="end_time" value="%s" required>
+					</div>
+					<div class="help-text">Leave the end date blank for a shift that ends the same day. Times are in %s.</div>
+				</div>
+				<div class="form-group">
+					<label for="capacity">Volunteers Needed</label>
+					<input type="number" id="capacity" name="capacity" value="%d" min="1" max="%d" required>
+				</div>
+				<button type="submit" class="btn">Save Shift</button>
+			</form>
+		</div>
+	</div>
+</body>
+</html>`, title, GetDesignSystemCSS(), title, errorHTML, action, middleware.GetCSRFTokenHTML(c),
+		html.EscapeString(shift.Role),
+		html.EscapeString(shift.Description),
+		dateValue(start), timeValue(start, false),
+		dateValue(end), timeValue(end, false),
+		html.EscapeString(loc.String()),
+		shift.Capacity, maxShiftCapacity)
+
+	c.Data(status, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// shiftFromRequest fills shift from the posted shift form, returning a message for
+// the user if anything is invalid
+func shiftFromRequest(c *gin.Context, shift *models.Shift) string {
+	loc := events.Location()
+
+	shift.Role = strings.TrimSpace(c.PostForm("role"))
+	shift.Description = strings.TrimSpace(c.PostForm("description"))
+	capacity, err := strconv.Atoi(c.PostForm("capacity"))
+	if err != nil || capacity < 1 || capacity > maxShiftCapacity {
+		return fmt.Sprintf("Volunteers needed must be between 1 and %d", maxShiftCapacity)
+	}
+	shift.Capacity = capacity
+
+	if shift.Role == "" {
+		return "Role is required"
+	}
+
+	endDate := c.PostForm("end_date")
+	if endDate == "" {
+		endDate = c.PostForm("start_date")
+	}
+	start, err := parseEventTime(c.PostForm("start_date"), c.PostForm("start_time"), loc)
+	if err != nil || c.PostForm("start_time") == "" {
+		return "Start date and time are required"
+	}
+	end, err := parseEventTime(endDate, c.PostForm("end_time"), loc)
+	if err != nil || c.PostForm("end_time") == "" {
+		return "End time is required"
+	}
+	if !end.After(start) {
+		return "The shift must end after it starts"
+	}
+	shift.StartsAt = start
+	shift.EndsAt = end
+	return ""
+}
+
+// loadSiteShift loads a shift by the :id route param, scoped to the site
+func loadSiteShift(c *gin.Context, site *models.Site) (*models.Shift, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid shift ID")
+		return nil, false
+	}
+	var shift models.Shift
+	if err := db.GetDB().Where("id = ? AND site_id = ?", id, site.ID).First(&shift).Error; err != nil {
+		c.String(http.StatusNotFound, "Shift not found")
+		return nil, false
+	}
+	return &shift, true
+}
+
+// NewShiftFormHandler shows the form for creating a shift
+func NewShiftFormHandler(c *gin.Context) {
+	if _, exists := c.Get("site"); !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+
+	// Default to a four-hour morning shift tomorrow
+	now := time.Now().In(events.Location())
+	start := time.Date(now.Year(), now.Month(), now.Day()+1, 9, 0, 0, 0, events.Location())
+	renderShiftForm(c, &models.Shift{StartsAt: start, EndsAt: start.Add(4 * time.Hour), Capacity: 4}, "")
+}
+
+// CreateShiftHandler saves a new shift
+func CreateShiftHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	shift := &models.Shift{SiteID: site.ID}
+	if errMsg := shiftFromRequest(c, shift); errMsg != "" {
+		renderShiftForm(c, shift, errMsg)
+		return
+	}
+
+	if err := db.GetDB().Create(shift).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to create shift")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/shifts")
+}
+
+// EditShiftFormHandler shows the form for editing a shift
+func EditShiftFormHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	shift, ok := loadSiteShift(c, siteVal.(*models.Site))
+	if !ok {
+		return
+	}
+	renderShiftForm(c, shift, "")
+}
+
+// UpdateShiftHandler saves changes to a shift. Existing sign-ups are kept even
+// if the capacity is lowered below them.
+func UpdateShiftHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	shift, ok := loadSiteShift(c, siteVal.(*models.Site))
+	if !ok {
+		return
+	}
+
+	oldStart := shift.StartsAt
+	if errMsg := shiftFromRequest(c, shift); errMsg != "" {
+		renderShiftForm(c, shift, errMsg)
+		return
+	}
+
+	if err := db.GetDB().Save(shift).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to update shift")
+		return
+	}
+
+	// A rescheduled shift deserves a fresh reminder
+	if !shift.StartsAt.Equal(oldStart) {
+		db.GetDB().Model(&models.ShiftSignup{}).Where("shift_id = ?", shift.ID).Update("reminder_sent_at", nil)
+	}
+
+	c.Redirect(http.StatusFound, "/admin/shifts")
+}
+
+// DeleteShiftHandler deletes a shift and its sign-ups
+func DeleteShiftHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	shift, ok := loadSiteShift(c, siteVal.(*models.Site))
+	if !ok {
+		return
+	}
+
+	if err := db.GetDB().Where("shift_id = ?", shift.ID).Delete(&models.ShiftSignup{}).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to delete sign-ups")
+		return
+	}
+	if err := db.GetDB().Delete(shift).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to delete shift")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/shifts")
+}
+
+// ShiftRosterHandler lists the volunteers signed up for a shift
+func ShiftRosterHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	shift, ok := loadSiteShift(c, siteVal.(*models.Site))
+	if !ok {
+		return
+	}
+
+	var signups []models.ShiftSignup
+	db.GetDB().Where("shift_id = ?", shift.ID).Order("created_at ASC").Find(&signups)
+
+	csrfToken := middleware.GetCSRFTokenHTML(c)
+
+	var tableRows strings.Builder
+	for _, signup := range signups {
+		reminded := "—"
+		if signup.ReminderSentAt != nil {
+			reminded = signup.ReminderSentAt.In(events.Location()).Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(&tableRows, `
+			<tr>
+				<td>%s</td>
+				<td><a href="mailto:%s">%s</a></td>
+				<td>%s</td>
+				<td>%s</td>
+				<td>
+					<form method="POST" action="/admin/shifts/signups/%d/delete" style="display: inline;" onsubmit="return confirm('Remove this volunteer from the shift?');">
+						%s
+						<button type="submit" class="btn btn-small btn-danger">Remove</button>
+					</form>
+				</td>
+			</tr>`, html.EscapeString(signup.Name), html.EscapeString(signup.Email), html.EscapeString(signup.Email),
+			signup.CreatedAt.In(events.Location()).Format("2006-01-02 15:04"), reminded, signup.ID, csrfToken)
+	}
+	if len(signups) == 0 {
+		tableRows.WriteString(`<tr><td colspan="5" style="text-align: center; color: #666;">Nobody has signed up yet.</td></tr>`)
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>%s Roster - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 1200px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>%s</h1>
+			<div class="header-actions">
+				<a href="/admin/shifts/export?shift=%d" class="btn btn-secondary">Export CSV</a>
+				<a href="/admin/shifts" class="btn btn-secondary">← Back to Shifts</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		<p>%s · %d of %d spots filled</p>
+		<div class="card">
+			<table class="data-table">
+				<thead>
+					<tr>
+						<th>Name</th>
+						<th>Email</th>
+						<th>Signed Up</th>
+						<th>Reminder Sent</th>
+						<th>Actions</th>
+					</tr>
+				</thead>
+				<tbody>
+					%s
+				</tbody>
+			</table>
+		</div>
+	</div>
+</body>
+</html>`, html.EscapeString(shift.Role), GetDesignSystemCSS(), html.EscapeString(shift.Role), shift.ID,
+		html.EscapeString(shifts.When(shift)), len(signups), shift.Capacity, tableRows.String())
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// ShiftSignupDeleteHandler removes a volunteer from a shift
+func ShiftSignupDeleteHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	var signup models.ShiftSignup
+	if err := db.GetDB().Where("id = ? AND site_id = ?", c.Param("id"), site.ID).First(&signup).Error; err != nil {
+		c.String(http.StatusNotFound, "Sign-up not found")
+		return
+	}
+
+	if err := db.GetDB().Delete(&signup).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to remove sign-up")
+		return
+	}
+
+	c.Redirect(http.StatusFound, fmt.Sprintf("/admin/shifts/%d", signup.ShiftID))
+}
+
+// ShiftRosterExportHandler downloads the roster as CSV: every shift, or one
+// shift if ?shift=<id> is given
+func ShiftRosterExportHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	query := db.GetDB().Where("site_id = ?", site.ID)
+	filename := fmt.Sprintf("shifts-%s.csv", site.Subdomain)
+	if shiftID := c.Query("shift"); shiftID != "" {
+		query = query.Where("id = ?", shiftID)
+		filename = fmt.Sprintf("shift-%s-%s.csv", shiftID, site.Subdomain)
+	}
+	var list []models.Shift
+	if err := query.Preload("Signups", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("created_at ASC")
+	}).Order("starts_at ASC").Find(&list).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load shifts")
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	loc := events.Location()
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"Role", "Starts", "Ends", "Capacity", "Name", "Email", "Signed Up"})
+	for _, shift := range list {
+		starts := shift.StartsAt.In(loc).Format("2006-01-02 15:04")
+		ends := shift.EndsAt.In(loc).Format("2006-01-02 15:04")
+		capacity := strconv.Itoa(shift.Capacity)
+		if len(shift.Signups) == 0 {
+			// Keep empty shifts in the roster so gaps are visible
+			w.Write([]string{csvSafe(shift.Role), starts, ends, capacity, "", "", ""})
+		}
+		for _, signup := range shift.Signups {
+			w.Write([]string{csvSafe(shift.Role), starts, ends, capacity,
+				csvSafe(signup.Name), csvSafe(signup.Email), signup.CreatedAt.In(loc).Format("2006-01-02 15:04")})
+		}
+	}
+	w.Flush()
+}
+
+// renderShiftsBlockEditor renders the edit screen for a volunteer shifts block
+func renderShiftsBlockEditor(c *gin.Context, site *models.Site, pageIDStr, blockIDStr, dataJSON string) string {
+	data, err := blocks.ParseShiftsBlockData(dataJSON)
+	if err != nil {
+		data = &blocks.ShiftsBlockData{}
+	}
+
+	// Offer the roles already in use
+	var roles []string
+	db.GetDB().Model(&models.Shift{}).Where("site_id = ?", site.ID).Distinct("role").Order("role").Pluck("role", &roles)
+	roleOptions := `<option value="">All roles</option>`
+	for _, role := range roles {
+		selected := ""
+		if role == data.Role {
+			selected = " selected"
+		}
+		roleOptions += fmt.Sprintf(`<option value="%s"%s>%s</option>`, html.EscapeString(role), selected, html.EscapeString(role))
+	}
+
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>Edit Volunteer Shifts Block</title>
+    <style>
+        body { font-family: system-ui, sans-serif; max-width: 800px; margin: 40px auto; padding: 0 20px; background: #f5f5f5; }
+        .container { background: white; padding: 30px; border-radius: 8px; box-shadow: 0 2px 4px rgba(0,0,0,0.1); }
+        h1 { color: #333; margin-top: 0; }
+        label { display: block; margin-bottom: 8px; font-weight: 600; color: #555; }
+        input[type="text"], select, textarea { width: 100%%; padding: 12px; border: 1px solid #ddd; border-radius: 4px; font-size: 14px; box-sizing: border-box; margin-bottom: 15px; font-family: system-ui; }
+        input:focus, select:focus, textarea:focus { outline: none; border-color: #2563eb; }
+        .help-text { font-size: 12px; color: #666; margin-top: -10px; margin-bottom: 15px; }
+        .button-group { margin-top: 20px; display: flex; gap: 10px; }
+        button { padding: 10px 20px; border: none; border-radius: 4px; cursor: pointer; font-size: 14px; font-weight: 600; }
+        button[type="submit"] { background: #2563eb; color: white; }
+        button[type="submit"]:hover { background: #1d4ed8; }
+        a.cancel { padding: 10px 20px; background: #6b7280; color: white; text-decoration: none; border-radius: 4px; font-size: 14px; font-weight: 600; }
+        a.cancel:hover { background: #4b5563; }
+        .note { background: #f0f4f8; padding: 15px; border-radius: 4px; margin-bottom: 20px; color: #555; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1>Edit Volunteer Shifts Block</h1>
+        <div class="note">
+            <strong>Note:</strong> This block lists upcoming shifts with a sign-up form for each. Shifts are managed on the <a href="/admin/shifts">Volunteer Shifts</a> screen.
+        </div>
+        <form method="POST" action="/admin/pages/%s/blocks/%s">
+            `+middleware.GetCSRFTokenHTML(c)+`
+            <label for="title">Title (optional):</label>
+            <input type="text" id="title" name="title" value="%s" placeholder="Volunteer">
+
+            <label for="description">Description (optional):</label>
+            <textarea id="description" name="description" rows="3">%s</textarea>
+
+            <label for="role">Show Shifts For:</label>
+            <select id="role" name="role">%s</select>
+            <p class="help-text">Limit this block to one role, e.g. a Kitchen page that only lists kitchen shifts</p>
+
+            <div class="button-group">
+                <button type="submit">Save &amp; Return</button>
+                <a href="/admin/pages/%s/edit" class="cancel">Cancel</a>
+            </div>
+        </form>
+    </div>
+</body>
+</html>`, pageIDStr, blockIDStr, html.EscapeString(data.Title), html.EscapeString(data.Description), roleOptions, pageIDStr)
+}
+
+// shiftsBlockDataFromRequest builds shifts block JSON from the posted editor form
+func shiftsBlockDataFromRequest(c *gin.Context) (string, error) {
+	data := blocks.ShiftsBlockData{
+		Title:       strings.TrimSpace(c.PostForm("title")),
+		Description: strings.TrimSpace(c.PostForm("description")),
+		Role:        c.PostForm("role"),
+	}
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
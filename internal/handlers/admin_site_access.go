@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/visibility"
+)
+
+// siteAccessBadge marks the Site Access button while the site is private
+func siteAccessBadge(site *models.Site) string {
+	if visibility.IsPublicSite(site) {
+		return ""
+	}
+	return ` <span style="background: #fff; color: #7c3aed; border-radius: 10px; padding: 0 8px; font-size: 12px; margin-left: 4px;">Private</span>`
+}
+
+// SiteAccessHandler shows who can visit the site
+func SiteAccessHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	renderSiteAccess(c, siteVal.(*models.Site), http.StatusOK, "")
+}
+
+// renderSiteAccess renders the site access settings, with an optional error message
+func renderSiteAccess(c *gin.Context, site *models.Site, status int, errMsg string) {
+	csrfToken := middleware.GetCSRFTokenHTML(c)
+
+	current := site.Visibility
+	if current == "" {
+		current = visibility.Public
+	}
+	descriptions := map[string]string{
+		visibility.Public:      "Anyone can visit the site.",
+		visibility.Password:    "Visitors enter a shared password once per browser. Good for a pre-launch preview.",
+		visibility.Members:     "Only people who can log in to this site's admin can visit it.",
+		visibility.IPAllowlist: "Only visitors from the site's allowed IP ranges can visit it.",
+	}
+	var options strings.Builder
+	for _, mode := range []string{visibility.Public, visibility.Password, visibility.Members, visibility.IPAllowlist} {
+		checked := ""
+		if mode == current {
+			checked = " checked"
+		}
+		fmt.Fprintf(&options, `
+				<label class="access-option">
+					<input type="radio" name="visibility" value="%s"%s>
+					<strong>%s</strong>
+					<span>%s</span>
+				</label>`, mode, checked, visibility.Label(mode), descriptions[mode])
+	}
+
+	passwordHint := "Share this password with the people you want to let in."
+	if site.PasswordHash != "" {
+		passwordHint = "A password is set. Leave blank to keep it, or enter a new one to lock out everyone who used the old one."
+	}
+
+	allowlistHTML := `<p style="color: #666;">No IP ranges yet. Add them with <code>stinky site allow-ip</code>.</p>`
+	if ranges := allowedIPs(site); len(ranges) > 0 {
+		var b strings.Builder
+		b.WriteString(`<ul>`)
+		for _, r := range ranges {
+			fmt.Fprintf(&b, `<li><code>%s</code></li>`, html.EscapeString(r))
+		}
+		b.WriteString(`</ul><p style="color: #666; font-size: 14px;">The allowlist also limits who can reach this admin. Change it with <code>stinky site allow-ip</code> and <code>stinky site remove-allowed-ip</code>.</p>`)
+		allowlistHTML = b.String()
+	}
+
+	errorHTML := ""
+	if errMsg != "" {
+		errorHTML = `<div style="background: #f8d7da; border: 1px solid #f5c6cb; color: #721c24; padding: 12px; border-radius: 4px; margin-bottom: 16px;">` + html.EscapeString(errMsg) + `</div>`
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Site Access - StinkyKitty</title>
+	<style>%s
+		body { padding: 0; }
+		.content-wrapper {
+			max-width: 1200px;
+			margin: 0 auto;
+			padding: var(--spacing-md);
+		}
+		.access-option { display: block; padding: 10px 0; border-bottom: 1px solid #eee; }
+		.access-option span { display: block; margin-left: 24px; color: #666; font-size: 14px; }
+	</style>
+</head>
+<body>
+	<div class="admin-header">
+		<div class="container">
+			<h1>Site Access</h1>
+			<div class="header-actions">
+				<a href="/admin/pages?site=%d" class="btn btn-secondary">← Back to Pages</a>
+			</div>
+		</div>
+	</div>
+
+	<div class="content-wrapper">
+		<p>Keep the whole site private while it's being built, or for invite-only camps. Private sites show visitors a login page with your site's name and colors, and <code>robots.txt</code> asks search engines to stay away. Logged-in members can always see the site.</p>
+		%s
+		<div class="card">
+			<h2>Who Can Visit</h2>
+			<form method="POST" action="/admin/access">
+				%s
+				%s
+				<div style="margin: 16px 0;">
+					<label for="site_password" style="display: block; font-weight: 600; margin-bottom: 4px;">Site Password</label>
+					<input type="password" id="site_password" name="password" autocomplete="new-password">
+					<small style="display: block; color: #666;">%s</small>
+				</div>
+				<button type="submit" class="btn">Save Access</button>
+			</form>
+		</div>
+
+		<div class="card">
+			<h2>IP Allowlist</h2>
+			%s
+		</div>
+	</div>
+</body>
+</html>`, GetDesignSystemCSS(), site.ID, errorHTML, csrfToken, options.String(), html.EscapeString(passwordHint), allowlistHTML)
+
+	c.Data(status, "text/html; charset=utf-8", []byte(htmlContent))
+}
+
+// UpdateSiteAccessHandler saves who can visit the site
+func UpdateSiteAccessHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	if err := SetSiteVisibility(site, c.PostForm("visibility"), c.PostForm("password")); err != nil {
+		if errors.Is(err, visibility.ErrInvalidSiteMode) || errors.Is(err, visibility.ErrSitePasswordRequired) ||
+			errors.Is(err, visibility.ErrAllowlistRequired) {
+			renderSiteAccess(c, site, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("Error updating access for site %d: %v", site.ID, err)
+		renderSiteAccess(c, site, http.StatusInternalServerError, "Failed to save site access")
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/admin/access")
+}
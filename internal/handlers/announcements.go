@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/announcements"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// announcementColors maps a severity to its banner background and text colors
+var announcementColors = map[string][2]string{
+	announcements.Info:     {"#2563eb", "#ffffff"},
+	announcements.Warning:  {"#f59e0b", "#1f2937"},
+	announcements.Critical: {"#dc2626", "#ffffff"},
+}
+
+// announcementDismissScript hides banners the visitor already dismissed and
+// remembers new dismissals in the browser, so pages stay the same for everyone
+const announcementDismissScript = `<script>
+(function() {
+	document.querySelectorAll('[data-announcement]').forEach(function(el) {
+		var key = 'stinky-announcement-' + el.getAttribute('data-announcement');
+		try { if (localStorage.getItem(key)) { el.remove(); return; } } catch (e) {}
+		var btn = el.querySelector('.site-announcement-dismiss');
+		if (btn) btn.addEventListener('click', function() {
+			try { localStorage.setItem(key, '1'); } catch (e) {}
+			el.remove();
+		});
+	});
+})();
+</script>`
+
+// renderAnnouncements renders the banners a site is showing right now. Offline
+// copies and archives leave them out, since they're only news at the time.
+func renderAnnouncements(c *gin.Context, site *models.Site) string {
+	if c.GetBool("staticExport") {
+		return ""
+	}
+	list, err := announcements.Active(db.GetDB(), site.ID, time.Now())
+	if err != nil || len(list) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	dismissible := false
+	for _, a := range list {
+		colors, ok := announcementColors[a.Severity]
+		if !ok {
+			colors = announcementColors[announcements.Info]
+		}
+		role := "status"
+		if a.Severity == announcements.Critical {
+			role = "alert"
+		}
+
+		linkHTML := ""
+		if a.LinkURL != "" {
+			text := a.LinkText
+			if text == "" {
+				text = "Learn more"
+			}
+			linkHTML = fmt.Sprintf(` <a href="%s" style="color: inherit; text-decoration: underline; font-weight: 600;">%s</a>`,
+				html.EscapeString(a.LinkURL), html.EscapeString(text))
+		}
+		dismissHTML := ""
+		if a.Dismissible {
+			dismissible = true
+			dismissHTML = `<button type="button" class="site-announcement-dismiss" aria-label="Dismiss" style="background: none; border: none; color: inherit; font-size: 20px; line-height: 1; cursor: pointer; padding: 0 4px;">&times;</button>`
+		}
+
+		fmt.Fprintf(&b, `
+<div class="site-announcement site-announcement-%s" role="%s" data-announcement="%d" style="background: %s; color: %s; padding: 10px 20px; margin: 0 -20px; display: flex; gap: 12px; align-items: center; justify-content: center; font-size: 15px;">
+	<span>%s%s</span>
+	%s
+</div>`, a.Severity, role, a.ID, colors[0], colors[1], html.EscapeString(a.Message), linkHTML, dismissHTML)
+	}
+	if dismissible {
+		b.WriteString(announcementDismissScript)
+	}
+	return b.String()
+}
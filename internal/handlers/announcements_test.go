@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+func TestAnnouncementBanner_OnPublicPages(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	if err := testDB.AutoMigrate(&models.Announcement{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/about", Title: "About", Published: true})
+
+	c, _ := postFormWithSite(site, "/admin/announcements", nil, url.Values{
+		"message":     {"Gate closed <due> to weather"},
+		"severity":    {"critical"},
+		"link_url":    {"/weather"},
+		"dismissible": {"1"},
+	}, CreateAnnouncementHandler)
+	if c.Writer.Status() != http.StatusFound {
+		t.Fatalf("Expected redirect after posting, got %d", c.Writer.Status())
+	}
+
+	w := getWithSite(site, "/about", nil, ServePage)
+	body := w.Body.String()
+	if !strings.Contains(body, "Gate closed &lt;due&gt; to weather") || !strings.Contains(body, `role="alert"`) ||
+		!strings.Contains(body, `href="/weather"`) || !strings.Contains(body, "site-announcement-dismiss") {
+		t.Fatalf("Expected an escaped, dismissible critical banner, got:\n%s", body)
+	}
+
+	c, _ = postFormWithSite(site, "/admin/announcements/1/end", gin.Params{{Key: "id", Value: "1"}}, nil, EndAnnouncementHandler)
+	if c.Writer.Status() != http.StatusFound {
+		t.Fatalf("Expected redirect after ending, got %d", c.Writer.Status())
+	}
+	w = getWithSite(site, "/about", nil, ServePage)
+	if strings.Contains(w.Body.String(), "Gate closed") {
+		t.Error("Expected an ended announcement to come down right away")
+	}
+}
+
+func TestAnnouncementBanner_LeftOutOfExports(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	if err := testDB.AutoMigrate(&models.Announcement{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	testDB.Create(&models.Announcement{SiteID: site.ID, Severity: "info", Message: "Ice at center camp"})
+	page := &models.Page{SiteID: site.ID, Slug: "/about", Title: "About", Published: true}
+	testDB.Create(page)
+
+	doc, err := renderPageForExport(site, page)
+	if err != nil {
+		t.Fatalf("renderPageForExport: %v", err)
+	}
+	if strings.Contains(string(doc), "Ice at center camp") {
+		t.Error("Expected offline copies to leave out announcements")
+	}
+}
@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/seo"
+	"github.com/thatcatcamp/stinkykitty/internal/staticsite"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrArchiveExists is returned when a site already has an archive for the year
+	ErrArchiveExists = errors.New("an archive for that year already exists")
+	// ErrInvalidArchiveYear is returned for years that can't be archived
+	ErrInvalidArchiveYear = errors.New("year must be between 1990 and next year")
+)
+
+// archiveRoot is the path an archive is served under
+func archiveRoot(year int) string {
+	return fmt.Sprintf("/archive/%d", year)
+}
+
+// archiveAssetsDir is where an archive's copies of its media are kept, so
+// later changes to the media library don't affect it
+func archiveAssetsDir(siteID uint, year int) string {
+	return filepath.Join(media.MediaDir(), "archives", strconv.FormatUint(uint64(siteID), 10), strconv.Itoa(year))
+}
+
+// archiveBanner is shown at the top of every archived page
+func archiveBanner(site *models.Site, archive *models.Archive) string {
+	return fmt.Sprintf(`<div class="archive-banner" style="background: var(--color-secondary, #475569); color: var(--color-secondary-contrast, #fff); padding: 10px 20px; margin: 0 -20px; text-align: center; font-size: 14px;">
+	You're viewing <strong>%s</strong>, an archived copy of %s from %d.
+	<a href="/" style="color: inherit; text-decoration: underline;">Go to the current site</a> ·
+	<a href="/archive" style="color: inherit; text-decoration: underline;">All archives</a>
+</div>`, html.EscapeString(archive.Title), html.EscapeString(seo.SiteName(site)), archive.Year)
+}
+
+// CreateArchive freezes a site's published pages into a read-only archive
+// for the given year. Pages are stored as rendered HTML with links pointing
+// into the archive, and the media they use is copied, so later edits to the
+// live site don't change the archive.
+func CreateArchive(site *models.Site, year int, title string) (*models.Archive, error) {
+	if year < 1990 || year > time.Now().Year()+1 {
+		return nil, ErrInvalidArchiveYear
+	}
+	var count int64
+	db.GetDB().Model(&models.Archive{}).Where("site_id = ? AND year = ?", site.ID, year).Count(&count)
+	if count > 0 {
+		return nil, ErrArchiveExists
+	}
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = fmt.Sprintf("%s %d", seo.SiteName(site), year)
+	}
+
+	sitePages, err := loadPublicPages(site.ID)
+	if err != nil {
+		return nil, err
+	}
+	slugs := []string{staticsite.SearchSlug}
+	for _, page := range sitePages {
+		slugs = append(slugs, page.Slug)
+	}
+	rewriter := staticsite.NewRewriter("", slugs)
+	rewriter.Root = archiveRoot(year)
+
+	archive := &models.Archive{SiteID: site.ID, Year: year, Title: title}
+	banner := archiveBanner(site, archive)
+	for i := range sitePages {
+		page := &sitePages[i]
+		doc, err := renderPageForExport(site, page)
+		if err != nil {
+			return nil, err
+		}
+		doc = rewriter.Rewrite(page.Slug, doc)
+		archive.Pages = append(archive.Pages, models.ArchivePage{
+			Slug:  page.Slug,
+			Title: page.Title,
+			HTML:  strings.Replace(string(doc), "<body>", "<body>\n"+banner, 1),
+			Text:  staticsite.PlainText(renderBlocks(site, page.Blocks)),
+		})
+	}
+
+	assetsDir := archiveAssetsDir(site.ID, year)
+	missing, err := staticsite.CopyAssets(media.OpenUpload, assetsDir, rewriter.Assets())
+	if err != nil {
+		os.RemoveAll(assetsDir)
+		return nil, err
+	}
+	for _, name := range missing {
+		fmt.Printf("Warning: archive %d of site %d skipped missing file %s\n", year, site.ID, name)
+	}
+
+	if err := db.GetDB().Create(archive).Error; err != nil {
+		os.RemoveAll(assetsDir)
+		return nil, fmt.Errorf("failed to save archive: %w", err)
+	}
+	return archive, nil
+}
+
+// DeleteArchive removes an archive, its pages and its copies of media
+func DeleteArchive(archive *models.Archive) error {
+	err := db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("archive_id = ?", archive.ID).Delete(&models.ArchivePage{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(archive).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete archive: %w", err)
+	}
+	os.RemoveAll(archiveAssetsDir(archive.SiteID, archive.Year))
+	return nil
+}
+
+// ArchivesIndexHandler lists a site's archives
+func ArchivesIndexHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	var archives []models.Archive
+	db.GetDB().Where("site_id = ?", site.ID).Order("year DESC").Find(&archives)
+
+	var b strings.Builder
+	if len(archives) == 0 {
+		b.WriteString(`<p>There are no archives yet.</p>`)
+	} else {
+		b.WriteString(`<ul>`)
+		for _, a := range archives {
+			fmt.Fprintf(&b, `<li><a href="%s/">%s</a> (%d)</li>`, archiveRoot(a.Year), html.EscapeString(a.Title), a.Year)
+		}
+		b.WriteString(`</ul>`)
+	}
+	renderSimplePage(c, site, http.StatusOK, "Archives", b.String())
+}
+
+// ArchivePageHandler serves an archived page, its media or the archive search
+func ArchivePageHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		ServePage(c)
+		return
+	}
+	var archive models.Archive
+	if err := db.GetDB().Where("site_id = ? AND year = ?", site.ID, year).First(&archive).Error; err != nil {
+		ServePage(c)
+		return
+	}
+
+	slug := "/" + strings.Trim(c.Param("path"), "/")
+	switch {
+	case strings.HasPrefix(slug, "/assets/"):
+		serveArchiveAsset(c, &archive, strings.TrimPrefix(slug, "/assets/"))
+		return
+	case slug == staticsite.SearchSlug:
+		archiveSearch(c, site, &archive)
+		return
+	}
+
+	var page models.ArchivePage
+	if err := db.GetDB().Where("archive_id = ? AND slug = ?", archive.ID, slug).First(&page).Error; err != nil {
+		renderSimplePage(c, site, http.StatusNotFound, "Page Not Found",
+			fmt.Sprintf(`<p>That page isn't part of the %d archive.</p><p><a href="%s/">Archive home</a></p>`, archive.Year, archiveRoot(archive.Year)))
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page.HTML))
+}
+
+// serveArchiveAsset serves an archive's copy of a media file
+func serveArchiveAsset(c *gin.Context, archive *models.Archive, name string) {
+	dir := filepath.Clean(archiveAssetsDir(archive.SiteID, archive.Year))
+	filePath := filepath.Clean(filepath.Join(dir, "assets", filepath.FromSlash(name)))
+	if !strings.HasPrefix(filePath, dir+string(os.PathSeparator)) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	c.File(filePath)
+}
+
+// archiveSearch searches the pages of one archive. Archives are kept out of
+// the live search index, so this is a simple match on the stored page text.
+func archiveSearch(c *gin.Context, site *models.Site, archive *models.Archive) {
+	query := strings.TrimSpace(c.Query("q"))
+	form := fmt.Sprintf(`<form method="GET" action="%s/search" style="display: flex; gap: 10px; margin-bottom: 20px;">
+	<input type="text" name="q" value="%s" placeholder="Search the %d archive..." style="flex: 1; padding: 10px; font-size: 16px;">
+	<button type="submit" class="btn">Search</button>
+</form>`, archiveRoot(archive.Year), html.EscapeString(query), archive.Year)
+
+	var b strings.Builder
+	b.WriteString(archiveBanner(site, archive))
+	b.WriteString(form)
+	if query != "" {
+		q := db.GetDB().Where("archive_id = ?", archive.ID)
+		for _, word := range strings.Fields(strings.ToLower(query)) {
+			like := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(word) + "%"
+			q = q.Where(`(LOWER(title) LIKE ? ESCAPE '\' OR LOWER(text) LIKE ? ESCAPE '\')`, like, like)
+		}
+		var results []models.ArchivePage
+		q.Select("slug", "title", "text").Order("title ASC").Limit(50).Find(&results)
+
+		if len(results) == 0 {
+			b.WriteString(`<p>No pages found.</p>`)
+		}
+		for _, r := range results {
+			href := archiveRoot(archive.Year) + r.Slug
+			if r.Slug == "/" {
+				href += "/"
+			}
+			snippet := r.Text
+			if len([]rune(snippet)) > 200 {
+				snippet = string([]rune(snippet)[:200]) + "…"
+			}
+			fmt.Fprintf(&b, `<div style="margin-bottom: 20px;"><h3 style="margin: 0;"><a href="%s">%s</a></h3><p style="margin: 4px 0;">%s</p></div>`,
+				html.EscapeString(href), html.EscapeString(r.Title), html.EscapeString(snippet))
+		}
+	}
+	renderSimplePage(c, site, http.StatusOK, fmt.Sprintf("Search the %d Archive", archive.Year), b.String())
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+func TestCreateArchive_FrozenCopy(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	if err := testDB.AutoMigrate(&models.Archive{}, &models.ArchivePage{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	config.InitConfig(filepath.Join(t.TempDir(), "config.yaml"))
+	mediaDir := t.TempDir()
+	config.Set("storage.media_dir", mediaDir)
+	os.MkdirAll(filepath.Join(mediaDir, "uploads"), 0755)
+	os.WriteFile(filepath.Join(mediaDir, "uploads", "camp.jpg"), []byte("jpeg"), 0644)
+
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/", Title: "Welcome", Published: true})
+	info := &models.Page{SiteID: site.ID, Slug: "/info", Title: "Info", Published: true}
+	testDB.Create(info)
+	testDB.Create(&models.Block{PageID: info.ID, Type: "text", Order: 0, Data: `{"content":"Gate opens Sunday"}`})
+	testDB.Create(&models.Block{PageID: info.ID, Type: "image", Order: 1, Data: `{"url":"/assets/camp.jpg","alt":"Camp"}`})
+	testDB.Create(&models.MenuItem{SiteID: site.ID, Label: "Info", URL: "/info"})
+
+	if _, err := CreateArchive(site, 2025, ""); err != nil {
+		t.Fatalf("CreateArchive failed: %v", err)
+	}
+	if _, err := CreateArchive(site, 2025, ""); !errors.Is(err, ErrArchiveExists) {
+		t.Errorf("Expected ErrArchiveExists, got %v", err)
+	}
+
+	// Edits to the live site, including media, don't reach the archive
+	testDB.Model(&models.Block{}).Where("page_id = ? AND type = ?", info.ID, "text").Update("data", `{"content":"Gate opens Monday"}`)
+	os.Remove(filepath.Join(mediaDir, "uploads", "camp.jpg"))
+
+	params := gin.Params{{Key: "year", Value: "2025"}, {Key: "path", Value: "/info"}}
+	w := getWithSite(site, "/archive/2025/info", params, ArchivePageHandler)
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, "Gate opens Sunday") {
+		t.Fatalf("Expected the archived text, got %d:\n%s", w.Code, body)
+	}
+	for _, want := range []string{`class="archive-banner"`, `href="/archive/2025/info"`, `src="/archive/2025/assets/camp.jpg"`, `action="/archive/2025/search"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %s in archived page", want)
+		}
+	}
+
+	w = getWithSite(site, "/archive/2025/assets/camp.jpg", gin.Params{{Key: "year", Value: "2025"}, {Key: "path", Value: "/assets/camp.jpg"}}, ArchivePageHandler)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected the archive's copy of the image, got %d", w.Code)
+	}
+
+	w = getWithSite(site, "/archive/2025/search?q=sunday", gin.Params{{Key: "year", Value: "2025"}, {Key: "path", Value: "/search"}}, ArchivePageHandler)
+	if !strings.Contains(w.Body.String(), `href="/archive/2025/info"`) {
+		t.Errorf("Expected archive search to find Info, got:\n%s", w.Body.String())
+	}
+	w = getWithSite(site, "/archive/2025/search?q=monday", gin.Params{{Key: "year", Value: "2025"}, {Key: "path", Value: "/search"}}, ArchivePageHandler)
+	if !strings.Contains(w.Body.String(), "No pages found") {
+		t.Errorf("Archive search must not see live edits, got:\n%s", w.Body.String())
+	}
+}
@@ -2,47 +2,138 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
+	"github.com/thatcatcamp/stinkykitty/internal/storage"
 )
 
-// ServeAssetHandler serves uploaded media from centralized storage
+// ServeAssetHandler serves uploaded media. Files on this server are served
+// directly; files in a bucket are redirected to a signed URL or proxied,
+// depending on storage.s3.serve.
 func ServeAssetHandler(c *gin.Context) {
 	// Get filename from URL
-	filename := c.Param("filepath")
+	filename := strings.TrimPrefix(c.Param("filepath"), "/")
 	if filename == "" {
 		c.AbortWithStatus(http.StatusNotFound)
 		return
 	}
 
-	// Strip leading slash if present
-	if len(filename) > 0 && filename[0] == '/' {
-		filename = filename[1:]
+	// CRITICAL: Prevent path traversal attacks
+	key, err := storage.CleanKey(media.UploadKey(filename))
+	if err != nil {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
 	}
 
-	// Get centralized media directory
-	mediaDir := config.GetString("storage.media_dir")
-	if mediaDir == "" {
-		mediaDir = "/var/lib/stinkykitty/media"
+	if media.WantsTransform(c.Request.URL.Query()) {
+		serveTransformed(c, filename)
+		return
 	}
 
-	// Build full path to file (files are in uploads subdirectory)
-	uploadsDir := filepath.Join(mediaDir, "uploads")
-	filePath := filepath.Join(uploadsDir, filename)
+	store, err := media.StorageForUpload(filename)
+	if err != nil {
+		log.Printf("Storage for %s unavailable: %v", filename, err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+	if local, ok := store.(*storage.Local); ok {
+		path, err := local.Path(key)
+		if err != nil {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.File(path)
+		return
+	}
+	serveStored(c, store, key)
+}
 
-	// CRITICAL: Prevent path traversal attacks
-	cleanPath := filepath.Clean(filePath)
-	cleanUploadsDir := filepath.Clean(uploadsDir)
-	if !strings.HasPrefix(cleanPath, cleanUploadsDir+string(os.PathSeparator)) {
-		c.AbortWithStatus(http.StatusForbidden)
+// serveTransformed sends a resized copy of an uploaded image, drawing and
+// caching it on first request
+func serveTransformed(c *gin.Context, filename string) {
+	if media.IsDocument(filename) {
+		c.String(http.StatusBadRequest, "Only images can be transformed")
 		return
 	}
+	if filename != media.UploadFilename(filename) {
+		c.String(http.StatusBadRequest, "Only original images can be transformed")
+		return
+	}
+	t, err := media.ParseTransform(c.Request.URL.Query())
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	path, err := media.TransformedImage(filename, t)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		log.Printf("Failed to transform %s: %v", filename, err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	// Uploads only change under the same name when "media scrub" turns a photo
+	// upright, which purges these copies, so they can be cached for long
+	c.Header("Cache-Control", "public, max-age=604800")
+	c.File(path)
+}
 
-	// Serve the file
-	c.File(cleanPath)
+// serveStored sends a file from remote storage, by redirect when the backend
+// can sign URLs and the config allows it, otherwise by streaming it through
+func serveStored(c *gin.Context, store storage.Storage, key string) {
+	if config.GetString("storage.s3.serve") != "proxy" {
+		expiry := config.GetDuration("storage.s3.url_expiry")
+		if expiry <= 0 {
+			expiry = time.Hour
+		}
+		link, err := store.SignedURL(key, expiry)
+		if err == nil {
+			// Let browsers reuse the redirect for a while, but not past the link's expiry
+			c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(expiry.Seconds()/2)))
+			c.Redirect(http.StatusFound, link)
+			return
+		}
+		if !errors.Is(err, storage.ErrSignedURLUnsupported) {
+			log.Printf("Failed to sign URL for %s: %v", key, err)
+		}
+	}
+
+	r, info, err := store.Get(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		log.Printf("Failed to fetch %s: %v", key, err)
+		c.AbortWithStatus(http.StatusBadGateway)
+		return
+	}
+	defer r.Close()
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	if !info.ModTime.IsZero() {
+		c.Header("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+	}
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	if info.Size >= 0 {
+		c.DataFromReader(http.StatusOK, info.Size, contentType, r, nil)
+		return
+	}
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", contentType)
+	io.Copy(c.Writer, r)
 }
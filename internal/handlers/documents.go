@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"mime/multipart"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/blocks"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// fileDownload returns the media library's details for a document, or nil
+// for files that aren't in it
+func fileDownload(url string) *blocks.FileDownload {
+	name := strings.TrimPrefix(url, "/assets/")
+	if name == url || name == "" || db.GetDB() == nil {
+		return nil
+	}
+	var item models.MediaItem
+	if err := db.GetDB().Where("filename = ?", name).First(&item).Error; err != nil {
+		return nil
+	}
+	return &blocks.FileDownload{
+		Name: item.OriginalName,
+		Size: media.FormatSize(item.FileSize),
+		Type: media.DocumentLabel(item.Filename),
+		Icon: media.DocumentIcon(item.Filename),
+	}
+}
+
+// renderFileBlockHTML renders a file download block with its file's name,
+// size and type from the media library
+func renderFileBlockHTML(dataJSON string) (string, error) {
+	data, err := blocks.ParseFileBlockData(dataJSON)
+	if err != nil {
+		return "", err
+	}
+	return blocks.RenderFileBlock(dataJSON, fileDownload(data.URL))
+}
+
+// libraryDocuments lists the documents in the media library, newest first.
+// Like images, they're shared across all sites.
+func libraryDocuments() []models.MediaItem {
+	var items []models.MediaItem
+	db.GetDB().Order("created_at DESC").Find(&items)
+	docs := items[:0]
+	for _, item := range items {
+		if media.IsDocument(item.Filename) {
+			docs = append(docs, item)
+		}
+	}
+	return docs
+}
+
+// documentAccept lists the allowed document extensions for a file input's
+// accept attribute, e.g. ".pdf,.docx"
+func documentAccept() string {
+	var exts []string
+	for _, ext := range media.AllowedDocumentTypes() {
+		exts = append(exts, "."+ext)
+	}
+	return strings.Join(exts, ",")
+}
+
+// renderFileBlockEditor renders the edit screen for file download blocks
+func renderFileBlockEditor(c *gin.Context, pageIDStr, blockIDStr, dataJSON string) string {
+	data, err := blocks.ParseFileBlockData(dataJSON)
+	if err != nil {
+		data = &blocks.FileBlockData{}
+	}
+
+	options := `<option value="">(choose a document)</option>`
+	for _, item := range libraryDocuments() {
+		url := "/assets/" + item.Filename
+		selected := ""
+		if url == data.URL {
+			selected = " selected"
+		}
+		options += fmt.Sprintf(`<option value="%s"%s>%s (%s, %s)</option>`, html.EscapeString(url), selected,
+			html.EscapeString(item.OriginalName), media.DocumentLabel(item.Filename), media.FormatSize(item.FileSize))
+	}
+
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>Edit File Download Block</title>
+    <style>
+        body { font-family: system-ui, sans-serif; max-width: 800px; margin: 40px auto; padding: 0 20px; background: #f5f5f5; }
+        .container { background: white; padding: 30px; border-radius: 8px; box-shadow: 0 2px 4px rgba(0,0,0,0.1); }
+        h1 { color: #333; margin-top: 0; }
+        label { display: block; margin-bottom: 8px; font-weight: 600; color: #555; }
+        input[type="text"], select, textarea { width: 100%%; padding: 12px; border: 1px solid #ddd; border-radius: 4px; font-size: 14px; box-sizing: border-box; margin-bottom: 15px; font-family: inherit; }
+        input[type="file"] { margin-bottom: 15px; }
+        input:focus, select:focus, textarea:focus { outline: none; border-color: #2563eb; }
+        .help-text { font-size: 13px; color: #666; margin: -10px 0 15px; }
+        .button-group { margin-top: 20px; display: flex; gap: 10px; }
+        button { padding: 10px 20px; border: none; border-radius: 4px; cursor: pointer; font-size: 14px; font-weight: 600; }
+        button[type="submit"] { background: #2563eb; color: white; }
+        button[type="submit"]:hover { background: #1d4ed8; }
+        a.cancel { padding: 10px 20px; background: #6b7280; color: white; text-decoration: none; border-radius: 4px; font-size: 14px; font-weight: 600; }
+        a.cancel:hover { background: #4b5563; }
+        .note { background: #f0f4f8; padding: 15px; border-radius: 4px; margin-bottom: 20px; color: #555; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1>Edit File Download Block</h1>
+        <div class="note">
+            <strong>Note:</strong> Visitors see the file's name, type and size with a download link. Allowed types: %s.
+        </div>
+        <form method="POST" action="/admin/pages/%s/blocks/%s" enctype="multipart/form-data">
+            %s
+            <label for="url">Document from Library:</label>
+            <select id="url" name="url">%s</select>
+
+            <label for="file">Or Upload a New Document:</label>
+            <input type="file" id="file" name="file" accept="%s">
+            <p class="help-text">Uploads are added to the <a href="/admin/media">Media Library</a>.</p>
+
+            <label for="title">Link Text (optional):</label>
+            <input type="text" id="title" name="title" value="%s" placeholder="Defaults to the file name">
+
+            <label for="description">Description (optional):</label>
+            <textarea id="description" name="description" rows="3">%s</textarea>
+
+            <div class="button-group">
+                <button type="submit">Save &amp; Return</button>
+                <a href="/admin/pages/%s/edit" class="cancel">Cancel</a>
+            </div>
+        </form>
+    </div>
+</body>
+</html>`, html.EscapeString(strings.Join(media.AllowedDocumentTypes(), ", ")), pageIDStr, blockIDStr,
+		middleware.GetCSRFTokenHTML(c), options, documentAccept(),
+		html.EscapeString(data.Title), html.EscapeString(data.Description), pageIDStr)
+}
+
+// fileBlockDataFromRequest builds file download block JSON from the posted
+// editor form, adding a newly uploaded document to the media library
+func fileBlockDataFromRequest(c *gin.Context, site *models.Site, user *models.User) (string, error) {
+	data := blocks.FileBlockData{
+		URL:         c.PostForm("url"),
+		Title:       strings.TrimSpace(c.PostForm("title")),
+		Description: strings.TrimSpace(c.PostForm("description")),
+	}
+
+	if fileHeader, err := c.FormFile("file"); err == nil && fileHeader != nil {
+		url, err := addDocumentToLibrary(site, user, fileHeader)
+		if err != nil {
+			return "", err
+		}
+		data.URL = url
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
+
+// addDocumentToLibrary stores an uploaded document and records it in the
+// media library, returning its URL. A copy already in the library is reused.
+func addDocumentToLibrary(site *models.Site, user *models.User, fileHeader *multipart.FileHeader) (string, error) {
+	if !media.IsDocument(fileHeader.Filename) {
+		return "", fmt.Errorf("%s is not a document", fileHeader.Filename)
+	}
+	if err := checkUploadSize(fileHeader.Filename, fileHeader.Size); err != nil {
+		return "", err
+	}
+	upload, err := media.PrepareMedia(fileHeader)
+	if err != nil {
+		return "", err
+	}
+	if existing := media.FindDuplicate(db.GetDB(), site.ID, upload.Hash); existing != nil {
+		return "/assets/" + existing.Filename, nil
+	}
+
+	store, bucket, err := media.StorageForSite(site)
+	if err != nil {
+		return "", err
+	}
+	mediaItem, err := addToLibrary(site, user, store, bucket, fileHeader.Filename, upload)
+	if err != nil {
+		return "", err
+	}
+	media.UpdateQuotaAlerts(db.GetDB(), site)
+	return "/assets/" + mediaItem.Filename, nil
+}
@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/blocks"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/events"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// loadSiteEvents returns all events for a site, ordered by start time
+func loadSiteEvents(siteID uint) []models.Event {
+	var evs []models.Event
+	if err := db.GetDB().Where("site_id = ?", siteID).Order("starts_at ASC").Find(&evs).Error; err != nil {
+		log.Printf("Error loading events for site %d: %v", siteID, err)
+	}
+	return evs
+}
+
+// renderEventsBlock renders an events-list block with the site's upcoming events
+func renderEventsBlock(site *models.Site, dataJSON string) (string, error) {
+	data, err := blocks.ParseEventsBlockData(dataJSON)
+	if err != nil {
+		return "", err
+	}
+	upcoming := events.Upcoming(loadSiteEvents(site.ID), time.Now(), data.Limit)
+	return blocks.RenderEventsBlock(dataJSON, upcoming)
+}
+
+// renderCalendarBlock renders a month-calendar block for the current month
+func renderCalendarBlock(site *models.Site, dataJSON string) (string, error) {
+	from, to := events.MonthRange(time.Now())
+	return blocks.RenderCalendarBlock(dataJSON, from, events.Between(loadSiteEvents(site.ID), from, to))
+}
+
+// EventsPageHandler shows a month calendar and the list of upcoming events.
+// An optional ?month=YYYY-MM selects which month the calendar shows.
+func EventsPageHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	month := time.Now()
+	if m := c.Query("month"); m != "" {
+		if parsed, err := time.ParseInLocation("2006-01", m, events.Location()); err == nil {
+			month = parsed
+		}
+	}
+
+	evs := loadSiteEvents(site.ID)
+	from, to := events.MonthRange(month)
+
+	calendarHTML, err := blocks.RenderCalendarBlock(`{}`, from, events.Between(evs, from, to))
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to render calendar")
+		return
+	}
+	listHTML, err := blocks.RenderEventsBlock(`{"title":"Upcoming","limit":20}`, events.Upcoming(evs, time.Now(), 20))
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to render events")
+		return
+	}
+
+	renderSimplePage(c, site, http.StatusOK, "Events", calendarHTML+listHTML)
+}
+
+// EventDetailHandler shows a single event. An optional ?date=YYYY-MM-DD picks
+// which occurrence of a repeating event to show; otherwise the next one is shown.
+func EventDetailHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	var ev models.Event
+	if err := db.GetDB().Where("id = ? AND site_id = ?", id, site.ID).First(&ev).Error; err != nil {
+		renderSimplePage(c, site, http.StatusNotFound, "Event Not Found",
+			`<p>This event may have been removed.</p><p><a href="/events">See all events</a></p>`)
+		return
+	}
+
+	var occ *events.Occurrence
+	if d := c.Query("date"); d != "" {
+		if day, err := time.ParseInLocation("2006-01-02", d, events.Location()); err == nil {
+			if occs := events.Expand(&ev, day, day.AddDate(0, 0, 1)); len(occs) > 0 {
+				occ = &occs[0]
+			}
+		}
+	}
+	if occ == nil {
+		if occs := events.Upcoming([]models.Event{ev}, time.Now(), 1); len(occs) > 0 {
+			occ = &occs[0]
+		}
+	}
+	if occ == nil {
+		// Past event: show its first occurrence
+		occ = &events.Occurrence{Event: &ev, Start: ev.StartsAt.In(events.Location()), End: ev.EndsAt.In(events.Location())}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<p style="font-size: 1.1em;"><strong>When:</strong> %s</p>`, html.EscapeString(occ.When()))
+	if ev.Recurrence != events.RecurNone {
+		for _, opt := range events.RecurrenceLabels {
+			if opt.Value == ev.Recurrence {
+				fmt.Fprintf(&b, `<p><strong>Repeats:</strong> %s`, opt.Label)
+				if ev.RecurUntil != nil {
+					fmt.Fprintf(&b, " until %s", ev.RecurUntil.In(events.Location()).Format("Jan 2, 2006"))
+				}
+				b.WriteString("</p>")
+			}
+		}
+	}
+	if ev.Location != "" {
+		fmt.Fprintf(&b, `<p><strong>Where:</strong> %s</p>`, html.EscapeString(ev.Location))
+	}
+	if ev.Description != "" {
+		fmt.Fprintf(&b, `<div style="margin: 20px 0;">%s</div>`,
+			strings.ReplaceAll(html.EscapeString(ev.Description), "\n", "<br>"))
+	}
+	b.WriteString(`<p><a href="/events">&larr; All events</a> · <a href="/events.ics">Subscribe (iCal)</a></p>`)
+
+	renderSimplePage(c, site, http.StatusOK, ev.Title, b.String())
+}
+
+// EventsICSHandler serves the site's events as an iCalendar feed that phone and
+// desktop calendar apps can subscribe to
+func EventsICSHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	name := site.SiteTitle
+	if name == "" {
+		name = site.Subdomain
+	}
+
+	var buf bytes.Buffer
+	if err := events.WriteICS(&buf, name, siteDomain(site), loadSiteEvents(site.ID)); err != nil {
+		c.String(http.StatusInternalServerError, "Failed to generate calendar")
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="events.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
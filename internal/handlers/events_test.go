@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+)
+
+func setupEventsTest(t *testing.T) (*gorm.DB, *models.Site) {
+	gin.SetMode(gin.TestMode)
+	testDB := setupTestDB(t)
+	if err := testDB.AutoMigrate(&models.User{}, &models.MenuItem{}, &models.Event{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	db.SetDB(testDB)
+
+	site := &models.Site{ID: 1, Subdomain: "test", OwnerID: 1, SiteDir: "/tmp/test", SiteTitle: "Test Camp"}
+	testDB.Create(site)
+	return testDB, site
+}
+
+func TestEventsICSHandler(t *testing.T) {
+	testDB, site := setupEventsTest(t)
+
+	start := time.Now().Add(48 * time.Hour).UTC()
+	testDB.Create(&models.Event{SiteID: site.ID, Title: "Build Weekend", StartsAt: start, EndsAt: start.Add(time.Hour), Recurrence: "weekly"})
+	testDB.Create(&models.Event{SiteID: 2, Title: "Other Camp Party", StartsAt: start, EndsAt: start.Add(time.Hour)})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/events.ics", nil)
+	c.Set("site", site)
+
+	EventsICSHandler(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("Expected text/calendar content type, got %s", ct)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "SUMMARY:Build Weekend") || !strings.Contains(body, "RRULE:FREQ=WEEKLY") {
+		t.Errorf("Expected feed to contain the site's event, got:\n%s", body)
+	}
+	if strings.Contains(body, "Other Camp Party") {
+		t.Error("Feed must not include another site's events")
+	}
+}
+
+func TestEventDetailHandler_Occurrence(t *testing.T) {
+	testDB, site := setupEventsTest(t)
+
+	start := time.Date(2025, 1, 6, 19, 0, 0, 0, time.UTC)
+	ev := &models.Event{SiteID: site.ID, Title: "Camp Meeting", Description: "<script>x</script>", StartsAt: start, EndsAt: start.Add(time.Hour), Recurrence: "weekly"}
+	testDB.Create(ev)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/events/1?date=2025-02-03", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set("site", site)
+
+	EventDetailHandler(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "Mon, Feb 3, 2025") {
+		t.Error("Expected the requested occurrence date to be shown")
+	}
+	if strings.Contains(body, "<script>x</script>") {
+		t.Error("Expected description to be escaped")
+	}
+}
+
+func TestRenderBlockHTML_EventsBlock(t *testing.T) {
+	testDB, site := setupEventsTest(t)
+
+	start := time.Now().Add(24 * time.Hour)
+	testDB.Create(&models.Event{SiteID: site.ID, Title: "Potluck", StartsAt: start, EndsAt: start.Add(time.Hour)})
+
+	out, err := renderBlockHTML(site, models.Block{Type: "events", Data: `{"title":"Coming Up","limit":3}`})
+	if err != nil {
+		t.Fatalf("renderBlockHTML failed: %v", err)
+	}
+	if !strings.Contains(out, "Potluck") {
+		t.Error("Expected upcoming event in events block")
+	}
+}
+
+func TestCreateEventHandler(t *testing.T) {
+	testDB, site := setupEventsTest(t)
+
+	form := url.Values{
+		"title":       {"Burn Week"},
+		"start_date":  {"2025-08-24"},
+		"end_date":    {"2025-09-01"},
+		"all_day":     {"1"},
+		"recurrence":  {"yearly"},
+		"recur_until": {"2030-12-31"},
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/events", strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Set("site", site)
+
+	CreateEventHandler(c)
+
+	if c.Writer.Status() != http.StatusFound {
+		t.Fatalf("Expected redirect, got %d: %s", c.Writer.Status(), w.Body.String())
+	}
+	var ev models.Event
+	if err := testDB.First(&ev).Error; err != nil {
+		t.Fatalf("Expected event to be created: %v", err)
+	}
+	if !ev.AllDay || ev.Recurrence != "yearly" || ev.RecurUntil == nil || ev.SiteID != site.ID {
+		t.Errorf("Unexpected event: %+v", ev)
+	}
+}
+
+func TestCreateEventHandler_EndBeforeStart(t *testing.T) {
+	testDB, site := setupEventsTest(t)
+
+	form := url.Values{
+		"title":      {"Backwards"},
+		"start_date": {"2025-08-24"},
+		"start_time": {"18:00"},
+		"end_time":   {"17:00"},
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/events", strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Set("site", site)
+
+	CreateEventHandler(c)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	var count int64
+	testDB.Model(&models.Event{}).Count(&count)
+	if count != 0 {
+		t.Error("Expected no event to be created")
+	}
+}
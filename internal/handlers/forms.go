@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/blocks"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/email"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// loadPublicFormBlock loads a form block and its page, ensuring both belong to
+// the site and that the page is published
+func loadPublicFormBlock(site *models.Site, blockID int) (*models.Block, *models.Page, error) {
+	var block models.Block
+	if err := db.GetDB().Where("id = ? AND type = ?", blockID, "form").First(&block).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var page models.Page
+	if err := db.GetDB().Where("id = ? AND site_id = ? AND published = ?", block.PageID, site.ID, true).First(&page).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return &block, &page, nil
+}
+
+// FormSubmitHandler stores a visitor's response to a form block
+func FormSubmitHandler(c *gin.Context) {
+	// Get site from context
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	blockID, err := strconv.Atoi(c.Param("block_id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid form")
+		return
+	}
+
+	block, page, err := loadPublicFormBlock(site, blockID)
+	if err != nil {
+		c.String(http.StatusNotFound, "Form not found")
+		return
+	}
+
+	if rejectSpam(c, site) {
+		return
+	}
+
+	formData, err := blocks.ParseFormBlockData(block.Data)
+	if err != nil {
+		log.Printf("Error parsing form block %d: %v", block.ID, err)
+		c.String(http.StatusInternalServerError, "Error processing form")
+		return
+	}
+
+	values, errs := formData.ValidateSubmission(c.PostForm)
+	if len(errs) > 0 {
+		var list strings.Builder
+		for _, e := range errs {
+			list.WriteString("<li>" + html.EscapeString(e) + "</li>")
+		}
+		renderSimplePage(c, site, http.StatusBadRequest, "Please fix the following",
+			fmt.Sprintf(`<div class="error-message"><ul>%s</ul></div>
+		<p><a href="javascript:history.back()">← Go back and try again</a></p>`, list.String()))
+		return
+	}
+
+	submission := models.FormSubmission{
+		SiteID:    site.ID,
+		PageID:    page.ID,
+		BlockID:   block.ID,
+		IPAddress: c.ClientIP(),
+	}
+	if err := submission.SetValues(values); err != nil {
+		c.String(http.StatusInternalServerError, "Error processing form")
+		return
+	}
+	release, ok := consumeSpamToken(c, site)
+	if !ok {
+		return
+	}
+	if err := db.GetDB().Create(&submission).Error; err != nil {
+		release()
+		log.Printf("Error saving form submission: %v", err)
+		c.String(http.StatusInternalServerError, "Error processing form")
+		return
+	}
+
+	if formData.NotifyEmail != "" {
+		sendFormNotification(site, page, formData, values)
+	}
+
+	renderSimplePage(c, site, http.StatusOK, "Thank You",
+		fmt.Sprintf(`<div class="success-message">%s</div>
+		<p><a href="%s">← Back to %s</a></p>`,
+			html.EscapeString(formData.ConfirmationMessage), html.EscapeString(page.Slug), html.EscapeString(page.Title)))
+}
+
+// sendFormNotification queues a copy of a submission for the form's notify address
+func sendFormNotification(site *models.Site, page *models.Page, formData *blocks.FormBlockData, values map[string]string) {
+	title := formData.Title
+	if title == "" {
+		title = page.Title
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "New submission to \"%s\" on %s:\n\n", title, site.Subdomain)
+	for _, field := range formData.Fields {
+		fmt.Fprintf(&body, "%s: %s\n", field.Label, values[field.Name])
+	}
+	body.WriteString("\n---\nView all submissions in the admin panel under Forms.")
+
+	subject := fmt.Sprintf("Form Submission: %s", title)
+	if err := email.Enqueue(db.GetDB(), formData.NotifyEmail, subject, body.String()); err != nil {
+		log.Printf("Error queueing form notification: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+)
+
+func setupFormTest(t *testing.T) (*gorm.DB, *models.Site, *models.Block) {
+	gin.SetMode(gin.TestMode)
+	testDB := setupTestDB(t)
+	if err := testDB.AutoMigrate(&models.MenuItem{}, &models.FormSubmission{}, &models.SpamCounter{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	db.SetDB(testDB)
+
+	site := &models.Site{ID: 1, Subdomain: "test", OwnerID: 1, SiteDir: "/tmp/test"}
+	testDB.Create(site)
+	page := &models.Page{ID: 1, SiteID: site.ID, Slug: "/join", Title: "Join", Published: true}
+	testDB.Create(page)
+	block := &models.Block{
+		PageID: page.ID,
+		Type:   "form",
+		Data:   `{"title":"Join","fields":[{"name":"name","label":"Name","type":"text","required":true},{"name":"email","label":"Email","type":"email","required":true}],"confirmation_message":"See you on the playa"}`,
+	}
+	testDB.Create(block)
+
+	return testDB, site, block
+}
+
+func postForm(site *models.Site, blockID string, form url.Values) *httptest.ResponseRecorder {
+	for k, v := range validSpamFields(site) {
+		if _, set := form[k]; !set {
+			form[k] = v
+		}
+	}
+	return submitForm(site, blockID, form)
+}
+
+// submitForm posts a form block submission as-is, keeping the current spam guard
+func submitForm(site *models.Site, blockID string, form url.Values) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/forms/"+blockID, strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Params = gin.Params{{Key: "block_id", Value: blockID}}
+	c.Set("site", site)
+	FormSubmitHandler(c)
+	return w
+}
+
+func TestFormSubmitHandler_StoresSubmission(t *testing.T) {
+	testDB, site, block := setupFormTest(t)
+
+	w := postForm(site, "1", url.Values{"f_name": {"Mittens"}, "f_email": {"mittens@example.com"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "See you on the playa") {
+		t.Error("Expected confirmation message in response")
+	}
+
+	var submission models.FormSubmission
+	if err := testDB.Where("block_id = ?", block.ID).First(&submission).Error; err != nil {
+		t.Fatalf("Expected submission to be stored: %v", err)
+	}
+	if submission.GetValues()["email"] != "mittens@example.com" {
+		t.Errorf("Unexpected stored values: %s", submission.Data)
+	}
+}
+
+func TestFormSubmitHandler_RejectsMissingRequired(t *testing.T) {
+	testDB, site, _ := setupFormTest(t)
+
+	w := postForm(site, "1", url.Values{"f_name": {"Mittens"}})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+
+	var count int64
+	testDB.Model(&models.FormSubmission{}).Count(&count)
+	if count != 0 {
+		t.Errorf("Expected no submissions, got %d", count)
+	}
+}
+
+func TestFormSubmitHandler_ResubmitAfterFix(t *testing.T) {
+	testDB, site, _ := setupFormTest(t)
+
+	form := validSpamFields(site)
+	form.Set("f_name", "Mittens")
+	if w := submitForm(site, "1", form); w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for a missing field, got %d", w.Code)
+	}
+
+	// Going back and filling in the field sends the same token again
+	form.Set("f_email", "mittens@example.com")
+	if w := submitForm(site, "1", form); w.Code != http.StatusOK {
+		t.Fatalf("Expected corrected submission to be accepted, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Once accepted, the token can't be used again
+	if w := submitForm(site, "1", form); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a replayed submission to be rejected, got %d", w.Code)
+	}
+
+	var count int64
+	testDB.Model(&models.FormSubmission{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected 1 submission, got %d", count)
+	}
+}
+
+func TestFormSubmitHandler_OtherSite(t *testing.T) {
+	testDB, _, _ := setupFormTest(t)
+
+	other := &models.Site{ID: 2, Subdomain: "other", OwnerID: 1, SiteDir: "/tmp/other"}
+	testDB.Create(other)
+
+	w := postForm(other, "1", url.Values{"f_name": {"Mittens"}, "f_email": {"mittens@example.com"}})
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for another site's form, got %d", w.Code)
+	}
+}
+
+func TestFormSubmissionsExportHandler(t *testing.T) {
+	testDB, site, block := setupFormTest(t)
+
+	sub := models.FormSubmission{SiteID: site.ID, PageID: block.PageID, BlockID: block.ID}
+	sub.SetValues(map[string]string{"name": "=HYPERLINK(\"x\")", "email": "a@example.com"})
+	testDB.Create(&sub)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/forms/1/export", nil)
+	c.Params = gin.Params{{Key: "block_id", Value: "1"}}
+	c.Set("site", site)
+	FormSubmissionsExportHandler(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.HasPrefix(body, "Submitted,Name,Email\n") {
+		t.Errorf("Unexpected CSV header: %s", body)
+	}
+	if !strings.Contains(body, `'=HYPERLINK`) {
+		t.Errorf("Expected formula to be neutralised, got: %s", body)
+	}
+}
+
+func TestFormBlockDataFromRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	form := url.Values{
+		"title":            {"RSVP"},
+		"field_count":      {"3"},
+		"field_0_label":    {"Email Address"},
+		"field_0_type":     {"email"},
+		"field_0_required": {"1"},
+		"field_1_label":    {""}, // removed
+		"field_2_label":    {"Meal"},
+		"field_2_type":     {"select"},
+		"field_2_options":  {"Veg, Meat , "},
+		"notify_email":     {"not an address"},
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	data, err := formBlockDataFromRequest(c)
+	if err != nil {
+		t.Fatalf("formBlockDataFromRequest failed: %v", err)
+	}
+
+	for _, want := range []string{`"name":"email_address"`, `"required":true`, `"options":["Veg","Meat"]`} {
+		if !strings.Contains(data, want) {
+			t.Errorf("Expected %s in %s", want, data)
+		}
+	}
+	if strings.Contains(data, "not an address") {
+		t.Error("Expected invalid notify email to be dropped")
+	}
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/thatcatcamp/stinkykitty/internal/blocks"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// derivativeQueueSize is how many legacy images can wait for their sizes to
+// be made; more than that are picked up on a later page view
+const derivativeQueueSize = 256
+
+var (
+	// derivativesQueued holds the media items whose derivatives are being made
+	// in the background. Items stay in it, so one that fails isn't retried on
+	// every page view; a restart tries again.
+	derivativesQueued sync.Map
+
+	// derivativeQueue feeds a single worker, so a page full of old photos
+	// doesn't decode them all at once
+	derivativeQueue     chan models.MediaItem
+	derivativeQueueOnce sync.Once
+)
+
+// responsiveImage returns the sizes an uploaded image is available in, or nil
+// for images that aren't in the media library. Media uploaded before
+// derivatives existed is measured in the background on first view, and gets
+// a srcset from then on.
+func responsiveImage(url string) *blocks.ResponsiveImage {
+	name := strings.TrimPrefix(url, "/assets/")
+	if name == url || name == "" || db.GetDB() == nil {
+		return nil
+	}
+	var item models.MediaItem
+	if err := db.GetDB().Where("filename = ?", media.UploadFilename(name)).First(&item).Error; err != nil {
+		return nil
+	}
+	if item.Width <= 0 {
+		queueDerivatives(item)
+		return nil
+	}
+	return &blocks.ResponsiveImage{
+		Width:  item.Width,
+		Height: item.Height,
+		SrcSet: media.SrcSet(&item),
+		Sizes:  media.ImageSizes,
+	}
+}
+
+// queueDerivatives makes an item's derivatives in the background, one image
+// at a time
+func queueDerivatives(item models.MediaItem) {
+	derivativeQueueOnce.Do(func() {
+		derivativeQueue = make(chan models.MediaItem, derivativeQueueSize)
+		go derivativeWorker(derivativeQueue)
+	})
+	if _, queued := derivativesQueued.LoadOrStore(item.ID, true); queued {
+		return
+	}
+	select {
+	case derivativeQueue <- item:
+	default:
+		// Queue full; forget the item so a later view queues it again
+		derivativesQueued.Delete(item.ID)
+	}
+}
+
+// derivativeWorker makes derivatives for each queued item in turn
+func derivativeWorker(queue <-chan models.MediaItem) {
+	for item := range queue {
+		if err := media.EnsureDerivatives(db.GetDB(), &item); err != nil {
+			log.Printf("Warning: failed to generate image sizes for %s: %v", item.Filename, err)
+		}
+	}
+}
+
+// renderImageBlockHTML renders an image block with the sizes its image is
+// available in
+func renderImageBlockHTML(dataJSON string) (string, error) {
+	var data blocks.ImageBlockData
+	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
+		return "", fmt.Errorf("failed to parse image block data: %w", err)
+	}
+	return blocks.RenderImageBlock(dataJSON, responsiveImage(data.URL))
+}
@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/auth"
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/search"
+	"github.com/thatcatcamp/stinkykitty/internal/visibility"
+)
+
+// viewerKey signs the viewer cookie
+func viewerKey() []byte {
+	return auth.SigningKey("page-viewer")
+}
+
+// pageViewer works out who is viewing a site's pages: members are logged in
+// with access to the site, and other visitors carry the password pages they
+// unlocked in the signed viewer cookie. The result is cached on the request.
+func pageViewer(c *gin.Context, site *models.Site) *visibility.Viewer {
+	if v, ok := c.Get("viewer"); ok {
+		return v.(*visibility.Viewer)
+	}
+
+	viewer := &visibility.Viewer{Grants: visibility.Grants{}}
+	if token, err := c.Cookie("stinky_token"); err == nil && token != "" {
+		if claims, err := auth.ValidateToken(token); err == nil {
+			var user models.User
+			if err := db.GetDB().First(&user, claims.UserID).Error; err == nil {
+				viewer.Member = auth.HasSiteAccess(&user, site)
+			}
+		}
+	}
+	if cookie, err := c.Cookie(visibility.CookieName); err == nil {
+		viewer.Grants = visibility.DecodeGrants(viewerKey(), site.ID, cookie, time.Now())
+	}
+
+	c.Set("viewer", viewer)
+	return viewer
+}
+
+// renderPageLocked shows the password prompt or members-only notice in place
+// of a page the visitor can't see
+func renderPageLocked(c *gin.Context, site *models.Site, page *models.Page, errMsg string) {
+	if page.Visibility == visibility.Members {
+		renderSimplePage(c, site, http.StatusUnauthorized, "Members Only",
+			`<p>This page is only available to members of this site.</p><p><a href="/admin/login" class="btn">Log In</a></p>`)
+		return
+	}
+
+	errorHTML := ""
+	if errMsg != "" {
+		errorHTML = `<div class="error-message">` + html.EscapeString(errMsg) + `</div>`
+	}
+	body := fmt.Sprintf(`%s
+		<p>This page is password protected.</p>
+		<form method="POST" action="/unlock" style="display: flex; gap: 10px; max-width: 480px;">
+			<input type="hidden" name="page_id" value="%d">
+			<input type="password" name="password" placeholder="Password" required autofocus style="flex: 1; padding: 10px; font-size: 16px;">
+			<button type="submit" class="btn">Unlock</button>
+		</form>`, errorHTML, page.ID)
+	renderSimplePage(c, site, http.StatusUnauthorized, page.Title, body)
+}
+
+// UnlockPageHandler checks a password page's password and remembers the
+// unlocked page in the viewer cookie
+func UnlockPageHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	var page models.Page
+	if err := db.GetDB().Where("id = ? AND site_id = ? AND published = ? AND visibility = ?",
+		c.PostForm("page_id"), site.ID, true, visibility.Password).First(&page).Error; err != nil {
+		c.String(http.StatusNotFound, "Page not found")
+		return
+	}
+
+	if page.PasswordHash == "" || !auth.CheckPassword(c.PostForm("password"), page.PasswordHash) {
+		c.Header("Cache-Control", "private, no-store")
+		renderPageLocked(c, site, &page, "That password isn't right.")
+		return
+	}
+
+	grants := pageViewer(c, site).Grants
+	grants.Unlock(page.ID, visibility.PasswordVersion(page.PasswordHash), time.Now())
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(
+		visibility.CookieName,
+		visibility.EncodeGrants(viewerKey(), site.ID, grants, time.Now().Add(visibility.CookieLifetime)),
+		int(visibility.CookieLifetime.Seconds()),
+		"/",
+		"",
+		config.GetBool("server.tls_enabled"),
+		true,
+	)
+	c.Redirect(http.StatusFound, page.Slug)
+}
+
+// pageVisibilitySectionHTML renders the page editor section for who can see the page
+func pageVisibilitySectionHTML(c *gin.Context, page *models.Page, csrfToken string) string {
+	if page.Slug == "/" {
+		return ""
+	}
+
+	errorHTML := ""
+	if msg := c.Query("visibility_error"); msg != "" {
+		errorHTML = `<div class="error-message" style="background: #f8d7da; border: 1px solid #f5c6cb; color: #721c24; padding: 12px; border-radius: 4px; margin-bottom: 12px;">` + html.EscapeString(msg) + `</div>`
+	}
+
+	var options strings.Builder
+	current := page.Visibility
+	if current == "" {
+		current = visibility.Public
+	}
+	for _, mode := range []string{visibility.Public, visibility.Password, visibility.Members} {
+		selected := ""
+		if mode == current {
+			selected = " selected"
+		}
+		fmt.Fprintf(&options, `<option value="%s"%s>%s</option>`, mode, selected, visibility.Label(mode))
+	}
+
+	passwordHint := "Visitors enter this password once per browser."
+	if page.PasswordHash != "" {
+		passwordHint = "A password is set. Leave blank to keep it, or enter a new one to lock out everyone who used the old one."
+	}
+
+	return fmt.Sprintf(`
+            <div class="section">
+                <h2>Who Can See This Page</h2>
+                %s
+                <form method="POST" action="/admin/pages/%d/visibility">
+                    %s
+                    <div style="margin-bottom: 12px;">
+                        <label for="visibility" style="display: block; font-weight: 600; margin-bottom: 4px;">Visibility</label>
+                        <select id="visibility" name="visibility">%s</select>
+                        <small style="display: block; color: var(--color-text-secondary);">Password and members-only pages are left out of search, the sitemap and offline copies. Members-only pages are hidden from the menu for visitors who aren't logged in.</small>
+                    </div>
+                    <div style="margin-bottom: 12px;">
+                        <label for="page_password" style="display: block; font-weight: 600; margin-bottom: 4px;">Page Password</label>
+                        <input type="password" id="page_password" name="password" autocomplete="new-password" style="width: 100%%; box-sizing: border-box;">
+                        <small style="color: var(--color-text-secondary);">%s</small>
+                    </div>
+                    <button type="submit" class="btn btn-secondary">Save Visibility</button>
+                </form>
+            </div>`, errorHTML, page.ID, csrfToken, options.String(), html.EscapeString(passwordHint))
+}
+
+// UpdatePageVisibilityHandler sets who can see a page and its password
+func UpdatePageVisibilityHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	pageIDStr := c.Param("id")
+	pageID, err := strconv.Atoi(pageIDStr)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid page ID")
+		return
+	}
+
+	var page models.Page
+	if err := db.GetDB().Where("id = ? AND site_id = ?", pageID, site.ID).First(&page).Error; err != nil {
+		c.String(http.StatusNotFound, "Page not found")
+		return
+	}
+
+	editURL := "/admin/pages/" + pageIDStr + "/edit"
+	fail := func(err error) {
+		c.Redirect(http.StatusFound, editURL+"?visibility_error="+url.QueryEscape(err.Error()))
+	}
+
+	mode := c.PostForm("visibility")
+	password := c.PostForm("password")
+	switch {
+	case !visibility.Valid(mode):
+		fail(visibility.ErrInvalidMode)
+		return
+	case page.Slug == "/" && mode != visibility.Public:
+		fail(visibility.ErrHomepage)
+		return
+	case mode == visibility.Password && password == "" && page.PasswordHash == "":
+		fail(visibility.ErrPasswordRequired)
+		return
+	}
+
+	page.Visibility = mode
+	if password != "" {
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to set password")
+			return
+		}
+		page.PasswordHash = hash
+	}
+
+	if err := db.GetDB().Model(&page).Select("visibility", "password_hash").Updates(&page).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to update page")
+		return
+	}
+
+	// Add or remove the page from search to match
+	if err := search.IndexPage(db.GetDB(), &page); err != nil {
+		// Log error but don't fail the request
+		fmt.Printf("Warning: Failed to index page %d: %v\n", page.ID, err)
+	}
+
+	c.Redirect(http.StatusFound, editURL)
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/auth"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/visibility"
+)
+
+func TestPasswordPage_UnlockWithViewerCookie(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	hash, _ := auth.HashPassword("playa")
+	gate := &models.Page{SiteID: site.ID, Slug: "/gate", Title: "Gate Info", Published: true, Visibility: visibility.Password, PasswordHash: hash}
+	testDB.Create(gate)
+	testDB.Create(&models.Block{PageID: gate.ID, Type: "text", Order: 0, Data: `{"content":"Gate code 4321"}`})
+
+	w := getWithSite(site, "/gate", nil, ServePage)
+	if w.Code != http.StatusUnauthorized || strings.Contains(w.Body.String(), "4321") || !strings.Contains(w.Body.String(), `action="/unlock"`) {
+		t.Fatalf("Expected a password prompt without the content, got %d:\n%s", w.Code, w.Body.String())
+	}
+
+	_, w = postFormWithSite(site, "/unlock", nil, url.Values{"page_id": {"1"}, "password": {"wrong"}}, UnlockPageHandler)
+	if w.Code != http.StatusUnauthorized || len(w.Result().Cookies()) != 0 {
+		t.Errorf("Expected a wrong password to be refused, got %d", w.Code)
+	}
+
+	c, w := postFormWithSite(site, "/unlock", nil, url.Values{"page_id": {"1"}, "password": {"playa"}}, UnlockPageHandler)
+	cookies := w.Result().Cookies()
+	if c.Writer.Status() != http.StatusFound || len(cookies) != 1 || cookies[0].Name != visibility.CookieName {
+		t.Fatalf("Expected a redirect with the viewer cookie, got %d %v", c.Writer.Status(), cookies)
+	}
+
+	rec := httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest("GET", "/gate", nil)
+	c.Request.AddCookie(cookies[0])
+	c.Set("site", site)
+	ServePage(c)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Gate code 4321") {
+		t.Errorf("Expected the unlocked page, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `content="noindex"`) {
+		t.Error("Expected protected pages to be noindex")
+	}
+}
+
+func TestMembersPage_HiddenFromVisitors(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/about", Title: "About", Published: true})
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/roster", Title: "Roster", Published: true, Visibility: visibility.Members})
+	testDB.Create(&models.MenuItem{SiteID: site.ID, Label: "About", URL: "/about", Order: 0})
+	testDB.Create(&models.MenuItem{SiteID: site.ID, Label: "Roster", URL: "/roster", Order: 1})
+
+	w := getWithSite(site, "/roster", nil, ServePage)
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "Members Only") {
+		t.Errorf("Expected members-only notice, got %d", w.Code)
+	}
+
+	w = getWithSite(site, "/about", nil, ServePage)
+	if strings.Contains(w.Body.String(), `href="/roster"`) {
+		t.Error("Menu must not link to members-only pages for visitors")
+	}
+
+	w = getWithSite(site, "/sitemap.xml", nil, SitemapXMLHandler)
+	if strings.Contains(w.Body.String(), "/roster") {
+		t.Error("Sitemap must not list members-only pages")
+	}
+}
+
+func TestUpdatePageVisibilityHandler_RequiresPassword(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/dues", Title: "Dues"})
+
+	c, _ := postFormWithSite(site, "/admin/pages/1/visibility", gin.Params{{Key: "id", Value: "1"}}, url.Values{"visibility": {"password"}}, UpdatePageVisibilityHandler)
+	if loc := c.Writer.Header().Get("Location"); !strings.Contains(loc, "visibility_error=") {
+		t.Errorf("Expected an error without a password, got %q", loc)
+	}
+
+	postFormWithSite(site, "/admin/pages/1/visibility", gin.Params{{Key: "id", Value: "1"}}, url.Values{"visibility": {"password"}, "password": {"dues2026"}}, UpdatePageVisibilityHandler)
+	var page models.Page
+	testDB.First(&page)
+	if page.Visibility != visibility.Password || !auth.CheckPassword("dues2026", page.PasswordHash) {
+		t.Errorf("Expected a password-protected page, got %+v", page)
+	}
+}
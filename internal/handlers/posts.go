@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/blocks"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/posts"
+)
+
+// renderPostsBlock renders a latest-posts block with the site's newest posts
+func renderPostsBlock(site *models.Site, dataJSON string) (string, error) {
+	data, err := blocks.ParsePostsBlockData(dataJSON)
+	if err != nil {
+		return "", err
+	}
+	list, err := posts.Latest(db.GetDB(), site.ID, data.Tag, data.Limit, time.Now())
+	if err != nil {
+		return "", err
+	}
+	return blocks.RenderPostsBlock(dataJSON, list)
+}
+
+// siteFeed describes a site for its RSS and Atom feeds
+func siteFeed(site *models.Site) posts.Feed {
+	name := site.SiteTitle
+	if name == "" {
+		name = site.Subdomain
+	}
+	return posts.Feed{Title: name, Description: site.SiteTagline, SiteURL: SiteURL(site)}
+}
+
+// renderPostArchive renders one page of the blog archive, optionally for a single tag
+func renderPostArchive(c *gin.Context, site *models.Site, tag string) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	perPage := posts.PerPage()
+	list, total, err := posts.List(db.GetDB(), site.ID, tag, page, perPage, time.Now())
+	if err != nil {
+		log.Printf("Error loading posts for site %d: %v", site.ID, err)
+		c.String(http.StatusInternalServerError, "Failed to load posts")
+		return
+	}
+
+	title := "Blog"
+	base := "/blog"
+	if tag != "" {
+		title = "Posts tagged “" + tag + "”"
+		base = posts.TagURL(tag)
+	}
+
+	totalPages := int((total + int64(perPage) - 1) / int64(perPage))
+	if page > 1 && page > totalPages {
+		renderSimplePage(c, site, http.StatusNotFound, title,
+			`<p>There are no more posts.</p><p><a href="/blog">&larr; Back to the blog</a></p>`)
+		return
+	}
+
+	var b strings.Builder
+	if len(list) == 0 {
+		b.WriteString(`<p style="color: var(--color-text-secondary, #666);">No posts yet.</p>`)
+	} else {
+		b.WriteString(`<div class="post-archive">` + blocks.RenderPostSummaries(list) + "\n</div>")
+	}
+
+	b.WriteString(`<nav class="pagination" style="display: flex; justify-content: space-between; margin: 24px 0;"><span>`)
+	if page > 1 {
+		fmt.Fprintf(&b, `<a href="%s?page=%d">&larr; Newer posts</a>`, html.EscapeString(base), page-1)
+	}
+	b.WriteString(`</span><span>`)
+	if page < totalPages {
+		fmt.Fprintf(&b, `<a href="%s?page=%d">Older posts &rarr;</a>`, html.EscapeString(base), page+1)
+	}
+	b.WriteString(`</span></nav>`)
+
+	if tag != "" {
+		b.WriteString(`<p><a href="/blog">&larr; All posts</a> · <a href="/feed.xml">RSS</a> · <a href="/atom.xml">Atom</a></p>`)
+	} else {
+		b.WriteString(`<p><a href="/feed.xml">RSS</a> · <a href="/atom.xml">Atom</a></p>`)
+	}
+
+	renderSimplePage(c, site, http.StatusOK, title, b.String())
+}
+
+// BlogArchiveHandler lists published posts, newest first, with ?page= pagination
+func BlogArchiveHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	renderPostArchive(c, siteVal.(*models.Site), "")
+}
+
+// BlogTagHandler lists published posts with a tag
+func BlogTagHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	renderPostArchive(c, siteVal.(*models.Site), posts.Slugify(c.Param("tag")))
+}
+
+// BlogPostHandler shows a single published post
+func BlogPostHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	var post models.Post
+	err := db.GetDB().Preload("Tags").Where("site_id = ? AND slug = ?", site.ID, c.Param("slug")).First(&post).Error
+	if err != nil || !posts.IsVisible(&post, time.Now()) {
+		renderSimplePage(c, site, http.StatusNotFound, "Post Not Found",
+			`<p>This post may have been removed.</p><p><a href="/blog">See all posts</a></p>`)
+		return
+	}
+
+	var b strings.Builder
+	byline := blocks.PostDate(&post)
+	if post.Author != "" {
+		byline = "By " + post.Author + " · " + byline
+	}
+	fmt.Fprintf(&b, `<p class="post-byline" style="color: var(--color-text-secondary, #666);">%s</p>`, html.EscapeString(byline))
+	if post.CoverImage != "" {
+		fmt.Fprintf(&b, `<img class="post-cover" src="%s" alt="" style="width: 100%%; max-height: 420px; object-fit: cover; border-radius: 8px; margin: 12px 0 20px;">`,
+			html.EscapeString(post.CoverImage))
+	}
+	fmt.Fprintf(&b, `<div class="post-content" style="line-height: 1.6;">%s</div>`, posts.RenderContent(post.Content))
+
+	if tags := posts.TagNames(&post); len(tags) > 0 {
+		b.WriteString(`<p class="post-tags" style="margin-top: 24px;">Tags: `)
+		for i, tag := range tags {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(posts.TagURL(tag)), html.EscapeString(tag))
+		}
+		b.WriteString(`</p>`)
+	}
+	b.WriteString(`<p><a href="/blog">&larr; All posts</a></p>`)
+
+	renderSimplePage(c, site, http.StatusOK, post.Title, b.String())
+}
+
+// feedPosts loads the newest posts for the RSS and Atom feeds
+func feedPosts(site *models.Site) ([]models.Post, error) {
+	return posts.Latest(db.GetDB(), site.ID, "", posts.FeedSize(), time.Now())
+}
+
+// RSSFeedHandler serves the site's posts as an RSS 2.0 feed
+func RSSFeedHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	list, err := feedPosts(site)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load posts")
+		return
+	}
+	var buf bytes.Buffer
+	if err := posts.WriteRSS(&buf, siteFeed(site), list); err != nil {
+		c.String(http.StatusInternalServerError, "Failed to generate feed")
+		return
+	}
+	c.Data(http.StatusOK, "application/rss+xml; charset=utf-8", buf.Bytes())
+}
+
+// AtomFeedHandler serves the site's posts as an Atom feed
+func AtomFeedHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	list, err := feedPosts(site)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load posts")
+		return
+	}
+	var buf bytes.Buffer
+	if err := posts.WriteAtom(&buf, siteFeed(site), list); err != nil {
+		c.String(http.StatusInternalServerError, "Failed to generate feed")
+		return
+	}
+	c.Data(http.StatusOK, "application/atom+xml; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+)
+
+func setupPostsTest(t *testing.T) (*gorm.DB, *models.Site) {
+	gin.SetMode(gin.TestMode)
+	testDB := setupTestDB(t)
+	if err := testDB.AutoMigrate(&models.User{}, &models.MenuItem{}, &models.Post{}, &models.PostTag{}, &models.Redirect{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	db.SetDB(testDB)
+
+	site := &models.Site{ID: 1, Subdomain: "test", OwnerID: 1, SiteDir: "/tmp/test", SiteTitle: "Test Camp"}
+	testDB.Create(site)
+	return testDB, site
+}
+
+func getWithSite(site *models.Site, target string, params gin.Params, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", target, nil)
+	c.Params = params
+	c.Set("site", site)
+	handler(c)
+	return w
+}
+
+func TestBlogArchiveHandler_Pagination(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+
+	now := time.Now().UTC()
+	for i, slug := range []string{"first", "second", "third"} {
+		testDB.Create(&models.Post{SiteID: site.ID, Slug: slug, Title: "Post " + slug, Published: true, PublishedAt: now.Add(time.Duration(i-10) * time.Hour)})
+	}
+	testDB.Create(&models.Post{SiteID: site.ID, Slug: "later", Title: "Post later", Published: true, PublishedAt: now.Add(24 * time.Hour)})
+	testDB.Create(&models.Post{SiteID: 2, Slug: "elsewhere", Title: "Other Camp Post", Published: true, PublishedAt: now.Add(-time.Hour)})
+
+	w := getWithSite(site, "/blog", nil, BlogArchiveHandler)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	// Ten per page by default, newest first
+	if strings.Index(body, "Post third") > strings.Index(body, "Post first") {
+		t.Error("Expected newest post first")
+	}
+	if strings.Contains(body, "Post later") || strings.Contains(body, "Other Camp Post") {
+		t.Error("Archive must not show scheduled posts or another site's posts")
+	}
+	if strings.Contains(body, "Older posts") {
+		t.Error("Expected a single page of posts")
+	}
+
+	w = getWithSite(site, "/blog?page=5", nil, BlogArchiveHandler)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 past the last page, got %d", w.Code)
+	}
+}
+
+func TestBlogTagHandler(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+
+	at := time.Now().Add(-time.Hour).UTC()
+	tagged := &models.Post{SiteID: site.ID, Slug: "tagged", Title: "Tagged Post", Published: true, PublishedAt: at}
+	testDB.Create(tagged)
+	testDB.Create(&models.PostTag{PostID: tagged.ID, SiteID: site.ID, TagName: "build-week"})
+	testDB.Create(&models.Post{SiteID: site.ID, Slug: "untagged", Title: "Untagged Post", Published: true, PublishedAt: at})
+
+	w := getWithSite(site, "/blog/tag/build-week", gin.Params{{Key: "tag", Value: "build-week"}}, BlogTagHandler)
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, "Tagged Post") || strings.Contains(body, "Untagged Post") {
+		t.Errorf("Expected only the tagged post, got %d:\n%s", w.Code, body)
+	}
+}
+
+func TestBlogPostHandler(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+
+	post := &models.Post{SiteID: site.ID, Slug: "hello", Title: "Hello Camp", Author: "Mittens",
+		Content: "<p>Welcome!</p><script>alert(1)</script>", Published: true, PublishedAt: time.Now().Add(-time.Hour).UTC()}
+	testDB.Create(post)
+	testDB.Create(&models.PostTag{PostID: post.ID, SiteID: site.ID, TagName: "news"})
+	testDB.Create(&models.Post{SiteID: site.ID, Slug: "draft", Title: "Draft", PublishedAt: time.Now().Add(-time.Hour).UTC()})
+
+	w := getWithSite(site, "/blog/hello", gin.Params{{Key: "slug", Value: "hello"}}, BlogPostHandler)
+	body := w.Body.String()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	for _, want := range []string{"Hello Camp", "By Mittens", "<p>Welcome!</p>", `href="/blog/tag/news"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected post page to contain %q", want)
+		}
+	}
+	if strings.Contains(body, "<script>alert") {
+		t.Error("Post content must be sanitized")
+	}
+
+	w = getWithSite(site, "/blog/draft", gin.Params{{Key: "slug", Value: "draft"}}, BlogPostHandler)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected drafts to 404, got %d", w.Code)
+	}
+}
+
+func TestRSSFeedHandler(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	testDB.Create(&models.Post{SiteID: site.ID, Slug: "hello", Title: "Hello Camp", Published: true, PublishedAt: time.Now().Add(-time.Hour).UTC()})
+
+	w := getWithSite(site, "/feed.xml", nil, RSSFeedHandler)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/rss+xml") {
+		t.Fatalf("Expected RSS feed, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), "<link>https://test.localhost/blog/hello</link>") {
+		t.Errorf("Expected post link in feed, got:\n%s", w.Body.String())
+	}
+
+	w = getWithSite(site, "/atom.xml", nil, AtomFeedHandler)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<id>https://test.localhost/blog/hello</id>") {
+		t.Errorf("Expected Atom entry for post, got %d:\n%s", w.Code, w.Body.String())
+	}
+}
+
+func TestRenderBlockHTML_PostsBlock(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	testDB.Create(&models.Post{SiteID: site.ID, Slug: "hello", Title: "Hello Camp", Excerpt: "Big news", Published: true, PublishedAt: time.Now().Add(-time.Hour).UTC()})
+
+	out, err := renderBlockHTML(site, models.Block{Type: "posts", Data: `{"title":"Latest News","limit":3}`})
+	if err != nil {
+		t.Fatalf("renderBlockHTML failed: %v", err)
+	}
+	if !strings.Contains(out, "Hello Camp") || !strings.Contains(out, "Big news") {
+		t.Errorf("Expected latest post in block, got:\n%s", out)
+	}
+}
+
+func TestCreatePostHandler(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+
+	form := url.Values{
+		"title":        {"Build Week Recap!"},
+		"author":       {"Mittens"},
+		"content":      {"<p>It was great</p>"},
+		"tags":         {"News, Build Week"},
+		"published":    {"1"},
+		"publish_date": {"2026-03-01"},
+		"publish_time": {"09:30"},
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/posts", strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Set("site", site)
+
+	CreatePostHandler(c)
+
+	if c.Writer.Status() != http.StatusFound {
+		t.Fatalf("Expected redirect, got %d: %s", c.Writer.Status(), w.Body.String())
+	}
+	var post models.Post
+	if err := testDB.Preload("Tags").First(&post).Error; err != nil {
+		t.Fatalf("Expected post to be created: %v", err)
+	}
+	if post.Slug != "build-week-recap" || !post.Published || post.PublishedAt.IsZero() || len(post.Tags) != 2 {
+		t.Errorf("Unexpected post: %+v", post)
+	}
+
+	// A second post with the same slug is refused
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/posts", strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Set("site", site)
+	CreatePostHandler(c)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a duplicate slug, got %d", w.Code)
+	}
+}
@@ -18,16 +18,160 @@ import (
 	"github.com/thatcatcamp/stinkykitty/internal/email"
 	"github.com/thatcatcamp/stinkykitty/internal/middleware"
 	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/pages"
+	"github.com/thatcatcamp/stinkykitty/internal/posts"
+	"github.com/thatcatcamp/stinkykitty/internal/redirects"
+	"github.com/thatcatcamp/stinkykitty/internal/visibility"
 	"gorm.io/gorm"
 )
 
-// renderNavigationLinks generates just the navigation links (for header)
-func renderNavigationLinks(siteID uint) string {
+// renderBlocks renders a page's blocks to HTML in order, skipping any that fail
+func renderBlocks(site *models.Site, pageBlocks []models.Block) string {
+	var content strings.Builder
+	for _, block := range pageBlocks {
+		blockHTML, err := renderBlockHTML(site, block)
+		if err != nil {
+			// Log error but continue rendering other blocks
+			log.Printf("Error rendering block %d: %v", block.ID, err)
+			continue
+		}
+		content.WriteString(blockHTML)
+		content.WriteString("\n")
+	}
+	return content.String()
+}
+
+// renderBlockHTML renders a single block. Blocks that need their ID or data from
+// the database are rendered here; everything else goes through blocks.RenderBlock.
+func renderBlockHTML(site *models.Site, block models.Block) (string, error) {
+	switch block.Type {
+	case "image":
+		return renderImageBlockHTML(block.Data)
+	case "file":
+		return renderFileBlockHTML(block.Data)
+	case "contact":
+		return blocks.RenderContactBlock(block.Data, spamFieldsHTML(site))
+	case "form":
+		return blocks.RenderFormBlock(block.ID, block.Data, spamFieldsHTML(site))
+	case "events":
+		return renderEventsBlock(site, block.Data)
+	case "calendar":
+		return renderCalendarBlock(site, block.Data)
+	case "shifts":
+		return renderShiftsBlock(site, block.Data)
+	case "posts":
+		return renderPostsBlock(site, block.Data)
+	case "children":
+		children, err := pages.Children(db.GetDB(), site.ID, block.PageID, true)
+		if err != nil {
+			return "", err
+		}
+		// Members-only pages are never listed; the block is rendered the same for everyone
+		listed := children[:0]
+		for _, child := range children {
+			if child.Visibility != visibility.Members {
+				listed = append(listed, child)
+			}
+		}
+		return blocks.RenderChildPagesBlock(block.Data, listed)
+	default:
+		return blocks.RenderBlock(block.Type, block.Data)
+	}
+}
+
+// renderBreadcrumbs renders the trail from the homepage down to a page.
+// Unpublished parents are shown without a link.
+func renderBreadcrumbs(page *models.Page) string {
+	if page.Slug == pages.HomepageSlug {
+		return ""
+	}
+	ancestors, err := pages.Ancestors(db.GetDB(), page)
+	if err != nil {
+		log.Printf("Error loading parents of page %d: %v", page.ID, err)
+	}
+
+	var b strings.Builder
+	b.WriteString(`<nav class="breadcrumbs" aria-label="Breadcrumb" style="margin: 20px 0 0; font-size: 0.9em; color: var(--color-text-secondary);"><a href="/">Home</a>`)
+	for _, parent := range ancestors {
+		b.WriteString(" › ")
+		if parent.Published {
+			fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(parent.Slug), html.EscapeString(parent.Title))
+		} else {
+			b.WriteString(html.EscapeString(parent.Title))
+		}
+	}
+	fmt.Fprintf(&b, ` › <span aria-current="page">%s</span></nav>`, html.EscapeString(page.Title))
+	return b.String()
+}
+
+// renderSimplePage renders a themed page with a title and body, used for form
+// responses and other pages that aren't built from blocks
+func renderSimplePage(c *gin.Context, site *models.Site, status int, title, bodyHTML string) {
+	themeCSS, _ := c.Get("themeCSS")
+	themeCSSStr, _ := themeCSS.(string)
+
+	page := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>%s - %s</title>
+	<link rel="alternate" type="application/rss+xml" title="RSS" href="/feed.xml">
+	<link rel="alternate" type="application/atom+xml" title="Atom" href="/atom.xml">
+	<style>
+		%s
+		body { font-family: system-ui; margin: 0; padding: 20px; }
+		.container { max-width: 800px; margin: 50px auto; }
+		.success-message { background: #d4edda; border: 1px solid #c3e6cb; color: #155724; padding: 15px; border-radius: 4px; margin-bottom: 20px; }
+		.error-message { background: #f8d7da; border: 1px solid #f5c6cb; color: #721c24; padding: 15px; border-radius: 4px; margin-bottom: 20px; }
+		a { color: var(--color-primary, #2563eb); text-decoration: none; }
+		a:hover { text-decoration: underline; }
+	</style>
+</head>
+<body>
+	%s
+	<div class="container">
+		<h1>%s</h1>
+		%s
+		%s
+	</div>
+</body>
+</html>`, html.EscapeString(title), html.EscapeString(site.SiteTitle), GetDesignSystemCSS()+"\n"+themeCSSStr,
+		renderHeader(c, site, renderNavigationLinks(c, site)), html.EscapeString(title), bodyHTML, renderFooter(site, true))
+
+	c.Data(status, "text/html; charset=utf-8", []byte(page))
+}
+
+// visibleMenuItems loads a site's menu, leaving out links to members-only
+// pages unless the visitor is a member
+func visibleMenuItems(c *gin.Context, site *models.Site) []models.MenuItem {
 	var menuItems []models.MenuItem
-	db.GetDB().Where("site_id = ?", siteID).
+	db.GetDB().Where("site_id = ?", site.ID).
 		Order("`order` ASC").
 		Find(&menuItems)
 
+	var membersOnly []string
+	db.GetDB().Model(&models.Page{}).Where("site_id = ? AND visibility = ?", site.ID, visibility.Members).Pluck("slug", &membersOnly)
+	if len(membersOnly) == 0 || pageViewer(c, site).Member {
+		return menuItems
+	}
+
+	hidden := map[string]bool{}
+	for _, slug := range membersOnly {
+		hidden[slug] = true
+	}
+	visible := menuItems[:0]
+	for _, item := range menuItems {
+		if !hidden[strings.TrimSuffix(item.URL, "/")] {
+			visible = append(visible, item)
+		}
+	}
+	return visible
+}
+
+// renderNavigationLinks generates just the navigation links (for header)
+func renderNavigationLinks(c *gin.Context, site *models.Site) string {
+	menuItems := visibleMenuItems(c, site)
 	if len(menuItems) == 0 {
 		return ""
 	}
@@ -46,12 +190,8 @@ func renderNavigationLinks(siteID uint) string {
 }
 
 // renderNavigation generates the navigation menu HTML for a site
-func renderNavigation(siteID uint) string {
-	var menuItems []models.MenuItem
-	db.GetDB().Where("site_id = ?", siteID).
-		Order("`order` ASC").
-		Find(&menuItems)
-
+func renderNavigation(c *gin.Context, site *models.Site) string {
+	menuItems := visibleMenuItems(c, site)
 	if len(menuItems) == 0 {
 		return ""
 	}
@@ -95,9 +235,10 @@ func getCopyrightText(site *models.Site) string {
 	return copyright
 }
 
-// renderHeader generates header HTML for pages
-func renderHeader(site *models.Site, navigationLinks string) string {
-	return fmt.Sprintf(`
+// renderHeader generates header HTML for pages, topped by any announcements
+// the site is showing
+func renderHeader(c *gin.Context, site *models.Site, navigationLinks string) string {
+	return renderAnnouncements(c, site) + fmt.Sprintf(`
 <header class="site-header">
 	<div class="site-header-content">
 		<a href="/" class="site-header-logo">%s</a>
@@ -173,20 +314,10 @@ func ServeHomepage(c *gin.Context) {
 	}
 
 	// Render navigation links for header
-	navigationLinks := renderNavigationLinks(site.ID)
+	navigationLinks := renderNavigationLinks(c, site)
 
 	// Render all blocks
-	var content strings.Builder
-	for _, block := range page.Blocks {
-		blockHTML, err := blocks.RenderBlock(block.Type, block.Data)
-		if err != nil {
-			// Log error but continue rendering other blocks
-			log.Printf("Error rendering block %d: %v", block.ID, err)
-			continue
-		}
-		content.WriteString(blockHTML)
-		content.WriteString("\n")
-	}
+	content := renderBlocks(site, page.Blocks)
 
 	// Get theme CSS from context
 	themeCSS, _ := c.Get("themeCSS")
@@ -200,7 +331,7 @@ func ServeHomepage(c *gin.Context) {
 	<meta charset="utf-8">
 	<meta name="viewport" content="width=device-width, initial-scale=1">
 	<title>%s</title>
-	<style>
+%s	<style>
 		%s
 		body { font-family: system-ui, sans-serif; max-width: 800px; margin: 0 auto; padding: 0 20px 20px; line-height: 1.6; }
 		.text-block { margin-bottom: 1.5em; }
@@ -233,7 +364,7 @@ func ServeHomepage(c *gin.Context) {
 	%s
 </body>
 </html>
-`, page.Title, GetDesignSystemCSS()+"\n"+themeCSSStr, getGoogleAnalyticsScript(site), renderHeader(site, navigationLinks), page.Title, content.String(), renderFooter(site, false))
+`, page.Title, pageMeta(site, &page).Tags(), GetDesignSystemCSS()+"\n"+themeCSSStr, getGoogleAnalyticsScript(site), renderHeader(c, site, navigationLinks), page.Title, content, renderFooter(site, false))
 
 	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
 }
@@ -260,6 +391,15 @@ func ServePage(c *gin.Context) {
 		First(&page)
 
 	if result.Error != nil {
+		// Old addresses of moved or deleted pages, and imported links from old sites
+		if redirect, ok := redirects.Lookup(db.GetDB(), site.ID, slug); ok {
+			if err := redirects.RecordHit(db.GetDB(), redirect); err != nil {
+				log.Printf("Error counting hit for redirect %d: %v", redirect.ID, err)
+			}
+			c.Redirect(redirect.StatusCode, redirects.Location(redirect, c.Request.URL.RawQuery))
+			return
+		}
+
 		// Render nice 404 page
 		themeCSS, _ := c.Get("themeCSS")
 		themeCSSStr, _ := themeCSS.(string)
@@ -296,21 +436,20 @@ func ServePage(c *gin.Context) {
 		return
 	}
 
+	// Password and members-only pages
+	if !visibility.IsPublic(&page) {
+		c.Header("Cache-Control", "private, no-store")
+		if !pageViewer(c, site).CanView(&page) {
+			renderPageLocked(c, site, &page, "")
+			return
+		}
+	}
+
 	// Render navigation links for header
-	navigationLinks := renderNavigationLinks(site.ID)
+	navigationLinks := renderNavigationLinks(c, site)
 
 	// Render all blocks
-	var content strings.Builder
-	for _, block := range page.Blocks {
-		blockHTML, err := blocks.RenderBlock(block.Type, block.Data)
-		if err != nil {
-			// Log error but continue rendering other blocks
-			log.Printf("Error rendering block %d: %v", block.ID, err)
-			continue
-		}
-		content.WriteString(blockHTML)
-		content.WriteString("\n")
-	}
+	content := renderBlocks(site, page.Blocks)
 
 	// Get theme CSS from context
 	themeCSS, _ := c.Get("themeCSS")
@@ -324,7 +463,7 @@ func ServePage(c *gin.Context) {
 	<meta charset="utf-8">
 	<meta name="viewport" content="width=device-width, initial-scale=1">
 	<title>%s</title>
-	<style>
+%s	<style>
 		%s
 		body { font-family: system-ui, sans-serif; max-width: 800px; margin: 0 auto; padding: 0 20px 20px; line-height: 1.6; }
 		.text-block { margin-bottom: 1.5em; }
@@ -352,12 +491,13 @@ func ServePage(c *gin.Context) {
 			<button type="submit">Search</button>
 		</form>
 	</div>
+	%s
 	<h1>%s</h1>
 	%s
 	%s
 </body>
 </html>
-`, page.Title, GetDesignSystemCSS()+"\n"+themeCSSStr, getGoogleAnalyticsScript(site), renderHeader(site, navigationLinks), page.Title, content.String(), renderFooter(site, true))
+`, page.Title, pageMeta(site, &page).Tags(), GetDesignSystemCSS()+"\n"+themeCSSStr, getGoogleAnalyticsScript(site), renderHeader(c, site, navigationLinks), renderBreadcrumbs(&page), page.Title, content, renderFooter(site, true))
 
 	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
 }
@@ -383,10 +523,14 @@ func ContactFormHandler(c *gin.Context) {
 	}
 
 	// Get navigation links for header
-	navigationLinks := renderNavigationLinks(site.ID)
+	navigationLinks := renderNavigationLinks(c, site)
 
 	// Handle POST requests (form submission)
 	if c.Request.Method == "POST" {
+		if rejectSpam(c, site) {
+			return
+		}
+
 		name := strings.TrimSpace(c.PostForm("name"))
 		senderEmail := strings.TrimSpace(c.PostForm("email"))
 		subject := strings.TrimSpace(c.PostForm("subject"))
@@ -405,25 +549,38 @@ func ContactFormHandler(c *gin.Context) {
 			return
 		}
 
+		release, ok := consumeSpamToken(c, site)
+		if !ok {
+			return
+		}
+
+		// Store the submission so it shows up in the admin inbox
+		submission := models.ContactSubmission{
+			SiteID:    site.ID,
+			Name:      name,
+			Email:     senderEmail,
+			Subject:   subject,
+			Message:   message,
+			IPAddress: c.ClientIP(),
+		}
+		if err := db.GetDB().Create(&submission).Error; err != nil {
+			release()
+			log.Printf("Error saving contact submission: %v", err)
+			c.String(http.StatusInternalServerError, "Error processing contact form")
+			return
+		}
+
 		// Sanitize inputs
 		name = html.EscapeString(name)
 		senderEmail = html.EscapeString(senderEmail)
 		subject = html.EscapeString(subject)
 		message = html.EscapeString(message)
 
-		// Load site owner to get admin email
+		// Queue notification email to site owner. The message is already saved,
+		// so failures here are logged rather than shown to the visitor.
 		var owner models.User
 		if err := db.GetDB().First(&owner, site.OwnerID).Error; err != nil {
 			log.Printf("Error loading site owner: %v", err)
-			c.String(http.StatusInternalServerError, "Error processing contact form")
-			return
-		}
-
-		// Send email to site owner
-		svc, err := email.NewEmailService()
-		if err != nil {
-			log.Printf("Error creating email service: %v", err)
-			// Don't expose error to user, just log it
 		} else {
 			emailSubject := fmt.Sprintf("Contact Form Submission: %s", subject)
 			emailBody := fmt.Sprintf(`New contact form submission:
@@ -437,9 +594,8 @@ Message:
 ---
 Do not reply to this email. To respond, contact the sender at: %s`, name, senderEmail, subject, message, senderEmail)
 
-			if err := svc.SendEmail(owner.Email, emailSubject, emailBody); err != nil {
-				log.Printf("Error sending contact email: %v", err)
-				// Don't expose error to user
+			if err := email.Enqueue(db.GetDB(), owner.Email, emailSubject, emailBody); err != nil {
+				log.Printf("Error queueing contact email: %v", err)
 			}
 		}
 
@@ -469,7 +625,7 @@ Do not reply to this email. To respond, contact the sender at: %s`, name, sender
 		%s
 	</div>
 </body>
-</html>`, site.SiteTitle, themeCSSStr, renderHeader(site, navigationLinks), renderFooter(site, true))
+</html>`, site.SiteTitle, themeCSSStr, renderHeader(c, site, navigationLinks), renderFooter(site, true))
 
 		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(successHTML))
 		return
@@ -518,6 +674,7 @@ Do not reply to this email. To respond, contact the sender at: %s`, name, sender
 	<div class="container">
 		<h1>Contact Us</h1>
 		<form method="POST" action="/contact">
+			%s
 			%s
 			<div class="form-group">
 				<label for="name">Name</label>
@@ -543,7 +700,7 @@ Do not reply to this email. To respond, contact the sender at: %s`, name, sender
 		%s
 	</div>
 </body>
-</html>`, site.SiteTitle, themeCSSStr, renderHeader(site, navigationLinks), csrfToken, renderFooter(site, true))
+</html>`, site.SiteTitle, themeCSSStr, renderHeader(c, site, navigationLinks), csrfToken, spamFieldsHTML(site), renderFooter(site, true))
 
 	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(formHTML))
 }
@@ -557,6 +714,12 @@ func RobotsTxtHandler(c *gin.Context) {
 	}
 	s := site.(*models.Site)
 
+	// Private sites ask every crawler to stay away
+	if !visibility.IsPublicSite(s) {
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte("User-agent: *\nDisallow: /\n"))
+		return
+	}
+
 	// Get the domain for the sitemap URL
 	var domain string
 	if s.CustomDomain != nil && *s.CustomDomain != "" {
@@ -578,6 +741,24 @@ Sitemap: https://%s/sitemap.xml
 	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(robotsTxt))
 }
 
+// siteDomain returns the public hostname for a site: its custom domain if set,
+// otherwise its subdomain of the configured base domain
+func siteDomain(s *models.Site) string {
+	if s.CustomDomain != nil && *s.CustomDomain != "" {
+		return *s.CustomDomain
+	}
+	baseDomain := config.GetString("server.base_domain")
+	if baseDomain == "" {
+		baseDomain = "localhost"
+	}
+	return s.Subdomain + "." + baseDomain
+}
+
+// SiteURL returns a site's public base URL, e.g. "https://mycamp.example.com"
+func SiteURL(s *models.Site) string {
+	return "https://" + siteDomain(s)
+}
+
 // SitemapXMLHandler generates sitemap.xml for the site
 func SitemapXMLHandler(c *gin.Context) {
 	site, exists := c.Get("site")
@@ -587,21 +768,11 @@ func SitemapXMLHandler(c *gin.Context) {
 	}
 	s := site.(*models.Site)
 
-	// Get the domain
-	var domain string
-	if s.CustomDomain != nil && *s.CustomDomain != "" {
-		domain = *s.CustomDomain
-	} else {
-		baseDomain := config.GetString("server.base_domain")
-		if baseDomain == "" {
-			baseDomain = "localhost"
-		}
-		domain = s.Subdomain + "." + baseDomain
-	}
+	domain := siteDomain(s)
 
-	// Get all pages for this site
-	var pages []models.Page
-	db.GetDB().Where("site_id = ?", s.ID).Order("updated_at DESC").Find(&pages)
+	// Get the pages search engines should see
+	var sitePages []models.Page
+	db.GetDB().Where("site_id = ? AND published = ? AND no_index = ? AND visibility = ?", s.ID, true, false, visibility.Public).Order("updated_at DESC").Find(&sitePages)
 
 	// Build sitemap XML
 	xml := `<?xml version="1.0" encoding="UTF-8"?>
@@ -609,12 +780,11 @@ func SitemapXMLHandler(c *gin.Context) {
 `
 
 	// Add each page to the sitemap
-	for _, page := range pages {
-		var url string
-		if page.Slug == "home" || page.Slug == "" {
-			url = fmt.Sprintf("https://%s/", domain)
-		} else {
-			url = fmt.Sprintf("https://%s/%s", domain, page.Slug)
+	for _, page := range sitePages {
+		url := fmt.Sprintf("https://%s%s", domain, page.Slug)
+		// Pages that name another canonical URL leave the sitemap to that one
+		if page.CanonicalURL != "" && page.CanonicalURL != url {
+			continue
 		}
 
 		// Format the last modified date in W3C format
@@ -622,7 +792,7 @@ func SitemapXMLHandler(c *gin.Context) {
 
 		// Set priority based on whether it's the homepage
 		priority := "0.8"
-		if page.Slug == "home" || page.Slug == "" {
+		if page.Slug == pages.HomepageSlug {
 			priority = "1.0"
 		}
 
@@ -632,7 +802,19 @@ func SitemapXMLHandler(c *gin.Context) {
     <changefreq>weekly</changefreq>
     <priority>%s</priority>
   </url>
-`, url, lastmod, priority)
+`, html.EscapeString(url), lastmod, priority)
+	}
+
+	// Add published blog posts
+	postList, _ := posts.Latest(db.GetDB(), s.ID, "", 1000, time.Now())
+	for _, post := range postList {
+		xml += fmt.Sprintf(`  <url>
+    <loc>https://%s%s</loc>
+    <lastmod>%s</lastmod>
+    <changefreq>monthly</changefreq>
+    <priority>0.6</priority>
+  </url>
+`, domain, posts.URL(&post), post.UpdatedAt.Format("2006-01-02T15:04:05-07:00"))
 	}
 
 	xml += `</urlset>`
@@ -38,7 +38,7 @@ func SearchHandler(c *gin.Context) {
 	}
 
 	// Render navigation
-	navigation := renderNavigation(site.ID)
+	navigation := renderNavigation(c, site)
 
 	// Get theme CSS from context
 	themeCSS, _ := c.Get("themeCSS")
@@ -27,7 +27,7 @@ func setupSearchTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// Migrate models
-	if err := testDB.AutoMigrate(&models.User{}, &models.Site{}, &models.Page{}, &models.Block{}); err != nil {
+	if err := testDB.AutoMigrate(&models.User{}, &models.Site{}, &models.Page{}, &models.Block{}, &models.Post{}, &models.PostTag{}); err != nil {
 		t.Fatalf("Failed to migrate: %v", err)
 	}
 
@@ -45,6 +45,9 @@ func setupSearchTestDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Skipf("Skipping test: FTS5 not available in test environment: %v", err)
 	}
+	if _, err := sqlDB.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(post_id UNINDEXED, site_id UNINDEXED, title, content)`); err != nil {
+		t.Fatalf("Failed to create posts FTS index: %v", err)
+	}
 
 	return testDB
 }
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/seo"
+	"github.com/thatcatcamp/stinkykitty/internal/visibility"
+)
+
+// shareImageURL returns the address of a page's generated share image. The
+// version parameter changes with the image, so it can be cached for a long time.
+func shareImageURL(site *models.Site, page *models.Page) string {
+	return fmt.Sprintf("/og-image/%d.png?v=%s", page.ID, media.ShareImageKey(site, page))
+}
+
+// pageMeta builds a page's SEO and social tags, using the generated share
+// image when the page doesn't have its own
+func pageMeta(site *models.Site, page *models.Page) seo.Meta {
+	meta := seo.ForPage(site, page, SiteURL(site))
+	if page.SocialImage == "" && visibility.IsPublic(page) {
+		meta.Image = SiteURL(site) + shareImageURL(site, page)
+	}
+	// Protected pages stay out of search engines even when a member views them
+	if !visibility.IsPublic(page) {
+		meta.NoIndex = true
+	}
+	return meta
+}
+
+// ShareImageHandler serves the generated 1200x630 Open Graph image for a published page
+func ShareImageHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	file := c.Param("file")
+	pageID, err := strconv.ParseUint(strings.TrimSuffix(file, ".png"), 10, 32)
+	if err != nil || !strings.HasSuffix(file, ".png") {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	var page models.Page
+	// The homepage is served whether or not it's published, so its image is too
+	if err := db.GetDB().Where("id = ? AND site_id = ? AND (published = ? OR slug = ?) AND visibility = ?", pageID, site.ID, true, "/", visibility.Public).First(&page).Error; err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	path, err := media.GenerateShareImage(site, &page)
+	if err != nil {
+		log.Printf("Error generating share image for page %d: %v", page.ID, err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.File(path)
+}
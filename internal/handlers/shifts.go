@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/auth"
+	"github.com/thatcatcamp/stinkykitty/internal/blocks"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/email"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/shifts"
+)
+
+// ShiftCancelKey is the signing key for volunteer cancel links
+func ShiftCancelKey() []byte {
+	return auth.SigningKey("shift-cancel")
+}
+
+// renderShiftsBlock renders a shift sign-up block with the site's upcoming shifts
+func renderShiftsBlock(site *models.Site, dataJSON string) (string, error) {
+	data, err := blocks.ParseShiftsBlockData(dataJSON)
+	if err != nil {
+		return "", err
+	}
+	slots, err := shifts.Upcoming(db.GetDB(), site.ID, data.Role, time.Now())
+	if err != nil {
+		return "", err
+	}
+	return blocks.RenderShiftsBlock(dataJSON, slots, spamFieldsHTML(site))
+}
+
+// ShiftSignupHandler signs a volunteer up for a shift and emails a confirmation
+func ShiftSignupHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid shift")
+		return
+	}
+	var shift models.Shift
+	if err := db.GetDB().Where("id = ? AND site_id = ?", id, site.ID).First(&shift).Error; err != nil {
+		c.String(http.StatusNotFound, "Shift not found")
+		return
+	}
+
+	if rejectSpam(c, site) {
+		return
+	}
+
+	name := strings.TrimSpace(c.PostForm("name"))
+	emailAddr := strings.TrimSpace(c.PostForm("email"))
+	var errs []string
+	if name == "" || len(name) > 200 {
+		errs = append(errs, "Please enter your name")
+	}
+	if _, err := mail.ParseAddress(emailAddr); err != nil || len(emailAddr) > 254 {
+		errs = append(errs, "Please enter a valid email address")
+	}
+	if len(errs) > 0 {
+		renderSimplePage(c, site, http.StatusBadRequest, "Please fix the following",
+			fmt.Sprintf(`<div class="error-message">%s</div>
+		<p><a href="javascript:history.back()">← Go back and try again</a></p>`, html.EscapeString(strings.Join(errs, ". "))))
+		return
+	}
+
+	release, ok := consumeSpamToken(c, site)
+	if !ok {
+		return
+	}
+	signup, err := shifts.SignUp(db.GetDB(), &shift, name, emailAddr, c.ClientIP(), time.Now())
+	if err != nil {
+		release()
+		if errors.Is(err, shifts.ErrShiftFull) || errors.Is(err, shifts.ErrShiftStarted) || errors.Is(err, shifts.ErrAlreadySignedUp) {
+			renderSimplePage(c, site, http.StatusConflict, "Couldn't Sign You Up",
+				fmt.Sprintf(`<div class="error-message">Sorry, %s.</div>
+		<p><a href="javascript:history.back()">← Pick another shift</a></p>`, html.EscapeString(err.Error())))
+			return
+		}
+		log.Printf("Error saving shift signup: %v", err)
+		c.String(http.StatusInternalServerError, "Error processing sign-up")
+		return
+	}
+
+	subject, body := shifts.ConfirmationEmail(site, &shift, signup, shifts.CancelURL(ShiftCancelKey(), SiteURL(site), signup))
+	if err := email.Enqueue(db.GetDB(), signup.Email, subject, body); err != nil {
+		log.Printf("Error queueing shift confirmation: %v", err)
+	}
+
+	renderSimplePage(c, site, http.StatusOK, "You're Signed Up",
+		fmt.Sprintf(`<div class="success-message">Thanks, %s! You're signed up for <strong>%s</strong> on %s.</div>
+		<p>We've emailed a confirmation to %s with a link to cancel if your plans change.</p>`,
+			html.EscapeString(signup.Name), html.EscapeString(shift.Role), html.EscapeString(shifts.When(&shift)), html.EscapeString(signup.Email)))
+}
+
+// ShiftCancelHandler lets a volunteer cancel a sign-up from the link in their
+// email. GET asks for confirmation so link previews can't cancel by accident;
+// POST cancels.
+func ShiftCancelHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	token := c.Query("token")
+	if c.Request.Method == http.MethodPost {
+		token = c.PostForm("token")
+	}
+
+	var signup models.ShiftSignup
+	err := db.GetDB().Preload("Shift").Where("id = ? AND site_id = ?", c.Param("id"), site.ID).First(&signup).Error
+	if err != nil || !shifts.ValidCancelToken(ShiftCancelKey(), &signup, token) {
+		renderSimplePage(c, site, http.StatusNotFound, "Sign-up Not Found",
+			`<div class="error-message">This sign-up has already been cancelled, or the link is invalid.</div>`)
+		return
+	}
+
+	if c.Request.Method != http.MethodPost {
+		renderSimplePage(c, site, http.StatusOK, "Cancel Sign-up",
+			fmt.Sprintf(`<p>Cancel %s's sign-up for <strong>%s</strong> on %s?</p>
+		<form method="POST" action="/shift-signups/%d/cancel">
+			<input type="hidden" name="token" value="%s">
+			<button type="submit" class="btn">Yes, cancel my sign-up</button>
+		</form>`, html.EscapeString(signup.Name), html.EscapeString(signup.Shift.Role), html.EscapeString(shifts.When(&signup.Shift)),
+				signup.ID, html.EscapeString(token)))
+		return
+	}
+
+	if err := db.GetDB().Delete(&signup).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to cancel sign-up")
+		return
+	}
+
+	renderSimplePage(c, site, http.StatusOK, "Sign-up Cancelled",
+		fmt.Sprintf(`<div class="success-message">You're no longer signed up for %s on %s. Thanks for letting us know!</div>`,
+			html.EscapeString(signup.Shift.Role), html.EscapeString(shifts.When(&signup.Shift))))
+}
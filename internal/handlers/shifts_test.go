@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/shifts"
+	"gorm.io/gorm"
+)
+
+func setupShiftsTest(t *testing.T) (*gorm.DB, *models.Site, *models.Shift) {
+	gin.SetMode(gin.TestMode)
+	testDB := setupTestDB(t)
+	if err := testDB.AutoMigrate(&models.User{}, &models.MenuItem{}, &models.Shift{}, &models.ShiftSignup{}, &models.OutboundEmail{}, &models.SpamCounter{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	db.SetDB(testDB)
+
+	site := &models.Site{ID: 1, Subdomain: "test", OwnerID: 1, SiteDir: "/tmp/test", SiteTitle: "Test Camp"}
+	testDB.Create(site)
+	start := time.Now().Add(72 * time.Hour)
+	shift := &models.Shift{SiteID: site.ID, Role: "Gate", StartsAt: start, EndsAt: start.Add(4 * time.Hour), Capacity: 1}
+	testDB.Create(shift)
+	return testDB, site, shift
+}
+
+func postShiftSignup(site *models.Site, name, email string) *httptest.ResponseRecorder {
+	form := validSpamFields(site)
+	form.Set("name", name)
+	form.Set("email", email)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/shifts/1/signup", strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set("site", site)
+
+	ShiftSignupHandler(c)
+	return w
+}
+
+func TestShiftSignupHandler(t *testing.T) {
+	testDB, site, _ := setupShiftsTest(t)
+
+	w := postShiftSignup(site, "Mittens", "mittens@example.com")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var queued models.OutboundEmail
+	if err := testDB.First(&queued).Error; err != nil {
+		t.Fatalf("Expected confirmation email to be queued: %v", err)
+	}
+	if queued.To != "mittens@example.com" || !strings.Contains(queued.Body, "https://test.localhost/shift-signups/1/cancel?token=") {
+		t.Errorf("Unexpected confirmation email: %+v", queued)
+	}
+
+	// Capacity is 1, so the next volunteer is turned away
+	w = postShiftSignup(site, "Socks", "socks@example.com")
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a full shift, got %d", w.Code)
+	}
+	var count int64
+	testDB.Model(&models.ShiftSignup{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected 1 sign-up, got %d", count)
+	}
+}
+
+func TestShiftCancelHandler(t *testing.T) {
+	testDB, site, shift := setupShiftsTest(t)
+
+	signup := &models.ShiftSignup{SiteID: site.ID, ShiftID: shift.ID, Name: "Mittens", Email: "mittens@example.com"}
+	testDB.Create(signup)
+	token := shifts.CancelToken(ShiftCancelKey(), signup)
+
+	// A bad token is refused
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/shift-signups/1/cancel", strings.NewReader(url.Values{"token": {"nope"}}.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set("site", site)
+	ShiftCancelHandler(c)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a bad token, got %d", w.Code)
+	}
+
+	// GET only asks for confirmation
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/shift-signups/1/cancel?token="+token, nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set("site", site)
+	ShiftCancelHandler(c)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Yes, cancel my sign-up") {
+		t.Fatalf("Expected confirmation page, got %d", w.Code)
+	}
+	var count int64
+	testDB.Model(&models.ShiftSignup{}).Count(&count)
+	if count != 1 {
+		t.Fatal("GET must not cancel the sign-up")
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/shift-signups/1/cancel", strings.NewReader(url.Values{"token": {token}}.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set("site", site)
+	ShiftCancelHandler(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	testDB.Model(&models.ShiftSignup{}).Count(&count)
+	if count != 0 {
+		t.Error("Expected sign-up to be cancelled")
+	}
+}
+
+func TestShiftRosterExportHandler(t *testing.T) {
+	testDB, site, shift := setupShiftsTest(t)
+	testDB.Create(&models.ShiftSignup{SiteID: site.ID, ShiftID: shift.ID, Name: "=cmd()", Email: "a@example.com"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/shifts/export", nil)
+	c.Set("site", site)
+
+	ShiftRosterExportHandler(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.HasPrefix(body, "Role,Starts,Ends,Capacity,Name,Email,Signed Up") {
+		t.Errorf("Unexpected CSV header: %s", body)
+	}
+	if !strings.Contains(body, "Gate,") || !strings.Contains(body, "'=cmd()") {
+		t.Errorf("Expected roster row with formula escaped, got:\n%s", body)
+	}
+}
@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/auth"
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/visibility"
+)
+
+// siteUnlockPath is where the private site interstitial posts its password
+const siteUnlockPath = "/site-unlock"
+
+// privateSiteExempt reports whether a path stays reachable on a private site:
+// the admin (so members can log in), robots.txt (so crawlers are told to stay
+// away), the favicon and the unlock form itself
+func privateSiteExempt(path string) bool {
+	switch path {
+	case "/admin", "/robots.txt", "/favicon.ico", siteUnlockPath:
+		return true
+	}
+	return strings.HasPrefix(path, "/admin/")
+}
+
+// PrivateSiteMiddleware keeps visitors out of sites that aren't public yet.
+// Password and members-only sites show a login interstitial; IP allowlisted
+// sites turn away visitors from other addresses. Members can always see the
+// site. Media under /assets is served outside the site group and isn't covered.
+func PrivateSiteMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		siteVal, exists := c.Get("site")
+		if !exists {
+			c.Next()
+			return
+		}
+		site := siteVal.(*models.Site)
+		if visibility.IsPublicSite(site) || privateSiteExempt(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		// Private pages must not be cached by proxies or indexed
+		c.Header("Cache-Control", "private, no-store")
+		c.Header("X-Robots-Tag", "noindex, nofollow")
+
+		if site.Visibility == visibility.IPAllowlist {
+			// An emptied allowlist lets nobody in rather than everybody
+			if len(allowedIPs(site)) > 0 && middleware.SiteAllowsClient(c, site) {
+				c.Next()
+				return
+			}
+			renderSiteInterstitial(c, site, http.StatusForbidden, "")
+			c.Abort()
+			return
+		}
+
+		if pageViewer(c, site).CanEnterSite(site) {
+			c.Next()
+			return
+		}
+		renderSiteInterstitial(c, site, http.StatusUnauthorized, "")
+		c.Abort()
+	}
+}
+
+// renderSiteInterstitial shows the branded page visitors see in place of a
+// private site. It leaves out the menu so page titles aren't revealed.
+func renderSiteInterstitial(c *gin.Context, site *models.Site, status int, errMsg string) {
+	themeCSS, _ := c.Get("themeCSS")
+	themeCSSStr, _ := themeCSS.(string)
+
+	logoHTML := ""
+	if site.LogoPath != "" {
+		logoHTML = `<img src="` + html.EscapeString(site.LogoPath) + `" alt="" class="interstitial-logo">`
+	}
+	errorHTML := ""
+	if errMsg != "" {
+		errorHTML = `<div class="error-message">` + html.EscapeString(errMsg) + `</div>`
+	}
+
+	var body string
+	switch site.Visibility {
+	case visibility.Password:
+		body = fmt.Sprintf(`<p>This site is password protected.</p>
+		%s
+		<form method="POST" action="%s" class="interstitial-form">
+			<input type="hidden" name="next" value="%s">
+			<input type="password" name="password" placeholder="Password" required autofocus>
+			<button type="submit" class="btn">Enter</button>
+		</form>
+		<p class="interstitial-note">Members can <a href="/admin/login">log in</a> instead.</p>`,
+			errorHTML, siteUnlockPath, html.EscapeString(c.Request.URL.RequestURI()))
+	case visibility.Members:
+		body = `<p>This site is only open to its members.</p>
+		<p><a href="/admin/login" class="btn">Log In</a></p>`
+	default:
+		body = `<p>This site isn't open to visitors from your network yet.</p>`
+	}
+
+	page := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<meta name="robots" content="noindex, nofollow">
+	<title>%s</title>
+	<style>
+		%s
+		body { font-family: system-ui; margin: 0; padding: 20px; background: var(--color-bg, #f1f5f9); }
+		.interstitial { max-width: 420px; margin: 10vh auto; padding: 32px; text-align: center; background: var(--color-surface, #fff); border: 1px solid var(--color-border, #e2e8f0); border-radius: 8px; }
+		.interstitial h1 { margin: 0 0 8px; color: var(--color-primary, #2563eb); }
+		.interstitial-logo { max-width: 120px; max-height: 120px; margin-bottom: 16px; }
+		.interstitial-form { display: flex; gap: 10px; }
+		.interstitial-form input[type=password] { flex: 1; padding: 10px; font-size: 16px; }
+		.interstitial-note { font-size: 14px; color: var(--color-text-muted, #64748b); }
+		.error-message { background: #f8d7da; border: 1px solid #f5c6cb; color: #721c24; padding: 12px; border-radius: 4px; margin-bottom: 16px; }
+		a { color: var(--color-primary, #2563eb); }
+	</style>
+</head>
+<body>
+	<div class="interstitial">
+		%s
+		<h1>%s</h1>
+		%s
+	</div>
+</body>
+</html>`, html.EscapeString(site.SiteTitle), GetDesignSystemCSS()+"\n"+themeCSSStr,
+		logoHTML, html.EscapeString(site.SiteTitle), body)
+
+	c.Data(status, "text/html; charset=utf-8", []byte(page))
+}
+
+// SiteUnlockHandler checks a password-protected site's password and
+// remembers it in the viewer cookie
+func SiteUnlockHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	if site.Visibility != visibility.Password {
+		c.Redirect(http.StatusFound, "/")
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	if site.PasswordHash == "" || !auth.CheckPassword(c.PostForm("password"), site.PasswordHash) {
+		renderSiteInterstitial(c, site, http.StatusUnauthorized, "That password isn't right.")
+		return
+	}
+
+	grants := pageViewer(c, site).Grants
+	grants.Unlock(visibility.SiteGrantID, visibility.PasswordVersion(site.PasswordHash), time.Now())
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(
+		visibility.CookieName,
+		visibility.EncodeGrants(viewerKey(), site.ID, grants, time.Now().Add(visibility.CookieLifetime)),
+		int(visibility.CookieLifetime.Seconds()),
+		"/",
+		"",
+		config.GetBool("server.tls_enabled"),
+		true,
+	)
+
+	// Only follow local paths, so the form can't be used as an open redirect
+	next := c.PostForm("next")
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, `/\`) || next == siteUnlockPath {
+		next = "/"
+	}
+	c.Redirect(http.StatusFound, next)
+}
+
+// SetSiteVisibility sets who can visit a site. A password is required for
+// password-protected sites unless one is already set, and IP allowlist mode
+// needs at least one allowed range.
+func SetSiteVisibility(site *models.Site, mode, password string) error {
+	switch {
+	case !visibility.ValidSite(mode):
+		return visibility.ErrInvalidSiteMode
+	case mode == visibility.Password && password == "" && site.PasswordHash == "":
+		return visibility.ErrSitePasswordRequired
+	case mode == visibility.IPAllowlist && len(allowedIPs(site)) == 0:
+		return visibility.ErrAllowlistRequired
+	}
+
+	site.Visibility = mode
+	if password != "" {
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			return fmt.Errorf("failed to set password: %w", err)
+		}
+		site.PasswordHash = hash
+	}
+
+	if err := db.GetDB().Model(site).Select("visibility", "password_hash").Updates(site).Error; err != nil {
+		return fmt.Errorf("failed to update site: %w", err)
+	}
+	return nil
+}
+
+// allowedIPs returns a site's IP allowlist
+func allowedIPs(site *models.Site) []string {
+	var ranges []string
+	if site.AllowedIPs != "" {
+		json.Unmarshal([]byte(site.AllowedIPs), &ranges)
+	}
+	return ranges
+}
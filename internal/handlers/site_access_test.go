@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/auth"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/visibility"
+)
+
+// privateSiteRouter serves a site's pages behind the private site middleware
+func privateSiteRouter(site *models.Site) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("site", site) }, PrivateSiteMiddleware())
+	r.GET("/robots.txt", RobotsTxtHandler)
+	r.GET("/admin/login", func(c *gin.Context) { c.String(http.StatusOK, "login") })
+	r.POST("/site-unlock", SiteUnlockHandler)
+	r.NoRoute(ServePage)
+	return r
+}
+
+func TestPrivateSite_PasswordInterstitial(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/about", Title: "About", Published: true})
+	hash, _ := auth.HashPassword("preview")
+	site.Visibility = visibility.Password
+	site.PasswordHash = hash
+	r := privateSiteRouter(site)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/about", nil))
+	body := w.Body.String()
+	if w.Code != http.StatusUnauthorized || !strings.Contains(body, `action="/site-unlock"`) || !strings.Contains(body, "Test Camp") {
+		t.Fatalf("Expected the branded password page, got %d:\n%s", w.Code, body)
+	}
+	if strings.Contains(body, "About") || w.Header().Get("X-Robots-Tag") == "" {
+		t.Error("Expected the interstitial to hide the menu and be noindex")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/login", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected the admin login to stay reachable, got %d", w.Code)
+	}
+
+	form := url.Values{"password": {"preview"}, "next": {"/about"}}
+	req := httptest.NewRequest("POST", "/site-unlock", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	cookies := w.Result().Cookies()
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/about" || len(cookies) != 1 {
+		t.Fatalf("Expected a redirect back with the viewer cookie, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	req = httptest.NewRequest("GET", "/about", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected the unlocked site, got %d", w.Code)
+	}
+}
+
+func TestSiteUnlockHandler_RejectsOffsiteRedirect(t *testing.T) {
+	_, site := setupPostsTest(t)
+	hash, _ := auth.HashPassword("preview")
+	site.Visibility = visibility.Password
+	site.PasswordHash = hash
+
+	c, _ := postFormWithSite(site, "/site-unlock", nil, url.Values{"password": {"preview"}, "next": {"//evil.example"}}, SiteUnlockHandler)
+	if loc := c.Writer.Header().Get("Location"); loc != "/" {
+		t.Errorf("Expected an offsite next to fall back to /, got %q", loc)
+	}
+}
+
+func TestPrivateSite_IPAllowlist(t *testing.T) {
+	_, site := setupPostsTest(t)
+	site.Visibility = visibility.IPAllowlist
+	site.AllowedIPs = `["10.0.0.0/8"]`
+	r := privateSiteRouter(site)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.168.1.5:1234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected visitors outside the allowlist to be refused, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/robots.txt", nil)
+	req.RemoteAddr = "192.168.1.5:1234"
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Disallow: /") {
+		t.Errorf("Expected robots.txt to disallow everything, got %d:\n%s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.1.2.3:1234"
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code == http.StatusForbidden {
+		t.Error("Expected visitors from the allowlist to get in")
+	}
+}
+
+func TestSetSiteVisibility_Validation(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+
+	if err := SetSiteVisibility(site, "secret", ""); err != visibility.ErrInvalidSiteMode {
+		t.Errorf("Expected ErrInvalidSiteMode, got %v", err)
+	}
+	if err := SetSiteVisibility(site, visibility.Password, ""); err != visibility.ErrSitePasswordRequired {
+		t.Errorf("Expected ErrSitePasswordRequired, got %v", err)
+	}
+	if err := SetSiteVisibility(site, visibility.IPAllowlist, ""); err != visibility.ErrAllowlistRequired {
+		t.Errorf("Expected ErrAllowlistRequired, got %v", err)
+	}
+	if err := SetSiteVisibility(site, visibility.Members, ""); err != nil {
+		t.Fatalf("SetSiteVisibility: %v", err)
+	}
+
+	var saved models.Site
+	if err := testDB.First(&saved, site.ID).Error; err != nil || saved.Visibility != visibility.Members {
+		t.Errorf("Expected members mode to be saved, got %q (%v)", saved.Visibility, err)
+	}
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/antispam"
+	"github.com/thatcatcamp/stinkykitty/internal/auth"
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/middleware"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// formGuard protects the contact form and form blocks. Tests may set it
+	// before first use to override the config-driven defaults.
+	formGuard     *antispam.Guard
+	formGuardOnce sync.Once
+)
+
+// publicFormGuard returns the spam guard for public forms, configured from the spam.* settings
+func publicFormGuard() *antispam.Guard {
+	formGuardOnce.Do(func() {
+		if formGuard != nil {
+			return
+		}
+		g := antispam.NewGuard(auth.SigningKey("public-forms"))
+		if d := config.GetDuration("spam.min_fill_time"); d > 0 {
+			g.MinFillTime = d
+		}
+		g.PowDifficulty = config.GetInt("spam.pow_difficulty")
+		if limit := config.GetInt("spam.rate_limit"); limit > 0 {
+			interval := config.GetDuration("spam.rate_interval")
+			if interval <= 0 {
+				interval = 10 * time.Minute
+			}
+			g.Limiter = middleware.NewRateLimiter(limit, interval)
+		}
+		formGuard = g
+	})
+	return formGuard
+}
+
+// spamScope ties spam tokens to a single site
+func spamScope(site *models.Site) string {
+	return fmt.Sprintf("site:%d", site.ID)
+}
+
+// spamFieldsHTML returns the hidden anti-spam inputs to embed in a public form
+func spamFieldsHTML(site *models.Site) string {
+	return publicFormGuard().FieldsHTML(spamScope(site), time.Now())
+}
+
+// rejectSpam checks a public form submission. If it looks like spam, the
+// rejection is counted, a response is written and true is returned.
+func rejectSpam(c *gin.Context, site *models.Site) bool {
+	err := publicFormGuard().Check(spamScope(site), c.ClientIP(), c.PostForm, time.Now())
+	if err == nil {
+		return false
+	}
+	respondSpam(c, site, err)
+	return true
+}
+
+// consumeSpamToken uses up a submission's anti-spam token. Handlers call it
+// once the submission has passed validation, and call release if saving it
+// fails. If the token was already used, a response is written and false is
+// returned.
+func consumeSpamToken(c *gin.Context, site *models.Site) (release func(), ok bool) {
+	release, err := publicFormGuard().Consume(spamScope(site), c.PostForm, time.Now())
+	if err != nil {
+		respondSpam(c, site, err)
+		return nil, false
+	}
+	return release, true
+}
+
+// respondSpam counts a rejected submission and writes the matching response
+func respondSpam(c *gin.Context, site *models.Site, err error) {
+	var rejection *antispam.Rejection
+	reason := antispam.ReasonInvalidToken
+	if errors.As(err, &rejection) {
+		reason = rejection.Reason
+	}
+	recordSpamRejection(site.ID, reason)
+
+	switch reason {
+	case antispam.ReasonRateLimited:
+		renderSimplePage(c, site, http.StatusTooManyRequests, "Slow Down",
+			`<div class="error-message">You've sent several submissions in a short time. Please wait a few minutes and try again.</div>`)
+	case antispam.ReasonTooFast, antispam.ReasonInvalidToken:
+		renderSimplePage(c, site, http.StatusBadRequest, "Please Try Again",
+			`<div class="error-message">This form has expired or was submitted too quickly. Please go back, reload the page and try again.</div>
+		<p><a href="javascript:history.back()">← Go back</a></p>`)
+	default:
+		renderSimplePage(c, site, http.StatusBadRequest, "Submission Not Accepted",
+			`<div class="error-message">Your submission could not be accepted.</div>`)
+	}
+}
+
+// recordSpamRejection increments today's counter for a site and reason
+func recordSpamRejection(siteID uint, reason string) {
+	counter := models.SpamCounter{
+		SiteID: siteID,
+		Day:    time.Now().UTC().Format("2006-01-02"),
+		Reason: reason,
+		Hits:   1,
+	}
+	err := db.GetDB().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "site_id"}, {Name: "day"}, {Name: "reason"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"hits": gorm.Expr("hits + 1")}),
+	}).Create(&counter).Error
+	if err != nil {
+		log.Printf("Warning: failed to record spam rejection: %v", err)
+	}
+}
+
+// renderSpamSummary renders a one-line summary of rejected submissions over the last 30 days
+func renderSpamSummary(siteID uint) string {
+	type reasonTotal struct {
+		Reason string
+		Total  int
+	}
+	var totals []reasonTotal
+	since := time.Now().UTC().AddDate(0, 0, -30).Format("2006-01-02")
+	db.GetDB().Model(&models.SpamCounter{}).
+		Select("reason, SUM(hits) AS total").
+		Where("site_id = ? AND day >= ?", siteID, since).
+		Group("reason").Scan(&totals)
+
+	sum := 0
+	var parts []string
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Total > totals[j].Total })
+	for _, t := range totals {
+		sum += t.Total
+		label := antispam.ReasonLabels[t.Reason]
+		if label == "" {
+			label = t.Reason
+		}
+		parts = append(parts, fmt.Sprintf("%s: %d", html.EscapeString(label), t.Total))
+	}
+
+	if sum == 0 {
+		return `<p style="color: #666; font-size: 13px;">🛡️ No spam submissions blocked in the last 30 days.</p>`
+	}
+	return fmt.Sprintf(`<p style="color: #666; font-size: 13px;">🛡️ Blocked <strong>%d</strong> spam submissions in the last 30 days (%s).</p>`,
+		sum, strings.Join(parts, " · "))
+}
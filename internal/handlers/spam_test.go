@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/antispam"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// validSpamFields installs a predictable spam guard and returns form values
+// that pass it, as if the form had been rendered a minute ago
+func validSpamFields(site *models.Site) url.Values {
+	formGuard = antispam.NewGuard([]byte("test-key"))
+	return url.Values{
+		antispam.TokenField: {formGuard.IssueToken(spamScope(site), time.Now().Add(-time.Minute))},
+	}
+}
+
+func TestFormSubmitHandler_RejectsHoneypot(t *testing.T) {
+	testDB, site, _ := setupFormTest(t)
+
+	form := url.Values{"f_name": {"Bot"}, "f_email": {"bot@example.com"}, antispam.HoneypotField: {"http://spam.example"}}
+	w := postForm(site, "1", form)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+
+	var count int64
+	testDB.Model(&models.FormSubmission{}).Count(&count)
+	if count != 0 {
+		t.Errorf("Expected honeypot submission to be discarded, got %d stored", count)
+	}
+
+	var counter models.SpamCounter
+	if err := testDB.Where("site_id = ? AND reason = ?", site.ID, antispam.ReasonHoneypot).First(&counter).Error; err != nil {
+		t.Fatalf("Expected rejection to be counted: %v", err)
+	}
+	if counter.Hits != 1 {
+		t.Errorf("Expected 1 hit, got %d", counter.Hits)
+	}
+}
+
+func TestFormSubmitHandler_RejectsMissingToken(t *testing.T) {
+	_, site, _ := setupFormTest(t)
+
+	form := url.Values{"f_name": {"Bot"}, "f_email": {"bot@example.com"}, antispam.TokenField: {""}}
+	w := postForm(site, "1", form)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestRecordSpamRejectionAccumulates(t *testing.T) {
+	testDB, site, _ := setupFormTest(t)
+
+	for i := 0; i < 3; i++ {
+		recordSpamRejection(site.ID, antispam.ReasonTooFast)
+	}
+	recordSpamRejection(site.ID, antispam.ReasonHoneypot)
+
+	var counters []models.SpamCounter
+	testDB.Find(&counters)
+	if len(counters) != 2 {
+		t.Fatalf("Expected 2 counter rows, got %d", len(counters))
+	}
+
+	summary := renderSpamSummary(site.ID)
+	if !contains(summary, "<strong>4</strong>") || !contains(summary, "Submitted too fast: 3") {
+		t.Errorf("Unexpected summary: %s", summary)
+	}
+}
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/media"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/staticsite"
+	"github.com/thatcatcamp/stinkykitty/internal/themes"
+	"github.com/thatcatcamp/stinkykitty/internal/visibility"
+	"gorm.io/gorm"
+)
+
+// StaticExportResult summarizes a static export
+type StaticExportResult struct {
+	Pages         int
+	Assets        int
+	MissingAssets []string
+}
+
+// siteThemeCSS generates a site's theme CSS, as the theme middleware does for requests
+func siteThemeCSS(site *models.Site) string {
+	palette := themes.GetPalette(site.ThemePalette)
+	if palette == nil {
+		palette = themes.GetPalette("slate")
+	}
+	return themes.GenerateCSS(themes.GenerateColors(palette, site.DarkMode))
+}
+
+// loadPublicPages loads the pages anyone can see, with their blocks. The
+// homepage is served whether or not it's published, so it's always included;
+// password and members-only pages never are.
+func loadPublicPages(siteID uint) ([]models.Page, error) {
+	var sitePages []models.Page
+	if err := db.GetDB().Where("site_id = ? AND (published = ? OR slug = ?) AND visibility = ?", siteID, true, "/", visibility.Public).
+		Preload("Blocks", func(db *gorm.DB) *gorm.DB {
+			return db.Order("`order` ASC")
+		}).
+		Order("slug ASC").Find(&sitePages).Error; err != nil {
+		return nil, fmt.Errorf("failed to load pages: %w", err)
+	}
+	return sitePages, nil
+}
+
+// renderForExport runs a public handler for a path and returns the page it renders
+func renderForExport(site *models.Site, target string, handler gin.HandlerFunc) ([]byte, error) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	c.Request.Host = siteDomain(site)
+	c.Set("site", site)
+	c.Set("themeCSS", siteThemeCSS(site))
+	c.Set("staticExport", true)
+	handler(c)
+	if w.Code != http.StatusOK {
+		return nil, fmt.Errorf("rendering %s returned status %d", target, w.Code)
+	}
+	return w.Body.Bytes(), nil
+}
+
+// renderPageForExport renders a page exactly as ServePage or ServeHomepage would
+func renderPageForExport(site *models.Site, page *models.Page) ([]byte, error) {
+	if page.Slug == "/" {
+		return renderForExport(site, page.Slug, ServeHomepage)
+	}
+	return renderForExport(site, page.Slug, ServePage)
+}
+
+// RenderStaticSite writes every published page of a site to outDir as plain
+// HTML, using the same rendering as the live site. Links and media are made
+// relative, only the media the pages use is copied, and a search page that
+// works without a server is added.
+func RenderStaticSite(site *models.Site, outDir string) (*StaticExportResult, error) {
+	sitePages, err := loadPublicPages(site.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	slugs := []string{staticsite.SearchSlug}
+	for _, page := range sitePages {
+		slugs = append(slugs, page.Slug)
+	}
+	rewriter := staticsite.NewRewriter(SiteURL(site), slugs)
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	var index []staticsite.SearchEntry
+	for i := range sitePages {
+		page := &sitePages[i]
+		doc, err := renderPageForExport(site, page)
+		if err != nil {
+			return nil, err
+		}
+		if err := staticsite.WritePage(outDir, page.Slug, rewriter.Rewrite(page.Slug, doc)); err != nil {
+			return nil, err
+		}
+		index = append(index, staticsite.SearchEntry{
+			Title: page.Title,
+			URL:   staticsite.PagePath(page.Slug),
+			Text:  staticsite.PlainText(renderBlocks(site, page.Blocks)),
+		})
+	}
+
+	// Search page, built from the same simple page template as form responses
+	doc, err := renderForExport(site, staticsite.SearchSlug, func(c *gin.Context) {
+		renderSimplePage(c, site, http.StatusOK, "Search", staticsite.SearchPageBody)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := staticsite.WritePage(outDir, staticsite.SearchSlug, rewriter.Rewrite(staticsite.SearchSlug, doc)); err != nil {
+		return nil, err
+	}
+	if err := staticsite.WriteSearchIndex(outDir, index); err != nil {
+		return nil, err
+	}
+
+	assets := rewriter.Assets()
+	missing, err := staticsite.CopyAssets(media.OpenUpload, outDir, assets)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StaticExportResult{
+		Pages:         len(sitePages),
+		Assets:        len(assets) - len(missing),
+		MissingAssets: missing,
+	}, nil
+}
+
+// StaticExportHandler downloads a zip of the site rendered as static HTML,
+// for offline copies and archives
+func StaticExportHandler(c *gin.Context) {
+	siteVal, exists := c.Get("site")
+	if !exists {
+		c.String(http.StatusInternalServerError, "Site not found")
+		return
+	}
+	site := siteVal.(*models.Site)
+
+	outDir, err := os.MkdirTemp("", "stinky-static-*")
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to create export")
+		return
+	}
+	defer os.RemoveAll(outDir)
+
+	result, err := RenderStaticSite(site, outDir)
+	if err != nil {
+		log.Printf("Static export of site %d failed: %v", site.ID, err)
+		c.String(http.StatusInternalServerError, "Failed to create export")
+		return
+	}
+	for _, name := range result.MissingAssets {
+		fmt.Printf("Warning: static export of site %d skipped missing file %s\n", site.ID, name)
+	}
+
+	filename := fmt.Sprintf("%s-static-%s.zip", site.Subdomain, time.Now().Format("2006-01-02"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Status(http.StatusOK)
+	if err := staticsite.ZipDir(c.Writer, outDir); err != nil {
+		log.Printf("Static export of site %d failed: %v", site.ID, err)
+	}
+}
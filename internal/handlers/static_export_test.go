@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MIT
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+func TestRenderStaticSite(t *testing.T) {
+	testDB, site := setupPostsTest(t)
+	config.InitConfig(filepath.Join(t.TempDir(), "config.yaml"))
+	mediaDir := t.TempDir()
+	config.Set("storage.media_dir", mediaDir)
+	os.MkdirAll(filepath.Join(mediaDir, "uploads"), 0755)
+	os.WriteFile(filepath.Join(mediaDir, "uploads", "camp.jpg"), []byte("jpeg"), 0644)
+	os.WriteFile(filepath.Join(mediaDir, "uploads", "unused.jpg"), []byte("jpeg"), 0644)
+
+	home := &models.Page{SiteID: site.ID, Slug: "/", Title: "Welcome", Published: true}
+	testDB.Create(home)
+	testDB.Create(&models.Block{PageID: home.ID, Type: "text", Order: 0, Data: `{"content":"Hello from camp"}`})
+	testDB.Create(&models.MenuItem{SiteID: site.ID, Label: "Info", URL: "/info"})
+	info := &models.Page{SiteID: site.ID, Slug: "/info", Title: "Info", Published: true}
+	testDB.Create(info)
+	testDB.Create(&models.Block{PageID: info.ID, Type: "image", Order: 0, Data: `{"url":"/assets/camp.jpg","alt":"Our camp"}`})
+	testDB.Create(&models.Page{SiteID: site.ID, Slug: "/draft", Title: "Draft"})
+
+	outDir := t.TempDir()
+	result, err := RenderStaticSite(site, outDir)
+	if err != nil {
+		t.Fatalf("RenderStaticSite failed: %v", err)
+	}
+	if result.Pages != 2 || result.Assets != 1 {
+		t.Errorf("Expected 2 pages and 1 asset, got %+v", result)
+	}
+
+	homeHTML, _ := os.ReadFile(filepath.Join(outDir, "index.html"))
+	if !strings.Contains(string(homeHTML), `href="info/index.html"`) || !strings.Contains(string(homeHTML), `action="search/index.html"`) {
+		t.Errorf("Expected relative links on the homepage, got:\n%s", homeHTML)
+	}
+	infoHTML, _ := os.ReadFile(filepath.Join(outDir, "info", "index.html"))
+	if !strings.Contains(string(infoHTML), `src="../assets/camp.jpg"`) {
+		t.Errorf("Expected a relative image path, got:\n%s", infoHTML)
+	}
+
+	if _, err := os.Stat(filepath.Join(outDir, "draft", "index.html")); !os.IsNotExist(err) {
+		t.Error("Unpublished pages must not be exported")
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "assets", "unused.jpg")); !os.IsNotExist(err) {
+		t.Error("Only referenced media should be copied")
+	}
+	index, err := os.ReadFile(filepath.Join(outDir, "search", "search-index.js"))
+	if err != nil || !strings.Contains(string(index), `"url":"info/index.html"`) {
+		t.Errorf("Expected the search index to list Info, got %s (%v)", index, err)
+	}
+}
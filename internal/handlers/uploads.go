@@ -7,7 +7,6 @@ import (
 	"path/filepath"
 
 	"github.com/gin-gonic/gin"
-	"github.com/thatcatcamp/stinkykitty/internal/config"
 	"github.com/thatcatcamp/stinkykitty/internal/db"
 	"github.com/thatcatcamp/stinkykitty/internal/media"
 	"github.com/thatcatcamp/stinkykitty/internal/models"
@@ -38,33 +37,65 @@ func UploadImageHandler(c *gin.Context) {
 		return
 	}
 
-	// Save the file to centralized storage
-	filename, err := media.SaveToCentralizedStorage(file)
+	upload, err := media.PrepareImage(file)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to save image: %v", err)})
 		return
 	}
 
+	// Reuse the copy already in the library
+	if existing := media.FindDuplicate(db.GetDB(), site.ID, upload.Hash); existing != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"url": "/assets/" + existing.Filename,
+		})
+		return
+	}
+
+	// Turn away files that don't fit in the site's storage quota
+	release, err := media.ReserveQuota(db.GetDB(), site, upload.Size)
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Failed to save image: %v", err)})
+		return
+	}
+	defer release()
+
+	// Save the file to the site's storage
+	store, bucket, err := media.StorageForSite(site)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to save image: %v", err)})
+		return
+	}
+	saved, err := upload.Store(store)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to save image: %v", err)})
+		return
+	}
+	filename := saved.Filename
+
 	// Create media item record for tracking
 	mediaItem := models.MediaItem{
 		SiteID:             site.ID,
 		UploadedFromSiteID: &site.ID,
 		Filename:           filename,
 		OriginalName:       file.Filename,
-		FileSize:           file.Size,
-		MimeType:           file.Header.Get("Content-Type"),
+		FileSize:           saved.Size,
+		MimeType:           saved.ContentType,
 		UploadedBy:         user.ID,
+		Bucket:             bucket,
+		TakenAt:            saved.TakenAt,
+		ContentHash:        saved.Hash,
+	}
+	// Measure the image and make smaller copies for responsive pages
+	if err := media.GenerateDerivatives(store, &mediaItem); err != nil {
+		fmt.Printf("Warning: Failed to generate image sizes for %s: %v\n", filename, err)
 	}
-	db.GetDB().Create(&mediaItem) // Ignore error - not critical
 
 	// Generate thumbnail
-	mediaDir := config.GetString("storage.media_dir")
-	if mediaDir == "" {
-		mediaDir = "/var/lib/stinkykitty/media"
-	}
-	srcPath := filepath.Join(mediaDir, "uploads", filename)
-	thumbPath := filepath.Join(mediaDir, "uploads", "thumbs", filename)
-	_ = media.GenerateThumbnail(srcPath, thumbPath, 200, 200)
+	_ = media.SaveThumbnail(store, filename)
+	mediaItem.DerivativesSize = media.MeasureDerivatives(store, &mediaItem)
+
+	db.GetDB().Create(&mediaItem) // Ignore error - not critical
+	media.UpdateQuotaAlerts(db.GetDB(), site)
 
 	// Return the web-accessible URL
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrCollectionNotFound is returned for collections that don't exist on a site
+var ErrCollectionNotFound = errors.New("collection not found")
+
+// CollectionNode is a collection in a site's tree, with its place in it
+type CollectionNode struct {
+	models.MediaCollection
+	Depth int    // 0 for top-level collections
+	Path  string // Names from the top, e.g. "Burn 2026 / Build week"
+}
+
+// CollectionTree lists a site's collections depth first, each followed by
+// the collections inside it, sorted by name at each level
+func CollectionTree(db *gorm.DB, siteID uint) []CollectionNode {
+	var all []models.MediaCollection
+	db.Where("site_id = ?", siteID).Find(&all)
+
+	children := map[uint][]models.MediaCollection{}
+	for _, col := range all {
+		parent := uint(0)
+		if col.ParentID != nil {
+			parent = *col.ParentID
+		}
+		children[parent] = append(children[parent], col)
+	}
+
+	var nodes []CollectionNode
+	var walk func(parent uint, depth int, path string)
+	walk = func(parent uint, depth int, path string) {
+		cols := children[parent]
+		sort.Slice(cols, func(i, j int) bool { return strings.ToLower(cols[i].Name) < strings.ToLower(cols[j].Name) })
+		for _, col := range cols {
+			p := col.Name
+			if path != "" {
+				p = path + " / " + col.Name
+			}
+			nodes = append(nodes, CollectionNode{MediaCollection: col, Depth: depth, Path: p})
+			walk(col.ID, depth+1, p)
+		}
+	}
+	walk(0, 0, "")
+	return nodes
+}
+
+// FindCollection returns a site's collection
+func FindCollection(db *gorm.DB, siteID, id uint) (*models.MediaCollection, error) {
+	var col models.MediaCollection
+	if err := db.Where("id = ? AND site_id = ?", id, siteID).First(&col).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, err
+	}
+	return &col, nil
+}
+
+// CollectionIDs returns a collection's ID and those of every collection
+// nested inside it, so filtering by a collection includes its sub-collections
+func CollectionIDs(db *gorm.DB, siteID, id uint) []uint {
+	ids := []uint{id}
+	for _, node := range CollectionTree(db, siteID) {
+		if node.ParentID == nil {
+			continue
+		}
+		for _, have := range ids {
+			if *node.ParentID == have {
+				ids = append(ids, node.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// CreateCollection adds a collection to a site, inside parentID if it's set.
+// Names must be unique among their siblings.
+func CreateCollection(db *gorm.DB, siteID uint, parentID *uint, name string) (*models.MediaCollection, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("collection name required")
+	}
+	if parentID != nil {
+		if _, err := FindCollection(db, siteID, *parentID); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkSiblingName(db, siteID, parentID, name, 0); err != nil {
+		return nil, err
+	}
+	col := models.MediaCollection{SiteID: siteID, ParentID: parentID, Name: name}
+	if err := db.Create(&col).Error; err != nil {
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+	return &col, nil
+}
+
+// RenameCollection renames one of a site's collections
+func RenameCollection(db *gorm.DB, siteID, id uint, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("collection name required")
+	}
+	col, err := FindCollection(db, siteID, id)
+	if err != nil {
+		return err
+	}
+	if err := checkSiblingName(db, siteID, col.ParentID, name, col.ID); err != nil {
+		return err
+	}
+	return db.Model(col).Update("name", name).Error
+}
+
+// checkSiblingName refuses a name another collection in the same place has
+func checkSiblingName(db *gorm.DB, siteID uint, parentID *uint, name string, except uint) error {
+	query := db.Model(&models.MediaCollection{}).Where("site_id = ? AND LOWER(name) = ? AND id <> ?", siteID, strings.ToLower(name), except)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	var count int64
+	query.Count(&count)
+	if count > 0 {
+		return fmt.Errorf("there's already a collection called %q here", name)
+	}
+	return nil
+}
+
+// DeleteCollection removes one of a site's collections. Its media and the
+// collections inside it move up to its parent; no media is deleted.
+func DeleteCollection(db *gorm.DB, siteID, id uint) error {
+	col, err := FindCollection(db, siteID, id)
+	if err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.MediaItem{}).Where("collection_id = ?", col.ID).Update("collection_id", col.ParentID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.MediaCollection{}).Where("parent_id = ?", col.ID).Update("parent_id", col.ParentID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(col).Error
+	})
+}
+
+// MoveItems files a site's media items in a collection, or takes them out of
+// any with a nil collectionID. Items of other sites are left alone. It
+// returns the number of items moved.
+func MoveItems(db *gorm.DB, siteID uint, itemIDs []uint, collectionID *uint) (int64, error) {
+	if collectionID != nil {
+		if _, err := FindCollection(db, siteID, *collectionID); err != nil {
+			return 0, err
+		}
+	}
+	res := db.Model(&models.MediaItem{}).
+		Where("id IN ? AND site_id = ?", itemIDs, siteID).
+		Update("collection_id", collectionID)
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCollections(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Site{}, &models.User{}, &models.MediaItem{}, &models.MediaCollection{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	user := models.User{Email: "test@example.com", PasswordHash: "hash"}
+	db.Create(&user)
+	site := models.Site{Subdomain: "test", OwnerID: user.ID, SiteDir: "/tmp"}
+	db.Create(&site)
+	otherSite := models.Site{Subdomain: "other", OwnerID: user.ID, SiteDir: "/tmp"}
+	db.Create(&otherSite)
+
+	burn, err := CreateCollection(db, site.ID, nil, "Burn 2026")
+	if err != nil {
+		t.Fatalf("CreateCollection failed: %v", err)
+	}
+	build, _ := CreateCollection(db, site.ID, &burn.ID, "Build week")
+	logos, _ := CreateCollection(db, site.ID, nil, "Logos")
+	CreateCollection(db, otherSite.ID, nil, "Elsewhere")
+
+	if _, err := CreateCollection(db, site.ID, nil, "burn 2026"); err == nil {
+		t.Error("Expected a duplicate sibling name to be refused")
+	}
+	if _, err := CreateCollection(db, site.ID, &logos.ID, "Burn 2026"); err != nil {
+		t.Errorf("Expected the same name to be allowed elsewhere: %v", err)
+	}
+	if _, err := CreateCollection(db, otherSite.ID, &burn.ID, "Sneaky"); !errors.Is(err, ErrCollectionNotFound) {
+		t.Errorf("Expected another site's parent to be refused, got %v", err)
+	}
+
+	tree := CollectionTree(db, site.ID)
+	var paths []string
+	for _, node := range tree {
+		paths = append(paths, node.Path)
+	}
+	want := []string{"Burn 2026", "Burn 2026 / Build week", "Logos", "Logos / Burn 2026"}
+	if len(paths) != len(want) {
+		t.Fatalf("Expected tree %v, got %v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("Expected tree %v, got %v", want, paths)
+		}
+	}
+	if tree[1].Depth != 1 {
+		t.Errorf("Expected Build week at depth 1, got %d", tree[1].Depth)
+	}
+	if ids := CollectionIDs(db, site.ID, burn.ID); len(ids) != 2 {
+		t.Errorf("Expected Burn 2026 and Build week, got %v", ids)
+	}
+
+	// Only the site's own media moves
+	mine := models.MediaItem{SiteID: site.ID, Filename: "a.png", OriginalName: "a.png", UploadedBy: user.ID}
+	theirs := models.MediaItem{SiteID: otherSite.ID, Filename: "b.png", OriginalName: "b.png", UploadedBy: user.ID}
+	db.Create(&mine)
+	db.Create(&theirs)
+	moved, err := MoveItems(db, site.ID, []uint{mine.ID, theirs.ID}, &build.ID)
+	if err != nil || moved != 1 {
+		t.Fatalf("Expected 1 item moved, got %d (%v)", moved, err)
+	}
+	db.First(&theirs, theirs.ID)
+	if theirs.CollectionID != nil {
+		t.Error("Expected another site's item not to move")
+	}
+
+	if err := RenameCollection(db, site.ID, build.ID, "Setup"); err != nil {
+		t.Fatalf("RenameCollection failed: %v", err)
+	}
+
+	// Deleting a collection moves its media and sub-collections up a level
+	if err := DeleteCollection(db, site.ID, burn.ID); err != nil {
+		t.Fatalf("DeleteCollection failed: %v", err)
+	}
+	db.First(build, build.ID)
+	if build.ParentID != nil {
+		t.Error("Expected Setup to move to the top level")
+	}
+	if err := DeleteCollection(db, site.ID, build.ID); err != nil {
+		t.Fatalf("DeleteCollection failed: %v", err)
+	}
+	db.First(&mine, mine.ID)
+	if mine.CollectionID != nil {
+		t.Errorf("Expected the item to be in no collection, got %v", *mine.CollectionID)
+	}
+	var items int64
+	db.Model(&models.MediaItem{}).Count(&items)
+	if items != 2 {
+		t.Errorf("Expected no media deleted, got %d items", items)
+	}
+}
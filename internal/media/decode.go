@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+)
+
+// defaultMaxPixels is used when media.max_pixels isn't configured
+const defaultMaxPixels = 50_000_000
+
+// ErrImageTooLarge is returned for images with more pixels than media.max_pixels
+var ErrImageTooLarge = errors.New("image dimensions too large")
+
+// MaxPixels returns the largest image, as width × height, that will be decoded
+func MaxPixels() int64 {
+	if n := config.GetInt("media.max_pixels"); n > 0 {
+		return int64(n)
+	}
+	return defaultMaxPixels
+}
+
+// checkPixels returns ErrImageTooLarge if an image of the given size is over MaxPixels
+func checkPixels(cfg image.Config) error {
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return fmt.Errorf("invalid image dimensions %dx%d", cfg.Width, cfg.Height)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels() {
+		return fmt.Errorf("%w: %dx%d is over %d megapixels", ErrImageTooLarge, cfg.Width, cfg.Height, MaxPixels()/1_000_000)
+	}
+	return nil
+}
+
+// CheckDimensions reads an image's header and returns ErrImageTooLarge if
+// decoding it would take more than MaxPixels. A small file can claim to be
+// enormous, so this is checked before anything decodes it.
+func CheckDimensions(data []byte) error {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to read image size: %w", err)
+	}
+	return checkPixels(cfg)
+}
+
+// DecodeImage decodes an image like image.Decode, but reads its size first
+// and refuses images over MaxPixels rather than allocating for them
+func DecodeImage(r io.Reader) (image.Image, string, error) {
+	var header bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.TeeReader(r, &header))
+	if err != nil {
+		return nil, "", err
+	}
+	if err := checkPixels(cfg); err != nil {
+		return nil, "", err
+	}
+	return image.Decode(io.MultiReader(&header, r))
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/storage"
+)
+
+// hugePNG returns a tiny PNG whose header claims the given dimensions, the
+// way a decompression bomb does
+func hugePNG(width, height uint32) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], width)
+	binary.BigEndian.PutUint32(ihdr[4:], height)
+	ihdr[8], ihdr[9] = 8, 2 // 8-bit RGB
+
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	chunk := append([]byte("IHDR"), ihdr...)
+	buf.Write(chunk)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+func TestDecodeImageRejectsHugeDimensions(t *testing.T) {
+	bomb := hugePNG(30000, 30000)
+
+	if _, _, err := DecodeImage(bytes.NewReader(bomb)); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Expected DecodeImage to refuse a 30000x30000 image, got %v", err)
+	}
+	if err := CheckDimensions(bomb); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Expected CheckDimensions to refuse a 30000x30000 image, got %v", err)
+	}
+	if _, err := PrepareImageData("bomb.png", bomb); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Expected the upload to be refused, got %v", err)
+	}
+	if err := EncodeTransform(bytes.NewReader(bomb), &bytes.Buffer{}, &Transform{Width: 400}); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Expected EncodeTransform to refuse the image, got %v", err)
+	}
+
+	store := storage.NewLocal(t.TempDir())
+	if err := store.Put(UploadKey("bomb.png"), bytes.NewReader(bomb), int64(len(bomb)), "image/png"); err != nil {
+		t.Fatalf("Failed to store test image: %v", err)
+	}
+	if err := GenerateDerivatives(store, &models.MediaItem{Filename: "bomb.png"}); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Expected GenerateDerivatives to refuse the image, got %v", err)
+	}
+
+	// Ordinary images still decode
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 30))); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	img, format, err := DecodeImage(&buf)
+	if err != nil || format != "png" || img.Bounds().Dx() != 40 {
+		t.Errorf("Expected a 40px wide png, got %v %q", err, format)
+	}
+}
@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+)
+
+// ContentHash returns the SHA-256 of a file's bytes, hex encoded
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// FindDuplicate returns a site's media item with the given content hash, the
+// oldest if there are several, or nil if there is none
+func FindDuplicate(db *gorm.DB, siteID uint, hash string) *models.MediaItem {
+	if hash == "" {
+		return nil
+	}
+	var item models.MediaItem
+	if err := db.Where("site_id = ? AND content_hash = ?", siteID, hash).Order("id").First(&item).Error; err != nil {
+		return nil
+	}
+	return &item
+}
+
+// HashItem reads a media item's stored file and returns its content hash
+func HashItem(item *models.MediaItem) (string, error) {
+	store, err := StorageForItem(item)
+	if err != nil {
+		return "", err
+	}
+	r, _, err := store.Get(UploadKey(item.Filename))
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Merge is a duplicate media item folded into the copy that's kept
+type Merge struct {
+	Kept    models.MediaItem
+	Removed models.MediaItem
+	Blocks  int // Blocks pointed at the kept copy
+}
+
+// DedupeResult summarizes a dedupe run
+type DedupeResult struct {
+	Hashed int     // Items hashed for the first time
+	Failed int     // Items that couldn't be read, left alone
+	Merges []Merge // Duplicates removed
+	Freed  int64   // Bytes no longer stored
+}
+
+// Dedupe finds media items of a site (0 for all sites) with the same content
+// and keeps only the oldest of each. Blocks, post covers, share images and
+// logos using a removed copy are pointed at the kept one, and its tags are
+// carried over. Items uploaded before hashes were recorded are hashed first.
+// With dryRun nothing is changed, but the result says what would be.
+func Dedupe(db *gorm.DB, siteID uint, dryRun bool) (*DedupeResult, error) {
+	query := db.Order("id")
+	if siteID != 0 {
+		query = query.Where("site_id = ?", siteID)
+	}
+	var items []models.MediaItem
+	if err := query.Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	res := &DedupeResult{}
+	kept := map[string]*models.MediaItem{}
+	for i := range items {
+		item := &items[i]
+		if item.ContentHash == "" {
+			hash, err := HashItem(item)
+			if err != nil {
+				fmt.Printf("Warning: Failed to hash %s: %v\n", item.Filename, err)
+				res.Failed++
+				continue
+			}
+			item.ContentHash = hash
+			res.Hashed++
+			if !dryRun {
+				if err := db.Model(item).Update("content_hash", hash).Error; err != nil {
+					return res, err
+				}
+			}
+		}
+
+		key := fmt.Sprintf("%d:%s", item.SiteID, item.ContentHash)
+		first, seen := kept[key]
+		if !seen {
+			kept[key] = item
+			continue
+		}
+
+		merge := Merge{Kept: *first, Removed: *item}
+		if !dryRun {
+			blocks, err := mergeItem(db, first, item)
+			if err != nil {
+				return res, fmt.Errorf("failed to merge %s into %s: %w", item.Filename, first.Filename, err)
+			}
+			merge.Blocks = blocks
+		}
+		res.Merges = append(res.Merges, merge)
+		res.Freed += item.FileSize
+	}
+	return res, nil
+}
+
+// mergeItem points everything using a duplicate at the kept item, moves its
+// tags across and deletes it. It returns the number of blocks rewritten.
+func mergeItem(db *gorm.DB, kept, dup *models.MediaItem) (int, error) {
+	var rewritten []models.Block
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Filenames are random, so they can be replaced wherever they appear,
+		// including in size folders like /assets/w800/<name>
+		var blocks []models.Block
+		if err := tx.Unscoped().Where("data LIKE ?", "%"+dup.Filename+"%").Find(&blocks).Error; err != nil {
+			return err
+		}
+		for i := range blocks {
+			blocks[i].Data = strings.ReplaceAll(blocks[i].Data, dup.Filename, kept.Filename)
+			if err := tx.Unscoped().Model(&blocks[i]).Update("data", blocks[i].Data).Error; err != nil {
+				return err
+			}
+		}
+		rewritten = blocks
+
+		for _, col := range []struct {
+			model  interface{}
+			column string
+		}{
+			{&models.Post{}, "cover_image"},
+			{&models.Page{}, "social_image"},
+			{&models.Site{}, "logo_path"},
+		} {
+			err := tx.Unscoped().Model(col.model).Where(col.column+" LIKE ?", "%"+dup.Filename+"%").
+				Update(col.column, gorm.Expr("REPLACE("+col.column+", ?, ?)", dup.Filename, kept.Filename)).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// Carry over tags the kept copy doesn't have
+		var tags []models.MediaTag
+		if err := tx.Where("media_item_id = ?", dup.ID).Find(&tags).Error; err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			var count int64
+			tx.Model(&models.MediaTag{}).Where("media_item_id = ? AND tag_name = ?", kept.ID, tag.TagName).Count(&count)
+			if count == 0 {
+				if err := tx.Create(&models.MediaTag{MediaItemID: kept.ID, TagName: tag.TagName}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		if err := tx.Where("media_item_id = ?", dup.ID).Delete(&models.MediaTag{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(dup).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range rewritten {
+		if err := UpdateBlockReferences(db, &rewritten[i]); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			fmt.Printf("Warning: Failed to record media used by block %d: %v\n", rewritten[i].ID, err)
+		}
+	}
+	if err := DeleteStored(dup); err != nil {
+		fmt.Printf("Warning: Failed to delete file %s: %v\n", dup.Filename, err)
+	}
+	return len(rewritten), nil
+}
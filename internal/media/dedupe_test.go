@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestDedupe(t *testing.T) {
+	if err := config.InitConfig(filepath.Join(t.TempDir(), "config.yaml")); err != nil {
+		t.Fatalf("Failed to init config: %v", err)
+	}
+	config.Set("storage.media_dir", t.TempDir())
+	store := LocalStorage()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Site{}, &models.User{}, &models.Page{}, &models.Block{}, &models.Post{},
+		&models.MediaItem{}, &models.MediaTag{}, &models.MediaReference{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	user := models.User{Email: "test@example.com", PasswordHash: "hash"}
+	db.Create(&user)
+	site := models.Site{Subdomain: "test", OwnerID: user.ID, SiteDir: "/tmp"}
+	db.Create(&site)
+	page := models.Page{SiteID: site.ID, Slug: "/", Title: "Home", Published: true, SocialImage: "/assets/bbbb.png"}
+	db.Create(&page)
+
+	// The same logo uploaded twice, before hashes were recorded, and another image
+	logo := []byte("\x89PNG logo")
+	for name, data := range map[string][]byte{"aaaa.png": logo, "bbbb.png": logo, "cccc.png": []byte("\x89PNG other")} {
+		if err := store.Put(UploadKey(name), bytes.NewReader(data), int64(len(data)), "image/png"); err != nil {
+			t.Fatalf("Failed to store %s: %v", name, err)
+		}
+	}
+	kept := models.MediaItem{SiteID: site.ID, Filename: "aaaa.png", OriginalName: "logo.png", FileSize: int64(len(logo)), MimeType: "image/png", UploadedBy: user.ID}
+	dup := models.MediaItem{SiteID: site.ID, Filename: "bbbb.png", OriginalName: "logo (1).png", FileSize: int64(len(logo)), MimeType: "image/png", UploadedBy: user.ID}
+	other := models.MediaItem{SiteID: site.ID, Filename: "cccc.png", OriginalName: "map.png", FileSize: 10, MimeType: "image/png", UploadedBy: user.ID}
+	db.Create(&kept)
+	db.Create(&dup)
+	db.Create(&other)
+	db.Create(&models.MediaTag{MediaItemID: dup.ID, TagName: "branding"})
+
+	block := models.Block{PageID: page.ID, Type: "columns", Data: `{"columns":[{"content":"<img src=\"/assets/w800/bbbb.png\">"}]}`}
+	db.Create(&block)
+	UpdateBlockReferences(db, &block)
+
+	// A dry run changes nothing
+	res, err := Dedupe(db, site.ID, true)
+	if err != nil {
+		t.Fatalf("Dedupe dry run failed: %v", err)
+	}
+	if len(res.Merges) != 1 || res.Merges[0].Removed.ID != dup.ID || res.Hashed != 3 {
+		t.Fatalf("Unexpected dry run result: %+v", res)
+	}
+	var count int64
+	db.Model(&models.MediaItem{}).Count(&count)
+	if count != 3 {
+		t.Fatalf("Expected dry run to keep all items, got %d", count)
+	}
+
+	res, err = Dedupe(db, site.ID, false)
+	if err != nil {
+		t.Fatalf("Dedupe failed: %v", err)
+	}
+	if len(res.Merges) != 1 || res.Merges[0].Blocks != 1 || res.Freed != int64(len(logo)) {
+		t.Fatalf("Unexpected result: %+v", res)
+	}
+
+	db.Model(&models.MediaItem{}).Count(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 items after dedupe, got %d", count)
+	}
+	var reloaded models.Block
+	db.First(&reloaded, block.ID)
+	if !strings.Contains(reloaded.Data, "/assets/w800/aaaa.png") || strings.Contains(reloaded.Data, "bbbb") {
+		t.Errorf("Expected block to point at the kept copy, got %s", reloaded.Data)
+	}
+	if usages := FindImageUsage(db, site.ID, "/assets/aaaa.png"); len(usages) != 1 {
+		t.Errorf("Expected the kept copy to be recorded as used, got %d usages", len(usages))
+	}
+	var reloadedPage models.Page
+	db.First(&reloadedPage, page.ID)
+	if reloadedPage.SocialImage != "/assets/aaaa.png" {
+		t.Errorf("Expected share image to point at the kept copy, got %s", reloadedPage.SocialImage)
+	}
+	var tags []models.MediaTag
+	db.Where("media_item_id = ?", kept.ID).Find(&tags)
+	if len(tags) != 1 || tags[0].TagName != "branding" {
+		t.Errorf("Expected the duplicate's tag to move to the kept copy, got %+v", tags)
+	}
+	if _, _, err := store.Get(UploadKey("bbbb.png")); err == nil {
+		t.Error("Expected the duplicate's file to be deleted")
+	}
+	if FindDuplicate(db, site.ID, ContentHash(logo)).ID != kept.ID {
+		t.Error("Expected FindDuplicate to return the kept copy")
+	}
+}
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/storage"
+	"golang.org/x/image/draw"
+	"gorm.io/gorm"
+)
+
+// DerivativeWidths are the widths smaller copies of uploaded images are made
+// at. Pages are at most 800px wide, so these cover phones through 2x screens.
+var DerivativeWidths = []int{400, 800, 1200, 1600}
+
+// ImageSizes is the sizes attribute matching the page layout: full width on
+// small screens, otherwise the 760px content column
+const ImageSizes = "(max-width: 800px) calc(100vw - 40px), 760px"
+
+// derivativePattern matches the folder derivatives live in, e.g. "w800/"
+var derivativePattern = regexp.MustCompile(`^w[0-9]+/`)
+
+// DerivativeName is the name under /assets/ of an image's copy at a width
+func DerivativeName(filename string, width int) string {
+	return "w" + strconv.Itoa(width) + "/" + filename
+}
+
+// UploadFilename returns the media item filename an /assets/ name belongs to,
+// so thumbnails and derivatives are found with their original
+func UploadFilename(name string) string {
+	name = strings.TrimPrefix(name, "thumbs/")
+	return derivativePattern.ReplaceAllString(name, "")
+}
+
+// Derivatives returns the widths an item has smaller copies at
+func Derivatives(item *models.MediaItem) []int {
+	var widths []int
+	for _, w := range strings.Split(item.Derivatives, ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(w)); err == nil && n > 0 {
+			widths = append(widths, n)
+		}
+	}
+	return widths
+}
+
+// SrcSet returns the srcset for an item: its derivatives and the original.
+// It's empty when the item's size isn't known yet.
+func SrcSet(item *models.MediaItem) string {
+	if item.Width <= 0 {
+		return ""
+	}
+	var parts []string
+	for _, w := range Derivatives(item) {
+		parts = append(parts, fmt.Sprintf("/assets/%s %dw", DerivativeName(item.Filename, w), w))
+	}
+	parts = append(parts, fmt.Sprintf("/assets/%s %dw", item.Filename, item.Width))
+	return strings.Join(parts, ", ")
+}
+
+// GenerateDerivatives records a stored image's dimensions on item and stores
+// copies at each of DerivativeWidths narrower than the original. JPEG and PNG
+// images get copies in the same format; GIFs keep their animation and WebP
+// can't be encoded here, so those are only measured. The caller saves item.
+func GenerateDerivatives(store storage.Storage, item *models.MediaItem) error {
+	src, _, err := store.Get(UploadKey(item.Filename))
+	if err != nil {
+		return fmt.Errorf("failed to open source image: %w", err)
+	}
+	data, err := io.ReadAll(src)
+	src.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read source image: %w", err)
+	}
+
+	img, format, err := DecodeImage(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+	bounds := img.Bounds()
+
+	var widths []string
+	if format == "jpeg" || format == "png" {
+		for _, w := range DerivativeWidths {
+			if w >= bounds.Dx() {
+				break
+			}
+			h := bounds.Dy() * w / bounds.Dx()
+			if h < 1 {
+				h = 1
+			}
+			scaled := image.NewRGBA(image.Rect(0, 0, w, h))
+			draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+
+			var buf bytes.Buffer
+			contentType := "image/jpeg"
+			if format == "png" {
+				contentType = "image/png"
+				err = png.Encode(&buf, scaled)
+			} else {
+				err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: 82})
+			}
+			if err != nil {
+				return fmt.Errorf("failed to encode %dpx copy: %w", w, err)
+			}
+			if err := store.Put(UploadKey(DerivativeName(item.Filename, w)), &buf, int64(buf.Len()), contentType); err != nil {
+				return fmt.Errorf("failed to store %dpx copy: %w", w, err)
+			}
+			widths = append(widths, strconv.Itoa(w))
+		}
+	}
+
+	item.Width = bounds.Dx()
+	item.Height = bounds.Dy()
+	item.Derivatives = strings.Join(widths, ",")
+	return nil
+}
+
+// EnsureDerivatives generates an item's derivatives if it doesn't have them
+// yet and saves its dimensions. Used for media uploaded before derivatives
+// existed.
+func EnsureDerivatives(gdb *gorm.DB, item *models.MediaItem) error {
+	if item.Width > 0 {
+		return nil
+	}
+	store, err := StorageForItem(item)
+	if err != nil {
+		return err
+	}
+	if err := GenerateDerivatives(store, item); err != nil {
+		return err
+	}
+	return gdb.Model(item).Updates(map[string]interface{}{
+		"width":            item.Width,
+		"height":           item.Height,
+		"derivatives":      item.Derivatives,
+		"derivatives_size": MeasureDerivatives(store, item),
+	}).Error
+}
+
+// derivativeKeys lists the storage keys of an item's derivatives
+func derivativeKeys(item *models.MediaItem) []string {
+	var keys []string
+	for _, w := range Derivatives(item) {
+		keys = append(keys, UploadKey(DerivativeName(item.Filename, w)))
+	}
+	return keys
+}
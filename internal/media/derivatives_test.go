@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/storage"
+)
+
+func TestGenerateDerivatives(t *testing.T) {
+	store := storage.NewLocal(t.TempDir())
+	img := image.NewRGBA(image.Rect(0, 0, 1000, 500))
+	for y := 0; y < 500; y++ {
+		for x := 0; x < 1000; x++ {
+			img.Set(x, y, color.RGBA{0, 128, 255, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	if err := store.Put(UploadKey("photo.jpg"), &buf, int64(buf.Len()), "image/jpeg"); err != nil {
+		t.Fatalf("Failed to store test image: %v", err)
+	}
+
+	item := &models.MediaItem{Filename: "photo.jpg"}
+	if err := GenerateDerivatives(store, item); err != nil {
+		t.Fatalf("GenerateDerivatives failed: %v", err)
+	}
+	if item.Width != 1000 || item.Height != 500 {
+		t.Errorf("Expected 1000x500, got %dx%d", item.Width, item.Height)
+	}
+	// Only widths narrower than the original are made
+	if item.Derivatives != "400,800" {
+		t.Errorf("Expected derivatives 400,800, got %q", item.Derivatives)
+	}
+
+	r, _, err := store.Get(UploadKey(DerivativeName("photo.jpg", 400)))
+	if err != nil {
+		t.Fatalf("400px copy missing: %v", err)
+	}
+	defer r.Close()
+	small, _, err := image.Decode(r)
+	if err != nil {
+		t.Fatalf("Failed to decode 400px copy: %v", err)
+	}
+	if b := small.Bounds(); b.Dx() != 400 || b.Dy() != 200 {
+		t.Errorf("Expected 400x200 copy, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	want := "/assets/w400/photo.jpg 400w, /assets/w800/photo.jpg 800w, /assets/photo.jpg 1000w"
+	if got := SrcSet(item); got != want {
+		t.Errorf("SrcSet = %q, want %q", got, want)
+	}
+}
+
+func TestUploadFilename(t *testing.T) {
+	for name, want := range map[string]string{
+		"abc.jpg":        "abc.jpg",
+		"thumbs/abc.jpg": "abc.jpg",
+		"w800/abc.jpg":   "abc.jpg",
+		"wide/abc.jpg":   "wide/abc.jpg",
+	} {
+		if got := UploadFilename(name); got != want {
+			t.Errorf("UploadFilename(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+)
+
+// defaultDocumentTypes is used when media.document_types isn't configured
+const defaultDocumentTypes = "pdf,txt,csv,docx,xlsx,pptx,odt,ods,odp"
+
+// imageTypes are the image formats uploads may be in
+var imageTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// oleSignature starts legacy Office files (.doc, .xls, .ppt)
+var oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// DocumentType is a kind of document the media library can hold
+type DocumentType struct {
+	Label       string // Shown to visitors, e.g. "PDF"
+	ContentType string // Stored and served
+	// matches checks the file's content, so a renamed executable isn't
+	// accepted as a PDF
+	matches func(sniffed string, data []byte) bool
+}
+
+func sniffedAs(prefix string) func(string, []byte) bool {
+	return func(sniffed string, _ []byte) bool { return strings.HasPrefix(sniffed, prefix) }
+}
+
+func isOLE(_ string, data []byte) bool { return bytes.HasPrefix(data, oleSignature) }
+
+// documentTypes are the document types that can be allowed, by extension.
+// Office Open XML and OpenDocument files are ZIP archives underneath.
+var documentTypes = map[string]DocumentType{
+	"pdf":  {"PDF", "application/pdf", sniffedAs("application/pdf")},
+	"txt":  {"Text", "text/plain; charset=utf-8", sniffedAs("text/plain")},
+	"csv":  {"CSV", "text/csv; charset=utf-8", sniffedAs("text/plain")},
+	"md":   {"Text", "text/markdown; charset=utf-8", sniffedAs("text/plain")},
+	"rtf":  {"RTF", "application/rtf", sniffedAs("text/plain")},
+	"docx": {"Word", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", sniffedAs("application/zip")},
+	"xlsx": {"Excel", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", sniffedAs("application/zip")},
+	"pptx": {"PowerPoint", "application/vnd.openxmlformats-officedocument.presentationml.presentation", sniffedAs("application/zip")},
+	"odt":  {"OpenDocument Text", "application/vnd.oasis.opendocument.text", sniffedAs("application/zip")},
+	"ods":  {"OpenDocument Spreadsheet", "application/vnd.oasis.opendocument.spreadsheet", sniffedAs("application/zip")},
+	"odp":  {"OpenDocument Presentation", "application/vnd.oasis.opendocument.presentation", sniffedAs("application/zip")},
+	"doc":  {"Word", "application/msword", isOLE},
+	"xls":  {"Excel", "application/vnd.ms-excel", isOLE},
+	"ppt":  {"PowerPoint", "application/vnd.ms-powerpoint", isOLE},
+	"zip":  {"ZIP", "application/zip", sniffedAs("application/zip")},
+}
+
+// AllowedDocumentTypes returns the extensions of the document types the
+// media library accepts, from media.document_types
+func AllowedDocumentTypes() []string {
+	raw := config.GetString("media.document_types")
+	if raw == "" {
+		raw = defaultDocumentTypes
+	}
+	var exts []string
+	for _, ext := range strings.Split(raw, ",") {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if _, known := documentTypes[ext]; known {
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
+
+// extension returns a filename's extension, lowercase and without the dot
+func extension(filename string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+}
+
+// IsDocument reports whether a stored file is a document rather than an image
+func IsDocument(filename string) bool {
+	_, ok := documentTypes[extension(filename)]
+	return ok
+}
+
+// DocumentLabel names a document's type for visitors, e.g. "PDF"
+func DocumentLabel(filename string) string {
+	if t, ok := documentTypes[extension(filename)]; ok {
+		return t.Label
+	}
+	return strings.ToUpper(extension(filename))
+}
+
+// CheckDocument verifies an upload is an allowed document type and its
+// content matches its extension, returning the content type to store it as
+func CheckDocument(filename string, data []byte) (string, error) {
+	ext := extension(filename)
+	allowed := false
+	for _, a := range AllowedDocumentTypes() {
+		if a == ext {
+			allowed = true
+			break
+		}
+	}
+	t, known := documentTypes[ext]
+	if !allowed || !known {
+		return "", fmt.Errorf("file type not allowed: .%s (allowed: images, %s)", ext, strings.Join(AllowedDocumentTypes(), ", "))
+	}
+	if !t.matches(http.DetectContentType(data), data) {
+		return "", fmt.Errorf("%s doesn't look like a %s file", filepath.Base(filename), t.Label)
+	}
+	return t.ContentType, nil
+}
+
+// DocumentIcon returns an SVG icon for a document, labelled with its type
+func DocumentIcon(filename string) string {
+	color := "#6b7280"
+	switch extension(filename) {
+	case "pdf":
+		color = "#dc2626"
+	case "doc", "docx", "odt", "rtf":
+		color = "#2563eb"
+	case "xls", "xlsx", "ods", "csv":
+		color = "#16a34a"
+	case "ppt", "pptx", "odp":
+		color = "#ea580c"
+	}
+	label := strings.ToUpper(extension(filename))
+	if len(label) > 4 {
+		label = label[:4]
+	}
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 48 60" width="48" height="60" role="img" aria-label="%s file"><path d="M4 2h28l14 14v40a2 2 0 0 1-2 2H4a2 2 0 0 1-2-2V4a2 2 0 0 1 2-2z" fill="#fff" stroke="%s" stroke-width="2"/><path d="M32 2v14h14" fill="none" stroke="%s" stroke-width="2"/><rect x="2" y="34" width="44" height="16" fill="%s"/><text x="24" y="46" font-family="system-ui, sans-serif" font-size="11" font-weight="700" fill="#fff" text-anchor="middle">%s</text></svg>`,
+		label, color, color, color, label)
+}
+
+// FormatSize formats a byte count for people, e.g. "1.4 MB"
+func FormatSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.0f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
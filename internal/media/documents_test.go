@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+)
+
+// fileHeader builds a multipart file header for an upload named name
+func fileHeader(t *testing.T, name string, data []byte) *multipart.FileHeader {
+	t.Helper()
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(data)
+	w.Close()
+
+	req := httptest.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatalf("Failed to parse form: %v", err)
+	}
+	return req.MultipartForm.File["file"][0]
+}
+
+func TestCheckDocument(t *testing.T) {
+	if err := config.InitConfig(filepath.Join(t.TempDir(), "config.yaml")); err != nil {
+		t.Fatalf("Failed to init config: %v", err)
+	}
+	pdf := []byte("%PDF-1.7\n1 0 obj\n<<>>\nendobj\n")
+
+	contentType, err := CheckDocument("waiver.PDF", pdf)
+	if err != nil {
+		t.Fatalf("Expected PDF to be accepted: %v", err)
+	}
+	if contentType != "application/pdf" {
+		t.Errorf("Expected application/pdf, got %s", contentType)
+	}
+
+	// A renamed executable isn't a PDF
+	if _, err := CheckDocument("waiver.pdf", []byte("MZ\x90\x00\x03\x00\x00\x00")); err == nil {
+		t.Error("Expected content that isn't a PDF to be rejected")
+	}
+	// Not in the default allowlist
+	if _, err := CheckDocument("archive.zip", []byte("PK\x03\x04")); err == nil {
+		t.Error("Expected .zip to be rejected by default")
+	}
+
+	config.Set("media.document_types", "pdf, .zip")
+	if got := AllowedDocumentTypes(); strings.Join(got, ",") != "pdf,zip" {
+		t.Errorf("Expected pdf,zip, got %v", got)
+	}
+	if _, err := CheckDocument("archive.zip", []byte("PK\x03\x04")); err != nil {
+		t.Errorf("Expected .zip to be accepted once allowed: %v", err)
+	}
+	if _, err := CheckDocument("list.txt", []byte("tent\nstakes\n")); err == nil {
+		t.Error("Expected .txt to be rejected once it's not allowed")
+	}
+}
+
+func TestSaveMediaDocument(t *testing.T) {
+	if err := config.InitConfig(filepath.Join(t.TempDir(), "config.yaml")); err != nil {
+		t.Fatalf("Failed to init config: %v", err)
+	}
+	config.Set("storage.media_dir", t.TempDir())
+	store := LocalStorage()
+	pdf := []byte("%PDF-1.4\nbuild plans\n")
+
+	saved, err := SaveMedia(store, fileHeader(t, "Build Plans.pdf", pdf))
+	if err != nil {
+		t.Fatalf("SaveMedia failed: %v", err)
+	}
+	if !saved.IsDocument || saved.ContentType != "application/pdf" || saved.Size != int64(len(pdf)) {
+		t.Errorf("Unexpected saved file: %+v", saved)
+	}
+	if !strings.HasSuffix(saved.Filename, ".pdf") || !IsDocument(saved.Filename) {
+		t.Errorf("Expected a .pdf filename, got %s", saved.Filename)
+	}
+	if _, _, err := store.Get(UploadKey(saved.Filename)); err != nil {
+		t.Errorf("Expected document to be stored: %v", err)
+	}
+
+	// Save is for images only
+	if _, err := Save(store, fileHeader(t, "Build Plans.pdf", pdf)); err == nil {
+		t.Error("Expected Save to reject documents")
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		512:                    "512 bytes",
+		2048:                   "2 KB",
+		1536 * 1024:            "1.5 MB",
+		3 * 1024 * 1024 * 1024: "3.0 GB",
+	}
+	for n, want := range tests {
+		if got := FormatSize(n); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
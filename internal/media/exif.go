@@ -0,0 +1,374 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+)
+
+// EXIF tags read from uploads
+const (
+	exifTagOrientation      = 0x0112
+	exifTagDateTime         = 0x0132
+	exifTagExifIFD          = 0x8769
+	exifTagDateTimeOriginal = 0x9003
+)
+
+// ImageMeta is the metadata kept from an upload before the rest is stripped
+type ImageMeta struct {
+	Orientation int        // EXIF orientation, 1 (or 0 when unknown) is upright
+	TakenAt     *time.Time // When the photo was taken, in the camera's clock
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// ReadMeta reads the orientation and capture time from a JPEG's EXIF data.
+// Other formats and images without EXIF return an empty ImageMeta.
+func ReadMeta(data []byte) ImageMeta {
+	var meta ImageMeta
+	walkJPEG(data, func(marker byte, segment []byte) bool {
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			meta = parseExif(segment[6:])
+			return false
+		}
+		return true
+	})
+	return meta
+}
+
+// Scrub applies a JPEG's EXIF orientation and removes EXIF, XMP, IPTC and
+// comment metadata (GPS coordinates included) from JPEG, PNG and WebP images.
+// Color profiles are kept. Other formats are returned unchanged. The
+// metadata worth keeping is returned alongside.
+func Scrub(data []byte) ([]byte, ImageMeta) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		meta := ReadMeta(data)
+		if meta.Orientation > 1 && meta.Orientation <= 8 {
+			if img, _, err := DecodeImage(bytes.NewReader(data)); err == nil {
+				var buf bytes.Buffer
+				// Re-encoding writes no metadata, so this strips it too
+				if err := jpeg.Encode(&buf, applyOrientation(img, meta.Orientation), &jpeg.Options{Quality: 92}); err == nil {
+					return withICCProfile(buf.Bytes(), data), meta
+				}
+			}
+		}
+		return stripJPEG(data), meta
+	case bytes.HasPrefix(data, pngSignature):
+		return stripPNG(data), ImageMeta{}
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return stripWebP(data), ImageMeta{}
+	}
+	return data, ImageMeta{}
+}
+
+// walkJPEG calls fn for each marker segment before the image data, until fn
+// returns false. It returns the offset the image data starts at, or -1 if
+// the file isn't a JPEG it understands.
+func walkJPEG(data []byte, fn func(marker byte, segment []byte) bool) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return -1
+	}
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return -1
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			// Fill byte
+			i++
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			// Start of scan or end of image: the rest isn't metadata
+			return i
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return -1
+		}
+		if !fn(marker, data[i+4:i+2+length]) {
+			return i
+		}
+		i += 2 + length
+	}
+	return -1
+}
+
+// withICCProfile copies the ICC color profile segments (APP2) of original
+// into a re-encoded JPEG, which has none of its own
+func withICCProfile(encoded, original []byte) []byte {
+	var profile []byte
+	walkJPEG(original, func(marker byte, segment []byte) bool {
+		if marker == 0xE2 && bytes.HasPrefix(segment, []byte("ICC_PROFILE\x00")) {
+			profile = append(profile, 0xFF, marker)
+			profile = binary.BigEndian.AppendUint16(profile, uint16(len(segment)+2))
+			profile = append(profile, segment...)
+		}
+		return true
+	})
+	if profile == nil {
+		return encoded
+	}
+	out := append([]byte{0xFF, 0xD8}, profile...)
+	return append(out, encoded[2:]...)
+}
+
+// stripJPEG removes APP1 (EXIF, XMP), APP13 (IPTC) and comment segments
+// without re-encoding the image
+func stripJPEG(data []byte) []byte {
+	out := []byte{0xFF, 0xD8}
+	end := walkJPEG(data, func(marker byte, segment []byte) bool {
+		if marker == 0xE1 || marker == 0xED || marker == 0xFE {
+			return true
+		}
+		out = append(out, 0xFF, marker)
+		out = binary.BigEndian.AppendUint16(out, uint16(len(segment)+2))
+		out = append(out, segment...)
+		return true
+	})
+	if end < 0 {
+		return data
+	}
+	return append(out, data[end:]...)
+}
+
+// stripPNG removes the eXIf chunk and text chunks, which hold XMP and comments
+func stripPNG(data []byte) []byte {
+	out := append([]byte(nil), pngSignature...)
+	for i := len(pngSignature); i+12 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if end > len(data) {
+			return data
+		}
+		switch string(data[i+4 : i+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt":
+		default:
+			out = append(out, data[i:end]...)
+		}
+		if string(data[i+4:i+8]) == "IEND" {
+			return out
+		}
+		i = end
+	}
+	return data
+}
+
+// stripWebP removes the EXIF and XMP chunks and clears their flags
+func stripWebP(data []byte) []byte {
+	out := append([]byte(nil), data[:12]...)
+	for i := 12; i+8 <= len(data); {
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2
+		if end > len(data) {
+			if i+8+size != len(data) {
+				return data
+			}
+			end = len(data)
+		}
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04 // EXIF and XMP present
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out
+}
+
+// parseExif reads the tags we keep from a TIFF-format EXIF block
+func parseExif(tiff []byte) ImageMeta {
+	var meta ImageMeta
+	if len(tiff) < 8 {
+		return meta
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return meta
+	}
+
+	var taken, original string
+	readIFD := func(offset uint32, fn func(tag, typ uint16, count uint32, value []byte)) {
+		if int(offset)+2 > len(tiff) {
+			return
+		}
+		n := int(order.Uint16(tiff[offset:]))
+		for e := 0; e < n; e++ {
+			at := int(offset) + 2 + e*12
+			if at+12 > len(tiff) {
+				return
+			}
+			fn(order.Uint16(tiff[at:]), order.Uint16(tiff[at+2:]), order.Uint32(tiff[at+4:]), tiff[at+8:at+12])
+		}
+	}
+	ascii := func(count uint32, value []byte) string {
+		if count <= 4 {
+			return strings.TrimRight(string(value[:count]), "\x00")
+		}
+		offset := order.Uint32(value)
+		if uint64(offset)+uint64(count) > uint64(len(tiff)) {
+			return ""
+		}
+		return strings.TrimRight(string(tiff[offset:offset+count]), "\x00")
+	}
+
+	var exifIFD uint32
+	readIFD(order.Uint32(tiff[4:]), func(tag, typ uint16, count uint32, value []byte) {
+		switch tag {
+		case exifTagOrientation:
+			if typ == 3 { // SHORT
+				meta.Orientation = int(order.Uint16(value))
+			}
+		case exifTagDateTime:
+			taken = ascii(count, value)
+		case exifTagExifIFD:
+			exifIFD = order.Uint32(value)
+		}
+	})
+	if exifIFD != 0 {
+		readIFD(exifIFD, func(tag, typ uint16, count uint32, value []byte) {
+			if tag == exifTagDateTimeOriginal {
+				original = ascii(count, value)
+			}
+		})
+	}
+	if original != "" {
+		taken = original
+	}
+	// Cameras record local time without a zone; keep the wall clock as is
+	if t, err := time.Parse("2006:01:02 15:04:05", strings.TrimSpace(taken)); err == nil {
+		meta.TakenAt = &t
+	}
+	return meta
+}
+
+// applyOrientation turns an image upright according to its EXIF orientation
+func applyOrientation(img image.Image, orientation int) image.Image {
+	b := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	w, h := b.Dx(), b.Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // Mirrored
+				sx, sy = w-1-x, y
+			case 3: // Upside down
+				sx, sy = w-1-x, h-1-y
+			case 4: // Mirrored upside down
+				sx, sy = x, h-1-y
+			case 5: // Mirrored, turned left
+				sx, sy = y, x
+			case 6: // Turned left, needs turning right
+				sx, sy = y, h-1-x
+			case 7: // Mirrored, turned right
+				sx, sy = w-1-y, h-1-x
+			case 8: // Turned right, needs turning left
+				sx, sy = w-1-y, x
+			default:
+				sx, sy = x, y
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
+
+// ScrubResult says what scrubbing a stored image changed
+type ScrubResult struct {
+	Changed bool // Metadata was removed
+	Rotated bool // The image was turned upright
+}
+
+// ScrubItem strips the metadata from a media item's stored file, turning it
+// upright first, and records its capture date if it wasn't known. Thumbnails
+// and derivatives of turned images are drawn again. With dryRun nothing is
+// changed, but the result says what would be.
+func ScrubItem(gdb *gorm.DB, item *models.MediaItem, dryRun bool) (ScrubResult, error) {
+	var res ScrubResult
+	store, err := StorageForItem(item)
+	if err != nil {
+		return res, err
+	}
+	r, info, err := store.Get(UploadKey(item.Filename))
+	if err != nil {
+		return res, err
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return res, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	cleaned, meta := Scrub(data)
+	res.Changed = !bytes.Equal(cleaned, data)
+	res.Rotated = res.Changed && meta.Orientation > 1 && meta.Orientation <= 8
+	if dryRun {
+		return res, nil
+	}
+
+	updates := map[string]interface{}{}
+	if item.TakenAt == nil && meta.TakenAt != nil {
+		updates["taken_at"] = meta.TakenAt
+	}
+	if res.Changed {
+		if err := store.Put(UploadKey(item.Filename), bytes.NewReader(cleaned), int64(len(cleaned)), info.ContentType); err != nil {
+			return res, fmt.Errorf("failed to store scrubbed file: %w", err)
+		}
+		updates["file_size"] = int64(len(cleaned))
+		updates["content_hash"] = ContentHash(cleaned)
+	}
+	if res.Rotated {
+		if err := SaveThumbnail(store, item.Filename); err != nil {
+			fmt.Printf("Warning: Failed to redraw thumbnail for %s: %v\n", item.Filename, err)
+		}
+		for _, key := range derivativeKeys(item) {
+			store.Delete(key)
+		}
+		if err := GenerateDerivatives(store, item); err != nil {
+			fmt.Printf("Warning: Failed to redraw image sizes for %s: %v\n", item.Filename, err)
+		} else {
+			updates["width"], updates["height"], updates["derivatives"] = item.Width, item.Height, item.Derivatives
+		}
+		updates["derivatives_size"] = MeasureDerivatives(store, item)
+	}
+	if res.Changed {
+		// Resized copies were drawn from the old file
+		PurgeTransforms(item.Filename)
+	}
+	if len(updates) == 0 {
+		return res, nil
+	}
+	return res, gdb.Model(item).Updates(updates).Error
+}
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// exifJPEG returns a 40x20 JPEG, red on the left and blue on the right, with
+// EXIF data giving its orientation and capture time
+func exifJPEG(t *testing.T, orientation uint16) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			if x < 20 {
+				img.Set(x, y, color.RGBA{255, 0, 0, 255})
+			} else {
+				img.Set(x, y, color.RGBA{0, 0, 255, 255})
+			}
+		}
+	}
+	var enc bytes.Buffer
+	if err := jpeg.Encode(&enc, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+
+	// Big-endian TIFF: IFD0 with orientation and a pointer to the EXIF IFD,
+	// which holds DateTimeOriginal
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 2)
+	tiff = append(tiff, 0x01, 0x12, 0x00, 0x03, 0, 0, 0, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0)
+	tiff = append(tiff, 0x87, 0x69, 0x00, 0x04, 0, 0, 0, 1, 0, 0, 0, 38)
+	tiff = append(tiff, 0, 0, 0, 0)
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = append(tiff, 0x90, 0x03, 0x00, 0x02, 0, 0, 0, 20, 0, 0, 0, 56)
+	tiff = append(tiff, 0, 0, 0, 0)
+	tiff = append(tiff, "2025:08:30 14:22:05\x00"...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	out := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	out = binary.BigEndian.AppendUint16(out, uint16(len(segment)+2))
+	out = append(out, segment...)
+	return append(out, enc.Bytes()[2:]...)
+}
+
+func TestScrubJPEG(t *testing.T) {
+	data := exifJPEG(t, 1)
+	meta := ReadMeta(data)
+	if meta.Orientation != 1 || meta.TakenAt == nil || meta.TakenAt.Format("2006-01-02 15:04:05") != "2025-08-30 14:22:05" {
+		t.Fatalf("Unexpected metadata: %+v", meta)
+	}
+
+	cleaned, _ := Scrub(data)
+	if bytes.Contains(cleaned, []byte("Exif")) {
+		t.Error("Expected EXIF data to be removed")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(cleaned)); err != nil {
+		t.Errorf("Scrubbed JPEG doesn't decode: %v", err)
+	}
+}
+
+func TestScrubJPEGAppliesOrientation(t *testing.T) {
+	cleaned, meta := Scrub(exifJPEG(t, 6))
+	if meta.Orientation != 6 {
+		t.Fatalf("Expected orientation 6, got %d", meta.Orientation)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(cleaned))
+	if err != nil {
+		t.Fatalf("Scrubbed JPEG doesn't decode: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 40 {
+		t.Fatalf("Expected the image turned to 20x40, got %dx%d", b.Dx(), b.Dy())
+	}
+	// Turned right, the red left half ends up on top
+	if r, _, b, _ := img.At(10, 5).RGBA(); r < b {
+		t.Error("Expected red at the top after turning")
+	}
+	if ReadMeta(cleaned).Orientation > 1 {
+		t.Error("Expected no orientation left in the scrubbed file")
+	}
+}
+
+func TestScrubJPEGKeepsICCProfile(t *testing.T) {
+	data := exifJPEG(t, 6)
+	icc := append([]byte("ICC_PROFILE\x00\x01\x01"), "fake profile"...)
+	segment := []byte{0xFF, 0xE2}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(icc)+2))
+	segment = append(segment, icc...)
+	data = append(append([]byte{0xFF, 0xD8}, segment...), data[2:]...)
+
+	cleaned, _ := Scrub(data)
+	if !bytes.Contains(cleaned, icc) {
+		t.Error("Expected the color profile to be kept when turning the image")
+	}
+	if bytes.Contains(cleaned, []byte("Exif")) {
+		t.Error("Expected EXIF data to be removed")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(cleaned)); err != nil {
+		t.Errorf("Scrubbed JPEG doesn't decode: %v", err)
+	}
+}
+
+func TestScrubPNG(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	data := buf.Bytes()
+
+	// Add a text chunk after IHDR
+	text := []byte("tEXtComment\x00taken at home")
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(text)-4))
+	chunk = append(chunk, text...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(text))
+	ihdrEnd := 8 + 12 + 13
+	withText := append(append(append([]byte(nil), data[:ihdrEnd]...), chunk...), data[ihdrEnd:]...)
+	if _, err := png.Decode(bytes.NewReader(withText)); err != nil {
+		t.Fatalf("Test PNG doesn't decode: %v", err)
+	}
+
+	cleaned, _ := Scrub(withText)
+	if bytes.Contains(cleaned, []byte("taken at home")) {
+		t.Error("Expected the text chunk to be removed")
+	}
+	if _, err := png.Decode(bytes.NewReader(cleaned)); err != nil {
+		t.Errorf("Scrubbed PNG doesn't decode: %v", err)
+	}
+}
@@ -0,0 +1,264 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/email"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/storage"
+	"gorm.io/gorm"
+)
+
+// defaultQuota is used when storage.quota isn't set
+const defaultQuota = "2GB"
+
+// Storage alert levels, in percent of a site's quota
+const (
+	QuotaWarnLevel = 80
+	QuotaFullLevel = 100
+)
+
+// ErrQuotaExceeded is returned for uploads that don't fit in a site's quota
+var ErrQuotaExceeded = errors.New("storage quota exceeded")
+
+// reserved holds the bytes of uploads that passed the quota check but aren't
+// recorded in the media library yet, per site, so uploads arriving together
+// can't all be let into the same free space. It's kept in memory, which
+// covers the one server process uploads go through.
+var (
+	reservedMu sync.Mutex
+	reserved   = map[uint]int64{}
+)
+
+// ParseSize parses a size like "500MB", "1.5GB" or "2048" (bytes). "unlimited"
+// and "0" mean no limit and return 0.
+func ParseSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(size), " ", ""))
+	if s == "UNLIMITED" {
+		return 0, nil
+	}
+	mult := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		mult   int64
+	}{{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s, mult = strings.TrimSuffix(s, unit.suffix), unit.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 500MB, 2GB or unlimited)", size)
+	}
+	return int64(n * float64(mult)), nil
+}
+
+// DefaultQuota returns the quota for sites without their own, from
+// storage.quota, in bytes; 0 is unlimited
+func DefaultQuota() int64 {
+	raw := config.GetString("storage.quota")
+	if raw == "" {
+		raw = defaultQuota
+	}
+	n, err := ParseSize(raw)
+	if err != nil {
+		log.Printf("Invalid storage.quota %q, using %s: %v", raw, defaultQuota, err)
+		n, _ = ParseSize(defaultQuota)
+	}
+	return n
+}
+
+// Quota returns a site's storage quota in bytes; 0 is unlimited
+func Quota(site *models.Site) int64 {
+	if site.StorageQuota != nil {
+		return *site.StorageQuota
+	}
+	return DefaultQuota()
+}
+
+// SiteUsage returns the bytes of media a site has uploaded, counting each
+// item's smaller copies and thumbnail
+func SiteUsage(db *gorm.DB, siteID uint) int64 {
+	var total int64
+	db.Model(&models.MediaItem{}).
+		Where("site_id = ?", siteID).
+		Select("COALESCE(SUM(file_size + derivatives_size), 0)").
+		Scan(&total)
+	return total
+}
+
+// UsagePercent returns how much of a quota is used, 0 for unlimited quotas
+func UsagePercent(usage, quota int64) int {
+	if quota <= 0 {
+		return 0
+	}
+	return int(usage * 100 / quota)
+}
+
+// CheckQuota returns ErrQuotaExceeded, with the site's usage, if storing
+// size more bytes would take a site over its quota, counting uploads still
+// being stored. The owner is emailed the first time an upload is turned away.
+func CheckQuota(db *gorm.DB, site *models.Site, size int64) error {
+	reservedMu.Lock()
+	defer reservedMu.Unlock()
+	return checkQuota(db, site, size)
+}
+
+// ReserveQuota checks an upload against a site's quota like CheckQuota and
+// holds its size until release is called, which should be once its media
+// item is saved or the upload has failed. Checking and reserving together
+// means concurrent uploads can't take a site over its quota, though the
+// smaller copies made afterwards may go a little over.
+func ReserveQuota(db *gorm.DB, site *models.Site, size int64) (release func(), err error) {
+	reservedMu.Lock()
+	defer reservedMu.Unlock()
+	if err := checkQuota(db, site, size); err != nil {
+		return nil, err
+	}
+	siteID := site.ID
+	reserved[siteID] += size
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			reservedMu.Lock()
+			defer reservedMu.Unlock()
+			if reserved[siteID] -= size; reserved[siteID] <= 0 {
+				delete(reserved, siteID)
+			}
+		})
+	}, nil
+}
+
+// checkQuota is CheckQuota, called with reservedMu held
+func checkQuota(db *gorm.DB, site *models.Site, size int64) error {
+	quota := Quota(site)
+	if quota <= 0 {
+		return nil
+	}
+	usage := SiteUsage(db, site.ID) + reserved[site.ID]
+	if usage+size <= quota {
+		return nil
+	}
+	notifyQuota(db, site, QuotaFullLevel, usage, quota)
+	return fmt.Errorf("%w: %s of %s used, this file needs %s (delete unused media or ask an administrator for more space)",
+		ErrQuotaExceeded, FormatSize(usage), FormatSize(quota), FormatSize(size))
+}
+
+// UpdateQuotaAlerts emails a site's owner when its usage first reaches 80%
+// and 100% of its quota. Call it after storing files. Once usage drops below
+// a level, crossing it again sends another email.
+func UpdateQuotaAlerts(db *gorm.DB, site *models.Site) {
+	quota := Quota(site)
+	usage := SiteUsage(db, site.ID)
+	percent := UsagePercent(usage, quota)
+	level := 0
+	switch {
+	case quota > 0 && percent >= QuotaFullLevel:
+		level = QuotaFullLevel
+	case quota > 0 && percent >= QuotaWarnLevel:
+		level = QuotaWarnLevel
+	}
+	if level < site.QuotaAlertLevel {
+		site.QuotaAlertLevel = level
+		db.Model(site).Update("quota_alert_level", level)
+		return
+	}
+	notifyQuota(db, site, level, usage, quota)
+}
+
+// notifyQuota queues the owner email for an alert level unless it was
+// already sent
+func notifyQuota(db *gorm.DB, site *models.Site, level int, usage, quota int64) {
+	if level == 0 || level <= site.QuotaAlertLevel {
+		return
+	}
+	if err := db.Model(site).Update("quota_alert_level", level).Error; err != nil {
+		log.Printf("Error recording storage alert for %s: %v", site.Subdomain, err)
+		return
+	}
+	site.QuotaAlertLevel = level
+
+	var owner models.User
+	if err := db.First(&owner, site.OwnerID).Error; err != nil {
+		log.Printf("Error loading site owner: %v", err)
+		return
+	}
+	subject, body := quotaEmail(site, level, usage, quota)
+	if err := email.Enqueue(db, owner.Email, subject, body); err != nil {
+		log.Printf("Error queueing storage alert: %v", err)
+	}
+}
+
+// quotaEmail builds the storage alert sent to a site's owner
+func quotaEmail(site *models.Site, level int, usage, quota int64) (string, string) {
+	name := site.SiteTitle
+	if name == "" {
+		name = site.Subdomain
+	}
+	if level >= QuotaFullLevel {
+		return fmt.Sprintf("%s is out of storage space", name), fmt.Sprintf(`%s has used all of its storage space: %s of %s.
+
+New uploads are being turned away. Delete media you no longer need from the
+Media Library ("Show Orphaned" lists files no page uses), or ask an
+administrator to raise the site's quota.`, name, FormatSize(usage), FormatSize(quota))
+	}
+	return fmt.Sprintf("%s has used %d%% of its storage space", name, UsagePercent(usage, quota)), fmt.Sprintf(`%s has used %s of its %s of storage space.
+
+Once it's full, new uploads will be turned away. Delete media you no longer
+need from the Media Library ("Show Orphaned" lists files no page uses), or ask
+an administrator to raise the site's quota.`, name, FormatSize(usage), FormatSize(quota))
+}
+
+// MeasureDerivatives returns the bytes stored for an item's smaller copies
+// and thumbnail. Missing files count as nothing.
+func MeasureDerivatives(store storage.Storage, item *models.MediaItem) int64 {
+	var total int64
+	for _, key := range append([]string{ThumbKey(item.Filename)}, derivativeKeys(item)...) {
+		if info, err := store.Stat(key); err == nil {
+			total += info.Size
+		}
+	}
+	return total
+}
+
+// MeasureUsage records the size of the smaller copies and thumbnails of a
+// site's images (0 for all sites) that haven't been measured, for media
+// uploaded before usage was tracked. It returns the number of items measured.
+func MeasureUsage(db *gorm.DB, siteID uint) (int, error) {
+	query := db.Where("derivatives_size = 0")
+	if siteID != 0 {
+		query = query.Where("site_id = ?", siteID)
+	}
+	var items []models.MediaItem
+	if err := query.Find(&items).Error; err != nil {
+		return 0, err
+	}
+	measured := 0
+	for i := range items {
+		item := &items[i]
+		if IsDocument(item.Filename) {
+			continue
+		}
+		store, err := StorageForItem(item)
+		if err != nil {
+			return measured, err
+		}
+		size := MeasureDerivatives(store, item)
+		if size == 0 {
+			continue
+		}
+		if err := db.Model(item).Update("derivatives_size", size).Error; err != nil {
+			return measured, err
+		}
+		measured++
+	}
+	return measured, nil
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"2048", 2048, true},
+		{"500MB", 500 << 20, true},
+		{"1.5 GB", 3 << 29, true},
+		{"2gb", 2 << 30, true},
+		{"unlimited", 0, true},
+		{"0", 0, true},
+		{"lots", 0, false},
+		{"-1GB", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d, ok=%v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestQuotaAlerts(t *testing.T) {
+	if err := config.InitConfig(filepath.Join(t.TempDir(), "config.yaml")); err != nil {
+		t.Fatalf("Failed to init config: %v", err)
+	}
+	config.Set("storage.quota", "1KB")
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Site{}, &models.User{}, &models.MediaItem{}, &models.OutboundEmail{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	user := models.User{Email: "owner@example.com", PasswordHash: "hash"}
+	db.Create(&user)
+	site := models.Site{Subdomain: "test", OwnerID: user.ID, SiteDir: "/tmp"}
+	db.Create(&site)
+
+	if Quota(&site) != 1024 {
+		t.Fatalf("Expected the default quota, got %d", Quota(&site))
+	}
+	emails := func() int64 {
+		var n int64
+		db.Model(&models.OutboundEmail{}).Count(&n)
+		return n
+	}
+
+	// 860 bytes, counting the smaller copies, is over 80%
+	db.Create(&models.MediaItem{SiteID: site.ID, Filename: "a.jpg", OriginalName: "a.jpg", FileSize: 700, DerivativesSize: 160, MimeType: "image/jpeg", UploadedBy: user.ID})
+	if SiteUsage(db, site.ID) != 860 {
+		t.Fatalf("Expected 860 bytes used, got %d", SiteUsage(db, site.ID))
+	}
+	UpdateQuotaAlerts(db, &site)
+	UpdateQuotaAlerts(db, &site)
+	if emails() != 1 || site.QuotaAlertLevel != QuotaWarnLevel {
+		t.Fatalf("Expected one 80%% email, got %d emails at level %d", emails(), site.QuotaAlertLevel)
+	}
+
+	// Uploads that don't fit are turned away, and the owner told once
+	if err := CheckQuota(db, &site, 100); err != nil {
+		t.Errorf("Expected 100 bytes to fit, got %v", err)
+	}
+	if err := CheckQuota(db, &site, 200); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	CheckQuota(db, &site, 200)
+	if emails() != 2 || site.QuotaAlertLevel != QuotaFullLevel {
+		t.Fatalf("Expected an out-of-space email, got %d emails at level %d", emails(), site.QuotaAlertLevel)
+	}
+
+	// Uploads being stored count until they're recorded
+	release, err := ReserveQuota(db, &site, 100)
+	if err != nil {
+		t.Fatalf("Expected 100 bytes to be reserved, got %v", err)
+	}
+	if _, err := ReserveQuota(db, &site, 100); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected a second upload not to fit alongside the first, got %v", err)
+	}
+	release()
+	release()
+	if err := CheckQuota(db, &site, 100); err != nil {
+		t.Errorf("Expected the space back once released, got %v", err)
+	}
+
+	// Raising the quota lowers the level so the owner hears again next time
+	quota := int64(10 << 10)
+	site.StorageQuota = &quota
+	UpdateQuotaAlerts(db, &site)
+	var reloaded models.Site
+	db.First(&reloaded, site.ID)
+	if reloaded.QuotaAlertLevel != 0 {
+		t.Errorf("Expected the alert level to reset, got %d", reloaded.QuotaAlertLevel)
+	}
+
+	// Unlimited sites never fill up
+	unlimited := int64(0)
+	site.StorageQuota = &unlimited
+	if err := CheckQuota(db, &site, 1<<40); err != nil {
+		t.Errorf("Expected no limit, got %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/themes"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// ShareImageWidth is the Open Graph image width recommended by most social sites
+	ShareImageWidth = 1200
+	// ShareImageHeight is the Open Graph image height recommended by most social sites
+	ShareImageHeight = 630
+
+	// shareImageVersion changes whenever the layout does, so cached images are redrawn
+	shareImageVersion = "1"
+
+	shareImageMargin     = 80
+	shareImageTitleSize  = 72
+	shareImageSiteSize   = 36
+	shareImageMaxLines   = 3
+	shareImageLogoHeight = 96
+)
+
+// ShareImageOptions describes what goes on a share image
+type ShareImageOptions struct {
+	Title     string
+	SiteTitle string
+	Colors    *themes.Colors
+	Logo      image.Image // Optional
+}
+
+// ShareImageKey identifies the current version of a page's share image. It
+// changes when the page, the site's title, logo or theme change.
+func ShareImageKey(site *models.Site, page *models.Page) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%t\x00%s\x00%d\x00%d",
+		shareImageVersion, page.Title, siteTitle(site), site.ThemePalette, site.DarkMode,
+		site.LogoPath, page.UpdatedAt.UnixNano(), site.UpdatedAt.UnixNano())
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// shareImageDir is where a site's generated share images are cached
+func shareImageDir(siteID uint) string {
+	return filepath.Join(MediaDir(), "og", strconv.FormatUint(uint64(siteID), 10))
+}
+
+// GenerateShareImage returns the path of a page's cached share image, drawing
+// it first if the cached copy is missing or out of date.
+func GenerateShareImage(site *models.Site, page *models.Page) (string, error) {
+	dir := shareImageDir(site.ID)
+	prefix := strconv.FormatUint(uint64(page.ID), 10) + "-"
+	path := filepath.Join(dir, prefix+ShareImageKey(site, page)+".png")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create share image directory: %w", err)
+	}
+
+	palette := themes.GetPalette(site.ThemePalette)
+	if palette == nil {
+		palette = themes.GetPalette("slate")
+	}
+	opts := ShareImageOptions{
+		Title:     page.Title,
+		SiteTitle: siteTitle(site),
+		Colors:    themes.GenerateColors(palette, site.DarkMode),
+		Logo:      loadLogo(site.LogoPath),
+	}
+
+	// Write to a temporary file and rename, so concurrent requests never see a partial image
+	tmp, err := os.CreateTemp(dir, prefix+"*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create share image: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := RenderShareImage(tmp, opts); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write share image: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to save share image: %w", err)
+	}
+
+	// Drop earlier versions of this page's image
+	old, _ := filepath.Glob(filepath.Join(dir, prefix+"*.png"))
+	for _, p := range old {
+		if p != path {
+			os.Remove(p)
+		}
+	}
+	return path, nil
+}
+
+// RemoveShareImages deletes a page's cached share images
+func RemoveShareImages(siteID, pageID uint) {
+	old, _ := filepath.Glob(filepath.Join(shareImageDir(siteID), strconv.FormatUint(uint64(pageID), 10)+"-*.png"))
+	for _, p := range old {
+		os.Remove(p)
+	}
+}
+
+// RenderShareImage draws a 1200x630 PNG with the page title and site title on
+// the theme's primary color, with an accent bar and the logo if there is one.
+func RenderShareImage(w io.Writer, opts ShareImageOptions) error {
+	img := image.NewRGBA(image.Rect(0, 0, ShareImageWidth, ShareImageHeight))
+	background := parseHexColor(opts.Colors.Primary)
+	foreground := parseHexColor(opts.Colors.PrimaryContrast)
+	accent := parseHexColor(opts.Colors.Secondary)
+
+	draw.Draw(img, img.Bounds(), &image.Uniform{background}, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, ShareImageHeight-24, ShareImageWidth, ShareImageHeight), &image.Uniform{accent}, image.Point{}, draw.Src)
+
+	titleFace, err := newFace(gobold.TTF, shareImageTitleSize)
+	if err != nil {
+		return err
+	}
+	defer titleFace.Close()
+	siteFace, err := newFace(goregular.TTF, shareImageSiteSize)
+	if err != nil {
+		return err
+	}
+	defer siteFace.Close()
+
+	top := shareImageMargin
+	if opts.Logo != nil {
+		drawLogo(img, opts.Logo, shareImageMargin, top)
+		top += shareImageLogoHeight + 40
+	}
+
+	// Title, wrapped to the image width
+	textWidth := ShareImageWidth - 2*shareImageMargin
+	lineHeight := shareImageTitleSize * 5 / 4
+	d := &font.Drawer{Dst: img, Src: &image.Uniform{foreground}, Face: titleFace}
+	for i, line := range wrapText(d, opts.Title, textWidth, shareImageMaxLines) {
+		d.Dot = fixed.P(shareImageMargin, top+shareImageTitleSize+i*lineHeight)
+		d.DrawString(line)
+	}
+
+	// Site title along the bottom
+	d.Face = siteFace
+	d.Dot = fixed.P(shareImageMargin, ShareImageHeight-24-shareImageMargin/2-8)
+	d.DrawString(truncateText(d, opts.SiteTitle, textWidth))
+
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("failed to encode share image: %w", err)
+	}
+	return nil
+}
+
+func newFace(ttf []byte, size float64) (font.Face, error) {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load font: %w", err)
+	}
+	return face, nil
+}
+
+// wrapText breaks text into lines no wider than width, ending with an
+// ellipsis if it needs more than maxLines
+func wrapText(d *font.Drawer, text string, width, maxLines int) []string {
+	var lines []string
+	line := ""
+	words := strings.Fields(text)
+	for i, word := range words {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line == "" || d.MeasureString(candidate).Ceil() <= width {
+			line = candidate
+			continue
+		}
+		if len(lines) == maxLines-1 {
+			lines = append(lines, truncateText(d, line+" "+strings.Join(words[i:], " "), width))
+			return lines
+		}
+		lines = append(lines, truncateText(d, line, width))
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, truncateText(d, line, width))
+	}
+	return lines
+}
+
+// truncateText shortens text with an ellipsis until it fits in width
+func truncateText(d *font.Drawer, text string, width int) string {
+	if d.MeasureString(text).Ceil() <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimRight(string(runes), " ") + "…"
+		if d.MeasureString(candidate).Ceil() <= width {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// drawLogo scales the logo to a fixed height and draws it at (x, y)
+func drawLogo(dst *image.RGBA, logo image.Image, x, y int) {
+	b := logo.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return
+	}
+	width := b.Dx() * shareImageLogoHeight / b.Dy()
+	if maxWidth := ShareImageWidth - 2*shareImageMargin; width > maxWidth {
+		width = maxWidth
+	}
+	draw.CatmullRom.Scale(dst, image.Rect(x, y, x+width, y+shareImageLogoHeight), logo, b, draw.Over, nil)
+}
+
+// loadLogo decodes a site logo stored in the media library. Logos elsewhere
+// (or missing files) are skipped rather than failing the whole image.
+func loadLogo(logoPath string) image.Image {
+	name := strings.TrimPrefix(logoPath, "/assets/")
+	if name == "" || name == logoPath || strings.Contains(name, "..") {
+		return nil
+	}
+	f, err := OpenUpload(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	img, _, err := DecodeImage(f)
+	if err != nil {
+		return nil
+	}
+	return img
+}
+
+// parseHexColor parses #RRGGBB, falling back to black
+func parseHexColor(s string) color.RGBA {
+	c := color.RGBA{A: 255}
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 {
+		return c
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return c
+	}
+	c.R, c.G, c.B = uint8(v>>16), uint8(v>>8), uint8(v)
+	return c
+}
+
+func siteTitle(site *models.Site) string {
+	if site.SiteTitle != "" {
+		return site.SiteTitle
+	}
+	return site.Subdomain
+}
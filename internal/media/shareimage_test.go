@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/themes"
+)
+
+func TestRenderShareImage(t *testing.T) {
+	colors := themes.GenerateColors(themes.GetPalette("indigo"), false)
+	var buf bytes.Buffer
+	err := RenderShareImage(&buf, ShareImageOptions{
+		Title:     strings.Repeat("A very long page title that needs wrapping ", 10),
+		SiteTitle: "Cat Camp",
+		Colors:    colors,
+	})
+	if err != nil {
+		t.Fatalf("RenderShareImage failed: %v", err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Expected a PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != ShareImageWidth || b.Dy() != ShareImageHeight {
+		t.Errorf("Expected 1200x630, got %dx%d", b.Dx(), b.Dy())
+	}
+	// The corner is plain background in the theme's primary color (#4f46e5)
+	r, g, b, _ := img.At(5, 5).RGBA()
+	if r>>8 != 0x4f || g>>8 != 0x46 || b>>8 != 0xe5 {
+		t.Errorf("Expected primary background, got %02x%02x%02x", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestGenerateShareImageCaches(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := config.InitConfig(configPath); err != nil {
+		t.Fatalf("Failed to init config: %v", err)
+	}
+	config.Set("storage.media_dir", t.TempDir())
+
+	site := &models.Site{ID: 1, Subdomain: "camp", ThemePalette: "emerald"}
+	page := &models.Page{ID: 7, Title: "Arrival", UpdatedAt: time.Now()}
+
+	first, err := GenerateShareImage(site, page)
+	if err != nil {
+		t.Fatalf("GenerateShareImage failed: %v", err)
+	}
+	again, _ := GenerateShareImage(site, page)
+	if again != first {
+		t.Errorf("Expected the cached image to be reused")
+	}
+
+	// A theme change draws a new image and drops the old one
+	site.ThemePalette = "rose"
+	second, err := GenerateShareImage(site, page)
+	if err != nil || second == first {
+		t.Fatalf("Expected a new image after a theme change, got %s (%v)", second, err)
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Error("Expected the outdated image to be removed")
+	}
+
+	RemoveShareImages(site.ID, page.ID)
+	if _, err := os.Stat(second); !os.IsNotExist(err) {
+		t.Error("Expected RemoveShareImages to delete the cached image")
+	}
+}
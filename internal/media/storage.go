@@ -2,93 +2,353 @@
 package media
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
-	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/db"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/storage"
+	"gorm.io/gorm"
 )
 
-// SaveToCentralizedStorage saves an uploaded file to the centralized media directory
-// and returns the generated filename.
+// StorageS3 is the Site.StorageType for sites keeping media in a bucket
+const StorageS3 = "s3"
+
+// ErrNoBucket is returned when a site uses S3 storage without a bucket
+var ErrNoBucket = errors.New("site uses S3 storage but no bucket is set (set the site's bucket or storage.s3.bucket)")
+
+// MediaDir returns the centralized media directory from config
+func MediaDir() string {
+	if dir := config.GetString("storage.media_dir"); dir != "" {
+		return dir
+	}
+	return "/var/lib/stinkykitty/media"
+}
+
+// UploadKey is the storage key of an uploaded file
+func UploadKey(filename string) string {
+	return "uploads/" + filename
+}
+
+// ThumbKey is the storage key of an uploaded image's thumbnail
+func ThumbKey(filename string) string {
+	return "uploads/thumbs/" + filename
+}
+
+// LocalStorage is the media directory on this server
+func LocalStorage() *storage.Local {
+	return storage.NewLocal(MediaDir())
+}
+
+// BucketStorage returns storage for an S3 bucket, or local storage when the
+// bucket is empty. Endpoint and credentials come from storage.s3 in config.
+func BucketStorage(bucket string) (storage.Storage, error) {
+	if bucket == "" {
+		return LocalStorage(), nil
+	}
+	return storage.NewS3(storage.S3Config{
+		Endpoint:  config.GetString("storage.s3.endpoint"),
+		Region:    config.GetString("storage.s3.region"),
+		Bucket:    bucket,
+		AccessKey: config.GetString("storage.s3.access_key"),
+		SecretKey: config.GetString("storage.s3.secret_key"),
+		PathStyle: config.GetBool("storage.s3.path_style"),
+	})
+}
+
+// SiteBucket returns the bucket a site's new uploads go to, or "" for local disk
+func SiteBucket(site *models.Site) (string, error) {
+	if site.StorageType != StorageS3 {
+		return "", nil
+	}
+	if site.S3Bucket != "" {
+		return site.S3Bucket, nil
+	}
+	if bucket := config.GetString("storage.s3.bucket"); bucket != "" {
+		return bucket, nil
+	}
+	return "", ErrNoBucket
+}
+
+// StorageForSite returns where a site's new uploads are stored, and the
+// bucket to record on their media items
+func StorageForSite(site *models.Site) (storage.Storage, string, error) {
+	bucket, err := SiteBucket(site)
+	if err != nil {
+		return nil, "", err
+	}
+	store, err := BucketStorage(bucket)
+	if err != nil {
+		return nil, "", err
+	}
+	return store, bucket, nil
+}
+
+// StorageForItem returns where a media item's file is stored. Items keep the
+// storage they were uploaded to, so changing a site's storage doesn't break
+// older files.
+func StorageForItem(item *models.MediaItem) (storage.Storage, error) {
+	return BucketStorage(item.Bucket)
+}
+
+// StorageForUpload returns the storage holding an uploaded file, its
+// thumbnail or one of its derivatives. Files in a bucket are recorded on their media item; anything
+// else is in the local media directory.
+func StorageForUpload(name string) (storage.Storage, error) {
+	var item models.MediaItem
+	gdb := db.GetDB()
+	if gdb == nil || gdb.Where("filename = ? AND bucket <> ?", UploadFilename(name), "").
+		First(&item).Error != nil {
+		return LocalStorage(), nil
+	}
+	return StorageForItem(&item)
+}
+
+// OpenUpload opens an uploaded file wherever it is stored, given its name
+// under /assets/. The caller must close it.
+func OpenUpload(name string) (io.ReadCloser, error) {
+	store, err := StorageForUpload(name)
+	if err != nil {
+		return nil, err
+	}
+	r, _, err := store.Get(UploadKey(name))
+	return r, err
+}
+
+// SaveToCentralizedStorage saves an uploaded image or allowed document to the
+// centralized media directory and returns the generated filename.
 func SaveToCentralizedStorage(file *multipart.FileHeader) (string, error) {
-	// Get centralized media directory from config
-	mediaDir := config.GetString("storage.media_dir")
-	if mediaDir == "" {
-		mediaDir = "/var/lib/stinkykitty/media"
+	saved, err := SaveMedia(LocalStorage(), file)
+	if err != nil {
+		return "", err
 	}
+	return saved.Filename, nil
+}
 
-	// Create uploads subdirectory if it doesn't exist
-	uploadsDir := filepath.Join(mediaDir, "uploads")
-	if err := os.MkdirAll(uploadsDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create media directory: %w", err)
+// SavedFile describes an upload once it's stored
+type SavedFile struct {
+	Filename    string
+	Size        int64      // Bytes stored, after metadata is stripped
+	ContentType string     // Detected from the content, not the browser's claim
+	IsDocument  bool       // A document rather than an image
+	TakenAt     *time.Time // When the photo was taken, if its EXIF data says
+	Hash        string     // SHA-256 of the stored bytes, hex
+}
+
+// Upload is an uploaded file that's been validated and cleaned but not yet
+// stored, so it can be checked against the library for an existing copy
+type Upload struct {
+	SavedFile
+	data []byte
+}
+
+// Save validates an uploaded image, turns it upright and strips its metadata
+// (unless media.keep_metadata is set), and stores it under a random name
+func Save(store storage.Storage, file *multipart.FileHeader) (*SavedFile, error) {
+	u, err := prepare(file, false)
+	if err != nil {
+		return nil, err
 	}
+	return u.Store(store)
+}
 
-	// Generate random filename to avoid conflicts
-	randomBytes := make([]byte, 16)
-	if _, err := rand.Read(randomBytes); err != nil {
-		return "", fmt.Errorf("failed to generate random filename: %w", err)
+// SaveMedia saves an uploaded image like Save, or a document of one of the
+// types in media.document_types
+func SaveMedia(store storage.Storage, file *multipart.FileHeader) (*SavedFile, error) {
+	u, err := PrepareMedia(file)
+	if err != nil {
+		return nil, err
 	}
-	randomName := hex.EncodeToString(randomBytes)
+	return u.Store(store)
+}
 
-	// Get file extension
-	ext := filepath.Ext(file.Filename)
-	if ext == "" {
-		ext = ".jpg" // default
+// PrepareMedia does everything SaveMedia does except storing the file
+func PrepareMedia(file *multipart.FileHeader) (*Upload, error) {
+	return prepare(file, true)
+}
+
+// PrepareImage does everything Save does except storing the file
+func PrepareImage(file *multipart.FileHeader) (*Upload, error) {
+	return prepare(file, false)
+}
+
+// Store saves a prepared upload
+func (u *Upload) Store(store storage.Storage) (*SavedFile, error) {
+	if err := store.Put(UploadKey(u.Filename), bytes.NewReader(u.data), int64(len(u.data)), u.ContentType); err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
 	}
+	saved := u.SavedFile
+	return &saved, nil
+}
 
-	// Create full path
-	filename := randomName + ext
-	fullPath := filepath.Join(uploadsDir, filename)
+// PrepareImageData validates and cleans an image that didn't arrive as an
+// upload, such as one read from a zip archive, like PrepareImage
+func PrepareImageData(name string, data []byte) (*Upload, error) {
+	return prepareData(name, data, false)
+}
 
+func prepare(file *multipart.FileHeader, allowDocuments bool) (*Upload, error) {
 	// Open uploaded file
 	src, err := file.Open()
 	if err != nil {
-		return "", fmt.Errorf("failed to open uploaded file: %w", err)
+		return nil, fmt.Errorf("failed to open uploaded file: %w", err)
 	}
 	defer src.Close()
 
-	// Validate file content type using Magic Bytes
-	buffer := make([]byte, 512)
-	n, err := src.Read(buffer)
-	if err != nil && err != io.EOF {
-		return "", fmt.Errorf("failed to read file for validation: %w", err)
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	return prepareData(file.Filename, data, allowDocuments)
+}
+
+func prepareData(name string, data []byte, allowDocuments bool) (*Upload, error) {
+	// Generate random filename to avoid conflicts
+	randomBytes := make([]byte, 16)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate random filename: %w", err)
 	}
+	randomName := hex.EncodeToString(randomBytes)
 
-	contentType := http.DetectContentType(buffer[:n])
-	validTypes := []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
-	isValid := false
-	for _, validType := range validTypes {
+	// Get file extension
+	ext := filepath.Ext(name)
+	if ext == "" {
+		ext = ".jpg" // default
+	}
+	filename := randomName + ext
+
+	// Validate file content type using Magic Bytes
+	contentType := http.DetectContentType(data)
+	isImage := false
+	for _, validType := range imageTypes {
 		if contentType == validType {
-			isValid = true
+			isImage = true
 			break
 		}
 	}
-	if !isValid {
-		return "", fmt.Errorf("invalid file type: %s (only images allowed)", contentType)
+
+	var meta ImageMeta
+	switch {
+	case isImage:
+		// Images are decoded for thumbnails and resized copies, so one that
+		// claims huge dimensions is refused before anything tries
+		if err := CheckDimensions(data); errors.Is(err, ErrImageTooLarge) {
+			return nil, err
+		}
+		// Phone photos carry their rotation and GPS position in EXIF data
+		if config.GetBool("media.keep_metadata") {
+			meta = ReadMeta(data)
+		} else {
+			data, meta = Scrub(data)
+		}
+		if IsDocument(filename) {
+			// Keep the name from claiming to be a document
+			filename = randomName + "." + strings.Replace(strings.TrimPrefix(contentType, "image/"), "jpeg", "jpg", 1)
+		}
+	case allowDocuments:
+		var err error
+		contentType, err = CheckDocument(name, data)
+		if err != nil {
+			return nil, err
+		}
+		filename = randomName + "." + extension(name)
+	default:
+		return nil, fmt.Errorf("invalid file type: %s (only images allowed)", contentType)
 	}
 
-	// Reset file pointer after validation
-	if _, err := src.Seek(0, io.SeekStart); err != nil {
-		return "", fmt.Errorf("failed to reset file pointer: %w", err)
+	return &Upload{
+		SavedFile: SavedFile{
+			Filename:    filename,
+			Size:        int64(len(data)),
+			ContentType: contentType,
+			IsDocument:  !isImage,
+			TakenAt:     meta.TakenAt,
+			Hash:        ContentHash(data),
+		},
+		data: data,
+	}, nil
+}
+
+// SaveThumbnail generates the standard thumbnail for a stored image
+func SaveThumbnail(store storage.Storage, filename string) error {
+	src, _, err := store.Get(UploadKey(filename))
+	if err != nil {
+		return fmt.Errorf("failed to open source image: %w", err)
 	}
+	defer src.Close()
 
-	// Create destination file
-	dst, err := os.Create(fullPath)
+	var buf bytes.Buffer
+	if err := EncodeThumbnail(src, &buf, ThumbnailWidth, ThumbnailHeight); err != nil {
+		return err
+	}
+	return store.Put(ThumbKey(filename), &buf, int64(buf.Len()), "image/jpeg")
+}
+
+// DeleteStored removes a media item's file, thumbnail and derivatives from
+// its storage
+func DeleteStored(item *models.MediaItem) error {
+	store, err := StorageForItem(item)
 	if err != nil {
-		return "", fmt.Errorf("failed to create destination file: %w", err)
+		return err
+	}
+	for _, key := range append([]string{UploadKey(item.Filename), ThumbKey(item.Filename)}, derivativeKeys(item)...) {
+		if err := store.Delete(key); err != nil {
+			return err
+		}
 	}
-	defer dst.Close()
+	return nil
+}
 
-	// Copy file contents
-	if _, err := io.Copy(dst, src); err != nil {
-		return "", fmt.Errorf("failed to copy file: %w", err)
+// MoveItem copies a media item's file, thumbnail and derivatives to another
+// bucket ("" for the local media directory), records the new bucket, then
+// removes the old copies. A missing thumbnail or derivative is skipped.
+func MoveItem(gdb *gorm.DB, item *models.MediaItem, bucket string) error {
+	if item.Bucket == bucket {
+		return nil
 	}
+	from, err := StorageForItem(item)
+	if err != nil {
+		return err
+	}
+	to, err := BucketStorage(bucket)
+	if err != nil {
+		return err
+	}
+	if err := copyStored(from, to, UploadKey(item.Filename)); err != nil {
+		return err
+	}
+	extras := append([]string{ThumbKey(item.Filename)}, derivativeKeys(item)...)
+	for _, key := range extras {
+		if err := copyStored(from, to, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+	}
+	if err := gdb.Model(item).Update("bucket", bucket).Error; err != nil {
+		return fmt.Errorf("failed to update media item: %w", err)
+	}
+	for _, key := range append([]string{UploadKey(item.Filename)}, extras...) {
+		if err := from.Delete(key); err != nil {
+			return fmt.Errorf("moved, but failed to remove old copy %s: %w", key, err)
+		}
+	}
+	return nil
+}
 
-	return filename, nil
+func copyStored(from, to storage.Storage, key string) error {
+	r, info, err := from.Get(key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return to.Put(key, r, info.Size, info.ContentType)
 }
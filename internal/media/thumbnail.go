@@ -7,6 +7,7 @@ import (
 	_ "image/gif"   // Register GIF decoder
 	"image/jpeg"
 	_ "image/png"   // Register PNG decoder
+	"io"
 	"os"
 	"path/filepath"
 
@@ -37,8 +38,25 @@ func GenerateThumbnail(srcPath, dstPath string, width, height int) error {
 	}
 	defer srcFile.Close()
 
+	// Create destination directory if needed
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Save thumbnail
+	dstFile, err := os.Create(dstPath)
+	if err != nil {
+		return fmt.Errorf("failed to create thumbnail file: %w", err)
+	}
+	defer dstFile.Close()
+
+	return EncodeThumbnail(srcFile, dstFile, width, height)
+}
+
+// EncodeThumbnail decodes an image and writes a center-cropped JPEG thumbnail
+func EncodeThumbnail(src io.Reader, dst io.Writer, width, height int) error {
 	// Decode image
-	img, _, err := image.Decode(srcFile)
+	img, _, err := DecodeImage(src)
 	if err != nil {
 		return fmt.Errorf("failed to decode image: %w", err)
 	}
@@ -71,20 +89,8 @@ func GenerateThumbnail(srcPath, dstPath string, width, height int) error {
 	// Scale and draw
 	draw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), img, cropRect, draw.Over, nil)
 
-	// Create destination directory if needed
-	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	// Save thumbnail
-	dstFile, err := os.Create(dstPath)
-	if err != nil {
-		return fmt.Errorf("failed to create thumbnail file: %w", err)
-	}
-	defer dstFile.Close()
-
 	// Always save as JPEG for consistent format
-	if err := jpeg.Encode(dstFile, thumbnail, &jpeg.Options{Quality: 85}); err != nil {
+	if err := jpeg.Encode(dst, thumbnail, &jpeg.Options{Quality: 85}); err != nil {
 		return fmt.Errorf("failed to encode thumbnail: %w", err)
 	}
 
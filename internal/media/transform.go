@@ -0,0 +1,352 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"golang.org/x/image/draw"
+)
+
+const (
+	// FitCover fills the whole box, cropping the overflow from the center
+	FitCover = "cover"
+	// FitContain fits the image inside the box without cropping
+	FitContain = "contain"
+
+	// defaultTransformPresets and defaultTransformQualities are used when
+	// media.transform.* isn't configured
+	defaultTransformPresets   = "400x0,800x0,1200x0,1600x0,200x200,400x400,800x800,1200x630"
+	defaultTransformQualities = "60,75,85"
+	defaultTransformQuality   = 85
+
+	// transformVersion is part of every cache key, so changing how images are
+	// drawn doesn't serve stale copies
+	transformVersion = "1"
+)
+
+// ErrTransformNotAllowed is returned for sizes or qualities that aren't in
+// the configured presets
+var ErrTransformNotAllowed = errors.New("image size not allowed")
+
+// TransformParams are the query parameters that ask for a transformed image
+var TransformParams = []string{"w", "h", "fit", "format", "q"}
+
+// Transform describes a resized copy of an image. A zero width or height
+// follows the other one, keeping the aspect ratio.
+type Transform struct {
+	Width   int
+	Height  int
+	Fit     string // FitCover or FitContain, when both sides are set
+	Format  string // "jpeg", "png", or "" to keep the original's where possible
+	Quality int    // JPEG quality
+}
+
+// WantsTransform reports whether a request asks for a transformed image
+func WantsTransform(query url.Values) bool {
+	for _, p := range TransformParams {
+		if _, ok := query[p]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTransform reads and validates transformation parameters. The size
+// must be one of media.transform.presets ("WIDTHxHEIGHT", 0 for either side
+// meaning "follow the other"), and the quality one of
+// media.transform.qualities, so the endpoint can only make a bounded number
+// of copies per image.
+func ParseTransform(query url.Values) (*Transform, error) {
+	t := &Transform{Fit: FitCover, Quality: defaultTransformQuality}
+	for _, side := range []struct {
+		param string
+		dst   *int
+	}{{"w", &t.Width}, {"h", &t.Height}} {
+		if s := query.Get(side.param); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid %s: %q", side.param, s)
+			}
+			*side.dst = n
+		}
+	}
+	if t.Width == 0 && t.Height == 0 {
+		return nil, errors.New("w or h is required")
+	}
+
+	if fit := query.Get("fit"); fit != "" {
+		if fit != FitCover && fit != FitContain {
+			return nil, fmt.Errorf("invalid fit %q (use cover or contain)", fit)
+		}
+		t.Fit = fit
+	}
+	switch format := strings.ToLower(query.Get("format")); format {
+	case "":
+	case "jpeg", "jpg":
+		t.Format = "jpeg"
+	case "png":
+		t.Format = "png"
+	default:
+		return nil, fmt.Errorf("invalid format %q (use jpeg or png)", format)
+	}
+	if s := query.Get("q"); s != "" {
+		q, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid q: %q", s)
+		}
+		t.Quality = q
+	}
+
+	if !containsPreset(TransformPresets(), t.Width, t.Height) {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTransformNotAllowed, t.Width, t.Height)
+	}
+	if s := query.Get("q"); s != "" && !containsInt(TransformQualities(), t.Quality) {
+		return nil, fmt.Errorf("%w: quality %d", ErrTransformNotAllowed, t.Quality)
+	}
+	return t, nil
+}
+
+// TransformPresets returns the allowed sizes as [width, height] pairs
+func TransformPresets() [][2]int {
+	raw := config.GetString("media.transform.presets")
+	if raw == "" {
+		raw = defaultTransformPresets
+	}
+	var presets [][2]int
+	for _, p := range strings.Split(raw, ",") {
+		w, h, ok := strings.Cut(strings.TrimSpace(p), "x")
+		width, errW := strconv.Atoi(w)
+		height, errH := strconv.Atoi(h)
+		if !ok || errW != nil || errH != nil || width < 0 || height < 0 {
+			continue
+		}
+		presets = append(presets, [2]int{width, height})
+	}
+	return presets
+}
+
+// TransformQualities returns the allowed JPEG qualities
+func TransformQualities() []int {
+	raw := config.GetString("media.transform.qualities")
+	if raw == "" {
+		raw = defaultTransformQualities
+	}
+	var qualities []int
+	for _, q := range strings.Split(raw, ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(q)); err == nil && n >= 1 && n <= 100 {
+			qualities = append(qualities, n)
+		}
+	}
+	return qualities
+}
+
+func containsPreset(presets [][2]int, w, h int) bool {
+	for _, p := range presets {
+		if p[0] == w && p[1] == h {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(list []int, n int) bool {
+	for _, v := range list {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
+// TransformCacheDir is where transformed images are cached
+func TransformCacheDir() string {
+	return filepath.Join(MediaDir(), "cache", "transforms")
+}
+
+// transformLocks stops two requests for the same copy drawing it twice
+var transformLocks sync.Map
+
+// transformKey returns the cache key of a transformed copy of an image
+func transformKey(name string, t *Transform) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%d\x00%d\x00%s\x00%s\x00%d",
+		transformVersion, name, t.Width, t.Height, t.Fit, t.Format, t.Quality)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// transformPath returns where the copy with a cache key is stored
+func transformPath(key string) string {
+	return filepath.Join(TransformCacheDir(), key[:2], key)
+}
+
+// PurgeTransforms deletes the cached transformed copies of one uploaded
+// image, for when the file is replaced. Cache keys are hashes, so every
+// combination of parameters ParseTransform allows is tried. It returns the
+// number of copies removed.
+func PurgeTransforms(name string) int {
+	qualities := append(TransformQualities(), defaultTransformQuality)
+	removed := 0
+	for _, p := range TransformPresets() {
+		for _, fit := range []string{FitCover, FitContain} {
+			for _, format := range []string{"", "jpeg", "png"} {
+				for _, q := range qualities {
+					t := &Transform{Width: p[0], Height: p[1], Fit: fit, Format: format, Quality: q}
+					if os.Remove(transformPath(transformKey(name, t))) == nil {
+						removed++
+					}
+				}
+			}
+		}
+	}
+	return removed
+}
+
+// TransformedImage returns the path of a cached transformed copy of an
+// uploaded image, drawing it first if it isn't cached yet
+func TransformedImage(name string, t *Transform) (string, error) {
+	key := transformKey(name, t)
+	path := transformPath(key)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	lock, _ := transformLocks.LoadOrStore(key, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer func() {
+		lock.(*sync.Mutex).Unlock()
+		transformLocks.Delete(key)
+	}()
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	src, err := OpenUpload(name)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	err = EncodeTransform(src, &buf, t)
+	src.Close()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+"*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to cache image: %w", err)
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to cache image: %w", err)
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to cache image: %w", err)
+	}
+	return path, nil
+}
+
+// EncodeTransform decodes an image and writes a transformed copy. Images are
+// never enlarged: a box bigger than the original gets the original's size
+// (cropped to the box's shape for cover).
+func EncodeTransform(src io.Reader, dst io.Writer, t *Transform) error {
+	img, format, err := DecodeImage(src)
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+	b := img.Bounds()
+	sw, sh := float64(b.Dx()), float64(b.Dy())
+
+	crop := b
+	var outW, outH float64
+	switch {
+	case t.Width > 0 && t.Height > 0 && t.Fit == FitCover:
+		scale := math.Max(float64(t.Width)/sw, float64(t.Height)/sh)
+		cropW, cropH := float64(t.Width)/scale, float64(t.Height)/scale
+		x := b.Min.X + int((sw-cropW)/2)
+		y := b.Min.Y + int((sh-cropH)/2)
+		crop = image.Rect(x, y, x+int(math.Round(cropW)), y+int(math.Round(cropH)))
+		scale = math.Min(scale, 1)
+		outW, outH = cropW*scale, cropH*scale
+	case t.Width > 0 && t.Height > 0:
+		scale := math.Min(math.Min(float64(t.Width)/sw, float64(t.Height)/sh), 1)
+		outW, outH = sw*scale, sh*scale
+	case t.Width > 0:
+		scale := math.Min(float64(t.Width)/sw, 1)
+		outW, outH = sw*scale, sh*scale
+	default:
+		scale := math.Min(float64(t.Height)/sh, 1)
+		outW, outH = sw*scale, sh*scale
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, int(math.Max(math.Round(outW), 1)), int(math.Max(math.Round(outH), 1))))
+	draw.CatmullRom.Scale(out, out.Bounds(), img, crop, draw.Src, nil)
+
+	outFormat := t.Format
+	if outFormat == "" {
+		outFormat = "jpeg"
+		if format == "png" || format == "gif" {
+			// Keep transparency
+			outFormat = "png"
+		}
+	}
+	if outFormat == "png" {
+		err = png.Encode(dst, out)
+	} else {
+		err = jpeg.Encode(dst, out, &jpeg.Options{Quality: t.Quality})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+	return nil
+}
+
+// PurgeTransformCache removes transformed images cached before cutoff, or all of them when cutoff is zero. It returns how many files and
+// bytes were removed.
+func PurgeTransformCache(cutoff time.Time) (int, int64, error) {
+	var files int
+	var size int64
+	err := filepath.Walk(TransformCacheDir(), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if !cutoff.IsZero() && !info.ModTime().Before(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		files++
+		size += info.Size()
+		return nil
+	})
+	return files, size, err
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+)
+
+func TestParseTransform(t *testing.T) {
+	tr, err := ParseTransform(url.Values{"w": {"400"}, "h": {"400"}, "fit": {"contain"}, "format": {"png"}, "q": {"75"}})
+	if err != nil {
+		t.Fatalf("ParseTransform failed: %v", err)
+	}
+	if tr.Width != 400 || tr.Height != 400 || tr.Fit != FitContain || tr.Format != "png" || tr.Quality != 75 {
+		t.Errorf("Unexpected transform: %+v", tr)
+	}
+
+	for _, bad := range []url.Values{
+		{"w": {"401"}},                  // not a preset
+		{"w": {"400"}, "q": {"99"}},     // quality not allowed
+		{"w": {"-1"}},                   // invalid number
+		{"w": {"400"}, "fit": {"zoom"}}, // unknown fit
+		{"w": {"400"}, "format": {"bmp"}},
+		{"fit": {"cover"}}, // no size
+	} {
+		if _, err := ParseTransform(bad); err == nil {
+			t.Errorf("Expected %v to be rejected", bad)
+		}
+	}
+	if _, err := ParseTransform(url.Values{"w": {"9999"}}); !errors.Is(err, ErrTransformNotAllowed) {
+		t.Errorf("Expected ErrTransformNotAllowed, got %v", err)
+	}
+}
+
+func TestEncodeTransform(t *testing.T) {
+	var src bytes.Buffer
+	jpeg.Encode(&src, image.NewRGBA(image.Rect(0, 0, 1000, 500)), nil)
+
+	for _, tc := range []struct {
+		t    Transform
+		w, h int
+	}{
+		{Transform{Width: 400, Height: 400, Fit: FitCover}, 400, 400},
+		{Transform{Width: 400, Height: 400, Fit: FitContain}, 400, 200},
+		{Transform{Width: 800}, 800, 400},
+		// Never enlarged
+		{Transform{Width: 1600}, 1000, 500},
+		{Transform{Width: 1200, Height: 630, Fit: FitCover}, 952, 500},
+	} {
+		tc.t.Quality = 75
+		var out bytes.Buffer
+		if err := EncodeTransform(bytes.NewReader(src.Bytes()), &out, &tc.t); err != nil {
+			t.Fatalf("EncodeTransform(%+v) failed: %v", tc.t, err)
+		}
+		img, _, err := image.Decode(&out)
+		if err != nil {
+			t.Fatalf("Failed to decode output: %v", err)
+		}
+		if b := img.Bounds(); b.Dx() != tc.w || b.Dy() != tc.h {
+			t.Errorf("%+v: expected %dx%d, got %dx%d", tc.t, tc.w, tc.h, b.Dx(), b.Dy())
+		}
+	}
+}
+
+func TestTransformedImageCacheAndPurge(t *testing.T) {
+	if err := config.InitConfig(filepath.Join(t.TempDir(), "config.yaml")); err != nil {
+		t.Fatalf("Failed to init config: %v", err)
+	}
+	config.Set("storage.media_dir", t.TempDir())
+
+	var src bytes.Buffer
+	jpeg.Encode(&src, image.NewRGBA(image.Rect(0, 0, 600, 300)), nil)
+	if err := LocalStorage().Put(UploadKey("photo.jpg"), &src, int64(src.Len()), "image/jpeg"); err != nil {
+		t.Fatalf("Failed to store image: %v", err)
+	}
+
+	tr := &Transform{Width: 400, Fit: FitCover, Quality: 85}
+	path, err := TransformedImage("photo.jpg", tr)
+	if err != nil {
+		t.Fatalf("TransformedImage failed: %v", err)
+	}
+	again, err := TransformedImage("photo.jpg", tr)
+	if err != nil || again != path {
+		t.Errorf("Expected the cached copy to be reused, got %s (%v)", again, err)
+	}
+
+	// Recent copies survive an --older-than purge
+	if files, _, _ := PurgeTransformCache(time.Now().Add(-time.Hour)); files != 0 {
+		t.Errorf("Expected nothing purged, got %d", files)
+	}
+	files, size, err := PurgeTransformCache(time.Time{})
+	if err != nil || files != 1 || size == 0 {
+		t.Errorf("Expected one file purged, got %d (%d bytes, %v)", files, size, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected the cached copy to be removed")
+	}
+}
+
+func TestPurgeTransforms(t *testing.T) {
+	if err := config.InitConfig(filepath.Join(t.TempDir(), "config.yaml")); err != nil {
+		t.Fatalf("Failed to init config: %v", err)
+	}
+	config.Set("storage.media_dir", t.TempDir())
+
+	for _, name := range []string{"photo.jpg", "other.jpg"} {
+		var src bytes.Buffer
+		jpeg.Encode(&src, image.NewRGBA(image.Rect(0, 0, 600, 300)), nil)
+		if err := LocalStorage().Put(UploadKey(name), &src, int64(src.Len()), "image/jpeg"); err != nil {
+			t.Fatalf("Failed to store image: %v", err)
+		}
+	}
+
+	var kept string
+	for _, tr := range []*Transform{
+		{Width: 400, Fit: FitCover, Quality: 85},
+		{Width: 200, Height: 200, Fit: FitContain, Format: "png", Quality: 60},
+	} {
+		if _, err := TransformedImage("photo.jpg", tr); err != nil {
+			t.Fatalf("TransformedImage failed: %v", err)
+		}
+		path, err := TransformedImage("other.jpg", tr)
+		if err != nil {
+			t.Fatalf("TransformedImage failed: %v", err)
+		}
+		kept = path
+	}
+
+	if n := PurgeTransforms("photo.jpg"); n != 2 {
+		t.Errorf("Expected 2 copies purged, got %d", n)
+	}
+	if _, err := os.Stat(kept); err != nil {
+		t.Error("Expected other images' copies to be kept")
+	}
+}
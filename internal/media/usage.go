@@ -3,13 +3,15 @@ package media
 
 import (
 	"encoding/json"
+	"regexp"
+	"strings"
 
 	"github.com/thatcatcamp/stinkykitty/internal/blocks"
 	"github.com/thatcatcamp/stinkykitty/internal/models"
 	"gorm.io/gorm"
 )
 
-// UsageLocation represents where an image is used
+// UsageLocation represents where an image or document is used
 type UsageLocation struct {
 	PageID    uint
 	PageTitle string
@@ -17,56 +19,224 @@ type UsageLocation struct {
 	BlockType string
 }
 
-// FindImageUsage finds all blocks that reference a specific image URL
-func FindImageUsage(db *gorm.DB, siteID uint, imageURL string) []UsageLocation {
-	var usages []UsageLocation
+// mediaURLPattern finds uploaded files in block content: /assets/ for the
+// media library and /uploads/ for files from before it. Absolute links to
+// the site match on their path.
+var mediaURLPattern = regexp.MustCompile(`/(?:assets|uploads)/[^\s"'<>()?#,]+`)
 
-	// Get all pages for this site
-	var pages []models.Page
-	db.Where("site_id = ? AND deleted_at IS NULL", siteID).Find(&pages)
-
-	// For each page, check blocks
-	for _, page := range pages {
-		var pageBlocks []models.Block
-		db.Where("page_id = ? AND deleted_at IS NULL", page.ID).Find(&pageBlocks)
-
-		for _, block := range pageBlocks {
-			if containsImageURL(block, imageURL) {
-				usages = append(usages, UsageLocation{
-					PageID:    page.ID,
-					PageTitle: page.Title,
-					BlockID:   block.ID,
-					BlockType: block.Type,
-				})
-			}
-		}
+// normalizeMediaURL reduces a link to an uploaded file to the URL of the
+// original, so thumbnails, sizes and transformed copies count as using it.
+// It returns "" for links that aren't to uploaded files.
+func normalizeMediaURL(link string) string {
+	u := mediaURLPattern.FindString(link)
+	if u == "" {
+		return ""
+	}
+	if name, ok := strings.CutPrefix(u, "/assets/"); ok {
+		return "/assets/" + UploadFilename(name)
 	}
+	return u
+}
 
-	return usages
+// mediaURLsIn finds every uploaded file linked from text or HTML, such as
+// <img src> and <a href> in column content
+func mediaURLsIn(s string) []string {
+	var urls []string
+	for _, link := range mediaURLPattern.FindAllString(s, -1) {
+		urls = append(urls, normalizeMediaURL(link))
+	}
+	return urls
 }
 
-// containsImageURL checks if a block contains a specific image URL
-func containsImageURL(block models.Block, imageURL string) bool {
+// stringsIn collects every string in decoded JSON
+func stringsIn(v interface{}, out []string) []string {
+	switch v := v.(type) {
+	case string:
+		out = append(out, v)
+	case []interface{}:
+		for _, e := range v {
+			out = stringsIn(e, out)
+		}
+	case map[string]interface{}:
+		for _, e := range v {
+			out = stringsIn(e, out)
+		}
+	}
+	return out
+}
+
+// BlockMediaURLs returns the uploaded files a block uses, each once
+func BlockMediaURLs(block models.Block) []string {
+	var urls []string
 	switch block.Type {
 	case "image":
 		var data blocks.ImageBlockData
 		if err := json.Unmarshal([]byte(block.Data), &data); err != nil {
-			return false
+			return nil
+		}
+		urls = append(urls, normalizeMediaURL(data.URL))
+
+	case "file":
+		var data blocks.FileBlockData
+		if err := json.Unmarshal([]byte(block.Data), &data); err != nil {
+			return nil
 		}
-		return data.URL == imageURL
+		urls = append(urls, normalizeMediaURL(data.URL))
 
 	case "button":
-		// Button blocks might have background images in the future
-		// For now, just check if the URL appears in the data
-		return false
+		var data blocks.ButtonBlockData
+		if err := json.Unmarshal([]byte(block.Data), &data); err != nil {
+			return nil
+		}
+		urls = append(urls, normalizeMediaURL(data.URL))
 
 	case "columns":
-		// Columns can contain nested blocks with images
-		// For V1, we'll do simple string matching
-		// TODO: Proper nested block parsing in V2
-		return false
+		var data blocks.ColumnsBlockData
+		if err := json.Unmarshal([]byte(block.Data), &data); err != nil {
+			return nil
+		}
+		for _, col := range data.Columns {
+			urls = append(urls, mediaURLsIn(col.Content)...)
+		}
 
 	default:
-		return false
+		// Other blocks may link to files in any of their text
+		var data interface{}
+		if err := json.Unmarshal([]byte(block.Data), &data); err != nil {
+			return nil
+		}
+		for _, s := range stringsIn(data, nil) {
+			urls = append(urls, mediaURLsIn(s)...)
+		}
+	}
+
+	seen := map[string]bool{}
+	unique := urls[:0]
+	for _, u := range urls {
+		if u != "" && !seen[u] {
+			seen[u] = true
+			unique = append(unique, u)
+		}
+	}
+	return unique
+}
+
+// containsImageURL checks if a block contains a specific image URL
+func containsImageURL(block models.Block, imageURL string) bool {
+	for _, u := range BlockMediaURLs(block) {
+		if u == imageURL {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateBlockReferences records the files a block uses, replacing what was
+// recorded for it before. Call it whenever a block is created or saved.
+func UpdateBlockReferences(db *gorm.DB, block *models.Block) error {
+	var page models.Page
+	if err := db.Unscoped().Select("id", "site_id").First(&page, block.PageID).Error; err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("block_id = ?", block.ID).Delete(&models.MediaReference{}).Error; err != nil {
+			return err
+		}
+		for _, u := range BlockMediaURLs(*block) {
+			ref := models.MediaReference{SiteID: page.SiteID, PageID: page.ID, BlockID: block.ID, URL: u}
+			if err := tx.Create(&ref).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteBlockReferences forgets the files a deleted block used
+func DeleteBlockReferences(db *gorm.DB, blockID uint) error {
+	return db.Where("block_id = ?", blockID).Delete(&models.MediaReference{}).Error
+}
+
+// RebuildReferences re-records the files used by every block of a site, for
+// blocks saved before references were kept. It returns the number of blocks
+// indexed.
+func RebuildReferences(db *gorm.DB, siteID uint) (int, error) {
+	var siteBlocks []models.Block
+	err := db.Joins("JOIN pages ON pages.id = blocks.page_id").
+		Where("pages.site_id = ?", siteID).
+		Find(&siteBlocks).Error
+	if err != nil {
+		return 0, err
+	}
+	if err := db.Where("site_id = ?", siteID).Delete(&models.MediaReference{}).Error; err != nil {
+		return 0, err
+	}
+	for i := range siteBlocks {
+		if err := UpdateBlockReferences(db, &siteBlocks[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(siteBlocks), nil
+}
+
+// EnsureReferences builds the reference table on first start after upgrading,
+// when there are blocks but nothing has been recorded yet
+func EnsureReferences(db *gorm.DB) error {
+	var refs, blockCount int64
+	if err := db.Model(&models.MediaReference{}).Count(&refs).Error; err != nil {
+		return err
+	}
+	if refs > 0 {
+		return nil
+	}
+	if err := db.Model(&models.Block{}).Count(&blockCount).Error; err != nil || blockCount == 0 {
+		return err
+	}
+	var siteIDs []uint
+	if err := db.Model(&models.Site{}).Pluck("id", &siteIDs).Error; err != nil {
+		return err
+	}
+	for _, id := range siteIDs {
+		if _, err := RebuildReferences(db, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usageQuery selects the live blocks of a site that use files, with their pages
+func usageQuery(db *gorm.DB, siteID uint) *gorm.DB {
+	return db.Table("media_references").
+		Joins("JOIN blocks ON blocks.id = media_references.block_id AND blocks.deleted_at IS NULL").
+		Joins("JOIN pages ON pages.id = media_references.page_id AND pages.deleted_at IS NULL").
+		Where("media_references.site_id = ?", siteID)
+}
+
+// FindImageUsage finds all blocks that reference a specific image or document URL
+func FindImageUsage(db *gorm.DB, siteID uint, imageURL string) []UsageLocation {
+	var usages []UsageLocation
+	usageQuery(db, siteID).
+		Select("pages.id AS page_id, pages.title AS page_title, blocks.id AS block_id, blocks.type AS block_type").
+		Where("media_references.url = ?", normalizeMediaURL(imageURL)).
+		Order("pages.id, blocks.id").
+		Scan(&usages)
+	return usages
+}
+
+// UsageCounts returns how many blocks of a site use each file, by URL, so
+// the media library can show usage and find orphaned media in one query
+func UsageCounts(db *gorm.DB, siteID uint) map[string]int {
+	var rows []struct {
+		URL   string
+		Count int
+	}
+	usageQuery(db, siteID).
+		Select("media_references.url AS url, COUNT(*) AS count").
+		Group("media_references.url").
+		Scan(&rows)
+	counts := make(map[string]int, len(rows))
+	for _, r := range rows {
+		counts[r.URL] = r.Count
 	}
+	return counts
 }
@@ -17,7 +17,7 @@ func TestFindImageUsage(t *testing.T) {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.Site{}, &models.Page{}, &models.Block{}, &models.User{}); err != nil {
+	if err := db.AutoMigrate(&models.Site{}, &models.Page{}, &models.Block{}, &models.User{}, &models.MediaReference{}); err != nil {
 		t.Fatalf("Failed to migrate: %v", err)
 	}
 
@@ -49,6 +49,10 @@ func TestFindImageUsage(t *testing.T) {
 	}
 	db.Create(&textBlock)
 
+	if _, err := RebuildReferences(db, site.ID); err != nil {
+		t.Fatalf("RebuildReferences failed: %v", err)
+	}
+
 	// Test finding usage
 	usages := FindImageUsage(db, site.ID, "/uploads/test123.jpg")
 
@@ -101,6 +105,20 @@ func TestContainsImageURL(t *testing.T) {
 			imageURL:  "/uploads/cat.jpg",
 			expected:  false,
 		},
+		{
+			name:      "file block with matching URL",
+			blockType: "file",
+			blockData: `{"url":"/assets/waiver.pdf","title":"Waiver"}`,
+			imageURL:  "/assets/waiver.pdf",
+			expected:  true,
+		},
+		{
+			name:      "file block with different URL",
+			blockType: "file",
+			blockData: `{"url":"/assets/plans.pdf"}`,
+			imageURL:  "/assets/waiver.pdf",
+			expected:  false,
+		},
 		{
 			name:      "image block with invalid JSON",
 			blockType: "image",
@@ -130,7 +148,7 @@ func TestFindImageUsageExcludesSoftDeleted(t *testing.T) {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.Site{}, &models.Page{}, &models.Block{}, &models.User{}); err != nil {
+	if err := db.AutoMigrate(&models.Site{}, &models.Page{}, &models.Block{}, &models.User{}, &models.MediaReference{}); err != nil {
 		t.Fatalf("Failed to migrate: %v", err)
 	}
 
@@ -178,6 +196,10 @@ func TestFindImageUsageExcludesSoftDeleted(t *testing.T) {
 	db.Create(&deletedBlock)
 	db.Delete(&deletedBlock) // Soft delete
 
+	if _, err := RebuildReferences(db, site.ID); err != nil {
+		t.Fatalf("RebuildReferences failed: %v", err)
+	}
+
 	// Test - should only find the active page/block
 	usages := FindImageUsage(db, site.ID, "/uploads/shared.jpg")
 
@@ -198,7 +220,7 @@ func TestFindImageUsageMultiplePages(t *testing.T) {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.Site{}, &models.Page{}, &models.Block{}, &models.User{}); err != nil {
+	if err := db.AutoMigrate(&models.Site{}, &models.Page{}, &models.Block{}, &models.User{}, &models.MediaReference{}); err != nil {
 		t.Fatalf("Failed to migrate: %v", err)
 	}
 
@@ -222,6 +244,10 @@ func TestFindImageUsageMultiplePages(t *testing.T) {
 		db.Create(&block)
 	}
 
+	if _, err := RebuildReferences(db, site.ID); err != nil {
+		t.Fatalf("RebuildReferences failed: %v", err)
+	}
+
 	// Test - should find all 3 usages
 	usages := FindImageUsage(db, site.ID, "/uploads/reused.jpg")
 
@@ -241,3 +267,81 @@ func TestFindImageUsageMultiplePages(t *testing.T) {
 		}
 	}
 }
+
+func TestBlockMediaURLs(t *testing.T) {
+	tests := []struct {
+		name  string
+		block models.Block
+		want  []string
+	}{
+		{"image size copy", models.Block{Type: "image", Data: `{"url":"/assets/w800/cat.jpg"}`}, []string{"/assets/cat.jpg"}},
+		{"button to a document", models.Block{Type: "button", Data: `{"text":"Waiver","url":"/assets/waiver.pdf"}`}, []string{"/assets/waiver.pdf"}},
+		{"button elsewhere", models.Block{Type: "button", Data: `{"text":"Tickets","url":"https://example.com/tickets"}`}, nil},
+		{
+			"columns HTML",
+			models.Block{Type: "columns", Data: `{"column_count":2,"columns":[{"content":"<img src=\"/assets/a.jpg?w=400\" style=\"width: 100%\"><img src='/assets/a.jpg'>"},{"content":"<a href=\"https://camp.example.com/assets/plans.pdf\">Plans</a>"}]}`},
+			[]string{"/assets/a.jpg", "/assets/plans.pdf"},
+		},
+		{"text mentioning a file", models.Block{Type: "text", Data: `{"content":"See /assets/thumbs/map.png for the map"}`}, []string{"/assets/map.png"}},
+		{"form with no files", models.Block{Type: "form", Data: `{"title":"Sign Up","fields":[]}`}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BlockMediaURLs(tt.block)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUpdateBlockReferences(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Site{}, &models.Page{}, &models.Block{}, &models.User{}, &models.MediaReference{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	user := models.User{Email: "test@example.com", PasswordHash: "hash"}
+	db.Create(&user)
+	site := models.Site{Subdomain: "test", OwnerID: user.ID, SiteDir: "/tmp"}
+	db.Create(&site)
+	page := models.Page{SiteID: site.ID, Slug: "/", Title: "Home", Published: true}
+	db.Create(&page)
+
+	block := models.Block{PageID: page.ID, Type: "columns", Data: `{"columns":[{"content":"<img src=\"/assets/a.jpg\">"},{"content":"<img src=\"/assets/b.jpg\">"}]}`}
+	db.Create(&block)
+	if err := UpdateBlockReferences(db, &block); err != nil {
+		t.Fatalf("UpdateBlockReferences failed: %v", err)
+	}
+	counts := UsageCounts(db, site.ID)
+	if counts["/assets/a.jpg"] != 1 || counts["/assets/b.jpg"] != 1 {
+		t.Errorf("Expected both images to be used once, got %v", counts)
+	}
+
+	// Saving again replaces the block's references
+	block.Data = `{"columns":[{"content":"<img src=\"/assets/b.jpg\">"}]}`
+	db.Save(&block)
+	if err := UpdateBlockReferences(db, &block); err != nil {
+		t.Fatalf("UpdateBlockReferences failed: %v", err)
+	}
+	if usages := FindImageUsage(db, site.ID, "/assets/a.jpg"); len(usages) != 0 {
+		t.Errorf("Expected a.jpg to be unused after the edit, got %d usages", len(usages))
+	}
+	if usages := FindImageUsage(db, site.ID, "/assets/b.jpg"); len(usages) != 1 || usages[0].BlockType != "columns" {
+		t.Errorf("Expected b.jpg to be used by the columns block, got %+v", usages)
+	}
+
+	db.Delete(&block)
+	if err := DeleteBlockReferences(db, block.ID); err != nil {
+		t.Fatalf("DeleteBlockReferences failed: %v", err)
+	}
+	var refs int64
+	db.Model(&models.MediaReference{}).Count(&refs)
+	if refs != 0 {
+		t.Errorf("Expected no references after deleting the block, got %d", refs)
+	}
+}
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+)
+
+// Defaults used when media.zip.* isn't set
+const (
+	defaultZipMaxFiles = 500
+	defaultZipMaxSize  = "1GB"
+)
+
+// zipImageTypes are the extensions of archive entries read as images. Their
+// content is checked when they're prepared like any upload.
+var zipImageTypes = map[string]bool{"jpg": true, "jpeg": true, "png": true, "gif": true, "webp": true}
+
+// ErrZipTooLarge is returned for archives holding more than an import allows
+var ErrZipTooLarge = errors.New("zip archive too large")
+
+// ZipLimits bounds what a zip import reads, so an archive can't fill the
+// server's memory or disk however it's built
+type ZipLimits struct {
+	MaxFiles     int   // Images per archive
+	MaxFileSize  int64 // Bytes per image, uncompressed
+	MaxTotalSize int64 // Bytes of images per archive, uncompressed
+}
+
+// ZipLimitsFor returns the limits from media.zip.max_files and
+// media.zip.max_size, with images up to maxFileSize each
+func ZipLimitsFor(maxFileSize int64) ZipLimits {
+	limits := ZipLimits{
+		MaxFiles:    config.GetInt("media.zip.max_files"),
+		MaxFileSize: maxFileSize,
+	}
+	if limits.MaxFiles <= 0 {
+		limits.MaxFiles = defaultZipMaxFiles
+	}
+	raw := config.GetString("media.zip.max_size")
+	if raw == "" {
+		raw = defaultZipMaxSize
+	}
+	total, err := ParseSize(raw)
+	if err != nil || total <= 0 {
+		total, _ = ParseSize(defaultZipMaxSize)
+	}
+	limits.MaxTotalSize = total
+	return limits
+}
+
+// ZipSkip is an archive entry that wasn't read, and why
+type ZipSkip struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// safeZipName reports whether an entry's name stays inside the archive.
+// Files are stored under random names, never their archive path, but entries
+// trying to climb out (zip slip) mark an archive built to do harm.
+func safeZipName(name string) bool {
+	if name == "" || strings.ContainsAny(name, "\\\x00:") || path.IsAbs(name) {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadZipImages calls fn with the file name and content of each image in a zip
+// archive, in archive order. Folders, macOS resource forks and hidden files
+// are passed over; other files, oversized images and entries with unsafe
+// paths are returned as skipped. Archives with more images than limits allow
+// are refused before anything is read. Reading stops at the first error from
+// fn, which is returned.
+func ReadZipImages(r io.ReaderAt, size int64, limits ZipLimits, fn func(name string, data []byte) error) ([]ZipSkip, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not a valid zip archive: %w", err)
+	}
+
+	var skipped []ZipSkip
+	var images []*zip.File
+	var declared uint64
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") || strings.HasPrefix(path.Base(f.Name), ".") {
+			continue
+		}
+		switch {
+		case !safeZipName(f.Name):
+			skipped = append(skipped, ZipSkip{f.Name, "unsafe path"})
+		case !zipImageTypes[extension(f.Name)]:
+			skipped = append(skipped, ZipSkip{f.Name, "not an image"})
+		case f.UncompressedSize64 > uint64(limits.MaxFileSize):
+			skipped = append(skipped, ZipSkip{f.Name, fmt.Sprintf("larger than %s", FormatSize(limits.MaxFileSize))})
+		default:
+			images = append(images, f)
+			declared += f.UncompressedSize64
+		}
+	}
+	if len(images) > limits.MaxFiles {
+		return skipped, fmt.Errorf("%w: %d images, the limit is %d", ErrZipTooLarge, len(images), limits.MaxFiles)
+	}
+	if declared > uint64(limits.MaxTotalSize) {
+		return skipped, fmt.Errorf("%w: %s of images, the limit is %s", ErrZipTooLarge, FormatSize(int64(declared)), FormatSize(limits.MaxTotalSize))
+	}
+
+	// The sizes above come from the archive, which may lie, so what's
+	// actually read is limited too
+	var total int64
+	for _, f := range images {
+		data, err := readZipEntry(f, limits.MaxFileSize)
+		if err != nil {
+			skipped = append(skipped, ZipSkip{f.Name, err.Error()})
+			continue
+		}
+		total += int64(len(data))
+		if total > limits.MaxTotalSize {
+			return skipped, fmt.Errorf("%w: more than %s of images", ErrZipTooLarge, FormatSize(limits.MaxTotalSize))
+		}
+		if err := fn(path.Base(f.Name), data); err != nil {
+			return skipped, err
+		}
+	}
+	return skipped, nil
+}
+
+// readZipEntry reads an entry of at most max bytes
+func readZipEntry(f *zip.File, max int64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("can't be read: %w", err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, max+1))
+	if err != nil {
+		return nil, fmt.Errorf("can't be read: %w", err)
+	}
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("larger than %s", FormatSize(max))
+	}
+	return data, nil
+}
@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: MIT
+package media
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// buildZip makes an archive of the given entries; names ending in / are folders
+func buildZip(t *testing.T, entries map[string][]byte) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, data := range entries {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		f.Write(data)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to write zip: %v", err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestReadZipImages(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	archive := buildZip(t, map[string][]byte{
+		"photos/":             nil,
+		"photos/sunrise.png":  png,
+		"photos/dome.JPG":     []byte("\xff\xd8\xff"),
+		"../../etc/evil.png":  png,
+		"notes.txt":           []byte("hello"),
+		"__MACOSX/._dome.JPG": []byte("fork"),
+		"photos/.DS_Store":    []byte("junk"),
+		"photos/huge.png":     bytes.Repeat([]byte{1}, 2000),
+	})
+	limits := ZipLimits{MaxFiles: 10, MaxFileSize: 1000, MaxTotalSize: 1 << 20}
+
+	var read []string
+	skipped, err := ReadZipImages(archive, archive.Size(), limits, func(name string, data []byte) error {
+		read = append(read, name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadZipImages failed: %v", err)
+	}
+	if strings.Join(read, ",") != "dome.JPG,sunrise.png" && strings.Join(read, ",") != "sunrise.png,dome.JPG" {
+		t.Errorf("Expected the two images by base name, got %v", read)
+	}
+
+	reasons := map[string]string{}
+	for _, s := range skipped {
+		reasons[s.Name] = s.Reason
+	}
+	if len(reasons) != 3 {
+		t.Errorf("Expected 3 skipped entries, got %+v", skipped)
+	}
+	if reasons["../../etc/evil.png"] != "unsafe path" {
+		t.Errorf("Expected the zip slip entry to be skipped as unsafe, got %q", reasons["../../etc/evil.png"])
+	}
+	if reasons["notes.txt"] != "not an image" {
+		t.Errorf("Expected notes.txt to be skipped as not an image, got %q", reasons["notes.txt"])
+	}
+	if !strings.HasPrefix(reasons["photos/huge.png"], "larger than") {
+		t.Errorf("Expected huge.png to be skipped as too large, got %q", reasons["photos/huge.png"])
+	}
+}
+
+func TestReadZipImagesLimits(t *testing.T) {
+	entries := map[string][]byte{}
+	for _, name := range []string{"a.png", "b.png", "c.png"} {
+		entries[name] = bytes.Repeat([]byte{1}, 100)
+	}
+	archive := buildZip(t, entries)
+	none := func(string, []byte) error {
+		t.Error("Expected nothing to be read from a refused archive")
+		return nil
+	}
+
+	_, err := ReadZipImages(archive, archive.Size(), ZipLimits{MaxFiles: 2, MaxFileSize: 1000, MaxTotalSize: 1 << 20}, none)
+	if !errors.Is(err, ErrZipTooLarge) {
+		t.Errorf("Expected too many images to be refused, got %v", err)
+	}
+	_, err = ReadZipImages(archive, archive.Size(), ZipLimits{MaxFiles: 10, MaxFileSize: 1000, MaxTotalSize: 250}, none)
+	if !errors.Is(err, ErrZipTooLarge) {
+		t.Errorf("Expected too many bytes to be refused, got %v", err)
+	}
+
+	// An error from the callback stops reading
+	stop := errors.New("stop")
+	calls := 0
+	_, err = ReadZipImages(archive, archive.Size(), ZipLimits{MaxFiles: 10, MaxFileSize: 1000, MaxTotalSize: 1 << 20}, func(string, []byte) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Expected reading to stop after the first error, got %v after %d call(s)", err, calls)
+	}
+
+	if _, err := ReadZipImages(bytes.NewReader([]byte("not a zip")), 9, ZipLimits{}, none); err == nil {
+		t.Error("Expected an error for data that isn't a zip archive")
+	}
+}
+
+func TestReadZipImagesLyingSize(t *testing.T) {
+	// An entry that claims to be small but isn't
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	data := bytes.Repeat([]byte{1}, 5000)
+	f, err := w.CreateRaw(&zip.FileHeader{Name: "bomb.png", Method: zip.Store, CompressedSize64: uint64(len(data)), UncompressedSize64: 10})
+	if err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	f.Write(data)
+	w.Close()
+	archive := bytes.NewReader(buf.Bytes())
+
+	skipped, err := ReadZipImages(archive, archive.Size(), ZipLimits{MaxFiles: 10, MaxFileSize: 1000, MaxTotalSize: 1 << 20}, func(name string, data []byte) error {
+		t.Errorf("Expected %s not to be read, got %d bytes", name, len(data))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadZipImages failed: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0].Name != "bomb.png" {
+		t.Errorf("Expected bomb.png to be skipped, got %+v", skipped)
+	}
+}
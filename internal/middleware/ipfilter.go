@@ -49,37 +49,47 @@ func IPFilterMiddleware(globalBlocklist []string) gin.HandlerFunc {
 		site := siteVal.(*models.Site)
 
 		// If site has allowlist, enforce it
-		if site.AllowedIPs != "" {
-			var allowedRanges []string
-			if err := json.Unmarshal([]byte(site.AllowedIPs), &allowedRanges); err != nil {
-				// Invalid JSON, block access
-				c.AbortWithStatus(403)
-				return
-			}
-
-			// Check if client IP is in allowlist
-			allowed := false
-			for _, cidr := range allowedRanges {
-				_, ipNet, err := net.ParseCIDR(cidr)
-				if err != nil {
-					continue
-				}
-				if ipNet.Contains(clientIP) {
-					allowed = true
-					break
-				}
-			}
-
-			if !allowed {
-				c.AbortWithStatus(403)
-				return
-			}
+		if !siteAllowsIP(site, clientIP) {
+			c.AbortWithStatus(403)
+			return
 		}
 
 		c.Next()
 	}
 }
 
+// SiteAllowsClient reports whether the request comes from the site's IP
+// allowlist. Sites without an allowlist allow everyone.
+func SiteAllowsClient(c *gin.Context, site *models.Site) bool {
+	clientIP := extractIP(c)
+	return clientIP != nil && siteAllowsIP(site, clientIP)
+}
+
+// siteAllowsIP checks an address against a site's allowlist
+func siteAllowsIP(site *models.Site, clientIP net.IP) bool {
+	if site.AllowedIPs == "" {
+		return true
+	}
+
+	var allowedRanges []string
+	if err := json.Unmarshal([]byte(site.AllowedIPs), &allowedRanges); err != nil {
+		// Invalid JSON, block access
+		return false
+	}
+
+	// Check if client IP is in allowlist
+	for _, cidr := range allowedRanges {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if ipNet.Contains(clientIP) {
+			return true
+		}
+	}
+	return false
+}
+
 // extractIP extracts the client IP from the request
 // Handles X-Forwarded-For header if behind proxy
 func extractIP(c *gin.Context) net.IP {
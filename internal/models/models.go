@@ -28,31 +28,35 @@ type User struct {
 
 // Site represents a camp website
 type Site struct {
-	ID             uint    `gorm:"primaryKey"`
-	Subdomain      string  `gorm:"uniqueIndex"`
-	CustomDomain   *string `gorm:"uniqueIndex"`
-	OwnerID        uint    `gorm:"not null"`
-	SiteDir        string  `gorm:"not null"`       // Directory path for this site
-	DatabaseType   string  `gorm:"default:sqlite"` // "sqlite" or "mariadb"
-	DatabasePath   string  // For SQLite
-	DatabaseHost   string  // For MariaDB
-	DatabaseName   string  // For MariaDB
-	StorageType    string  `gorm:"default:local"` // "local" or "s3"
-	S3Bucket       string  // For S3 storage
-	PrimaryColor   string  `gorm:"default:#2563eb"`
-	SecondaryColor string  `gorm:"default:#64748b"`
-	SiteTitle      string
-	SiteTagline    string
-	LogoPath       string
+	ID                uint    `gorm:"primaryKey"`
+	Subdomain         string  `gorm:"uniqueIndex"`
+	CustomDomain      *string `gorm:"uniqueIndex"`
+	OwnerID           uint    `gorm:"not null"`
+	SiteDir           string  `gorm:"not null"`       // Directory path for this site
+	DatabaseType      string  `gorm:"default:sqlite"` // "sqlite" or "mariadb"
+	DatabasePath      string  // For SQLite
+	DatabaseHost      string  // For MariaDB
+	DatabaseName      string  // For MariaDB
+	StorageType       string  `gorm:"default:local"` // "local" or "s3"
+	S3Bucket          string  // For S3 storage
+	PrimaryColor      string  `gorm:"default:#2563eb"`
+	SecondaryColor    string  `gorm:"default:#64748b"`
+	SiteTitle         string
+	SiteTagline       string
+	LogoPath          string
 	FontPair          string `gorm:"default:system"`
-	ThemePalette      string `gorm:"default:slate"` // Theme color palette name
-	DarkMode          bool   `gorm:"default:false"` // Enable dark mode
-	AllowedIPs        string `gorm:"type:text"`     // JSON array of CIDR ranges
+	ThemePalette      string `gorm:"default:slate"`           // Theme color palette name
+	DarkMode          bool   `gorm:"default:false"`           // Enable dark mode
+	AllowedIPs        string `gorm:"type:text"`               // JSON array of CIDR ranges
+	Visibility        string `gorm:"not null;default:public"` // "public", "password", "members" or "ip" (see the visibility package)
+	PasswordHash      string `json:"-"`                       // bcrypt hash of the site password
 	GoogleAnalyticsID string // GA tracking ID (G-XXXXXXXXXX or UA-XXXXXXXXX)
 	CopyrightText     string // Custom footer copyright text
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	StorageQuota      *int64 // Bytes of media allowed, overriding storage.quota; 0 is unlimited
+	QuotaAlertLevel   int    // Highest storage alert emailed to the owner (80 or 100), lowered as usage drops
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         gorm.DeletedAt `gorm:"index"`
 
 	// Relationships
 	Owner     User       `gorm:"foreignKey:OwnerID"`
@@ -80,9 +84,21 @@ type SiteUser struct {
 type Page struct {
 	ID        uint   `gorm:"primaryKey"`
 	SiteID    uint   `gorm:"not null;index:idx_site_slug,unique"`
-	Slug      string `gorm:"not null;index:idx_site_slug,unique"` // "/" for homepage, "/about", etc
+	Slug      string `gorm:"not null;index:idx_site_slug,unique"` // "/" for homepage, "/about", "/info/arrival", etc
 	Title     string `gorm:"not null"`
+	ParentID  *uint  `gorm:"index"` // Parent page; the slug is the parent's slug plus one segment
 	Published bool   `gorm:"default:false"`
+
+	// Search engine and social sharing metadata; blank fields fall back to site defaults
+	MetaDescription string `gorm:"type:text"`
+	SocialImage     string // URL from the media library
+	NoIndex         bool   `gorm:"default:false"` // Ask search engines not to index, and leave out of the sitemap
+	CanonicalURL    string // Overrides the page's own address as the canonical URL
+
+	// Who may see the page: "public", "password" or "members" (see the visibility package)
+	Visibility   string `gorm:"not null;default:public"`
+	PasswordHash string `json:"-"` // bcrypt hash of the page password
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
@@ -107,14 +123,22 @@ type Block struct {
 
 // MediaItem represents an uploaded image in the media library
 type MediaItem struct {
-	ID                 uint   `gorm:"primaryKey"`
-	SiteID             uint   `gorm:"not null;index"`
-	Filename           string `gorm:"not null"`        // Random hex filename
-	OriginalName       string `gorm:"not null"`        // User's original filename
-	FileSize           int64  `gorm:"not null"`        // Bytes
-	MimeType           string `gorm:"not null"`        // image/jpeg, etc.
-	UploadedBy         uint   `gorm:"not null"`        // User ID
-	UploadedFromSiteID *uint  `gorm:"index"`           // Track which site it was uploaded from
+	ID                 uint       `gorm:"primaryKey"`
+	SiteID             uint       `gorm:"not null;index"`
+	Filename           string     `gorm:"not null;index"` // Random hex filename
+	OriginalName       string     `gorm:"not null"`       // User's original filename
+	FileSize           int64      `gorm:"not null"`       // Bytes
+	MimeType           string     `gorm:"not null"`       // image/jpeg, etc.
+	UploadedBy         uint       `gorm:"not null"`       // User ID
+	UploadedFromSiteID *uint      `gorm:"index"`          // Track which site it was uploaded from
+	Bucket             string     // S3 bucket holding the file, empty for the local media directory
+	Width              int        // Pixels, 0 until measured
+	Height             int        // Pixels, 0 until measured
+	Derivatives        string     // Comma-separated widths of the smaller copies, e.g. "400,800"
+	DerivativesSize    int64      // Bytes used by the smaller copies and thumbnail
+	TakenAt            *time.Time // When the photo was taken, from its EXIF data
+	ContentHash        string     `gorm:"index"` // SHA-256 of the stored file, hex; empty until computed
+	CollectionID       *uint      `gorm:"index"` // Collection it's filed in, nil for none
 	CreatedAt          time.Time
 	UpdatedAt          time.Time
 	DeletedAt          gorm.DeletedAt `gorm:"index"`
@@ -137,6 +161,33 @@ type MediaTag struct {
 	MediaItem MediaItem `gorm:"foreignKey:MediaItemID"`
 }
 
+// MediaCollection is a folder for organizing a site's media, which may sit
+// inside another collection
+type MediaCollection struct {
+	ID        uint   `gorm:"primaryKey"`
+	SiteID    uint   `gorm:"not null;index"`
+	ParentID  *uint  `gorm:"index"` // nil for top-level collections
+	Name      string `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	// Relationships
+	Site Site `gorm:"foreignKey:SiteID"`
+}
+
+// MediaReference records that a block uses an uploaded file, so finding where
+// media is used doesn't mean parsing every block. Rows are replaced whenever
+// the block is saved or deleted.
+type MediaReference struct {
+	ID        uint   `gorm:"primaryKey"`
+	SiteID    uint   `gorm:"not null;index:idx_media_ref_url"`
+	PageID    uint   `gorm:"not null;index"`
+	BlockID   uint   `gorm:"not null;index"`
+	URL       string `gorm:"not null;index:idx_media_ref_url"` // e.g. /assets/3f2a.jpg, without size folders or query
+	CreatedAt time.Time
+}
+
 // MenuItem represents a navigation menu item
 type MenuItem struct {
 	ID        uint   `gorm:"primaryKey"`
@@ -151,6 +202,202 @@ type MenuItem struct {
 	Site Site `gorm:"foreignKey:SiteID"`
 }
 
+// FormSubmission stores a visitor's response to a form block
+type FormSubmission struct {
+	ID        uint   `gorm:"primaryKey"`
+	SiteID    uint   `gorm:"not null;index"`
+	PageID    uint   `gorm:"not null;index"`
+	BlockID   uint   `gorm:"not null;index"`
+	Data      string `gorm:"type:text"` // JSON object of field name -> submitted value
+	IPAddress string
+	CreatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Site Site `gorm:"foreignKey:SiteID"`
+}
+
+// ContactSubmission stores a message sent through a site's contact form
+type ContactSubmission struct {
+	ID        uint   `gorm:"primaryKey"`
+	SiteID    uint   `gorm:"not null;index"`
+	Name      string `gorm:"not null"`
+	Email     string `gorm:"not null"`
+	Subject   string `gorm:"not null"`
+	Message   string `gorm:"type:text"`
+	IsRead    bool   `gorm:"default:false;index"`
+	IPAddress string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Site Site `gorm:"foreignKey:SiteID"`
+}
+
+// OutboundEmail is a queued email waiting to be delivered (or retried) by the email queue
+type OutboundEmail struct {
+	ID            uint      `gorm:"primaryKey"`
+	To            string    `gorm:"not null"`
+	Subject       string    `gorm:"not null"`
+	Body          string    `gorm:"type:text"`
+	Status        string    `gorm:"not null;default:pending;index"` // "pending", "sent", "failed"
+	Attempts      int       `gorm:"not null;default:0"`
+	LastError     string    `gorm:"type:text"`
+	NextAttemptAt time.Time `gorm:"index"`
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// SpamCounter tallies rejected public form submissions per site, day and reason
+type SpamCounter struct {
+	ID     uint   `gorm:"primaryKey"`
+	SiteID uint   `gorm:"not null;uniqueIndex:idx_spam_site_day_reason"`
+	Day    string `gorm:"size:10;not null;uniqueIndex:idx_spam_site_day_reason"` // YYYY-MM-DD
+	Reason string `gorm:"size:32;not null;uniqueIndex:idx_spam_site_day_reason"`
+	Hits   int    `gorm:"not null;default:0"`
+}
+
+// Event represents a calendar event, optionally repeating
+type Event struct {
+	ID          uint   `gorm:"primaryKey"`
+	SiteID      uint   `gorm:"not null;index"`
+	Title       string `gorm:"not null"`
+	Description string `gorm:"type:text"`
+	Location    string
+	StartsAt    time.Time `gorm:"not null;index"`
+	EndsAt      time.Time `gorm:"not null"`
+	AllDay      bool      `gorm:"default:false"`
+	Recurrence  string    `gorm:"size:16"` // "", "daily", "weekly", "monthly", "yearly"
+	RecurUntil  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+
+	Site Site `gorm:"foreignKey:SiteID"`
+}
+
+// Shift is a volunteer shift visitors can sign up for, e.g. kitchen or gate duty
+type Shift struct {
+	ID          uint      `gorm:"primaryKey"`
+	SiteID      uint      `gorm:"not null;index"`
+	Role        string    `gorm:"not null"`
+	Description string    `gorm:"type:text"`
+	StartsAt    time.Time `gorm:"not null;index"`
+	EndsAt      time.Time `gorm:"not null"`
+	Capacity    int       `gorm:"not null;default:1"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+
+	Site    Site          `gorm:"foreignKey:SiteID"`
+	Signups []ShiftSignup `gorm:"foreignKey:ShiftID"`
+}
+
+// ShiftSignup is one volunteer's place on a shift
+type ShiftSignup struct {
+	ID             uint   `gorm:"primaryKey"`
+	SiteID         uint   `gorm:"not null;index"`
+	ShiftID        uint   `gorm:"not null;index"`
+	Name           string `gorm:"not null"`
+	Email          string `gorm:"not null"`
+	IPAddress      string
+	ReminderSentAt *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+
+	Shift Shift `gorm:"foreignKey:ShiftID"`
+}
+
+// Post is a dated blog/news post. Posts with a future PublishedAt are
+// scheduled and stay hidden until then.
+type Post struct {
+	ID          uint   `gorm:"primaryKey"`
+	SiteID      uint   `gorm:"not null;index:idx_post_site_slug,unique"`
+	Slug        string `gorm:"not null;index:idx_post_site_slug,unique"`
+	Title       string `gorm:"not null"`
+	Author      string
+	Excerpt     string    `gorm:"type:text"`
+	Content     string    `gorm:"type:text"` // HTML, sanitized when rendered
+	CoverImage  string    // URL from the media library
+	Published   bool      `gorm:"default:false"`
+	PublishedAt time.Time `gorm:"index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+
+	Site Site      `gorm:"foreignKey:SiteID"`
+	Tags []PostTag `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"`
+}
+
+// PostTag represents a tag on a post
+type PostTag struct {
+	ID      uint   `gorm:"primaryKey"`
+	PostID  uint   `gorm:"not null;index:idx_post_tag"`
+	SiteID  uint   `gorm:"not null;index:idx_post_tag_site"`
+	TagName string `gorm:"not null;index:idx_post_tag;index:idx_post_tag_site"`
+
+	Post Post `gorm:"foreignKey:PostID"`
+}
+
+// Redirect sends requests for an old path on a site to a new address.
+// Redirects are only consulted when no published page matches the path.
+type Redirect struct {
+	ID         uint   `gorm:"primaryKey"`
+	SiteID     uint   `gorm:"not null;index:idx_redirect_site_source,unique"`
+	SourcePath string `gorm:"not null;index:idx_redirect_site_source,unique"` // e.g. "/old-page"
+	Target     string `gorm:"not null"`                                       // Site path or absolute URL
+	StatusCode int    `gorm:"not null;default:301"`                           // 301 or 302
+	Hits       int64  `gorm:"not null;default:0"`
+	LastHitAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+
+	Site Site `gorm:"foreignKey:SiteID"`
+}
+
+// Archive is a frozen, read-only copy of a site's published pages, served
+// under /archive/<year>/ so last year's site survives this year's edits
+type Archive struct {
+	ID        uint   `gorm:"primaryKey"`
+	SiteID    uint   `gorm:"not null;index:idx_archive_site_year,unique"`
+	Year      int    `gorm:"not null;index:idx_archive_site_year,unique"`
+	Title     string `gorm:"not null"` // e.g. "Burning Man 2025"
+	CreatedAt time.Time
+
+	Site  Site          `gorm:"foreignKey:SiteID"`
+	Pages []ArchivePage `gorm:"foreignKey:ArchiveID;constraint:OnDelete:CASCADE"`
+}
+
+// ArchivePage is one page of an archive, stored as rendered HTML. Text holds
+// the page's content for archive search; archives aren't in the live FTS index.
+type ArchivePage struct {
+	ID        uint   `gorm:"primaryKey"`
+	ArchiveID uint   `gorm:"not null;index:idx_archive_page_slug,unique"`
+	Slug      string `gorm:"not null;index:idx_archive_page_slug,unique"` // The page's slug when archived
+	Title     string `gorm:"not null"`
+	HTML      string `gorm:"type:text;not null"`
+	Text      string `gorm:"type:text"`
+}
+
+// Announcement is a site-wide banner shown above every public page while it's
+// scheduled, for urgent notices like gate closures during an event
+type Announcement struct {
+	ID          uint       `gorm:"primaryKey"`
+	SiteID      uint       `gorm:"not null;index"`
+	Severity    string     `gorm:"not null;default:info"` // "info", "warning" or "critical"
+	Message     string     `gorm:"type:text;not null"`
+	LinkURL     string     // Optional site path or http(s) URL
+	LinkText    string     // Label for the link, "Learn more" when empty
+	StartsAt    *time.Time // Shown from this time, or right away when nil
+	EndsAt      *time.Time // Hidden from this time, or until removed when nil
+	Dismissible bool       `gorm:"not null;default:false"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	Site Site `gorm:"foreignKey:SiteID"`
+}
+
 // TableName overrides for consistent naming
 func (User) TableName() string {
 	return "users"
@@ -180,10 +427,70 @@ func (MediaItem) TableName() string {
 	return "media_items"
 }
 
+func (MediaReference) TableName() string {
+	return "media_references"
+}
+
 func (MediaTag) TableName() string {
 	return "media_tags"
 }
 
+func (FormSubmission) TableName() string {
+	return "form_submissions"
+}
+
+func (ContactSubmission) TableName() string {
+	return "contact_submissions"
+}
+
+func (OutboundEmail) TableName() string {
+	return "outbound_emails"
+}
+
+func (SpamCounter) TableName() string {
+	return "spam_counters"
+}
+
+func (Event) TableName() string {
+	return "events"
+}
+
+func (Shift) TableName() string {
+	return "shifts"
+}
+
+func (ShiftSignup) TableName() string {
+	return "shift_signups"
+}
+
+func (Post) TableName() string {
+	return "posts"
+}
+
+func (PostTag) TableName() string {
+	return "post_tags"
+}
+
+// GetValues returns the submitted field values keyed by field name
+func (f *FormSubmission) GetValues() map[string]string {
+	values := map[string]string{}
+	if f.Data == "" {
+		return values
+	}
+	_ = json.Unmarshal([]byte(f.Data), &values)
+	return values
+}
+
+// SetValues stores the submitted field values
+func (f *FormSubmission) SetValues(values map[string]string) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	f.Data = string(data)
+	return nil
+}
+
 // GetAllowedIPs returns the list of allowed IP ranges for this site
 func (s *Site) GetAllowedIPs() ([]string, error) {
 	if s.AllowedIPs == "" {
@@ -260,3 +567,19 @@ func (s *Site) RemoveAllowedIP(cidr string) error {
 
 	return s.SetAllowedIPs(newIPs)
 }
+
+func (Redirect) TableName() string {
+	return "redirects"
+}
+
+func (Archive) TableName() string {
+	return "archives"
+}
+
+func (ArchivePage) TableName() string {
+	return "archive_pages"
+}
+
+func (Announcement) TableName() string {
+	return "announcements"
+}
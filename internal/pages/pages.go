@@ -0,0 +1,290 @@
+// SPDX-License-Identifier: MIT
+
+// Package pages manages the page hierarchy: parent/child slugs, moving pages
+// between parents, and walking the tree for navigation.
+package pages
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+)
+
+// HomepageSlug is the slug of a site's homepage, which can't have a parent or children
+const HomepageSlug = "/"
+
+// maxDepth bounds how far up the tree Ancestors walks, guarding against bad data
+const maxDepth = 32
+
+var (
+	// ErrInvalidSegment is returned for a slug segment that isn't a single URL path part
+	ErrInvalidSegment = errors.New("slug may only contain letters, numbers, dots, dashes and underscores")
+	// ErrSlugTaken is returned when a move would give a page the same slug as another
+	ErrSlugTaken = errors.New("another page already uses this address")
+	// ErrCycle is returned when a page would become its own ancestor
+	ErrCycle = errors.New("a page can't be moved under itself or one of its subpages")
+	// ErrInvalidParent is returned when the parent is the homepage or on another site
+	ErrInvalidParent = errors.New("invalid parent page")
+)
+
+var segmentPattern = regexp.MustCompile(`^[A-Za-z0-9._~-]+$`)
+
+// CleanSegment trims slashes and spaces from a slug segment and validates it
+func CleanSegment(s string) (string, error) {
+	s = strings.Trim(strings.TrimSpace(s), "/")
+	if !segmentPattern.MatchString(s) || s == "." || s == ".." {
+		return "", ErrInvalidSegment
+	}
+	return s, nil
+}
+
+// Segment returns the last part of a slug, e.g. "/info/arrival" -> "arrival"
+func Segment(slug string) string {
+	return path.Base(slug)
+}
+
+// JoinSlug builds a child's slug from its parent's slug and its own segment.
+// A nil parent makes a top-level page.
+func JoinSlug(parent *models.Page, segment string) string {
+	if parent == nil || parent.Slug == HomepageSlug {
+		return "/" + segment
+	}
+	return strings.TrimSuffix(parent.Slug, "/") + "/" + segment
+}
+
+// Ancestors returns a page's parents, outermost first
+func Ancestors(db *gorm.DB, page *models.Page) ([]models.Page, error) {
+	var chain []models.Page
+	seen := map[uint]bool{page.ID: true}
+	parentID := page.ParentID
+	for parentID != nil && len(chain) < maxDepth {
+		if seen[*parentID] {
+			break
+		}
+		var parent models.Page
+		if err := db.Where("id = ? AND site_id = ?", *parentID, page.SiteID).First(&parent).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				break
+			}
+			return nil, err
+		}
+		seen[parent.ID] = true
+		chain = append(chain, parent)
+		parentID = parent.ParentID
+	}
+	// Reverse so the root comes first
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+// Children returns a page's direct subpages ordered by title, optionally only published ones
+func Children(db *gorm.DB, siteID, parentID uint, publishedOnly bool) ([]models.Page, error) {
+	q := db.Where("site_id = ? AND parent_id = ?", siteID, parentID)
+	if publishedOnly {
+		q = q.Where("published = ?", true)
+	}
+	var children []models.Page
+	err := q.Order("title ASC").Find(&children).Error
+	return children, err
+}
+
+// Node is a page placed in the tree, for indented listings
+type Node struct {
+	Page  models.Page
+	Depth int
+}
+
+// Tree arranges pages depth-first, each parent followed by its children. The
+// homepage comes first; siblings are ordered by slug. Pages whose parent is
+// missing are treated as top-level.
+func Tree(list []models.Page) []Node {
+	byID := map[uint]bool{}
+	for _, p := range list {
+		byID[p.ID] = true
+	}
+	children := map[uint][]models.Page{}
+	var roots []models.Page
+	for _, p := range list {
+		if p.ParentID != nil && byID[*p.ParentID] && *p.ParentID != p.ID {
+			children[*p.ParentID] = append(children[*p.ParentID], p)
+		} else {
+			roots = append(roots, p)
+		}
+	}
+
+	less := func(s []models.Page) func(i, j int) bool {
+		return func(i, j int) bool {
+			if (s[i].Slug == HomepageSlug) != (s[j].Slug == HomepageSlug) {
+				return s[i].Slug == HomepageSlug
+			}
+			return s[i].Slug < s[j].Slug
+		}
+	}
+
+	var out []Node
+	visited := map[uint]bool{}
+	var walk func(s []models.Page, depth int)
+	walk = func(s []models.Page, depth int) {
+		sort.SliceStable(s, less(s))
+		for _, p := range s {
+			if visited[p.ID] {
+				continue
+			}
+			visited[p.ID] = true
+			out = append(out, Node{Page: p, Depth: depth})
+			walk(children[p.ID], depth+1)
+		}
+	}
+	walk(roots, 0)
+
+	// Anything left over is part of a parent cycle; list it at the top level
+	for _, p := range list {
+		if !visited[p.ID] {
+			visited[p.ID] = true
+			out = append(out, Node{Page: p})
+		}
+	}
+	return out
+}
+
+// descendants returns every page below a page, parents before their children
+func descendants(db *gorm.DB, page *models.Page) ([]models.Page, error) {
+	var out []models.Page
+	queue := []uint{page.ID}
+	seen := map[uint]bool{page.ID: true}
+	for len(queue) > 0 {
+		var batch []models.Page
+		if err := db.Where("site_id = ? AND parent_id IN ?", page.SiteID, queue).Order("id ASC").Find(&batch).Error; err != nil {
+			return nil, err
+		}
+		queue = nil
+		for _, p := range batch {
+			if seen[p.ID] {
+				continue
+			}
+			seen[p.ID] = true
+			out = append(out, p)
+			queue = append(queue, p.ID)
+		}
+	}
+	return out, nil
+}
+
+// IsDescendant reports whether candidateID is page itself or somewhere below it
+func IsDescendant(db *gorm.DB, page *models.Page, candidateID uint) (bool, error) {
+	if candidateID == page.ID {
+		return true, nil
+	}
+	below, err := descendants(db, page)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range below {
+		if p.ID == candidateID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SlugChange records a page whose address changed during a move
+type SlugChange struct {
+	PageID  uint
+	OldSlug string
+	NewSlug string
+}
+
+// Move gives a page a new parent (nil for top level) and slug segment, and
+// rewrites the slugs of all its subpages to match. It returns every slug that
+// changed so callers can update links to the old addresses.
+func Move(db *gorm.DB, page *models.Page, parentID *uint, segment string) ([]SlugChange, error) {
+	if page.Slug == HomepageSlug {
+		return nil, ErrInvalidParent
+	}
+	segment, err := CleanSegment(segment)
+	if err != nil {
+		return nil, err
+	}
+
+	var parent *models.Page
+	if parentID != nil {
+		var p models.Page
+		if err := db.Where("id = ? AND site_id = ?", *parentID, page.SiteID).First(&p).Error; err != nil {
+			return nil, ErrInvalidParent
+		}
+		if p.Slug == HomepageSlug {
+			return nil, ErrInvalidParent
+		}
+		if below, err := IsDescendant(db, page, p.ID); err != nil {
+			return nil, err
+		} else if below {
+			return nil, ErrCycle
+		}
+		parent = &p
+	}
+
+	below, err := descendants(db, page)
+	if err != nil {
+		return nil, err
+	}
+
+	// Work out every new slug before touching the database
+	newSlugs := map[uint]string{page.ID: JoinSlug(parent, segment)}
+	for _, p := range below {
+		newSlugs[p.ID] = newSlugs[*p.ParentID] + "/" + Segment(p.Slug)
+	}
+
+	moving := []uint{page.ID}
+	for _, p := range below {
+		moving = append(moving, p.ID)
+	}
+	for _, slug := range newSlugs {
+		// Soft-deleted pages still hold their slug in the unique index
+		var count int64
+		if err := db.Unscoped().Model(&models.Page{}).
+			Where("site_id = ? AND slug = ? AND id NOT IN ?", page.SiteID, slug, moving).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, ErrSlugTaken
+		}
+	}
+
+	// Parents are updated before their children. Without a cycle a new slug can
+	// never equal the old slug of another page in the subtree, so this order is safe.
+	var changes []SlugChange
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Page{}).Where("id = ?", page.ID).
+			Updates(map[string]interface{}{"parent_id": parentID, "slug": newSlugs[page.ID]}).Error; err != nil {
+			return err
+		}
+		if page.Slug != newSlugs[page.ID] {
+			changes = append(changes, SlugChange{PageID: page.ID, OldSlug: page.Slug, NewSlug: newSlugs[page.ID]})
+		}
+		for _, p := range below {
+			if p.Slug == newSlugs[p.ID] {
+				continue
+			}
+			if err := tx.Model(&models.Page{}).Where("id = ?", p.ID).Update("slug", newSlugs[p.ID]).Error; err != nil {
+				return err
+			}
+			changes = append(changes, SlugChange{PageID: p.ID, OldSlug: p.Slug, NewSlug: newSlugs[p.ID]})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page.ParentID = parentID
+	page.Slug = newSlugs[page.ID]
+	return changes, nil
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: MIT
+package pages
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupPagesTestDB(t *testing.T) *gorm.DB {
+	testDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := testDB.AutoMigrate(&models.Page{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	return testDB
+}
+
+func createPage(t *testing.T, db *gorm.DB, slug string, parent *models.Page) *models.Page {
+	page := &models.Page{SiteID: 1, Slug: slug, Title: slug}
+	if parent != nil {
+		page.ParentID = &parent.ID
+	}
+	if err := db.Create(page).Error; err != nil {
+		t.Fatalf("Failed to create page %s: %v", slug, err)
+	}
+	return page
+}
+
+func slugOf(t *testing.T, db *gorm.DB, id uint) string {
+	var p models.Page
+	if err := db.First(&p, id).Error; err != nil {
+		t.Fatalf("Failed to load page %d: %v", id, err)
+	}
+	return p.Slug
+}
+
+func TestCleanSegment(t *testing.T) {
+	if got, err := CleanSegment(" /arrival/ "); err != nil || got != "arrival" {
+		t.Errorf("Expected arrival, got %q (%v)", got, err)
+	}
+	for _, bad := range []string{"", "a/b", "..", "hello world", "<x>"} {
+		if _, err := CleanSegment(bad); !errors.Is(err, ErrInvalidSegment) {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestMoveCascadesSlugs(t *testing.T) {
+	db := setupPagesTestDB(t)
+	info := createPage(t, db, "/info", nil)
+	arrival := createPage(t, db, "/info/arrival", info)
+	parking := createPage(t, db, "/info/arrival/parking", arrival)
+	about := createPage(t, db, "/about", nil)
+
+	changes, err := Move(db, info, &about.ID, "guide")
+	if err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	if len(changes) != 3 {
+		t.Errorf("Expected 3 slug changes, got %d", len(changes))
+	}
+	if info.Slug != "/about/guide" || info.ParentID == nil || *info.ParentID != about.ID {
+		t.Errorf("Expected moved page to be updated in place, got %+v", info)
+	}
+	if got := slugOf(t, db, arrival.ID); got != "/about/guide/arrival" {
+		t.Errorf("Expected child slug to follow, got %s", got)
+	}
+	if got := slugOf(t, db, parking.ID); got != "/about/guide/arrival/parking" {
+		t.Errorf("Expected grandchild slug to follow, got %s", got)
+	}
+
+	// Back to the top level
+	if _, err := Move(db, info, nil, "info"); err != nil {
+		t.Fatalf("Move to top level failed: %v", err)
+	}
+	if got := slugOf(t, db, parking.ID); got != "/info/arrival/parking" {
+		t.Errorf("Expected grandchild slug to be restored, got %s", got)
+	}
+}
+
+func TestMoveRejectsCyclesAndCollisions(t *testing.T) {
+	db := setupPagesTestDB(t)
+	home := createPage(t, db, "/", nil)
+	info := createPage(t, db, "/info", nil)
+	arrival := createPage(t, db, "/info/arrival", info)
+	createPage(t, db, "/arrival", nil)
+
+	if _, err := Move(db, info, &arrival.ID, "info"); !errors.Is(err, ErrCycle) {
+		t.Errorf("Expected ErrCycle moving a page under its child, got %v", err)
+	}
+	if _, err := Move(db, info, &info.ID, "info"); !errors.Is(err, ErrCycle) {
+		t.Errorf("Expected ErrCycle moving a page under itself, got %v", err)
+	}
+	if _, err := Move(db, arrival, nil, "arrival"); !errors.Is(err, ErrSlugTaken) {
+		t.Errorf("Expected ErrSlugTaken, got %v", err)
+	}
+	if _, err := Move(db, arrival, &home.ID, "x"); !errors.Is(err, ErrInvalidParent) {
+		t.Errorf("Expected the homepage to be refused as a parent, got %v", err)
+	}
+	if got := slugOf(t, db, arrival.ID); got != "/info/arrival" {
+		t.Errorf("Failed moves must not change slugs, got %s", got)
+	}
+}
+
+func TestAncestorsAndTree(t *testing.T) {
+	db := setupPagesTestDB(t)
+	createPage(t, db, "/", nil)
+	info := createPage(t, db, "/info", nil)
+	arrival := createPage(t, db, "/info/arrival", info)
+	createPage(t, db, "/about", nil)
+
+	chain, err := Ancestors(db, arrival)
+	if err != nil || len(chain) != 1 || chain[0].ID != info.ID {
+		t.Errorf("Expected /info as the only ancestor, got %+v (%v)", chain, err)
+	}
+
+	var list []models.Page
+	db.Find(&list)
+	var got []string
+	for _, n := range Tree(list) {
+		got = append(got, n.Page.Slug)
+	}
+	want := []string{"/", "/about", "/info", "/info/arrival"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+			break
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: MIT
+package posts
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// Feed describes the site a feed is generated for
+type Feed struct {
+	Title       string
+	Description string
+	SiteURL     string // e.g. "https://camp.example.com", no trailing slash
+}
+
+type rssFeed struct {
+	XMLName      xml.Name   `xml:"rss"`
+	Version      string     `xml:"version,attr"`
+	AtomNS       string     `xml:"xmlns:atom,attr"`
+	ContentNS    string     `xml:"xmlns:content,attr"`
+	DublinCoreNS string     `xml:"xmlns:dc,attr"`
+	Channel      rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"content:encoded"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomPerson  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    atomText       `xml:"summary"`
+	Content    atomText       `xml:"content"`
+}
+
+// feedHTML is the full post body for feed readers, with the cover image first
+func feedHTML(f Feed, p *models.Post) string {
+	body := RenderContent(p.Content)
+	if p.CoverImage == "" {
+		return body
+	}
+	src := p.CoverImage
+	if len(src) > 0 && src[0] == '/' {
+		src = f.SiteURL + src
+	}
+	return fmt.Sprintf(`<p><img src="%s" alt="%s"></p>`, html.EscapeString(src), html.EscapeString(p.Title)) + body
+}
+
+// lastUpdated returns the most recent change to any post in the feed
+func lastUpdated(list []models.Post) time.Time {
+	var latest time.Time
+	for _, p := range list {
+		if p.UpdatedAt.After(latest) {
+			latest = p.UpdatedAt
+		}
+		if p.PublishedAt.After(latest) {
+			latest = p.PublishedAt
+		}
+	}
+	return latest
+}
+
+// WriteRSS writes posts as an RSS 2.0 feed
+func WriteRSS(w io.Writer, f Feed, list []models.Post) error {
+	feed := rssFeed{
+		Version:      "2.0",
+		AtomNS:       "http://www.w3.org/2005/Atom",
+		ContentNS:    "http://purl.org/rss/1.0/modules/content/",
+		DublinCoreNS: "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.SiteURL + "/blog",
+			Description: f.Description,
+			AtomLink:    atomLink{Href: f.SiteURL + "/feed.xml", Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if updated := lastUpdated(list); !updated.IsZero() {
+		feed.Channel.LastBuildDate = updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for i := range list {
+		p := &list[i]
+		link := f.SiteURL + URL(p)
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       p.Title,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: "true", Value: link},
+			PubDate:     p.PublishedAt.UTC().Format(time.RFC1123Z),
+			Creator:     p.Author,
+			Categories:  TagNames(p),
+			Description: Summary(p),
+			Content:     feedHTML(f, p),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(feed)
+}
+
+// WriteAtom writes posts as an Atom 1.0 feed
+func WriteAtom(w io.Writer, f Feed, list []models.Post) error {
+	updated := lastUpdated(list)
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	feed := atomFeed{
+		Title:   f.Title,
+		ID:      f.SiteURL + "/blog",
+		Updated: updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.SiteURL + "/atom.xml", Rel: "self", Type: "application/atom+xml"},
+			{Href: f.SiteURL + "/blog", Rel: "alternate", Type: "text/html"},
+		},
+		// Entries without an author inherit the feed's
+		Author: atomPerson{Name: f.Title},
+	}
+
+	for i := range list {
+		p := &list[i]
+		link := f.SiteURL + URL(p)
+		entry := atomEntry{
+			Title:     p.Title,
+			ID:        link,
+			Link:      atomLink{Href: link, Rel: "alternate", Type: "text/html"},
+			Published: p.PublishedAt.UTC().Format(time.RFC3339),
+			Updated:   p.UpdatedAt.UTC().Format(time.RFC3339),
+			Summary:   atomText{Type: "text", Body: Summary(p)},
+			Content:   atomText{Type: "html", Body: feedHTML(f, p)},
+		}
+		if p.UpdatedAt.Before(p.PublishedAt) {
+			// Scheduled posts are edited before they go out
+			entry.Updated = entry.Published
+		}
+		if p.Author != "" {
+			entry.Author = &atomPerson{Name: p.Author}
+		}
+		for _, tag := range TagNames(p) {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(feed)
+}
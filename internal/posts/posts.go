@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: MIT
+
+// Package posts handles blog/news posts: scheduling, tags, archive listings
+// and RSS/Atom feeds.
+package posts
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/thatcatcamp/stinkykitty/internal/config"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxTags bounds how many tags a single post can carry
+const maxTags = 20
+
+// summaryLength is how many characters of content are used when a post has no excerpt
+const summaryLength = 200
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// PerPage returns how many posts are shown on each archive page
+func PerPage() int {
+	if n := config.GetInt("posts.per_page"); n > 0 {
+		return n
+	}
+	return 10
+}
+
+// FeedSize returns how many posts are included in the RSS and Atom feeds
+func FeedSize() int {
+	if n := config.GetInt("posts.feed_size"); n > 0 {
+		return n
+	}
+	return 20
+}
+
+// Slugify turns a title into a URL slug, e.g. "Burn Week 2026!" -> "burn-week-2026"
+func Slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+	if len(slug) > 80 {
+		slug = strings.TrimRight(slug[:80], "-")
+	}
+	return slug
+}
+
+// ValidSlug reports whether s is a usable post slug (lowercase letters, digits and single dashes)
+func ValidSlug(s string) bool {
+	return len(s) <= 100 && slugPattern.MatchString(s)
+}
+
+// ParseTags splits a comma-separated tag list, normalizing each tag to
+// lowercase with dashes and dropping blanks and duplicates
+func ParseTags(s string) []string {
+	seen := map[string]bool{}
+	var tags []string
+	for _, raw := range strings.Split(s, ",") {
+		tag := Slugify(raw)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) == maxTags {
+			break
+		}
+	}
+	return tags
+}
+
+// TagNames returns a post's tag names in the order they were added
+func TagNames(p *models.Post) []string {
+	names := make([]string, 0, len(p.Tags))
+	for _, t := range p.Tags {
+		names = append(names, t.TagName)
+	}
+	return names
+}
+
+// SetTags replaces a post's tags
+func SetTags(db *gorm.DB, p *models.Post, tags []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", p.ID).Delete(&models.PostTag{}).Error; err != nil {
+			return err
+		}
+		p.Tags = nil
+		for _, name := range tags {
+			tag := models.PostTag{PostID: p.ID, SiteID: p.SiteID, TagName: name}
+			if err := tx.Create(&tag).Error; err != nil {
+				return err
+			}
+			p.Tags = append(p.Tags, tag)
+		}
+		return nil
+	})
+}
+
+// IsVisible reports whether a post is published and its publish date has arrived
+func IsVisible(p *models.Post, now time.Time) bool {
+	return p.Published && !p.PublishedAt.After(now)
+}
+
+// visible scopes a query to a site's posts that are published and due
+func visible(db *gorm.DB, siteID uint, tag string, now time.Time) *gorm.DB {
+	q := db.Model(&models.Post{}).
+		Where("site_id = ? AND published = ? AND published_at <= ?", siteID, true, now.UTC())
+	if tag != "" {
+		q = q.Where("id IN (?)", db.Model(&models.PostTag{}).Select("post_id").Where("site_id = ? AND tag_name = ?", siteID, tag))
+	}
+	return q
+}
+
+// List returns one page (1-based) of visible posts, newest first, optionally
+// limited to a tag, along with the total number of matching posts
+func List(db *gorm.DB, siteID uint, tag string, page, perPage int, now time.Time) ([]models.Post, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	var total int64
+	if err := visible(db, siteID, tag, now).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []models.Post
+	err := visible(db, siteID, tag, now).
+		Preload("Tags").
+		Order("published_at DESC, id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&list).Error
+	return list, total, err
+}
+
+// Latest returns the newest visible posts, optionally limited to a tag
+func Latest(db *gorm.DB, siteID uint, tag string, limit int, now time.Time) ([]models.Post, error) {
+	list, _, err := List(db, siteID, tag, 1, limit, now)
+	return list, err
+}
+
+// URL returns the public path of a post
+func URL(p *models.Post) string {
+	return "/blog/" + p.Slug
+}
+
+// TagURL returns the public path of a tag's archive
+func TagURL(tag string) string {
+	return "/blog/tag/" + url.PathEscape(tag)
+}
+
+// contentPolicy allows the same safe HTML as columns blocks
+func contentPolicy() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	policy.AllowAttrs("class", "style").OnElements("div", "p", "h1", "h2", "h3", "h4", "h5", "h6", "span")
+	policy.AllowAttrs("src", "alt", "title", "width", "height", "style").OnElements("img")
+	return policy
+}
+
+// RenderContent sanitizes a post's HTML body for display, keeping line breaks
+func RenderContent(content string) string {
+	return strings.ReplaceAll(contentPolicy().Sanitize(content), "\n", "<br>")
+}
+
+// PlainText strips all markup from HTML and collapses whitespace
+func PlainText(s string) string {
+	text := html.UnescapeString(bluemonday.StrictPolicy().Sanitize(s))
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Summary returns a post's excerpt, or the start of its content if it has none
+func Summary(p *models.Post) string {
+	if excerpt := strings.TrimSpace(p.Excerpt); excerpt != "" {
+		return excerpt
+	}
+	text := PlainText(p.Content)
+	runes := []rune(text)
+	if len(runes) <= summaryLength {
+		return text
+	}
+	cut := string(runes[:summaryLength])
+	if i := strings.LastIndex(cut, " "); i > summaryLength/2 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
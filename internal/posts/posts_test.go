@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: MIT
+package posts
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupPostsTestDB(t *testing.T) *gorm.DB {
+	testDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := testDB.AutoMigrate(&models.Site{}, &models.Post{}, &models.PostTag{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	testDB.Create(&models.Site{ID: 1, Subdomain: "test", OwnerID: 1, SiteDir: "/tmp/test"})
+	return testDB
+}
+
+func createPost(t *testing.T, testDB *gorm.DB, slug string, published bool, at time.Time, tags ...string) *models.Post {
+	p := &models.Post{SiteID: 1, Slug: slug, Title: slug, Published: published, PublishedAt: at.UTC()}
+	if err := testDB.Create(p).Error; err != nil {
+		t.Fatalf("Failed to create post: %v", err)
+	}
+	if err := SetTags(testDB, p, tags); err != nil {
+		t.Fatalf("Failed to set tags: %v", err)
+	}
+	return p
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Burn Week 2026!":        "burn-week-2026",
+		"  Hello,   World  ":     "hello-world",
+		"Café & Crêpes":          "caf-cr-pes",
+		"---":                    "",
+		strings.Repeat("a", 100): strings.Repeat("a", 80),
+	}
+	for in, want := range tests {
+		if got := Slugify(in); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if !ValidSlug("build-week") || ValidSlug("Build Week") || ValidSlug("a--b") || ValidSlug("") {
+		t.Error("ValidSlug gave unexpected results")
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	got := ParseTags("News, build week, NEWS, , Art Cars")
+	want := []string{"news", "build-week", "art-cars"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTags = %v, want %v", got, want)
+	}
+}
+
+func TestListHidesDraftsAndScheduledPosts(t *testing.T) {
+	testDB := setupPostsTestDB(t)
+	now := time.Now()
+
+	createPost(t, testDB, "old", true, now.Add(-48*time.Hour), "news")
+	createPost(t, testDB, "new", true, now.Add(-time.Hour), "news", "art")
+	createPost(t, testDB, "draft", false, now.Add(-time.Hour), "news")
+	createPost(t, testDB, "scheduled", true, now.Add(24*time.Hour), "news")
+
+	list, total, err := List(testDB, 1, "", 1, 10, now)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 2 || len(list) != 2 || list[0].Slug != "new" || list[1].Slug != "old" {
+		t.Fatalf("Expected [new old], got %d posts (total %d): %+v", len(list), total, list)
+	}
+	if len(list[0].Tags) != 2 {
+		t.Errorf("Expected tags to be preloaded, got %+v", list[0].Tags)
+	}
+
+	// Pagination
+	list, total, _ = List(testDB, 1, "", 2, 1, now)
+	if total != 2 || len(list) != 1 || list[0].Slug != "old" {
+		t.Errorf("Expected page 2 to hold the older post, got %+v", list)
+	}
+
+	// Tag filter
+	list, total, _ = List(testDB, 1, "art", 1, 10, now)
+	if total != 1 || len(list) != 1 || list[0].Slug != "new" {
+		t.Errorf("Expected only the art post, got %+v", list)
+	}
+
+	// The scheduled post shows up once its date passes
+	list, _ = Latest(testDB, 1, "", 10, now.Add(48*time.Hour))
+	if len(list) != 3 || list[0].Slug != "scheduled" {
+		t.Errorf("Expected scheduled post to appear after its date, got %+v", list)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	if got := Summary(&models.Post{Excerpt: "Short and sweet", Content: "<p>Ignored</p>"}); got != "Short and sweet" {
+		t.Errorf("Expected excerpt, got %q", got)
+	}
+	if got := Summary(&models.Post{Content: "<p>Hello <b>camp</b> &amp; friends</p>"}); got != "Hello camp & friends" {
+		t.Errorf("Expected plain-text content, got %q", got)
+	}
+	long := Summary(&models.Post{Content: strings.Repeat("word ", 100)})
+	if len([]rune(long)) > summaryLength+1 || !strings.HasSuffix(long, "…") {
+		t.Errorf("Expected truncated summary, got %q", long)
+	}
+}
+
+func TestRenderContentSanitizes(t *testing.T) {
+	out := RenderContent("<p>Hi</p><script>alert(1)</script>\n<a href=\"javascript:alert(1)\">x</a>")
+	if strings.Contains(out, "<script") || strings.Contains(out, "javascript:") {
+		t.Errorf("Expected unsafe markup to be removed, got %q", out)
+	}
+	if !strings.Contains(out, "<p>Hi</p>") || !strings.Contains(out, "<br>") {
+		t.Errorf("Expected safe markup and line breaks to be kept, got %q", out)
+	}
+}
+
+func feedPost() []models.Post {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return []models.Post{{
+		ID: 1, Slug: "build-week", Title: "Build <Week>", Author: "Mittens",
+		Excerpt: "We built things", Content: "<p>Lots of things</p>", CoverImage: "/assets/cover.jpg",
+		Published: true, PublishedAt: at, UpdatedAt: at,
+		Tags: []models.PostTag{{TagName: "news"}},
+	}}
+}
+
+func TestWriteRSS(t *testing.T) {
+	var buf bytes.Buffer
+	f := Feed{Title: "Test Camp", Description: "News", SiteURL: "https://camp.example.com"}
+	if err := WriteRSS(&buf, f, feedPost()); err != nil {
+		t.Fatalf("WriteRSS failed: %v", err)
+	}
+
+	var parsed struct {
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title    string `xml:"title"`
+				Link     string `xml:"link"`
+				PubDate  string `xml:"pubDate"`
+				Category string `xml:"category"`
+				Content  string `xml:"encoded"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("RSS is not valid XML: %v\n%s", err, buf.String())
+	}
+	if parsed.Channel.Title != "Test Camp" || len(parsed.Channel.Items) != 1 {
+		t.Fatalf("Unexpected channel: %+v", parsed.Channel)
+	}
+	item := parsed.Channel.Items[0]
+	if item.Title != "Build <Week>" || item.Link != "https://camp.example.com/blog/build-week" ||
+		item.PubDate != "Sun, 01 Mar 2026 12:00:00 +0000" || item.Category != "news" {
+		t.Errorf("Unexpected item: %+v", item)
+	}
+	if !strings.Contains(item.Content, `src="https://camp.example.com/assets/cover.jpg"`) {
+		t.Errorf("Expected absolute cover image in content, got %q", item.Content)
+	}
+}
+
+func TestWriteAtom(t *testing.T) {
+	var buf bytes.Buffer
+	f := Feed{Title: "Test Camp", SiteURL: "https://camp.example.com"}
+	if err := WriteAtom(&buf, f, feedPost()); err != nil {
+		t.Fatalf("WriteAtom failed: %v", err)
+	}
+
+	var parsed struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID        string `xml:"id"`
+			Published string `xml:"published"`
+			Author    string `xml:"author>name"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("Atom is not valid XML: %v\n%s", err, buf.String())
+	}
+	if parsed.Updated != "2026-03-01T12:00:00Z" || len(parsed.Entries) != 1 {
+		t.Fatalf("Unexpected feed: %+v", parsed)
+	}
+	if e := parsed.Entries[0]; e.ID != "https://camp.example.com/blog/build-week" || e.Published != "2026-03-01T12:00:00Z" || e.Author != "Mittens" {
+		t.Errorf("Unexpected entry: %+v", e)
+	}
+}
@@ -0,0 +1,278 @@
+// SPDX-License-Identifier: MIT
+
+// Package redirects manages per-site redirects from old paths to new
+// addresses, including the ones created automatically when pages move.
+package redirects
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxPathLength bounds source paths and targets
+const maxPathLength = 2048
+
+// maxImportRows bounds how many lines a single CSV import will read
+const maxImportRows = 5000
+
+var (
+	// ErrInvalidSource is returned for a source that isn't a site path
+	ErrInvalidSource = errors.New("source must be a path on this site, like /old-page")
+	// ErrInvalidTarget is returned for a target that isn't a site path or http(s) URL
+	ErrInvalidTarget = errors.New("target must be a path like /new-page or a full http(s) URL")
+	// ErrInvalidStatus is returned for status codes other than 301 and 302
+	ErrInvalidStatus = errors.New("status must be 301 (permanent) or 302 (temporary)")
+	// ErrLoop is returned when a redirect would point at itself
+	ErrLoop = errors.New("a redirect can't point at its own source")
+)
+
+// NormalizeSource turns a path or old-site URL into the form redirects are
+// stored and looked up by: a leading slash, no trailing slash and no query.
+func NormalizeSource(source string) (string, error) {
+	source = strings.TrimSpace(source)
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		u, err := url.Parse(source)
+		if err != nil {
+			return "", ErrInvalidSource
+		}
+		source = u.Path
+	}
+	if i := strings.IndexAny(source, "?#"); i >= 0 {
+		source = source[:i]
+	}
+	if !strings.HasPrefix(source, "/") {
+		source = "/" + source
+	}
+	source = strings.TrimRight(source, "/")
+	// The homepage and admin always exist, so redirects from them never fire
+	if source == "" || source == "/admin" || strings.HasPrefix(source, "/admin/") ||
+		strings.HasPrefix(source, "//") || len(source) > maxPathLength || hasControl(source) {
+		return "", ErrInvalidSource
+	}
+	return source, nil
+}
+
+// ValidateTarget checks a redirect target and returns it trimmed
+func ValidateTarget(target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" || len(target) > maxPathLength || hasControl(target) {
+		return "", ErrInvalidTarget
+	}
+	if strings.HasPrefix(target, "/") {
+		if strings.HasPrefix(target, "//") {
+			return "", ErrInvalidTarget
+		}
+		return target, nil
+	}
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", ErrInvalidTarget
+	}
+	return target, nil
+}
+
+func hasControl(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return r < 0x20 || r == 0x7f }) >= 0
+}
+
+// targetPath returns the site path a target points at, or "" for external URLs
+func targetPath(target string) string {
+	if !strings.HasPrefix(target, "/") {
+		return ""
+	}
+	if i := strings.IndexAny(target, "?#"); i >= 0 {
+		target = target[:i]
+	}
+	if target != "/" {
+		target = strings.TrimRight(target, "/")
+	}
+	return target
+}
+
+// Set creates or updates the redirect for a source path. Existing redirects
+// that pointed at the source are repointed at the new target, so a page that
+// moves twice doesn't leave visitors following a chain of redirects.
+func Set(db *gorm.DB, siteID uint, source, target string, status int) (*models.Redirect, error) {
+	source, err := NormalizeSource(source)
+	if err != nil {
+		return nil, err
+	}
+	target, err = ValidateTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	if status != 301 && status != 302 {
+		return nil, ErrInvalidStatus
+	}
+	if targetPath(target) == source {
+		return nil, ErrLoop
+	}
+
+	var saved models.Redirect
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("site_id = ? AND source_path = ?", siteID, source).First(&saved).Error
+		switch {
+		case err == nil:
+			saved.Target = target
+			saved.StatusCode = status
+			if err := tx.Save(&saved).Error; err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			saved = models.Redirect{SiteID: siteID, SourcePath: source, Target: target, StatusCode: status}
+			if err := tx.Create(&saved).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		if err := tx.Model(&models.Redirect{}).
+			Where("site_id = ? AND target = ? AND id <> ?", siteID, source, saved.ID).
+			Update("target", target).Error; err != nil {
+			return err
+		}
+		// Anything repointed at its own source is now a loop
+		var looped []models.Redirect
+		if err := tx.Where("site_id = ? AND target = ?", siteID, target).Find(&looped).Error; err != nil {
+			return err
+		}
+		for _, r := range looped {
+			if targetPath(r.Target) == r.SourcePath {
+				if err := tx.Delete(&r).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// ForDeletedPage sends visitors to a deleted page's address on to another page,
+// typically its parent. It's temporary so the address can be reused later.
+func ForDeletedPage(db *gorm.DB, siteID uint, slug, target string) error {
+	if slug == "/" || slug == target {
+		return nil
+	}
+	_, err := Set(db, siteID, slug, target, 302)
+	return err
+}
+
+// ForSlugChange records a permanent redirect from a page's old slug to its new
+// one, and drops any redirect away from the new slug now that a page lives there.
+func ForSlugChange(db *gorm.DB, siteID uint, oldSlug, newSlug string) error {
+	if oldSlug == newSlug || oldSlug == "/" {
+		return nil
+	}
+	if err := db.Where("site_id = ? AND source_path = ?", siteID, newSlug).Delete(&models.Redirect{}).Error; err != nil {
+		return err
+	}
+	_, err := Set(db, siteID, oldSlug, newSlug, 301)
+	return err
+}
+
+// Lookup finds the redirect for a request path, if there is one
+func Lookup(db *gorm.DB, siteID uint, path string) (*models.Redirect, bool) {
+	source, err := NormalizeSource(path)
+	if err != nil {
+		return nil, false
+	}
+	var r models.Redirect
+	if err := db.Where("site_id = ? AND source_path = ?", siteID, source).First(&r).Error; err != nil {
+		return nil, false
+	}
+	return &r, true
+}
+
+// RecordHit counts a use of a redirect
+func RecordHit(db *gorm.DB, r *models.Redirect) error {
+	return db.Model(&models.Redirect{}).Where("id = ?", r.ID).Updates(map[string]interface{}{
+		"hits":        gorm.Expr("hits + 1"),
+		"last_hit_at": time.Now().UTC(),
+	}).Error
+}
+
+// Location returns where to send a visitor, carrying over the request's query
+// string when the target doesn't have one of its own
+func Location(r *models.Redirect, rawQuery string) string {
+	if rawQuery == "" || strings.ContainsAny(r.Target, "?#") {
+		return r.Target
+	}
+	return r.Target + "?" + rawQuery
+}
+
+// ImportResult summarizes a CSV import
+type ImportResult struct {
+	Imported int
+	Errors   []string // One message per rejected line
+}
+
+// ImportCSV reads redirects from CSV with the columns source, target and an
+// optional status (301 when blank). A header row is skipped if present.
+// Existing redirects for the same source are updated.
+func ImportCSV(db *gorm.DB, siteID uint, r io.Reader) (*ImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	result := &ImportResult{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("line %d: %w", line, err)
+		}
+		if line > maxImportRows {
+			result.Errors = append(result.Errors, fmt.Sprintf("stopped after %d lines", maxImportRows))
+			break
+		}
+		if len(record) == 0 || (len(record) == 1 && strings.TrimSpace(record[0]) == "") {
+			continue
+		}
+		if line == 1 && isHeader(record[0]) {
+			continue
+		}
+		if len(record) < 2 {
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: expected source,target[,status]", line))
+			continue
+		}
+
+		status := 301
+		if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+			status, err = strconv.Atoi(strings.TrimSpace(record[2]))
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("line %d: %v", line, ErrInvalidStatus))
+				continue
+			}
+		}
+		if _, err := Set(db, siteID, record[0], record[1], status); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		result.Imported++
+	}
+	return result, nil
+}
+
+func isHeader(field string) bool {
+	switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(field, "\ufeff"))) {
+	case "source", "source_path", "from", "old":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: MIT
+package redirects
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupRedirectsTestDB(t *testing.T) *gorm.DB {
+	testDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := testDB.AutoMigrate(&models.Redirect{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	return testDB
+}
+
+func targetOf(t *testing.T, db *gorm.DB, source string) string {
+	r, ok := Lookup(db, 1, source)
+	if !ok {
+		return ""
+	}
+	return r.Target
+}
+
+func TestNormalizeSource(t *testing.T) {
+	cases := map[string]string{
+		"/about/":                              "/about",
+		"about":                                "/about",
+		"https://old.example.org/team?ref=nav": "/team",
+		"/news#latest":                         "/news",
+	}
+	for in, want := range cases {
+		if got, err := NormalizeSource(in); err != nil || got != want {
+			t.Errorf("NormalizeSource(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"/", "", "/admin/pages", "//evil.example"} {
+		if _, err := NormalizeSource(bad); !errors.Is(err, ErrInvalidSource) {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestSetValidates(t *testing.T) {
+	db := setupRedirectsTestDB(t)
+	if _, err := Set(db, 1, "/a", "javascript:alert(1)", 301); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("Expected ErrInvalidTarget, got %v", err)
+	}
+	if _, err := Set(db, 1, "/a", "/b", 307); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("Expected ErrInvalidStatus, got %v", err)
+	}
+	if _, err := Set(db, 1, "/a/", "/a", 301); !errors.Is(err, ErrLoop) {
+		t.Errorf("Expected ErrLoop, got %v", err)
+	}
+}
+
+func TestForSlugChangeCollapsesChains(t *testing.T) {
+	db := setupRedirectsTestDB(t)
+
+	if err := ForSlugChange(db, 1, "/a", "/b"); err != nil {
+		t.Fatalf("ForSlugChange failed: %v", err)
+	}
+	if err := ForSlugChange(db, 1, "/b", "/c"); err != nil {
+		t.Fatalf("ForSlugChange failed: %v", err)
+	}
+	if got := targetOf(t, db, "/a"); got != "/c" {
+		t.Errorf("Expected /a to go straight to /c, got %q", got)
+	}
+
+	// Moving back to an old address drops the redirect away from it
+	if err := ForSlugChange(db, 1, "/c", "/a"); err != nil {
+		t.Fatalf("ForSlugChange failed: %v", err)
+	}
+	if got := targetOf(t, db, "/a"); got != "" {
+		t.Errorf("Expected no redirect from the page's current address, got %q", got)
+	}
+	if got := targetOf(t, db, "/b"); got != "/a" {
+		t.Errorf("Expected /b to follow the page back to /a, got %q", got)
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	db := setupRedirectsTestDB(t)
+	input := "source,target,status\n" +
+		"https://oldcamp.example.org/about-us.html,/about,\n" +
+		"/schedule,/events,302\n" +
+		"/broken\n" +
+		"/loop,/loop,301\n"
+
+	result, err := ImportCSV(db, 1, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ImportCSV failed: %v", err)
+	}
+	if result.Imported != 2 || len(result.Errors) != 2 {
+		t.Errorf("Expected 2 imported and 2 errors, got %+v", result)
+	}
+	if r, ok := Lookup(db, 1, "/about-us.html"); !ok || r.Target != "/about" || r.StatusCode != 301 {
+		t.Errorf("Expected old-site URL to be imported as a path, got %+v", r)
+	}
+	if r, ok := Lookup(db, 1, "/schedule/"); !ok || r.StatusCode != 302 {
+		t.Errorf("Expected temporary redirect for /schedule, got %+v", r)
+	}
+}
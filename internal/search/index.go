@@ -2,11 +2,15 @@
 package search
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/visibility"
 	"gorm.io/gorm"
 )
 
@@ -33,6 +37,18 @@ func InitFTSIndex(db *gorm.DB) error {
 		return fmt.Errorf("failed to create FTS index: %w", err)
 	}
 
+	_, err = sqlDB.Exec(`
+		CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(
+			post_id UNINDEXED,
+			site_id UNINDEXED,
+			title,
+			content
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create posts FTS index: %w", err)
+	}
+
 	return nil
 }
 
@@ -66,8 +82,9 @@ func IndexPage(db *gorm.DB, page *models.Page) error {
 		return fmt.Errorf("failed to delete old index entry: %w", err)
 	}
 
-	// Only index published pages
-	if !page.Published {
+	// Only index published pages anyone can see, so protected content never
+	// shows up in results or snippets
+	if !page.Published || !visibility.IsPublic(page) {
 		return nil
 	}
 
@@ -98,9 +115,59 @@ func RemovePageFromIndex(db *gorm.DB, pageID uint) error {
 	return nil
 }
 
+// IndexPost adds or updates a blog post in the FTS index. Scheduled posts are
+// indexed too; Search hides them until their publish date.
+func IndexPost(db *gorm.DB, post *models.Post) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get sql.DB: %w", err)
+	}
+
+	_, err = sqlDB.Exec(`DELETE FROM posts_fts WHERE post_id = ?`, post.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete old index entry: %w", err)
+	}
+
+	if !post.Published {
+		return nil
+	}
+
+	var tags []string
+	for _, tag := range post.Tags {
+		tags = append(tags, tag.TagName)
+	}
+	content := strings.Join([]string{post.Author, post.Excerpt, stripHTML(post.Content), strings.Join(tags, " ")}, " ")
+
+	_, err = sqlDB.Exec(`
+		INSERT INTO posts_fts (post_id, site_id, title, content)
+		VALUES (?, ?, ?, ?)
+	`, post.ID, post.SiteID, post.Title, content)
+	if err != nil {
+		return fmt.Errorf("failed to insert index entry: %w", err)
+	}
+
+	return nil
+}
+
+// RemovePostFromIndex removes a blog post from the FTS index
+func RemovePostFromIndex(db *gorm.DB, postID uint) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get sql.DB: %w", err)
+	}
+
+	_, err = sqlDB.Exec(`DELETE FROM posts_fts WHERE post_id = ?`, postID)
+	if err != nil {
+		return fmt.Errorf("failed to remove from index: %w", err)
+	}
+
+	return nil
+}
+
 // SearchResult represents a single search result
 type SearchResult struct {
 	PageID  uint    `json:"page_id"`
+	PostID  uint    `json:"post_id,omitempty"`
 	Title   string  `json:"title"`
 	Snippet string  `json:"snippet"`
 	URL     string  `json:"url"`
@@ -129,10 +196,10 @@ func Search(db *gorm.DB, siteID uint, query string) ([]SearchResult, error) {
 			rank
 		FROM pages_fts fts
 		INNER JOIN pages p ON fts.page_id = p.id
-		WHERE pages_fts MATCH ? AND fts.site_id = ?
+		WHERE pages_fts MATCH ? AND fts.site_id = ? AND p.visibility = ?
 		ORDER BY rank
 		LIMIT 50
-	`, query, siteID)
+	`, query, siteID, visibility.Public)
 	if err != nil {
 		return nil, fmt.Errorf("search query failed: %w", err)
 	}
@@ -153,6 +220,62 @@ func Search(db *gorm.DB, siteID uint, query string) ([]SearchResult, error) {
 		return nil, fmt.Errorf("error iterating results: %w", err)
 	}
 
+	postResults, err := searchPosts(sqlDB, siteID, query, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(postResults) > 0 {
+		// Interleave pages and posts by relevance
+		results = append(results, postResults...)
+		sort.SliceStable(results, func(i, j int) bool { return results[i].Rank < results[j].Rank })
+		if len(results) > 50 {
+			results = results[:50]
+		}
+	}
+
+	return results, nil
+}
+
+// searchPosts searches a site's published blog posts, skipping scheduled ones
+func searchPosts(sqlDB *sql.DB, siteID uint, query string, now time.Time) ([]SearchResult, error) {
+	rows, err := sqlDB.Query(`
+		SELECT
+			fts.post_id,
+			p.title,
+			p.slug,
+			p.published_at,
+			snippet(posts_fts, 3, '<mark>', '</mark>', '...', 50) as snippet,
+			rank
+		FROM posts_fts fts
+		INNER JOIN posts p ON fts.post_id = p.id
+		WHERE posts_fts MATCH ? AND fts.site_id = ? AND p.deleted_at IS NULL
+		ORDER BY rank
+		LIMIT 50
+	`, query, siteID)
+	if err != nil {
+		return nil, fmt.Errorf("post search query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var result SearchResult
+		var slug string
+		var publishedAt time.Time
+		if err := rows.Scan(&result.PostID, &result.Title, &slug, &publishedAt, &result.Snippet, &result.Rank); err != nil {
+			return nil, fmt.Errorf("failed to scan result: %w", err)
+		}
+		if publishedAt.After(now) {
+			continue
+		}
+		result.URL = "/blog/" + slug
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating results: %w", err)
+	}
+
 	return results, nil
 }
 
@@ -186,6 +309,13 @@ func extractTextFromBlock(block models.Block) string {
 		if alt, ok := data["alt"].(string); ok {
 			parts = append(parts, alt)
 		}
+	case "file":
+		if title, ok := data["title"].(string); ok {
+			parts = append(parts, title)
+		}
+		if description, ok := data["description"].(string); ok {
+			parts = append(parts, description)
+		}
 	}
 
 	return strings.Join(parts, " ")
@@ -239,5 +369,22 @@ func RebuildSiteIndex(db *gorm.DB, siteID uint) error {
 		}
 	}
 
+	// Rebuild blog posts the same way
+	_, err = sqlDB.Exec(`DELETE FROM posts_fts WHERE site_id = ?`, siteID)
+	if err != nil {
+		return fmt.Errorf("failed to clear site post index: %w", err)
+	}
+
+	var posts []models.Post
+	if err := db.Preload("Tags").Where("site_id = ? AND published = ?", siteID, true).Find(&posts).Error; err != nil {
+		return fmt.Errorf("failed to load posts: %w", err)
+	}
+
+	for _, post := range posts {
+		if err := IndexPost(db, &post); err != nil {
+			return fmt.Errorf("failed to index post %d: %w", post.ID, err)
+		}
+	}
+
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"github.com/thatcatcamp/stinkykitty/internal/visibility"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -16,7 +17,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// Migrate models
-	if err := db.AutoMigrate(&models.User{}, &models.Site{}, &models.Page{}, &models.Block{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.Site{}, &models.Page{}, &models.Block{}, &models.Post{}, &models.PostTag{}); err != nil {
 		t.Fatalf("Failed to migrate: %v", err)
 	}
 
@@ -34,6 +35,9 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Skipf("Skipping test: FTS5 not available in test environment: %v", err)
 	}
+	if _, err := sqlDB.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(post_id UNINDEXED, site_id UNINDEXED, title, content)`); err != nil {
+		t.Fatalf("Failed to create posts FTS index: %v", err)
+	}
 
 	return db
 }
@@ -210,6 +214,26 @@ func TestRemovePageFromIndex(t *testing.T) {
 	}
 }
 
+func TestProtectedPagesNotIndexed(t *testing.T) {
+	db := setupTestDB(t)
+	db.Create(&models.Site{ID: 1, Subdomain: "site1"})
+
+	page := models.Page{SiteID: 1, Slug: "/gate", Title: "Gate", Published: true, Visibility: visibility.Password}
+	db.Create(&page)
+	db.Create(&models.Block{PageID: page.ID, Type: "text", Data: `{"content":"The gate code is marmalade"}`})
+
+	if err := IndexPage(db, &page); err != nil {
+		t.Fatalf("IndexPage failed: %v", err)
+	}
+	results, err := Search(db, 1, "marmalade")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected protected page to stay out of search, got %+v", results)
+	}
+}
+
 func TestStripHTML(t *testing.T) {
 	tests := []struct {
 		input    string
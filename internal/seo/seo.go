@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: MIT
+
+// Package seo builds the meta description, canonical link, robots, Open Graph
+// and Twitter card tags for public pages.
+package seo
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// MaxDescriptionLength bounds meta descriptions; search engines show ~160 characters
+const MaxDescriptionLength = 300
+
+var (
+	// ErrDescriptionTooLong is returned for descriptions over MaxDescriptionLength
+	ErrDescriptionTooLong = fmt.Errorf("description must be %d characters or fewer", MaxDescriptionLength)
+	// ErrInvalidImage is returned for an image that isn't a site path or http(s) URL
+	ErrInvalidImage = errors.New("image must be a path like /assets/photo.jpg or a full http(s) URL")
+	// ErrInvalidCanonical is returned for a canonical URL that isn't a full http(s) URL
+	ErrInvalidCanonical = errors.New("canonical URL must be a full http(s) URL")
+)
+
+// Meta holds the metadata rendered into a page's <head>
+type Meta struct {
+	Title       string
+	SiteName    string
+	Description string
+	URL         string // Canonical URL
+	Image       string // Absolute URL
+	NoIndex     bool
+	Type        string // Open Graph type, "website" when blank
+}
+
+// ValidateDescription trims a description and checks its length
+func ValidateDescription(s string) (string, error) {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) > MaxDescriptionLength {
+		return "", ErrDescriptionTooLong
+	}
+	return s, nil
+}
+
+// ValidateImage checks an image reference: blank, a site path or an http(s) URL
+func ValidateImage(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	if strings.HasPrefix(s, "/") && !strings.HasPrefix(s, "//") {
+		return s, nil
+	}
+	if !isHTTPURL(s) {
+		return "", ErrInvalidImage
+	}
+	return s, nil
+}
+
+// ValidateCanonical checks a canonical URL override, which may be blank
+func ValidateCanonical(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s != "" && !isHTTPURL(s) {
+		return "", ErrInvalidCanonical
+	}
+	return s, nil
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// Absolute turns a site path into a full URL on baseURL, e.g. "https://camp.example.org"
+func Absolute(baseURL, ref string) string {
+	if ref == "" || !strings.HasPrefix(ref, "/") || strings.HasPrefix(ref, "//") {
+		return ref
+	}
+	return strings.TrimSuffix(baseURL, "/") + ref
+}
+
+// SiteName returns the name shown for a site in shared links
+func SiteName(site *models.Site) string {
+	if site.SiteTitle != "" {
+		return site.SiteTitle
+	}
+	return site.Subdomain
+}
+
+// ForPage builds the metadata for a page at baseURL. Blank page fields fall
+// back to the site's tagline and logo.
+func ForPage(site *models.Site, page *models.Page, baseURL string) Meta {
+	m := Meta{
+		Title:       page.Title,
+		SiteName:    SiteName(site),
+		Description: page.MetaDescription,
+		URL:         strings.TrimSuffix(baseURL, "/") + page.Slug,
+		Image:       page.SocialImage,
+		NoIndex:     page.NoIndex,
+	}
+	if m.Description == "" {
+		m.Description = site.SiteTagline
+	}
+	if m.Image == "" {
+		m.Image = site.LogoPath
+	}
+	m.Image = Absolute(baseURL, m.Image)
+	if page.CanonicalURL != "" {
+		m.URL = page.CanonicalURL
+	}
+	return m
+}
+
+// Tags renders the metadata as <meta> and <link> tags for a page's <head>
+func (m Meta) Tags() string {
+	var b strings.Builder
+	tag := func(attr, key, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "\t<meta %s=\"%s\" content=\"%s\">\n", attr, key, html.EscapeString(value))
+		}
+	}
+
+	tag("name", "description", m.Description)
+	if m.NoIndex {
+		tag("name", "robots", "noindex")
+	}
+	if m.URL != "" {
+		fmt.Fprintf(&b, "\t<link rel=\"canonical\" href=\"%s\">\n", html.EscapeString(m.URL))
+	}
+
+	ogType := m.Type
+	if ogType == "" {
+		ogType = "website"
+	}
+	tag("property", "og:type", ogType)
+	tag("property", "og:title", m.Title)
+	tag("property", "og:site_name", m.SiteName)
+	tag("property", "og:description", m.Description)
+	tag("property", "og:url", m.URL)
+	tag("property", "og:image", m.Image)
+
+	card := "summary"
+	if m.Image != "" {
+		card = "summary_large_image"
+	}
+	tag("name", "twitter:card", card)
+	tag("name", "twitter:title", m.Title)
+	tag("name", "twitter:description", m.Description)
+	tag("name", "twitter:image", m.Image)
+	return b.String()
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: MIT
+package seo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+func TestForPageFallsBackToSiteDefaults(t *testing.T) {
+	site := &models.Site{Subdomain: "camp", SiteTitle: "Cat Camp", SiteTagline: "Meow at the playa", LogoPath: "/assets/logo.png"}
+	page := &models.Page{Slug: "/about", Title: "About"}
+
+	m := ForPage(site, page, "https://camp.example.org")
+	if m.Description != "Meow at the playa" || m.Image != "https://camp.example.org/assets/logo.png" {
+		t.Errorf("Expected site defaults, got %+v", m)
+	}
+	if m.URL != "https://camp.example.org/about" || m.SiteName != "Cat Camp" {
+		t.Errorf("Unexpected URL or site name: %+v", m)
+	}
+
+	page.MetaDescription = "All about us"
+	page.SocialImage = "https://cdn.example.org/team.jpg"
+	page.CanonicalURL = "https://other.example.org/about"
+	m = ForPage(site, page, "https://camp.example.org")
+	if m.Description != "All about us" || m.Image != "https://cdn.example.org/team.jpg" || m.URL != "https://other.example.org/about" {
+		t.Errorf("Expected page values to win, got %+v", m)
+	}
+}
+
+func TestTags(t *testing.T) {
+	m := Meta{Title: `Tom & "Jerry"`, Description: "Hi", URL: "https://camp.example.org/", Image: "https://camp.example.org/a.png", NoIndex: true}
+	out := m.Tags()
+	for _, want := range []string{
+		`<meta name="description" content="Hi">`,
+		`<meta name="robots" content="noindex">`,
+		`<link rel="canonical" href="https://camp.example.org/">`,
+		`<meta property="og:title" content="Tom &amp; &#34;Jerry&#34;">`,
+		`<meta property="og:type" content="website">`,
+		`<meta name="twitter:card" content="summary_large_image">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected tags to contain %s, got:\n%s", want, out)
+		}
+	}
+
+	if out := (Meta{Title: "Plain"}).Tags(); strings.Contains(out, "robots") || !strings.Contains(out, `content="summary"`) {
+		t.Errorf("Expected an indexable summary card, got:\n%s", out)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	if _, err := ValidateDescription(strings.Repeat("a", MaxDescriptionLength+1)); !errors.Is(err, ErrDescriptionTooLong) {
+		t.Errorf("Expected ErrDescriptionTooLong, got %v", err)
+	}
+	if got, _ := ValidateDescription("  two\n lines "); got != "two lines" {
+		t.Errorf("Expected whitespace to be collapsed, got %q", got)
+	}
+	if _, err := ValidateImage("javascript:alert(1)"); !errors.Is(err, ErrInvalidImage) {
+		t.Errorf("Expected ErrInvalidImage, got %v", err)
+	}
+	if _, err := ValidateCanonical("/about"); !errors.Is(err, ErrInvalidCanonical) {
+		t.Errorf("Expected ErrInvalidCanonical, got %v", err)
+	}
+}
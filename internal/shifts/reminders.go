@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: MIT
+package shifts
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/email"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+)
+
+// Reminder queues reminder emails for volunteers shortly before their shifts.
+// Emails go through the outbound email queue, which delivers them with the
+// configured EmailService and retries failures.
+type Reminder struct {
+	DB           *gorm.DB
+	Key          []byte                         // Signing key for cancel links
+	SiteURL      func(site *models.Site) string // Base URL for cancel links, e.g. "https://mycamp.example.com"
+	Lead         time.Duration                  // How long before a shift to send the reminder
+	PollInterval time.Duration
+	ticker       *time.Ticker
+	done         chan bool
+	stopChan     chan bool
+}
+
+// NewReminder creates a new shift reminder worker
+func NewReminder(db *gorm.DB, key []byte, siteURL func(site *models.Site) string) *Reminder {
+	return &Reminder{
+		DB:           db,
+		Key:          key,
+		SiteURL:      siteURL,
+		Lead:         24 * time.Hour,
+		PollInterval: 10 * time.Minute,
+		done:         make(chan bool, 1),
+		stopChan:     make(chan bool, 1),
+	}
+}
+
+// Start begins checking for due reminders in a goroutine
+// Returns a done channel that will be signalled when the worker stops
+func (r *Reminder) Start() chan bool {
+	go func() {
+		r.ticker = time.NewTicker(r.PollInterval)
+		defer r.ticker.Stop()
+
+		if _, err := r.SendDue(time.Now()); err != nil {
+			log.Printf("shift reminders: %v\n", err)
+		}
+
+		for {
+			select {
+			case <-r.stopChan:
+				r.done <- true
+				return
+			case <-r.ticker.C:
+				if _, err := r.SendDue(time.Now()); err != nil {
+					log.Printf("shift reminders: %v\n", err)
+				}
+			}
+		}
+	}()
+
+	return r.done
+}
+
+// Stop stops the reminder worker
+func (r *Reminder) Stop() {
+	select {
+	case r.stopChan <- true:
+	default:
+	}
+}
+
+// SendDue queues a reminder for every sign-up whose shift starts within Lead of
+// now and hasn't been reminded yet. It returns how many reminders were queued.
+func (r *Reminder) SendDue(now time.Time) (int, error) {
+	var due []models.ShiftSignup
+	if err := r.DB.Joins("JOIN shifts ON shifts.id = shift_signups.shift_id AND shifts.deleted_at IS NULL").
+		Preload("Shift.Site").
+		Where("shift_signups.reminder_sent_at IS NULL").
+		Where("shifts.starts_at > ? AND shifts.starts_at <= ?", now, now.Add(r.Lead)).
+		Find(&due).Error; err != nil {
+		return 0, fmt.Errorf("failed to load due reminders: %w", err)
+	}
+
+	sent := 0
+	for i := range due {
+		signup := &due[i]
+		shift := &signup.Shift
+
+		// People who signed up inside the reminder window already have the
+		// details in their confirmation email
+		if signup.CreatedAt.Before(shift.StartsAt.Add(-r.Lead)) {
+			cancelURL := ""
+			if r.SiteURL != nil {
+				cancelURL = CancelURL(r.Key, r.SiteURL(&shift.Site), signup)
+			}
+			subject, body := ReminderEmail(&shift.Site, shift, signup, cancelURL)
+			if err := email.Enqueue(r.DB, signup.Email, subject, body); err != nil {
+				log.Printf("shift reminders: signup %d: %v\n", signup.ID, err)
+				continue
+			}
+			sent++
+		}
+
+		if err := r.DB.Model(signup).Update("reminder_sent_at", now).Error; err != nil {
+			log.Printf("shift reminders: failed to mark signup %d: %v\n", signup.ID, err)
+		}
+	}
+	return sent, nil
+}
@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: MIT
+
+// Package shifts handles volunteer shift sign-ups, cancel links and reminders.
+package shifts
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/events"
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sign-up errors shown to volunteers
+var (
+	ErrShiftFull       = errors.New("this shift is full")
+	ErrShiftStarted    = errors.New("this shift has already started")
+	ErrAlreadySignedUp = errors.New("you're already signed up for this shift")
+)
+
+// Slot is a shift together with how many volunteers have signed up
+type Slot struct {
+	Shift  models.Shift
+	Filled int
+}
+
+// SpotsLeft returns how many more volunteers the shift can take
+func (s Slot) SpotsLeft() int {
+	if left := s.Shift.Capacity - s.Filled; left > 0 {
+		return left
+	}
+	return 0
+}
+
+// Upcoming returns a site's shifts that haven't started yet, soonest first, with
+// sign-up counts. If role is non-empty only shifts with that role are returned.
+func Upcoming(db *gorm.DB, siteID uint, role string, now time.Time) ([]Slot, error) {
+	query := db.Where("site_id = ? AND starts_at > ?", siteID, now)
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	var list []models.Shift
+	if err := query.Order("starts_at ASC").Find(&list).Error; err != nil {
+		return nil, fmt.Errorf("failed to load shifts: %w", err)
+	}
+	return SlotsFor(db, list)
+}
+
+// SlotsFor pairs shifts with their current sign-up counts
+func SlotsFor(db *gorm.DB, list []models.Shift) ([]Slot, error) {
+	slots := make([]Slot, len(list))
+	if len(list) == 0 {
+		return slots, nil
+	}
+
+	ids := make([]uint, len(list))
+	for i, s := range list {
+		ids[i] = s.ID
+	}
+	var counts []struct {
+		ShiftID uint
+		Count   int
+	}
+	if err := db.Model(&models.ShiftSignup{}).Select("shift_id, COUNT(*) AS count").
+		Where("shift_id IN ?", ids).Group("shift_id").Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count sign-ups: %w", err)
+	}
+	byShift := make(map[uint]int, len(counts))
+	for _, c := range counts {
+		byShift[c.ShiftID] = c.Count
+	}
+
+	for i, s := range list {
+		slots[i] = Slot{Shift: s, Filled: byShift[s.ID]}
+	}
+	return slots, nil
+}
+
+// signUpAttempts is how many times SignUp tries a sign-up that SQLite turned
+// away because another one was being written at the same moment
+const signUpAttempts = 10
+
+// SignUp adds a volunteer to a shift, enforcing capacity. On MySQL the
+// shift's row is locked (SELECT ... FOR UPDATE) before sign-ups are counted,
+// so concurrent sign-ups for a shift wait their turn and two people can't
+// take the last spot at once. SQLite ignores the lock and lets only one of
+// two overlapping transactions write; the other fails as busy and is tried
+// again from the start.
+func SignUp(db *gorm.DB, shift *models.Shift, name, email, ip string, now time.Time) (*models.ShiftSignup, error) {
+	if !shift.StartsAt.After(now) {
+		return nil, ErrShiftStarted
+	}
+
+	var signup *models.ShiftSignup
+	var err error
+	for attempt := 1; attempt <= signUpAttempts; attempt++ {
+		signup, err = signUp(db, shift, name, email, ip)
+		if err == nil || !isBusy(err) {
+			break
+		}
+		time.Sleep(time.Duration(attempt) * 10 * time.Millisecond)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return signup, nil
+}
+
+// signUp makes one attempt at a sign-up in a transaction
+func signUp(db *gorm.DB, shift *models.Shift, name, email, ip string) (*models.ShiftSignup, error) {
+	signup := &models.ShiftSignup{
+		SiteID:    shift.SiteID,
+		ShiftID:   shift.ID,
+		Name:      name,
+		Email:     email,
+		IPAddress: ip,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Capacity may have changed since the shift was loaded. On MySQL this
+		// also locks the row until the sign-up is saved.
+		var locked models.Shift
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, shift.ID).Error; err != nil {
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&models.ShiftSignup{}).
+			Where("shift_id = ? AND LOWER(email) = ?", shift.ID, strings.ToLower(email)).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrAlreadySignedUp
+		}
+
+		var filled int64
+		if err := tx.Model(&models.ShiftSignup{}).Where("shift_id = ?", shift.ID).Count(&filled).Error; err != nil {
+			return err
+		}
+		if int(filled) >= locked.Capacity {
+			return ErrShiftFull
+		}
+
+		return tx.Create(signup).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signup, nil
+}
+
+// isBusy reports whether err is SQLite refusing a write because another
+// transaction holds the database (SQLITE_BUSY or SQLITE_LOCKED)
+func isBusy(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "database table is locked")
+}
+
+// CancelToken signs a sign-up so the volunteer can cancel it from an emailed
+// link without logging in
+func CancelToken(key []byte, signup *models.ShiftSignup) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "shift-signup|%d|%s", signup.ID, strings.ToLower(signup.Email))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidCancelToken reports whether token was issued by CancelToken for signup
+func ValidCancelToken(key []byte, signup *models.ShiftSignup, token string) bool {
+	return hmac.Equal([]byte(token), []byte(CancelToken(key, signup)))
+}
+
+// CancelURL returns the link a volunteer follows to cancel a sign-up.
+// siteURL is the site's base URL, e.g. "https://mycamp.example.com".
+func CancelURL(key []byte, siteURL string, signup *models.ShiftSignup) string {
+	return fmt.Sprintf("%s/shift-signups/%d/cancel?token=%s", siteURL, signup.ID, CancelToken(key, signup))
+}
+
+// When formats a shift's time window for display, e.g. "Sat, Aug 23, 2025, 9:00 AM – 1:00 PM"
+func When(shift *models.Shift) string {
+	loc := events.Location()
+	occ := events.Occurrence{Event: &models.Event{}, Start: shift.StartsAt.In(loc), End: shift.EndsAt.In(loc)}
+	return occ.When()
+}
+
+// ConfirmationEmail builds the email sent when a volunteer signs up
+func ConfirmationEmail(site *models.Site, shift *models.Shift, signup *models.ShiftSignup, cancelURL string) (string, string) {
+	subject := fmt.Sprintf("You're signed up: %s", shift.Role)
+	body := fmt.Sprintf(`Hi %s,
+
+Thanks for volunteering with %s! You're signed up for:
+
+  %s
+  %s
+`, signup.Name, SiteName(site), shift.Role, When(shift))
+	if shift.Description != "" {
+		body += "\n" + shift.Description + "\n"
+	}
+	body += fmt.Sprintf(`
+We'll send you a reminder before your shift.
+
+If you can't make it, please cancel so someone else can take your spot:
+%s
+`, cancelURL)
+	return subject, body
+}
+
+// SiteName returns the name to use for a site in emails
+func SiteName(site *models.Site) string {
+	if site.SiteTitle != "" {
+		return site.SiteTitle
+	}
+	return site.Subdomain
+}
+
+// ReminderEmail builds the email sent shortly before a shift
+func ReminderEmail(site *models.Site, shift *models.Shift, signup *models.ShiftSignup, cancelURL string) (string, string) {
+	subject := fmt.Sprintf("Reminder: %s shift", shift.Role)
+	body := fmt.Sprintf(`Hi %s,
+
+This is a reminder of your upcoming volunteer shift with %s:
+
+  %s
+  %s
+`, signup.Name, SiteName(site), shift.Role, When(shift))
+	if shift.Description != "" {
+		body += "\n" + shift.Description + "\n"
+	}
+	body += fmt.Sprintf(`
+If you can no longer make it, please cancel:
+%s
+`, cancelURL)
+	return subject, body
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: MIT
+package shifts
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupShiftsTestDB(t *testing.T) (*gorm.DB, *models.Shift) {
+	testDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := testDB.AutoMigrate(&models.Site{}, &models.Shift{}, &models.ShiftSignup{}, &models.OutboundEmail{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	site := &models.Site{ID: 1, Subdomain: "test", OwnerID: 1, SiteDir: "/tmp/test", SiteTitle: "Test Camp"}
+	testDB.Create(site)
+	start := time.Now().Add(72 * time.Hour)
+	shift := &models.Shift{SiteID: site.ID, Role: "Kitchen", StartsAt: start, EndsAt: start.Add(3 * time.Hour), Capacity: 2}
+	testDB.Create(shift)
+	return testDB, shift
+}
+
+func TestSignUpEnforcesCapacity(t *testing.T) {
+	testDB, shift := setupShiftsTestDB(t)
+	now := time.Now()
+
+	if _, err := SignUp(testDB, shift, "A", "a@example.com", "", now); err != nil {
+		t.Fatalf("First sign-up failed: %v", err)
+	}
+	if _, err := SignUp(testDB, shift, "A again", "A@Example.com", "", now); !errors.Is(err, ErrAlreadySignedUp) {
+		t.Errorf("Expected ErrAlreadySignedUp for duplicate email, got %v", err)
+	}
+	if _, err := SignUp(testDB, shift, "B", "b@example.com", "", now); err != nil {
+		t.Fatalf("Second sign-up failed: %v", err)
+	}
+	if _, err := SignUp(testDB, shift, "C", "c@example.com", "", now); !errors.Is(err, ErrShiftFull) {
+		t.Errorf("Expected ErrShiftFull, got %v", err)
+	}
+
+	slots, err := Upcoming(testDB, shift.SiteID, "", now)
+	if err != nil || len(slots) != 1 {
+		t.Fatalf("Expected 1 upcoming shift, got %d (%v)", len(slots), err)
+	}
+	if slots[0].Filled != 2 || slots[0].SpotsLeft() != 0 {
+		t.Errorf("Expected shift to be full, got %+v", slots[0])
+	}
+}
+
+func TestSignUpConcurrent(t *testing.T) {
+	// A file, so every connection shares one database as in production
+	testDB, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "shifts.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := testDB.AutoMigrate(&models.Shift{}, &models.ShiftSignup{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	start := time.Now().Add(72 * time.Hour)
+	shift := &models.Shift{SiteID: 1, Role: "Gate", StartsAt: start, EndsAt: start.Add(3 * time.Hour), Capacity: 3}
+	testDB.Create(shift)
+
+	const volunteers = 12
+	errs := make(chan error, volunteers)
+	var wg sync.WaitGroup
+	for i := 0; i < volunteers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := SignUp(testDB, shift, "Volunteer", fmt.Sprintf("v%d@example.com", i), "", time.Now())
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	// Anything but a full shift would be an error page for the volunteer
+	signedUp := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			signedUp++
+		case !errors.Is(err, ErrShiftFull):
+			t.Errorf("Expected sign-up to succeed or find the shift full, got %v", err)
+		}
+	}
+	var stored int64
+	testDB.Model(&models.ShiftSignup{}).Where("shift_id = ?", shift.ID).Count(&stored)
+	if signedUp != 3 || stored != 3 {
+		t.Errorf("Expected 3 sign-ups for 3 spots, got %d (%d stored)", signedUp, stored)
+	}
+}
+
+func TestSignUpAfterStart(t *testing.T) {
+	testDB, shift := setupShiftsTestDB(t)
+
+	if _, err := SignUp(testDB, shift, "Late", "late@example.com", "", shift.StartsAt.Add(time.Minute)); !errors.Is(err, ErrShiftStarted) {
+		t.Errorf("Expected ErrShiftStarted, got %v", err)
+	}
+}
+
+func TestCancelToken(t *testing.T) {
+	key := []byte("test-key")
+	signup := &models.ShiftSignup{ID: 4, Email: "a@example.com"}
+
+	token := CancelToken(key, signup)
+	if !ValidCancelToken(key, signup, token) {
+		t.Error("Expected token to validate")
+	}
+	if ValidCancelToken(key, &models.ShiftSignup{ID: 5, Email: "a@example.com"}, token) {
+		t.Error("Token must not validate for a different sign-up")
+	}
+	if ValidCancelToken([]byte("other-key"), signup, token) {
+		t.Error("Token must not validate with a different key")
+	}
+	if url := CancelURL(key, "https://camp.example.com", signup); !strings.HasPrefix(url, "https://camp.example.com/shift-signups/4/cancel?token=") {
+		t.Errorf("Unexpected cancel URL %s", url)
+	}
+}
+
+func TestReminderSendDue(t *testing.T) {
+	testDB, shift := setupShiftsTestDB(t)
+
+	signup := &models.ShiftSignup{SiteID: shift.SiteID, ShiftID: shift.ID, Name: "A", Email: "a@example.com"}
+	testDB.Create(signup)
+	// Backdate the sign-up so it predates the reminder window
+	testDB.Model(signup).Update("created_at", time.Now().Add(-7*24*time.Hour))
+
+	r := NewReminder(testDB, []byte("key"), func(*models.Site) string { return "https://camp.example.com" })
+
+	// Shift is 72h away: nothing due yet with a 24h lead
+	if n, err := r.SendDue(time.Now()); err != nil || n != 0 {
+		t.Fatalf("Expected no reminders yet, got %d (%v)", n, err)
+	}
+
+	// Two hours before the shift the reminder is due, and only sent once
+	at := shift.StartsAt.Add(-2 * time.Hour)
+	if n, err := r.SendDue(at); err != nil || n != 1 {
+		t.Fatalf("Expected 1 reminder, got %d (%v)", n, err)
+	}
+	if n, _ := r.SendDue(at.Add(time.Minute)); n != 0 {
+		t.Errorf("Expected reminder to be sent only once, got %d more", n)
+	}
+
+	var queued models.OutboundEmail
+	if err := testDB.First(&queued).Error; err != nil {
+		t.Fatalf("Expected reminder to be queued: %v", err)
+	}
+	if queued.To != "a@example.com" || !strings.Contains(queued.Body, "/shift-signups/") {
+		t.Errorf("Unexpected reminder email: %+v", queued)
+	}
+}
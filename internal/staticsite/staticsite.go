@@ -0,0 +1,319 @@
+// SPDX-License-Identifier: MIT
+
+// Package staticsite turns rendered pages into a self-contained folder of
+// HTML files that works offline: links and media use relative paths, only the
+// media the pages use is copied, and search runs in the browser.
+package staticsite
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/thatcatcamp/stinkykitty/internal/storage"
+)
+
+// SearchSlug is where the client-side search page lives in an export. It
+// matches the live site's search address, so search forms keep working.
+const SearchSlug = "/search"
+
+// PagePath returns the file a page is written to, relative to the export root.
+// Every page gets its own folder so relative links work the same for all depths.
+func PagePath(slug string) string {
+	slug = strings.Trim(slug, "/")
+	if slug == "" {
+		return "index.html"
+	}
+	return slug + "/index.html"
+}
+
+// relativeTo returns target (a path from the export root) as seen from the
+// page written to pageFile
+func relativeTo(pageFile, target string) string {
+	depth := strings.Count(pageFile, "/")
+	return strings.Repeat("../", depth) + target
+}
+
+var (
+	attrPattern   = regexp.MustCompile(`\b(href|src|action|poster)="(/[^"]*)"`)
+	srcsetPattern = regexp.MustCompile(`\bsrcset="([^"]*)"`)
+	urlPattern    = regexp.MustCompile(`url\((['"]?)(/assets/[^'")]+)(['"]?)\)`)
+)
+
+// Rewriter rewrites the site-relative links in rendered pages
+type Rewriter struct {
+	// BaseURL is the live site, e.g. "https://camp.example.org". Links to pages
+	// that aren't part of the export point there.
+	BaseURL string
+	// Pages holds the slugs of the exported pages
+	Pages map[string]bool
+	// Root, when set, makes links absolute paths under it (e.g. "/archive/2025")
+	// instead of relative files, for copies served by the site itself
+	Root string
+
+	assets map[string]bool
+}
+
+// NewRewriter creates a rewriter for an export of the given page slugs
+func NewRewriter(baseURL string, slugs []string) *Rewriter {
+	pages := make(map[string]bool, len(slugs))
+	for _, s := range slugs {
+		pages[s] = true
+	}
+	return &Rewriter{BaseURL: strings.TrimSuffix(baseURL, "/"), Pages: pages, assets: map[string]bool{}}
+}
+
+// Rewrite makes the links in a page written from slug relative. Links to
+// /assets/ files are recorded so Assets can list them for copying.
+func (r *Rewriter) Rewrite(slug string, doc []byte) []byte {
+	pageFile := PagePath(slug)
+	out := attrPattern.ReplaceAllStringFunc(string(doc), func(m string) string {
+		parts := attrPattern.FindStringSubmatch(m)
+		return parts[1] + `="` + r.link(pageFile, parts[2]) + `"`
+	})
+	out = srcsetPattern.ReplaceAllStringFunc(out, func(m string) string {
+		candidates := strings.Split(srcsetPattern.FindStringSubmatch(m)[1], ",")
+		for i, candidate := range candidates {
+			fields := strings.Fields(candidate)
+			if len(fields) > 0 && strings.HasPrefix(fields[0], "/") {
+				fields[0] = r.link(pageFile, fields[0])
+			}
+			candidates[i] = strings.Join(fields, " ")
+		}
+		return `srcset="` + strings.Join(candidates, ", ") + `"`
+	})
+	out = urlPattern.ReplaceAllStringFunc(out, func(m string) string {
+		parts := urlPattern.FindStringSubmatch(m)
+		return "url(" + parts[1] + r.link(pageFile, parts[2]) + parts[3] + ")"
+	})
+	return []byte(out)
+}
+
+// link rewrites one site-relative URL found in pageFile
+func (r *Rewriter) link(pageFile, ref string) string {
+	if strings.HasPrefix(ref, "//") {
+		return ref
+	}
+
+	p, suffix := ref, ""
+	if i := strings.IndexAny(p, "?#"); i >= 0 {
+		p, suffix = p[:i], p[i:]
+	}
+
+	if strings.HasPrefix(p, "/assets/") {
+		name := html.UnescapeString(strings.TrimPrefix(p, "/assets/"))
+		if name == "" || strings.Contains(name, "..") {
+			return r.BaseURL + ref
+		}
+		r.assets[name] = true
+		if r.Root != "" {
+			return r.Root + p
+		}
+		return relativeTo(pageFile, "assets/"+strings.TrimPrefix(p, "/assets/"))
+	}
+
+	slug := "/" + strings.Trim(path.Clean(p), "/")
+	if !r.Pages[slug] {
+		return r.BaseURL + ref
+	}
+	// Static files can't use query strings, but keep fragments for in-page links
+	fragment := ""
+	if i := strings.Index(suffix, "#"); i >= 0 {
+		fragment = suffix[i:]
+	}
+	if r.Root != "" {
+		if slug == "/" {
+			return r.Root + "/" + fragment
+		}
+		return r.Root + slug + fragment
+	}
+	return relativeTo(pageFile, PagePath(slug)) + fragment
+}
+
+// Assets lists the /assets/ files referenced by the pages rewritten so far,
+// relative to the uploads directory
+func (r *Rewriter) Assets() []string {
+	names := make([]string, 0, len(r.assets))
+	for name := range r.assets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CopyAssets copies the named files, read with open, into the export's
+// assets folder. Files that no longer exist are skipped and returned.
+func CopyAssets(open func(name string) (io.ReadCloser, error), outDir string, names []string) (missing []string, err error) {
+	for _, name := range names {
+		dst := filepath.Join(outDir, "assets", filepath.FromSlash(name))
+		if err := copyAsset(open, name, dst); err != nil {
+			if os.IsNotExist(err) || errors.Is(err, storage.ErrNotFound) {
+				missing = append(missing, name)
+				continue
+			}
+			return missing, fmt.Errorf("failed to copy %s: %w", name, err)
+		}
+	}
+	return missing, nil
+}
+
+// OpenDir returns an open function for CopyAssets reading files from dir
+func OpenDir(dir string) func(name string) (io.ReadCloser, error) {
+	return func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+	}
+}
+
+func copyAsset(open func(name string) (io.ReadCloser, error), name, dst string) error {
+	in, err := open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// WritePage writes a rewritten page to its file in the export
+func WritePage(outDir, slug string, doc []byte) error {
+	dst := filepath.Join(outDir, filepath.FromSlash(PagePath(slug)))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create folder for %s: %w", slug, err)
+	}
+	if err := os.WriteFile(dst, doc, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", slug, err)
+	}
+	return nil
+}
+
+// SearchEntry is one page in the client-side search index
+type SearchEntry struct {
+	Title string `json:"title"`
+	URL   string `json:"url"` // Relative to the export root
+	Text  string `json:"text"`
+}
+
+var (
+	scriptPattern = regexp.MustCompile(`(?is)<(script|style)\b.*?</(script|style)>`)
+	tagPattern    = regexp.MustCompile(`<[^>]*>`)
+)
+
+// PlainText extracts the searchable text from an HTML fragment
+func PlainText(fragment string) string {
+	text := scriptPattern.ReplaceAllString(fragment, " ")
+	text = tagPattern.ReplaceAllString(text, " ")
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
+
+// WriteSearchIndex writes the index loaded by the search page. It's a script
+// rather than JSON because browsers won't fetch() files opened from disk.
+func WriteSearchIndex(outDir string, entries []SearchEntry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode search index: %w", err)
+	}
+	dst := filepath.Join(outDir, filepath.FromSlash(path.Dir(PagePath(SearchSlug))), "search-index.js")
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create search folder: %w", err)
+	}
+	script := "window.STATIC_SEARCH_INDEX = " + string(data) + ";\n"
+	if err := os.WriteFile(dst, []byte(script), 0644); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	return nil
+}
+
+// SearchPageBody is the body of the export's search page. It reads ?q= and
+// matches every word against the titles and text in search-index.js.
+const SearchPageBody = `<form method="GET" action="/search" style="display: flex; gap: 10px; margin-bottom: 20px;">
+	<input type="text" id="static-search-q" name="q" placeholder="Search pages..." style="flex: 1; padding: 10px; font-size: 16px;">
+	<button type="submit" class="btn">Search</button>
+</form>
+<div id="static-search-results"></div>
+<script src="search-index.js"></script>
+<script>
+(function() {
+	var q = new URLSearchParams(window.location.search).get('q') || '';
+	var input = document.getElementById('static-search-q');
+	var results = document.getElementById('static-search-results');
+	input.value = q;
+	var words = q.toLowerCase().split(/\s+/).filter(function(w) { return w; });
+	if (!words.length) return;
+
+	var matches = (window.STATIC_SEARCH_INDEX || []).filter(function(entry) {
+		var haystack = (entry.title + ' ' + entry.text).toLowerCase();
+		return words.every(function(w) { return haystack.indexOf(w) !== -1; });
+	});
+	if (!matches.length) {
+		results.textContent = 'No pages found.';
+		return;
+	}
+	matches.forEach(function(entry) {
+		var item = document.createElement('div');
+		item.style.marginBottom = '20px';
+		var link = document.createElement('a');
+		link.href = '../' + entry.url;
+		link.textContent = entry.title;
+		var heading = document.createElement('h3');
+		heading.style.margin = '0';
+		heading.appendChild(link);
+		var snippet = document.createElement('p');
+		snippet.style.margin = '4px 0';
+		var at = entry.text.toLowerCase().indexOf(words[0]);
+		var start = Math.max(0, at - 60);
+		snippet.textContent = (start > 0 ? '…' : '') + entry.text.substr(start, 200) + (start + 200 < entry.text.length ? '…' : '');
+		item.appendChild(heading);
+		item.appendChild(snippet);
+		results.appendChild(item);
+	});
+})();
+</script>`
+
+// ZipDir writes the contents of dir to w as a zip archive
+func ZipDir(w io.Writer, dir string) error {
+	zw := zip.NewWriter(w)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		f, err := zw.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		in, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		_, err = io.Copy(f, in)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to zip export: %w", err)
+	}
+	return zw.Close()
+}
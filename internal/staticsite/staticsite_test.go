@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: MIT
+package staticsite
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPagePath(t *testing.T) {
+	cases := map[string]string{
+		"/":             "index.html",
+		"/about":        "about/index.html",
+		"/info/arrival": "info/arrival/index.html",
+	}
+	for slug, want := range cases {
+		if got := PagePath(slug); got != want {
+			t.Errorf("PagePath(%q) = %q, want %q", slug, got, want)
+		}
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	r := NewRewriter("https://camp.example.org", []string{"/", "/about", "/info/arrival", SearchSlug})
+	doc := `<a href="/">Home</a>
+<a href="/about#team">About</a>
+<a href="/events">Events</a>
+<a href="//cdn.example.org/x.js">CDN</a>
+<img src="/assets/photo.jpg?v=2" srcset="/assets/photo-400.jpg 400w, /assets/photo.jpg 800w">
+<div style="background-image: url('/assets/hero.jpg')"></div>
+<form action="/search" method="GET"></form>`
+
+	got := string(r.Rewrite("/info/arrival", []byte(doc)))
+	for _, want := range []string{
+		`href="../../index.html"`,
+		`href="../../about/index.html#team"`,
+		`href="https://camp.example.org/events"`,
+		`href="//cdn.example.org/x.js"`,
+		`src="../../assets/photo.jpg"`,
+		`srcset="../../assets/photo-400.jpg 400w, ../../assets/photo.jpg 800w"`,
+		`url('../../assets/hero.jpg')`,
+		`action="../../search/index.html"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %s in rewritten page:\n%s", want, got)
+		}
+	}
+
+	if assets := r.Assets(); !reflect.DeepEqual(assets, []string{"hero.jpg", "photo-400.jpg", "photo.jpg"}) {
+		t.Errorf("Unexpected assets: %v", assets)
+	}
+}
+
+func TestRewriteUnderRoot(t *testing.T) {
+	r := NewRewriter("", []string{"/", "/about"})
+	r.Root = "/archive/2025"
+	got := string(r.Rewrite("/about", []byte(`<a href="/">Home</a><a href="/about">About</a><a href="/events">Events</a><img src="/assets/a.jpg">`)))
+	want := `<a href="/archive/2025/">Home</a><a href="/archive/2025/about">About</a><a href="/events">Events</a><img src="/archive/2025/assets/a.jpg">`
+	if got != want {
+		t.Errorf("Rewrite under root:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	got := PlainText(`<h2>Tea &amp; Cake</h2><script>var x = 1;</script><p>Served   at <b>noon</b></p>`)
+	if got != "Tea & Cake Served at noon" {
+		t.Errorf("Unexpected text: %q", got)
+	}
+}
+
+func TestCopyAssetsAndZip(t *testing.T) {
+	uploads, out := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(uploads, "photo.jpg"), []byte("jpeg"), 0644)
+	os.WriteFile(filepath.Join(uploads, "unused.jpg"), []byte("jpeg"), 0644)
+
+	missing, err := CopyAssets(OpenDir(uploads), out, []string{"photo.jpg", "gone.jpg"})
+	if err != nil {
+		t.Fatalf("CopyAssets failed: %v", err)
+	}
+	if !reflect.DeepEqual(missing, []string{"gone.jpg"}) {
+		t.Errorf("Expected gone.jpg to be reported missing, got %v", missing)
+	}
+	if err := WritePage(out, "/", []byte("<html></html>")); err != nil {
+		t.Fatalf("WritePage failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := ZipDir(&buf, out); err != nil {
+		t.Fatalf("ZipDir failed: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Invalid zip: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"assets/photo.jpg", "index.html"}) {
+		t.Errorf("Unexpected zip contents: %v", names)
+	}
+}
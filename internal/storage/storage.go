@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MIT
+
+// Package storage keeps media files on local disk or in an S3-compatible
+// bucket behind one interface, so the rest of the app doesn't care where
+// a site's files live.
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when a file doesn't exist
+	ErrNotFound = errors.New("file not found")
+	// ErrInvalidKey is returned for keys that could escape the storage root
+	ErrInvalidKey = errors.New("invalid storage key")
+	// ErrSignedURLUnsupported is returned by backends that can't hand out
+	// direct links, so callers serve the file themselves
+	ErrSignedURLUnsupported = errors.New("storage backend doesn't support signed URLs")
+)
+
+// ObjectInfo describes a stored file
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage stores files by key, a slash-separated relative path such as
+// "uploads/3f2a.jpg"
+type Storage interface {
+	// Put stores a file, replacing any file with the same key. A negative
+	// size means the size isn't known in advance.
+	Put(key string, r io.Reader, size int64, contentType string) error
+	// Get opens a file for reading. The caller must close it.
+	Get(key string) (io.ReadCloser, *ObjectInfo, error)
+	// Delete removes a file. Deleting a missing file isn't an error.
+	Delete(key string) error
+	// Stat describes a file without reading it
+	Stat(key string) (*ObjectInfo, error)
+	// SignedURL returns a link that fetches the file directly until it expires
+	SignedURL(key string, expires time.Duration) (string, error)
+}
+
+// CleanKey checks a key and returns it without a leading slash
+func CleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || strings.ContainsAny(key, "\\\x00") {
+		return "", ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	if strings.IndexFunc(key, func(r rune) bool { return r < 0x20 || r == 0x7f }) >= 0 {
+		return "", ErrInvalidKey
+	}
+	return key, nil
+}
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: MIT
+package storage
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// exerciseStorage runs the same round trip against any backend
+func exerciseStorage(t *testing.T, s Storage) {
+	t.Helper()
+
+	if err := s.Put("uploads/hello.txt", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Put("uploads/thumbs/unknown-size.txt", strings.NewReader("streamed"), -1, "text/plain"); err != nil {
+		t.Fatalf("Put with unknown size: %v", err)
+	}
+
+	info, err := s.Stat("uploads/hello.txt")
+	if err != nil || info.Size != 5 {
+		t.Fatalf("Stat = %+v, %v", info, err)
+	}
+
+	r, info, err := s.Get("uploads/hello.txt")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "hello" || !strings.HasPrefix(info.ContentType, "text/plain") {
+		t.Errorf("Get = %q (%s)", data, info.ContentType)
+	}
+
+	if err := s.Delete("uploads/hello.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Stat("uploads/hello.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if _, _, err := s.Get("uploads/hello.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound from Get after delete, got %v", err)
+	}
+	if err := s.Delete("uploads/hello.txt"); err != nil {
+		t.Errorf("Deleting a missing file should succeed, got %v", err)
+	}
+	s.Delete("uploads/thumbs/unknown-size.txt")
+}
+
+func TestCleanKey(t *testing.T) {
+	for _, bad := range []string{"", "/", "../etc/passwd", "uploads/../../x", "uploads//x", `uploads\x`, "a/./b"} {
+		if _, err := CleanKey(bad); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("CleanKey(%q) should be rejected", bad)
+		}
+	}
+	if got, err := CleanKey("/uploads/a.jpg"); err != nil || got != "uploads/a.jpg" {
+		t.Errorf("CleanKey = %q, %v", got, err)
+	}
+}
+
+func TestLocal_RoundTrip(t *testing.T) {
+	s := NewLocal(t.TempDir())
+	exerciseStorage(t, s)
+	if _, err := s.SignedURL("uploads/a.jpg", time.Minute); !errors.Is(err, ErrSignedURLUnsupported) {
+		t.Errorf("Expected local storage to refuse signed URLs, got %v", err)
+	}
+}
+
+// fakeS3 is an in-memory bucket that checks requests are signed
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") || r.Header.Get("X-Amz-Date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := r.URL.Path
+	switch r.Method {
+	case http.MethodPut:
+		if r.ContentLength < 0 {
+			w.WriteHeader(http.StatusLengthRequired)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = data
+		f.types[key] = r.Header.Get("Content-Type")
+	case http.MethodGet, http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", f.types[key])
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3_RoundTripAgainstFakeServer(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s, err := NewS3(S3Config{Endpoint: server.URL, Bucket: "media", AccessKey: "key", SecretKey: "secret", PathStyle: true})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	exerciseStorage(t, s)
+
+	s.Put("uploads/a.jpg", strings.NewReader("x"), 1, "image/jpeg")
+	if _, ok := fake.objects["/media/uploads/a.jpg"]; !ok {
+		t.Errorf("Expected a path-style key, got %v", fake.objects)
+	}
+	link, err := s.SignedURL("uploads/a.jpg", time.Hour)
+	if err != nil || !strings.HasPrefix(link, server.URL+"/media/uploads/a.jpg?") || !strings.Contains(link, "X-Amz-Signature=") {
+		t.Errorf("SignedURL = %s, %v", link, err)
+	}
+}
+
+// TestS3_MinIO runs against a real S3-compatible server when one is configured,
+// e.g. docker run -p 9000:9000 minio/minio server /data, with a bucket created
+func TestS3_MinIO(t *testing.T) {
+	endpoint := os.Getenv("STINKY_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("set STINKY_TEST_S3_ENDPOINT, STINKY_TEST_S3_BUCKET, STINKY_TEST_S3_ACCESS_KEY and STINKY_TEST_S3_SECRET_KEY to test against MinIO")
+	}
+	s, err := NewS3(S3Config{
+		Endpoint:  endpoint,
+		Bucket:    os.Getenv("STINKY_TEST_S3_BUCKET"),
+		AccessKey: os.Getenv("STINKY_TEST_S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("STINKY_TEST_S3_SECRET_KEY"),
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	exerciseStorage(t, s)
+
+	if err := s.Put("uploads/signed.txt", strings.NewReader("signed"), 6, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	defer s.Delete("uploads/signed.txt")
+	link, err := s.SignedURL("uploads/signed.txt", time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+	resp, err := http.Get(link)
+	if err != nil {
+		t.Fatalf("GET signed URL: %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(data) != "signed" {
+		t.Errorf("Signed URL returned %d %q", resp.StatusCode, data)
+	}
+}
@@ -11,9 +11,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/thatcatcamp/stinkykitty/internal/media"
 )
 
-// SaveUploadedFile saves an uploaded file and returns its web-accessible path
+// SaveUploadedFile saves an uploaded image or allowed document and returns its
+// web-accessible path
 func SaveUploadedFile(file *multipart.FileHeader, siteDir string) (string, error) {
 	// Create uploads directory if it doesn't exist
 	uploadsDir := filepath.Join(siteDir, "uploads")
@@ -62,7 +65,10 @@ func SaveUploadedFile(file *multipart.FileHeader, siteDir string) (string, error
 		}
 	}
 	if !isValid {
-		return "", fmt.Errorf("invalid file type: %s (only images allowed)", contentType)
+		// Documents must be of a type in media.document_types and look like one
+		if _, err := media.CheckDocument(file.Filename, buffer[:n]); err != nil {
+			return "", err
+		}
 	}
 
 	// Reset file pointer to beginning after validation
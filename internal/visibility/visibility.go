@@ -0,0 +1,239 @@
+// SPDX-License-Identifier: MIT
+
+// Package visibility controls who can see a page or a whole site: everyone,
+// visitors who know the password, or the site's members. Sites can also be
+// limited to their IP allowlist. Unlocked passwords are remembered in a
+// signed viewer cookie.
+package visibility
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+// Page visibility modes
+const (
+	Public   = "public"
+	Password = "password"
+	Members  = "members"
+)
+
+// IPAllowlist limits a whole site to visitors from its allowed IP ranges. It
+// only applies to sites, not pages.
+const IPAllowlist = "ip"
+
+// SiteGrantID is the grant that unlocks a whole password-protected site
+const SiteGrantID = 0
+
+// CookieName is the cookie holding a visitor's unlocked password pages
+const CookieName = "stinky_viewer"
+
+// CookieLifetime is how long an unlocked page stays unlocked
+const CookieLifetime = 30 * 24 * time.Hour
+
+// maxGrants bounds the number of unlocked pages kept in the cookie. The site
+// grant doesn't count towards it.
+const maxGrants = 20
+
+var (
+	// ErrInvalidMode is returned for an unknown visibility mode
+	ErrInvalidMode = errors.New("visibility must be public, password or members")
+	// ErrPasswordRequired is returned when a password page has no password set
+	ErrPasswordRequired = errors.New("set a password for password-protected pages")
+	// ErrHomepage is returned when restricting the homepage
+	ErrHomepage = errors.New("the homepage is always public")
+	// ErrSitePasswordRequired is returned when a password site has no password set
+	ErrSitePasswordRequired = errors.New("set a password for password-protected sites")
+	// ErrAllowlistRequired is returned when limiting a site to an empty IP allowlist
+	ErrAllowlistRequired = errors.New("add IP ranges to the site's allowlist before limiting the site to them")
+	// ErrInvalidSiteMode is returned for an unknown site visibility mode
+	ErrInvalidSiteMode = errors.New("site visibility must be public, password, members or ip")
+)
+
+// Valid reports whether mode is a visibility mode
+func Valid(mode string) bool {
+	return mode == Public || mode == Password || mode == Members
+}
+
+// ValidSite reports whether mode is a site visibility mode
+func ValidSite(mode string) bool {
+	return Valid(mode) || mode == IPAllowlist
+}
+
+// Label returns a human-readable name for a visibility mode
+func Label(mode string) string {
+	switch mode {
+	case Password:
+		return "Password"
+	case Members:
+		return "Members only"
+	case IPAllowlist:
+		return "IP allowlist"
+	default:
+		return "Public"
+	}
+}
+
+// IsPublic reports whether anyone may see a page. Pages saved before
+// visibility existed have no mode and are public.
+func IsPublic(page *models.Page) bool {
+	return page.Visibility == "" || page.Visibility == Public
+}
+
+// IsPublicSite reports whether anyone may visit a site's public pages
+func IsPublicSite(site *models.Site) bool {
+	return site.Visibility == "" || site.Visibility == Public
+}
+
+// PasswordVersion identifies a page's current password without revealing it,
+// so changing the password locks out visitors who unlocked the old one
+func PasswordVersion(passwordHash string) string {
+	sum := sha256.Sum256([]byte(passwordHash))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Grant records a page (or site) a visitor unlocked
+type Grant struct {
+	Version    string // PasswordVersion of the password it was unlocked with
+	UnlockedAt int64  // Unix time, so the oldest can be dropped when the cookie is full
+}
+
+// Grants maps unlocked page IDs, or SiteGrantID, to their grants
+type Grants map[uint]Grant
+
+// Unlock records that a page (or the site, with SiteGrantID) was unlocked
+// with a password version
+func (g Grants) Unlock(id uint, version string, now time.Time) {
+	g[id] = Grant{Version: version, UnlockedAt: now.Unix()}
+}
+
+// Has reports whether a page (or the site) was unlocked with a password version
+func (g Grants) Has(id uint, version string) bool {
+	grant, ok := g[id]
+	return ok && grant.Version == version
+}
+
+// Viewer is who is looking at a site's pages
+type Viewer struct {
+	Member bool // Logged in with access to the site
+	Grants Grants
+}
+
+// CanView reports whether the viewer may see a page's content
+func (v *Viewer) CanView(page *models.Page) bool {
+	switch page.Visibility {
+	case Password:
+		return v.Member || (page.PasswordHash != "" && v.Grants.Has(page.ID, PasswordVersion(page.PasswordHash)))
+	case Members:
+		return v.Member
+	default:
+		return true
+	}
+}
+
+// CanEnterSite reports whether the viewer may see a password-protected or
+// members-only site. IP allowlists are checked against the request instead.
+func (v *Viewer) CanEnterSite(site *models.Site) bool {
+	switch site.Visibility {
+	case Password:
+		return v.Member || (site.PasswordHash != "" && v.Grants.Has(SiteGrantID, PasswordVersion(site.PasswordHash)))
+	case Members:
+		return v.Member
+	default:
+		return true
+	}
+}
+
+// CanList reports whether a page should appear in menus and listings for the
+// viewer. Password pages are listed so visitors can reach the password prompt.
+func (v *Viewer) CanList(page *models.Page) bool {
+	return page.Visibility != Members || v.Member
+}
+
+// EncodeGrants signs a site's grants into a cookie value. Beyond maxGrants
+// pages, the ones unlocked longest ago are dropped; the site grant is always
+// kept, or visitors to a password-protected site would be locked out of it.
+func EncodeGrants(key []byte, siteID uint, grants Grants, expires time.Time) string {
+	ids := make([]uint, 0, len(grants))
+	for id := range grants {
+		if id != SiteGrantID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := grants[ids[i]], grants[ids[j]]
+		if a.UnlockedAt != b.UnlockedAt {
+			return a.UnlockedAt > b.UnlockedAt
+		}
+		return ids[i] > ids[j]
+	})
+	if len(ids) > maxGrants {
+		ids = ids[:maxGrants]
+	}
+	if _, ok := grants[SiteGrantID]; ok {
+		ids = append(ids, SiteGrantID)
+	}
+
+	parts := []string{strconv.FormatUint(uint64(siteID), 10), strconv.FormatInt(expires.Unix(), 10)}
+	for _, id := range ids {
+		parts = append(parts, fmt.Sprintf("%d:%s:%d", id, grants[id].Version, grants[id].UnlockedAt))
+	}
+	payload := base64.RawURLEncoding.EncodeToString([]byte(strings.Join(parts, "|")))
+	return payload + "." + sign(key, payload)
+}
+
+// DecodeGrants verifies a cookie value and returns its grants for the site.
+// Tampered, expired or other sites' cookies yield no grants.
+func DecodeGrants(key []byte, siteID uint, value string, now time.Time) Grants {
+	grants := Grants{}
+	payload, sig, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(sign(key, payload))) {
+		return grants
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return grants
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) < 2 || parts[0] != strconv.FormatUint(uint64(siteID), 10) {
+		return grants
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > expires {
+		return grants
+	}
+	for _, part := range parts[2:] {
+		// id:version:unlocked, or id:version in cookies from before unlock
+		// times were kept
+		fields := strings.Split(part, ":")
+		if len(fields) < 2 || len(fields) > 3 {
+			continue
+		}
+		id, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			continue
+		}
+		grant := Grant{Version: fields[1]}
+		if len(fields) == 3 {
+			grant.UnlockedAt, _ = strconv.ParseInt(fields[2], 10, 64)
+		}
+		grants[uint(id)] = grant
+	}
+	return grants
+}
+
+func sign(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: MIT
+package visibility
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thatcatcamp/stinkykitty/internal/models"
+)
+
+func TestGrantsRoundTrip(t *testing.T) {
+	key := []byte("test-key")
+	now := time.Now()
+	value := EncodeGrants(key, 1, Grants{7: {Version: "abc"}, 9: {Version: "def"}}, now.Add(time.Hour))
+
+	grants := DecodeGrants(key, 1, value, now)
+	if !grants.Has(7, "abc") || !grants.Has(9, "def") {
+		t.Errorf("Expected both grants back, got %v", grants)
+	}
+
+	cases := map[string]Grants{
+		"other site": DecodeGrants(key, 2, value, now),
+		"other key":  DecodeGrants([]byte("other"), 1, value, now),
+		"expired":    DecodeGrants(key, 1, value, now.Add(2*time.Hour)),
+		"tampered":   DecodeGrants(key, 1, "x"+value, now),
+	}
+	for name, got := range cases {
+		if len(got) != 0 {
+			t.Errorf("%s: expected no grants, got %v", name, got)
+		}
+	}
+}
+
+func TestEncodeGrantsKeepsSiteGrant(t *testing.T) {
+	key := []byte("test-key")
+	now := time.Now()
+
+	grants := Grants{}
+	grants.Unlock(SiteGrantID, "site", now.Add(-time.Hour))
+	// 21 pages, unlocked a minute apart; page 1 the longest ago
+	for id := uint(1); id <= 21; id++ {
+		grants.Unlock(id, "v", now.Add(time.Duration(id)*time.Minute))
+	}
+
+	got := DecodeGrants(key, 1, EncodeGrants(key, 1, grants, now.Add(time.Hour)), now)
+	if !got.Has(SiteGrantID, "site") {
+		t.Error("Expected the site grant to be kept")
+	}
+	if _, ok := got[1]; ok {
+		t.Error("Expected the page unlocked longest ago to be dropped")
+	}
+	if len(got) != maxGrants+1 {
+		t.Errorf("Expected %d pages and the site grant, got %d grants", maxGrants, len(got))
+	}
+	if !got.Has(21, "v") {
+		t.Error("Expected the latest unlocked page to be kept")
+	}
+}
+
+func TestCanView(t *testing.T) {
+	page := &models.Page{ID: 7, Visibility: Password, PasswordHash: "hash-1"}
+	visitor := &Viewer{Grants: Grants{7: {Version: PasswordVersion("hash-1")}}}
+	if !visitor.CanView(page) {
+		t.Error("Expected an unlocked page to be visible")
+	}
+
+	// Changing the password locks out earlier visitors
+	page.PasswordHash = "hash-2"
+	if visitor.CanView(page) {
+		t.Error("Expected a new password to lock the page again")
+	}
+
+	members := &models.Page{ID: 8, Visibility: Members}
+	if visitor.CanView(members) || visitor.CanList(members) {
+		t.Error("Members-only pages must be hidden from visitors")
+	}
+	if member := (&Viewer{Member: true}); !member.CanView(members) || !member.CanView(page) {
+		t.Error("Members can see every page")
+	}
+	if !visitor.CanView(&models.Page{}) {
+		t.Error("Pages without a visibility are public")
+	}
+}